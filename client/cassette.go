@@ -0,0 +1,233 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// redactedHeaders are the response headers whose values are not
+// stored in the cassettes, as they contain credentials. The secret
+// fields of the bodies, like passwords and tokens, are redacted too
+var redactedHeaders = []string{"Token", "Set-Cookie"}
+
+// Cassette is a recording of the requests sent to a server and the
+// responses it returned
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a request and its response
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string `json:"method"`
+	// URL contains the path and the query of the request, so the
+	// cassette can be replayed against any server address
+	URL  string `json:"url"`
+	Body string `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode int                 `json:"status_code"`
+	Header     map[string][]string `json:"header,omitempty"`
+	Body       string              `json:"body,omitempty"`
+}
+
+// LoadCassette reads a cassette from a file
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(b, &cassette); err != nil {
+		return nil, fmt.Errorf("invalid cassette %q: %w", path, err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to a file
+func (c *Cassette) Save(path string) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(c); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+func readRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	if req.GetBody == nil {
+		// the body can't be read twice, so it is replaced by a copy
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		return string(b), nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	b, err := io.ReadAll(body)
+	return string(b), err
+}
+
+// Recorder stores the interactions made through its transports in a
+// cassette
+type Recorder struct {
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Transport wraps the base transport, or the default one if base is
+// nil, so its interactions are recorded
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recordTransport{base: base, recorder: r}
+}
+
+// Cassette returns a copy of the interactions recorded so far
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]*Interaction{}, r.cassette.Interactions...)}
+}
+
+type recordTransport struct {
+	base     http.RoundTripper
+	recorder *Recorder
+}
+
+func (rt *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := rt.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	header := resp.Header.Clone()
+	for _, name := range redactedHeaders {
+		if header.Get(name) != "" {
+			header.Set(name, "[redacted]")
+		}
+	}
+
+	rt.recorder.mu.Lock()
+	defer rt.recorder.mu.Unlock()
+	rt.recorder.cassette.Interactions = append(rt.recorder.cassette.Interactions, &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.RequestURI(),
+			Body:   redactSecretFields(reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       redactSecretFields(string(respBody)),
+		},
+	})
+
+	return resp, nil
+}
+
+// ReplayTransport answers the requests with the responses of a
+// cassette instead of sending them. Each interaction is used once and
+// requests are matched by method, path, query and body, in the order
+// they were recorded. The bodies are compared with their secret fields
+// redacted, as they are stored in the cassettes
+type ReplayTransport struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+func NewReplayTransport(cassette *Cassette) *ReplayTransport {
+	return &ReplayTransport{
+		cassette: cassette,
+		used:     make([]bool, len(cassette.Interactions)),
+	}
+}
+
+func (rt *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	url := req.URL.RequestURI()
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	for i, interaction := range rt.cassette.Interactions {
+		if rt.used[i] || interaction.Request.Method != req.Method || interaction.Request.URL != url || interaction.Request.Body != redactSecretFields(body) {
+			continue
+		}
+		rt.used[i] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header(interaction.Response.Header).Clone(),
+			Body:          io.NopCloser(bytes.NewReader([]byte(interaction.Response.Body))),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no recorded interaction matches the request %s %s", req.Method, url)
+}
+
+// Remaining returns the number of interactions that haven't been
+// replayed
+func (rt *ReplayTransport) Remaining() int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	remaining := 0
+	for _, used := range rt.used {
+		if !used {
+			remaining++
+		}
+	}
+	return remaining
+}
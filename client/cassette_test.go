@@ -0,0 +1,133 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package client
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassette(t *testing.T) {
+	recordTestInteractions := func(t *testing.T) *Cassette {
+		base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := newTestResponse(http.StatusOK, map[string]string{"Token": "secrettoken", "X-Version-Id": "7.5.0"})
+			if req.Body != nil {
+				b, _ := io.ReadAll(req.Body)
+				resp.Body = io.NopCloser(strings.NewReader("echo " + string(b)))
+			} else {
+				resp.Body = io.NopCloser(strings.NewReader(req.URL.Path))
+			}
+			return resp, nil
+		})
+
+		recorder := NewRecorder()
+		transport := recorder.Transport(base)
+
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost:8065/api/v4/users/me?etag=1", nil)
+		resp, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		b, _ := io.ReadAll(resp.Body)
+		require.Equal(t, "/api/v4/users/me", string(b))
+
+		req, _ = http.NewRequest(http.MethodPost, "http://localhost:8065/api/v4/posts", strings.NewReader(`{"message":"hi"}`))
+		_, err = transport.RoundTrip(req)
+		require.NoError(t, err)
+
+		return recorder.Cassette()
+	}
+
+	t.Run("should record the interactions redacting the credentials", func(t *testing.T) {
+		cassette := recordTestInteractions(t)
+
+		require.Len(t, cassette.Interactions, 2)
+		assert.Equal(t, RecordedRequest{Method: http.MethodGet, URL: "/api/v4/users/me?etag=1"}, cassette.Interactions[0].Request)
+		assert.Equal(t, []string{"[redacted]"}, cassette.Interactions[0].Response.Header["Token"])
+		assert.Equal(t, []string{"7.5.0"}, cassette.Interactions[0].Response.Header["X-Version-Id"])
+		assert.Equal(t, `{"message":"hi"}`, cassette.Interactions[1].Request.Body)
+		assert.Equal(t, `echo {"message":"hi"}`, cassette.Interactions[1].Response.Body)
+	})
+
+	t.Run("should replay a saved cassette against any address", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cassette.json")
+		require.NoError(t, recordTestInteractions(t).Save(path))
+
+		cassette, err := LoadCassette(path)
+		require.NoError(t, err)
+		replayer := NewReplayTransport(cassette)
+		require.Equal(t, 2, replayer.Remaining())
+
+		req, _ := http.NewRequest(http.MethodPost, "http://other:9000/api/v4/posts", strings.NewReader(`{"message":"hi"}`))
+		resp, err := replayer.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		b, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, `echo {"message":"hi"}`, string(b))
+		assert.Equal(t, 1, replayer.Remaining())
+
+		// each interaction is only replayed once
+		_, err = replayer.RoundTrip(req)
+		require.EqualError(t, err, "no recorded interaction matches the request POST /api/v4/posts")
+
+		req, _ = http.NewRequest(http.MethodGet, "http://other:9000/api/v4/users/me?etag=1", nil)
+		resp, err = replayer.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, "7.5.0", resp.Header.Get("X-Version-Id"))
+		assert.Equal(t, 0, replayer.Remaining())
+	})
+
+	t.Run("should not match requests with a different body", func(t *testing.T) {
+		replayer := NewReplayTransport(recordTestInteractions(t))
+
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost:8065/api/v4/posts", strings.NewReader(`{"message":"bye"}`))
+		_, err := replayer.RoundTrip(req)
+		require.Error(t, err)
+		assert.Equal(t, 2, replayer.Remaining())
+	})
+
+	t.Run("should not store the passwords and tokens of the bodies", func(t *testing.T) {
+		base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := newTestResponse(http.StatusOK, nil)
+			if req.URL.Path == "/api/v4/users/login" {
+				resp.Body = io.NopCloser(strings.NewReader(`{"id":"userid","username":"admin"}`))
+			} else {
+				resp.Body = io.NopCloser(strings.NewReader(`{"id":"tokenid","token":"accesstoken","user_id":"userid"}`))
+			}
+			return resp, nil
+		})
+		recorder := NewRecorder()
+		transport := recorder.Transport(base)
+
+		login := `{"login_id":"admin","password":"s3cr3t!"}`
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost:8065/api/v4/users/login", strings.NewReader(login))
+		_, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+		req, _ = http.NewRequest(http.MethodPost, "http://localhost:8065/api/v4/users/userid/tokens", strings.NewReader(`{"description":"ci"}`))
+		_, err = transport.RoundTrip(req)
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "cassette.json")
+		require.NoError(t, recorder.Cassette().Save(path))
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(b), "s3cr3t!")
+		assert.NotContains(t, string(b), "accesstoken")
+		assert.Contains(t, string(b), `"login_id\":\"admin\"`)
+
+		// the requests are matched with their secrets redacted
+		cassette, err := LoadCassette(path)
+		require.NoError(t, err)
+		replayer := NewReplayTransport(cassette)
+		req, _ = http.NewRequest(http.MethodPost, "http://other:9000/api/v4/users/login", strings.NewReader(login))
+		resp, err := replayer.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
@@ -15,6 +15,7 @@ type Client interface {
 	RemoveUserFromChannel(channelID, userID string) (*model.Response, error)
 	GetChannelMembers(channelID string, page, perPage int, etag string) (model.ChannelMembers, *model.Response, error)
 	AddChannelMember(channelID, userID string) (*model.ChannelMember, *model.Response, error)
+	UpdateChannelRoles(channelID, userID, roles string) (*model.Response, error)
 	DeleteChannel(channelID string) (*model.Response, error)
 	PermanentDeleteChannel(channelID string) (*model.Response, error)
 	MoveChannel(channelID, teamID string, force bool) (*model.Channel, *model.Response, error)
@@ -27,6 +28,7 @@ type Client interface {
 	GetChannelByName(channelName, teamID string, etag string) (*model.Channel, *model.Response, error)
 	GetChannelByNameIncludeDeleted(channelName, teamID string, etag string) (*model.Channel, *model.Response, error)
 	GetChannel(channelID, etag string) (*model.Channel, *model.Response, error)
+	AutocompleteChannelsForTeam(teamID, name string) (model.ChannelList, *model.Response, error)
 	GetTeam(teamID, etag string) (*model.Team, *model.Response, error)
 	GetTeamByName(name, etag string) (*model.Team, *model.Response, error)
 	GetAllTeams(etag string, page int, perPage int) ([]*model.Team, *model.Response, error)
@@ -34,6 +36,12 @@ type Client interface {
 	PatchTeam(teamID string, patch *model.TeamPatch) (*model.Team, *model.Response, error)
 	AddTeamMember(teamID, userID string) (*model.TeamMember, *model.Response, error)
 	RemoveTeamMember(teamID, userID string) (*model.Response, error)
+	GetTeamMembers(teamID string, page int, perPage int, etag string) ([]*model.TeamMember, *model.Response, error)
+	GetTeamsForUser(userID, etag string) ([]*model.Team, *model.Response, error)
+	GetTeamIcon(teamID, etag string) ([]byte, *model.Response, error)
+	SetTeamIcon(teamID string, data []byte) (*model.Response, error)
+	RemoveTeamIcon(teamID string) (*model.Response, error)
+	UpdateTeamMemberRoles(teamID, userID, newRoles string) (*model.Response, error)
 	SoftDeleteTeam(teamID string) (*model.Response, error)
 	PermanentDeleteTeam(teamID string) (*model.Response, error)
 	RestoreTeam(teamID string) (*model.Team, *model.Response, error)
@@ -43,15 +51,19 @@ type Client interface {
 	CreatePost(post *model.Post) (*model.Post, *model.Response, error)
 	GetPostsForChannel(channelID string, page, perPage int, etag string, collapsedThreads bool, includeDeleted bool) (*model.PostList, *model.Response, error)
 	GetPostsSince(channelID string, since int64, collapsedThreads bool) (*model.PostList, *model.Response, error)
+	SearchPostsWithParams(teamID string, params *model.SearchParameter) (*model.PostList, *model.Response, error)
 	DoAPIPost(url string, data string) (*http.Response, error)
 	GetLdapGroups() ([]*model.Group, *model.Response, error)
 	GetGroupsByChannel(channelID string, groupOpts model.GroupSearchOpts) ([]*model.GroupWithSchemeAdmin, int, *model.Response, error)
 	GetGroupsByTeam(teamID string, groupOpts model.GroupSearchOpts) ([]*model.GroupWithSchemeAdmin, int, *model.Response, error)
 	RestoreGroup(groupID string, etag string) (*model.Group, *model.Response, error)
+	GetGroupsByUserId(userID string) ([]*model.Group, *model.Response, error)
+	UpsertGroupMembers(groupID string, userIds *model.GroupModifyMembers) ([]*model.GroupMember, *model.Response, error)
 	UploadLicenseFile(data []byte) (*model.Response, error)
 	RemoveLicenseFile() (*model.Response, error)
 	GetLogs(page, perPage int) ([]string, *model.Response, error)
 	GetRoleByName(name string) (*model.Role, *model.Response, error)
+	GetAllRoles() ([]*model.Role, *model.Response, error)
 	PatchRole(roleID string, patch *model.RolePatch) (*model.Role, *model.Response, error)
 	UploadPlugin(file io.Reader) (*model.Manifest, *model.Response, error)
 	UploadPluginForced(file io.Reader) (*model.Manifest, *model.Response, error)
@@ -61,6 +73,7 @@ type Client interface {
 	GetPlugins() (*model.PluginsResponse, *model.Response, error)
 	GetUser(userID, etag string) (*model.User, *model.Response, error)
 	GetUserByUsername(userName, etag string) (*model.User, *model.Response, error)
+	AutocompleteUsers(username string, limit int, etag string) (*model.UserAutocomplete, *model.Response, error)
 	GetUserByEmail(email, etag string) (*model.User, *model.Response, error)
 	PermanentDeleteUser(userID string) (*model.Response, error)
 	PermanentDeleteAllUsers() (*model.Response, error)
@@ -70,12 +83,26 @@ type Client interface {
 	InviteUsersToTeam(teamID string, userEmails []string) (*model.Response, error)
 	SendPasswordResetEmail(email string) (*model.Response, error)
 	UpdateUser(user *model.User) (*model.User, *model.Response, error)
+	PatchUser(userID string, patch *model.UserPatch) (*model.User, *model.Response, error)
+	GetProfileImage(userID, etag string) ([]byte, *model.Response, error)
+	SetProfileImage(userID string, data []byte) (*model.Response, error)
+	SetDefaultProfileImage(userID string) (*model.Response, error)
+	GetPreferences(userID string) (model.Preferences, *model.Response, error)
+	GetPreferencesByCategory(userID, category string) (model.Preferences, *model.Response, error)
+	GetPreferenceByCategoryAndName(userID, category, preferenceName string) (*model.Preference, *model.Response, error)
+	UpdatePreferences(userID string, preferences model.Preferences) (*model.Response, error)
+	DeletePreferences(userID string, preferences model.Preferences) (*model.Response, error)
 	UpdateUserMfa(userID, code string, activate bool) (*model.Response, error)
 	UpdateUserPassword(userID, currentPassword, newPassword string) (*model.Response, error)
 	UpdateUserHashedPassword(userID, newHashedPassword string) (*model.Response, error)
 	CreateUserAccessToken(userID, description string) (*model.UserAccessToken, *model.Response, error)
 	RevokeUserAccessToken(tokenID string) (*model.Response, error)
 	GetUserAccessTokensForUser(userID string, page, perPage int) ([]*model.UserAccessToken, *model.Response, error)
+	GetSessions(userID, etag string) ([]*model.Session, *model.Response, error)
+	RevokeSession(userID, sessionID string) (*model.Response, error)
+	RevokeAllSessions(userID string) (*model.Response, error)
+	RevokeSessionsFromAllUsers() (*model.Response, error)
+	GetUserAudits(userID string, page, perPage int, etag string) (model.Audits, *model.Response, error)
 	ConvertUserToBot(userID string) (*model.Bot, *model.Response, error)
 	ConvertBotToUser(userID string, userPatch *model.UserPatch, setSystemAdmin bool) (*model.User, *model.Response, error)
 	PromoteGuestToUser(userID string) (*model.Response, error)
@@ -0,0 +1,176 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package client
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMaxRetries   = 3
+	DefaultRetryTimeout = time.Minute
+
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+)
+
+// RetryTransport is an http.RoundTripper that retries the requests
+// rejected by the rate limiter of the server, and the idempotent
+// requests that fail because of network errors or temporary server
+// errors
+type RetryTransport struct {
+	Base http.RoundTripper
+	// MaxRetries is the number of times a request is retried after
+	// the first attempt
+	MaxRetries int
+	// Timeout limits the total time spent waiting between retries
+	Timeout time.Duration
+
+	sleep  func(req *http.Request, d time.Duration) error
+	jitter func(d time.Duration) time.Duration
+}
+
+// NewRetryTransport wraps the base transport, or the default one if
+// base is nil, with the retry logic
+func NewRetryTransport(base http.RoundTripper, maxRetries int, timeout time.Duration) *RetryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RetryTransport{
+		Base:       base,
+		MaxRetries: maxRetries,
+		Timeout:    timeout,
+		sleep:      sleepWithContext,
+		jitter:     equalJitter,
+	}
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var waited time.Duration
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.Base.RoundTrip(attemptReq)
+		if attempt >= t.MaxRetries || !t.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay := retryDelay(resp)
+		if delay <= 0 {
+			delay = t.jitter(backoff(attempt))
+		}
+		if waited+delay > t.Timeout {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if sleepErr := t.sleep(req, delay); sleepErr != nil {
+			return nil, sleepErr
+		}
+		waited += delay
+	}
+}
+
+func (t *RetryTransport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	// requests with a body can only be retried if it can be read again
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	// rate limited requests were not processed by the server, so
+	// they are safe to retry regardless of their method
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	if !isIdempotent(req.Method) {
+		return false
+	}
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryDelay returns the time the server asks to wait before
+// retrying, or zero if it doesn't specify it
+func retryDelay(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return time.Until(date)
+		}
+	}
+
+	// the rate limiter of the server sends the seconds until the
+	// limit is reset
+	if resp.Header.Get("X-Ratelimit-Remaining") == "0" {
+		if seconds, err := strconv.Atoi(resp.Header.Get("X-Ratelimit-Reset")); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	return 0
+}
+
+func backoff(attempt int) time.Duration {
+	delay := retryBaseDelay << attempt
+	if delay <= 0 || delay > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return delay
+}
+
+// equalJitter returns a random duration between half of the delay and
+// the delay, so concurrent clients don't retry at the same time
+func equalJitter(d time.Duration) time.Duration {
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int63n(half+1)) //nolint:gosec
+}
+
+func sleepWithContext(req *http.Request, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
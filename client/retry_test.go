@@ -0,0 +1,136 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newTestResponse(status int, header map[string]string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+	for k, v := range header {
+		resp.Header.Set(k, v)
+	}
+	return resp
+}
+
+// newTestRetryTransport returns a transport that answers with the
+// given responses in order, recording the bodies it receives and
+// the delays it waits
+func newTestRetryTransport(responses ...*http.Response) (*RetryTransport, *[]string, *[]time.Duration) {
+	var bodies []string
+	var delays []time.Duration
+
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			b, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(b))
+		}
+		resp := responses[0]
+		responses = responses[1:]
+		if resp == nil {
+			return nil, errors.New("connection reset by peer")
+		}
+		return resp, nil
+	})
+
+	t := NewRetryTransport(base, DefaultMaxRetries, DefaultRetryTimeout)
+	t.sleep = func(_ *http.Request, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	t.jitter = func(d time.Duration) time.Duration { return d }
+	return t, &bodies, &delays
+}
+
+func TestRetryTransport(t *testing.T) {
+	t.Run("should retry idempotent requests with exponential backoff", func(t *testing.T) {
+		rt, _, delays := newTestRetryTransport(
+			newTestResponse(http.StatusBadGateway, nil),
+			nil,
+			newTestResponse(http.StatusOK, nil),
+		)
+
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost/api/v4/users/me", nil)
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Second}, *delays)
+	})
+
+	t.Run("should not retry non idempotent requests on server errors", func(t *testing.T) {
+		rt, _, delays := newTestRetryTransport(newTestResponse(http.StatusServiceUnavailable, nil))
+
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost/api/v4/users", strings.NewReader("{}"))
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Empty(t, *delays)
+	})
+
+	t.Run("should retry rate limited requests honouring the headers", func(t *testing.T) {
+		rt, bodies, delays := newTestRetryTransport(
+			newTestResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "2"}),
+			newTestResponse(http.StatusTooManyRequests, map[string]string{"X-Ratelimit-Remaining": "0", "X-Ratelimit-Reset": "5"}),
+			newTestResponse(http.StatusCreated, nil),
+		)
+
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost/api/v4/users", strings.NewReader(`{"username":"user"}`))
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+		assert.Equal(t, []time.Duration{2 * time.Second, 5 * time.Second}, *delays)
+		assert.Equal(t, []string{`{"username":"user"}`, `{"username":"user"}`, `{"username":"user"}`}, *bodies)
+	})
+
+	t.Run("should stop after the maximum number of retries", func(t *testing.T) {
+		rt, _, delays := newTestRetryTransport(
+			newTestResponse(http.StatusBadGateway, nil),
+			newTestResponse(http.StatusBadGateway, nil),
+		)
+		rt.MaxRetries = 1
+
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost/api/v4/teams", nil)
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		assert.Len(t, *delays, 1)
+	})
+
+	t.Run("should not wait longer than the timeout", func(t *testing.T) {
+		rt, _, delays := newTestRetryTransport(newTestResponse(http.StatusTooManyRequests, map[string]string{"Retry-After": "120"}))
+
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost/api/v4/teams", nil)
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Empty(t, *delays)
+	})
+
+	t.Run("should return network errors of non idempotent requests", func(t *testing.T) {
+		rt, _, _ := newTestRetryTransport(nil)
+
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost/api/v4/posts", strings.NewReader("{}"))
+		_, err := rt.RoundTrip(req)
+		require.EqualError(t, err, "connection reset by peer")
+	})
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// traceBodyLimit is the maximum number of bytes of a body that are
+// logged
+const traceBodyLimit = 16 * 1024
+
+var traceSecretFieldsRegexp = regexp.MustCompile(`("(?:password|current_password|new_password|token|secret|client_secret)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// Tracer logs every request and response made through its
+// transports, keeping track of the number of requests and the time
+// spent on them
+type Tracer struct {
+	Writer io.Writer
+	// Bodies enables logging the bodies of the requests and the
+	// responses
+	Bodies bool
+
+	mu       sync.Mutex
+	requests int
+	failures int
+	total    time.Duration
+	slowest  time.Duration
+	now      func() time.Time
+}
+
+// TraceSummary contains the statistics of the traced requests
+type TraceSummary struct {
+	Requests int
+	Failures int
+	Total    time.Duration
+	Slowest  time.Duration
+}
+
+// NewTracer creates a tracer that logs the requests to w
+func NewTracer(w io.Writer, bodies bool) *Tracer {
+	return &Tracer{
+		Writer: w,
+		Bodies: bodies,
+		now:    time.Now,
+	}
+}
+
+// Transport wraps the base transport, or the default one if base is
+// nil, so its requests are traced
+func (t *Tracer) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &traceTransport{base: base, tracer: t}
+}
+
+type traceTransport struct {
+	base   http.RoundTripper
+	tracer *Tracer
+}
+
+func (tt *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t := tt.tracer
+
+	var reqBody []byte
+	if t.Bodies && req.Body != nil && req.Body != http.NoBody && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	start := t.now()
+	resp, err := tt.base.RoundTrip(req)
+	elapsed := t.now().Sub(start)
+
+	var respBody []byte
+	if t.Bodies && resp != nil && resp.Body != nil {
+		// the body is read to log it, so it is replaced by a copy
+		respBody, _ = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s", req.Method, req.URL.String())
+	if err != nil {
+		fmt.Fprintf(&sb, " error=%q", err.Error())
+	} else {
+		fmt.Fprintf(&sb, " %d", resp.StatusCode)
+	}
+	fmt.Fprintf(&sb, " %s", elapsed.Round(time.Microsecond))
+	if resp != nil {
+		if requestID := resp.Header.Get("X-Request-Id"); requestID != "" {
+			fmt.Fprintf(&sb, " request_id=%s", requestID)
+		}
+	}
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		fmt.Fprintf(&sb, " authorization=%q", redactAuthorization(auth))
+	}
+	sb.WriteString("\n")
+	if len(reqBody) > 0 {
+		fmt.Fprintf(&sb, "  request body: %s\n", formatTraceBody(reqBody))
+	}
+	if len(respBody) > 0 {
+		fmt.Fprintf(&sb, "  response body: %s\n", formatTraceBody(respBody))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requests++
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		t.failures++
+	}
+	t.total += elapsed
+	if elapsed > t.slowest {
+		t.slowest = elapsed
+	}
+	_, _ = io.WriteString(t.Writer, sb.String())
+
+	return resp, err
+}
+
+// Summary returns the statistics of the requests traced so far
+func (t *Tracer) Summary() TraceSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return TraceSummary{
+		Requests: t.requests,
+		Failures: t.failures,
+		Total:    t.total,
+		Slowest:  t.slowest,
+	}
+}
+
+func (s TraceSummary) String() string {
+	if s.Requests == 0 {
+		return "0 requests"
+	}
+	average := s.Total / time.Duration(s.Requests)
+	return fmt.Sprintf("%d requests, %d failed, total %s, average %s, slowest %s",
+		s.Requests, s.Failures, s.Total.Round(time.Microsecond), average.Round(time.Microsecond), s.Slowest.Round(time.Microsecond))
+}
+
+// redactAuthorization keeps the scheme of the header, hiding the
+// credentials
+func redactAuthorization(value string) string {
+	if scheme, _, found := strings.Cut(value, " "); found {
+		return scheme + " [redacted]"
+	}
+	return "[redacted]"
+}
+
+// redactSecretFields replaces the values of the JSON fields that
+// contain passwords and tokens
+func redactSecretFields(body string) string {
+	return traceSecretFieldsRegexp.ReplaceAllString(body, `$1"[redacted]"`)
+}
+
+func formatTraceBody(body []byte) string {
+	truncated := 0
+	if len(body) > traceBodyLimit {
+		truncated = len(body) - traceBodyLimit
+		body = body[:traceBodyLimit]
+	}
+
+	s := redactSecretFields(string(body))
+	if truncated > 0 {
+		s += fmt.Sprintf("... (%d bytes truncated)", truncated)
+	}
+	return s
+}
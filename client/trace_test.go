@@ -0,0 +1,74 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracer(t *testing.T) {
+	newTestTracer := func(bodies bool, responses ...*http.Response) (*Tracer, http.RoundTripper, *bytes.Buffer) {
+		buf := &bytes.Buffer{}
+		tracer := NewTracer(buf, bodies)
+		now := time.Date(2022, 11, 1, 10, 0, 0, 0, time.UTC)
+		tracer.now = func() time.Time {
+			now = now.Add(10 * time.Millisecond)
+			return now
+		}
+
+		base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := responses[0]
+			responses = responses[1:]
+			return resp, nil
+		})
+		return tracer, tracer.Transport(base), buf
+	}
+
+	t.Run("should log the requests with the authorization redacted", func(t *testing.T) {
+		resp := newTestResponse(http.StatusOK, map[string]string{"X-Request-Id": "requestid"})
+		tracer, transport, buf := newTestTracer(false, resp, newTestResponse(http.StatusNotFound, nil))
+
+		req, _ := http.NewRequest(http.MethodGet, "http://localhost/api/v4/users/me", nil)
+		req.Header.Set("Authorization", "Bearer secrettoken")
+		_, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+
+		req, _ = http.NewRequest(http.MethodGet, "http://localhost/api/v4/teams/unknown", nil)
+		_, err = transport.RoundTrip(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, "GET http://localhost/api/v4/users/me 200 10ms request_id=requestid authorization=\"Bearer [redacted]\"\n"+
+			"GET http://localhost/api/v4/teams/unknown 404 10ms\n", buf.String())
+		assert.NotContains(t, buf.String(), "secrettoken")
+
+		summary := tracer.Summary()
+		assert.Equal(t, TraceSummary{Requests: 2, Failures: 1, Total: 20 * time.Millisecond, Slowest: 10 * time.Millisecond}, summary)
+		assert.Equal(t, "2 requests, 1 failed, total 20ms, average 10ms, slowest 10ms", summary.String())
+	})
+
+	t.Run("should log the bodies hiding the secrets", func(t *testing.T) {
+		resp := newTestResponse(http.StatusOK, nil)
+		resp.Body = io.NopCloser(strings.NewReader(`{"id":"userid"}`))
+		_, transport, buf := newTestTracer(true, resp)
+
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost/api/v4/users/login", strings.NewReader(`{"login_id":"user","password":"se\"cret"}`))
+		res, err := transport.RoundTrip(req)
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":"userid"}`, string(body))
+
+		assert.Contains(t, buf.String(), `  request body: {"login_id":"user","password":"[redacted]"}`)
+		assert.Contains(t, buf.String(), `  response body: {"id":"userid"}`)
+	})
+}
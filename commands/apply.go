@@ -0,0 +1,754 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+const (
+	PlanActionCreateTeam              = "create_team"
+	PlanActionRestoreTeam             = "restore_team"
+	PlanActionUpdateTeam              = "update_team"
+	PlanActionAddTeamMember           = "add_team_member"
+	PlanActionRemoveTeamMember        = "remove_team_member"
+	PlanActionUpdateTeamMemberRole    = "update_team_member_role"
+	PlanActionCreateChannel           = "create_channel"
+	PlanActionRestoreChannel          = "restore_channel"
+	PlanActionUpdateChannel           = "update_channel"
+	PlanActionAddChannelMember        = "add_channel_member"
+	PlanActionRemoveChannelMember     = "remove_channel_member"
+	PlanActionUpdateChannelMemberRole = "update_channel_member_role"
+
+	teamMemberRoles       = "team_user"
+	teamAdminRoles        = "team_user team_admin"
+	channelMemberRoles    = "channel_user"
+	channelAdminRoles     = "channel_user channel_admin"
+	memberPageSize        = 200
+	manifestStdinFileName = "-"
+)
+
+var PlanCmd = &cobra.Command{
+	Use:   "plan -f [manifest file]",
+	Short: "Show the changes needed to reach a desired state",
+	Long: `Reads a desired state manifest describing teams, channels and their members, compares it with the server and prints the changes that "apply" would perform.
+Fields that are not present in the manifest are not managed, and members not present in a managed members list are only removed if the --prune flag is set.`,
+	Example: `  plan -f state.yaml
+  plan -f state.yaml --prune --json`,
+	Args: cobra.NoArgs,
+	RunE: withClient(planCmdF),
+}
+
+var ApplyCmd = &cobra.Command{
+	Use:   "apply -f [manifest file]",
+	Short: "Apply a desired state manifest",
+	Long: `Reads a desired state manifest describing teams, channels and their members, compares it with the server and applies only the needed changes.
+
+Manifest example:
+
+  teams:
+    - name: engineering
+      display_name: Engineering
+      private: true
+      members:
+        - alice
+        - user: bob
+          admin: true
+      channels:
+        - name: backend
+          display_name: Backend
+          private: false
+          header: Backend discussions
+          members: [alice, bob]`,
+	Example: `  apply -f state.yaml
+  apply -f state.yaml --prune`,
+	Args: cobra.NoArgs,
+	RunE: withClient(applyCmdF),
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{PlanCmd, ApplyCmd} {
+		cmd.Flags().StringP("file", "f", "", "Path to the manifest file in YAML or JSON format. Use \"-\" to read it from the standard input")
+		_ = cmd.MarkFlagRequired("file")
+		cmd.Flags().Bool("prune", false, "Remove the team and channel members that are not present in the manifest")
+	}
+
+	RootCmd.AddCommand(PlanCmd, ApplyCmd)
+}
+
+// StateManifest describes the desired state of a set of teams
+type StateManifest struct {
+	Teams []*ManifestTeam `yaml:"teams"`
+}
+
+type ManifestTeam struct {
+	Name        string             `yaml:"name"`
+	DisplayName string             `yaml:"display_name"`
+	Description *string            `yaml:"description"`
+	Private     *bool              `yaml:"private"`
+	Members     []ManifestMember   `yaml:"members"`
+	Channels    []*ManifestChannel `yaml:"channels"`
+}
+
+type ManifestChannel struct {
+	Name        string           `yaml:"name"`
+	DisplayName string           `yaml:"display_name"`
+	Header      *string          `yaml:"header"`
+	Purpose     *string          `yaml:"purpose"`
+	Private     *bool            `yaml:"private"`
+	Members     []ManifestMember `yaml:"members"`
+}
+
+// ManifestMember can be written either as a user identifier or as an
+// object with the user and its admin flag
+type ManifestMember struct {
+	User  string `yaml:"user"`
+	Admin bool   `yaml:"admin"`
+}
+
+func (m *ManifestMember) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		m.User = value.Value
+		return nil
+	}
+
+	type plainMember ManifestMember
+	return value.Decode((*plainMember)(m))
+}
+
+// PlanAction is a single change needed to reach the desired state
+type PlanAction struct {
+	Action      string `json:"action"`
+	Team        string `json:"team"`
+	Channel     string `json:"channel,omitempty"`
+	User        string `json:"user,omitempty"`
+	Field       string `json:"field,omitempty"`
+	From        string `json:"from,omitempty"`
+	To          string `json:"to,omitempty"`
+	Description string `json:"description"`
+
+	apply func(c client.Client) error
+}
+
+func readStateManifest(path string) (*StateManifest, error) {
+	var b []byte
+	var err error
+	if path == manifestStdinFileName {
+		b, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		b, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read the manifest: %w", err)
+	}
+
+	var manifest StateManifest
+	if err := yaml.Unmarshal(b, &manifest); err != nil {
+		return nil, fmt.Errorf("could not parse the manifest: %w", err)
+	}
+
+	if err := manifest.validate(); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+func (m *StateManifest) validate() error {
+	teamNames := map[string]bool{}
+	for i, team := range m.Teams {
+		if team.Name == "" {
+			return fmt.Errorf("team #%d in the manifest has no name", i+1)
+		}
+		if teamNames[team.Name] {
+			return fmt.Errorf("team %q is defined more than once in the manifest", team.Name)
+		}
+		teamNames[team.Name] = true
+
+		channelNames := map[string]bool{}
+		for j, channel := range team.Channels {
+			if channel.Name == "" {
+				return fmt.Errorf("channel #%d of team %q in the manifest has no name", j+1, team.Name)
+			}
+			if channelNames[channel.Name] {
+				return fmt.Errorf("channel %q is defined more than once in team %q", channel.Name, team.Name)
+			}
+			channelNames[channel.Name] = true
+		}
+	}
+
+	return nil
+}
+
+// statePlanner compares a manifest with the server state and
+// builds the list of actions needed to reconcile them
+type statePlanner struct {
+	c       client.Client
+	prune   bool
+	users   map[string]*model.User
+	actions []*PlanAction
+}
+
+func newStatePlanner(c client.Client, prune bool) *statePlanner {
+	return &statePlanner{
+		c:     c,
+		prune: prune,
+		users: map[string]*model.User{},
+	}
+}
+
+func (p *statePlanner) add(action *PlanAction) {
+	p.actions = append(p.actions, action)
+}
+
+func (p *statePlanner) getUser(userArg string) (*model.User, error) {
+	if user, ok := p.users[userArg]; ok {
+		return user, nil
+	}
+
+	user, err := getUserFromArg(p.c, userArg)
+	if err != nil {
+		return nil, err
+	}
+	p.users[userArg] = user
+	return user, nil
+}
+
+func (p *statePlanner) plan(manifest *StateManifest) ([]*PlanAction, error) {
+	for _, team := range manifest.Teams {
+		if err := p.planTeam(team); err != nil {
+			return nil, err
+		}
+	}
+	return p.actions, nil
+}
+
+func teamTypeFromPrivate(private bool) string {
+	if private {
+		return model.TeamInvite
+	}
+	return model.TeamOpen
+}
+
+func channelTypeFromPrivate(private bool) model.ChannelType {
+	if private {
+		return model.ChannelTypePrivate
+	}
+	return model.ChannelTypeOpen
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func (p *statePlanner) planTeam(mt *ManifestTeam) error {
+	team, err := getTeamFromArg(p.c, mt.Name)
+	var nfErr ErrEntityNotFound
+	if err != nil && !errors.As(err, &nfErr) {
+		return fmt.Errorf("could not get team %q: %w", mt.Name, err)
+	}
+
+	// ref holds the team that the rest of the actions will use,
+	// which is only known at apply time for new teams
+	ref := &model.Team{}
+	if team == nil {
+		private := mt.Private != nil && *mt.Private
+		newTeam := &model.Team{
+			Name:            mt.Name,
+			DisplayName:     mt.DisplayName,
+			Description:     stringValue(mt.Description),
+			Type:            teamTypeFromPrivate(private),
+			AllowOpenInvite: !private,
+		}
+		if newTeam.DisplayName == "" {
+			newTeam.DisplayName = mt.Name
+		}
+		p.add(&PlanAction{
+			Action:      PlanActionCreateTeam,
+			Team:        mt.Name,
+			Description: fmt.Sprintf("+ create team %q", mt.Name),
+			apply: func(c client.Client) error {
+				created, _, err := c.CreateTeam(newTeam)
+				if err != nil {
+					return err
+				}
+				*ref = *created
+				return nil
+			},
+		})
+	} else {
+		*ref = *team
+		p.planTeamUpdate(mt, team)
+	}
+
+	// teamMembers holds the ids of the members of the team in the
+	// manifest, if they are managed, to check that the members of its
+	// channels are a subset of them
+	var teamMembers map[string]bool
+	if mt.Members != nil {
+		if teamMembers, err = p.planTeamMembers(mt, team, ref); err != nil {
+			return err
+		}
+	}
+
+	for _, mc := range mt.Channels {
+		if err := p.planChannel(mt, mc, team, ref, teamMembers); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *statePlanner) planTeamUpdate(mt *ManifestTeam, team *model.Team) {
+	teamID := team.Id
+	if team.DeleteAt > 0 {
+		p.add(&PlanAction{
+			Action:      PlanActionRestoreTeam,
+			Team:        mt.Name,
+			Description: fmt.Sprintf("+ restore archived team %q", mt.Name),
+			apply: func(c client.Client) error {
+				_, _, err := c.RestoreTeam(teamID)
+				return err
+			},
+		})
+	}
+
+	if mt.DisplayName != "" && mt.DisplayName != team.DisplayName {
+		displayName := mt.DisplayName
+		p.add(&PlanAction{
+			Action:      PlanActionUpdateTeam,
+			Team:        mt.Name,
+			Field:       "display_name",
+			From:        team.DisplayName,
+			To:          displayName,
+			Description: fmt.Sprintf("~ update team %q display_name: %q -> %q", mt.Name, team.DisplayName, displayName),
+			apply: func(c client.Client) error {
+				_, _, err := c.PatchTeam(teamID, &model.TeamPatch{DisplayName: &displayName})
+				return err
+			},
+		})
+	}
+
+	if mt.Description != nil && *mt.Description != team.Description {
+		description := *mt.Description
+		p.add(&PlanAction{
+			Action:      PlanActionUpdateTeam,
+			Team:        mt.Name,
+			Field:       "description",
+			From:        team.Description,
+			To:          description,
+			Description: fmt.Sprintf("~ update team %q description: %q -> %q", mt.Name, team.Description, description),
+			apply: func(c client.Client) error {
+				_, _, err := c.PatchTeam(teamID, &model.TeamPatch{Description: &description})
+				return err
+			},
+		})
+	}
+
+	if mt.Private != nil {
+		currentType := team.Type
+		desiredType := teamTypeFromPrivate(*mt.Private)
+		if currentType != desiredType {
+			p.add(&PlanAction{
+				Action:      PlanActionUpdateTeam,
+				Team:        mt.Name,
+				Field:       "privacy",
+				From:        currentType,
+				To:          desiredType,
+				Description: fmt.Sprintf("~ update team %q privacy: %q -> %q", mt.Name, currentType, desiredType),
+				apply: func(c client.Client) error {
+					_, _, err := c.UpdateTeamPrivacy(teamID, desiredType)
+					return err
+				},
+			})
+		}
+	}
+}
+
+func (p *statePlanner) planTeamMembers(mt *ManifestTeam, team, ref *model.Team) (map[string]bool, error) {
+	current := map[string]*model.TeamMember{}
+	if team != nil {
+		members, err := getPages(func(page, perPage int, etag string) ([]*model.TeamMember, *model.Response, error) {
+			return p.c.GetTeamMembers(team.Id, page, perPage, etag)
+		}, memberPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("could not get the members of team %q: %w", mt.Name, err)
+		}
+		for _, member := range members {
+			if member.DeleteAt == 0 {
+				current[member.UserId] = member
+			}
+		}
+	}
+
+	desired := map[string]bool{}
+	for _, mm := range mt.Members {
+		user, err := p.getUser(mm.User)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve member %q of team %q: %w", mm.User, mt.Name, err)
+		}
+		desired[user.Id] = true
+		userID, username, admin := user.Id, user.Username, mm.Admin
+
+		member, ok := current[user.Id]
+		if !ok {
+			p.add(&PlanAction{
+				Action:      PlanActionAddTeamMember,
+				Team:        mt.Name,
+				User:        username,
+				Description: fmt.Sprintf("+ add user %q to team %q", username, mt.Name),
+				apply: func(c client.Client) error {
+					_, _, err := c.AddTeamMember(ref.Id, userID)
+					return err
+				},
+			})
+			if !admin {
+				continue
+			}
+		} else if member.SchemeAdmin == admin {
+			continue
+		}
+
+		from, to := teamMemberRoles, teamAdminRoles
+		if !admin {
+			from, to = to, from
+		}
+		p.add(&PlanAction{
+			Action:      PlanActionUpdateTeamMemberRole,
+			Team:        mt.Name,
+			User:        username,
+			From:        from,
+			To:          to,
+			Description: fmt.Sprintf("~ update roles of user %q in team %q: %q -> %q", username, mt.Name, from, to),
+			apply: func(c client.Client) error {
+				_, err := c.UpdateTeamMemberRoles(ref.Id, userID, to)
+				return err
+			},
+		})
+	}
+
+	if !p.prune {
+		return desired, nil
+	}
+
+	return desired, p.planPrune(unmanagedMembers(current, desired), func(user *model.User) *PlanAction {
+		userID := user.Id
+		return &PlanAction{
+			Action:      PlanActionRemoveTeamMember,
+			Team:        mt.Name,
+			User:        user.Username,
+			Description: fmt.Sprintf("- remove user %q from team %q", user.Username, mt.Name),
+			apply: func(c client.Client) error {
+				_, err := c.RemoveTeamMember(ref.Id, userID)
+				return err
+			},
+		}
+	})
+}
+
+func unmanagedMembers[T any](current map[string]T, desired map[string]bool) []string {
+	userIDs := []string{}
+	for userID := range current {
+		if !desired[userID] {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Strings(userIDs)
+	return userIDs
+}
+
+// planPrune adds a removal action for each of the given users
+func (p *statePlanner) planPrune(userIDs []string, newAction func(user *model.User) *PlanAction) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	users, _, err := p.c.GetUsersByIds(userIDs)
+	if err != nil {
+		return fmt.Errorf("could not get the unmanaged members: %w", err)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+	for _, user := range users {
+		p.add(newAction(user))
+	}
+	return nil
+}
+
+func (p *statePlanner) planChannel(mt *ManifestTeam, mc *ManifestChannel, team, teamRef *model.Team, teamMembers map[string]bool) error {
+	channelName := mt.Name + channelArgSeparator + mc.Name
+
+	var channel *model.Channel
+	if team != nil {
+		var response *model.Response
+		var err error
+		channel, response, err = p.c.GetChannelByNameIncludeDeleted(mc.Name, team.Id, "")
+		if err != nil {
+			nErr := ExtractErrorFromResponse(response, err)
+			var nfErr *NotFoundError
+			if !errors.As(nErr, &nfErr) {
+				return fmt.Errorf("could not get channel %q: %w", channelName, nErr)
+			}
+		}
+	}
+
+	ref := &model.Channel{}
+	if channel == nil {
+		private := mc.Private != nil && *mc.Private
+		newChannel := &model.Channel{
+			Name:        mc.Name,
+			DisplayName: mc.DisplayName,
+			Header:      stringValue(mc.Header),
+			Purpose:     stringValue(mc.Purpose),
+			Type:        channelTypeFromPrivate(private),
+		}
+		if newChannel.DisplayName == "" {
+			newChannel.DisplayName = mc.Name
+		}
+		p.add(&PlanAction{
+			Action:      PlanActionCreateChannel,
+			Team:        mt.Name,
+			Channel:     mc.Name,
+			Description: fmt.Sprintf("+ create channel %q", channelName),
+			apply: func(c client.Client) error {
+				newChannel.TeamId = teamRef.Id
+				created, _, err := c.CreateChannel(newChannel)
+				if err != nil {
+					return err
+				}
+				*ref = *created
+				return nil
+			},
+		})
+	} else {
+		*ref = *channel
+		p.planChannelUpdate(mt, mc, channel)
+	}
+
+	if mc.Members == nil {
+		return nil
+	}
+
+	current := map[string]*model.ChannelMember{}
+	if channel != nil {
+		members, err := getPages(func(page, perPage int, etag string) ([]model.ChannelMember, *model.Response, error) {
+			return p.c.GetChannelMembers(channel.Id, page, perPage, etag)
+		}, memberPageSize)
+		if err != nil {
+			return fmt.Errorf("could not get the members of channel %q: %w", channelName, err)
+		}
+		for i := range members {
+			current[members[i].UserId] = &members[i]
+		}
+	}
+
+	desired := map[string]bool{}
+	for _, mm := range mc.Members {
+		user, err := p.getUser(mm.User)
+		if err != nil {
+			return fmt.Errorf("could not resolve member %q of channel %q: %w", mm.User, channelName, err)
+		}
+		if teamMembers != nil && !teamMembers[user.Id] {
+			return fmt.Errorf("user %q is a member of channel %q but not of team %q", mm.User, mc.Name, mt.Name)
+		}
+		desired[user.Id] = true
+		userID, username, admin := user.Id, user.Username, mm.Admin
+
+		member, ok := current[user.Id]
+		if !ok {
+			p.add(&PlanAction{
+				Action:      PlanActionAddChannelMember,
+				Team:        mt.Name,
+				Channel:     mc.Name,
+				User:        username,
+				Description: fmt.Sprintf("+ add user %q to channel %q", username, channelName),
+				apply: func(c client.Client) error {
+					_, _, err := c.AddChannelMember(ref.Id, userID)
+					return err
+				},
+			})
+			if !admin {
+				continue
+			}
+		} else if member.SchemeAdmin == admin {
+			continue
+		}
+
+		from, to := channelMemberRoles, channelAdminRoles
+		if !admin {
+			from, to = to, from
+		}
+		p.add(&PlanAction{
+			Action:      PlanActionUpdateChannelMemberRole,
+			Team:        mt.Name,
+			Channel:     mc.Name,
+			User:        username,
+			From:        from,
+			To:          to,
+			Description: fmt.Sprintf("~ update roles of user %q in channel %q: %q -> %q", username, channelName, from, to),
+			apply: func(c client.Client) error {
+				_, err := c.UpdateChannelRoles(ref.Id, userID, to)
+				return err
+			},
+		})
+	}
+
+	if !p.prune {
+		return nil
+	}
+
+	return p.planPrune(unmanagedMembers(current, desired), func(user *model.User) *PlanAction {
+		userID := user.Id
+		return &PlanAction{
+			Action:      PlanActionRemoveChannelMember,
+			Team:        mt.Name,
+			Channel:     mc.Name,
+			User:        user.Username,
+			Description: fmt.Sprintf("- remove user %q from channel %q", user.Username, channelName),
+			apply: func(c client.Client) error {
+				_, err := c.RemoveUserFromChannel(ref.Id, userID)
+				return err
+			},
+		}
+	})
+}
+
+func (p *statePlanner) planChannelUpdate(mt *ManifestTeam, mc *ManifestChannel, channel *model.Channel) {
+	channelName := mt.Name + channelArgSeparator + mc.Name
+	channelID := channel.Id
+
+	if channel.DeleteAt > 0 {
+		p.add(&PlanAction{
+			Action:      PlanActionRestoreChannel,
+			Team:        mt.Name,
+			Channel:     mc.Name,
+			Description: fmt.Sprintf("+ restore archived channel %q", channelName),
+			apply: func(c client.Client) error {
+				_, _, err := c.RestoreChannel(channelID)
+				return err
+			},
+		})
+	}
+
+	type fieldChange struct {
+		field   string
+		from    string
+		to      *string
+		setTo   func(patch *model.ChannelPatch, value *string)
+		enabled bool
+	}
+	displayName := mc.DisplayName
+	changes := []fieldChange{
+		{"display_name", channel.DisplayName, &displayName, func(patch *model.ChannelPatch, value *string) { patch.DisplayName = value }, mc.DisplayName != ""},
+		{"header", channel.Header, mc.Header, func(patch *model.ChannelPatch, value *string) { patch.Header = value }, mc.Header != nil},
+		{"purpose", channel.Purpose, mc.Purpose, func(patch *model.ChannelPatch, value *string) { patch.Purpose = value }, mc.Purpose != nil},
+	}
+	for _, change := range changes {
+		if !change.enabled || change.from == *change.to {
+			continue
+		}
+		patch := &model.ChannelPatch{}
+		change.setTo(patch, change.to)
+		p.add(&PlanAction{
+			Action:      PlanActionUpdateChannel,
+			Team:        mt.Name,
+			Channel:     mc.Name,
+			Field:       change.field,
+			From:        change.from,
+			To:          *change.to,
+			Description: fmt.Sprintf("~ update channel %q %s: %q -> %q", channelName, change.field, change.from, *change.to),
+			apply: func(c client.Client) error {
+				_, _, err := c.PatchChannel(channelID, patch)
+				return err
+			},
+		})
+	}
+
+	if mc.Private != nil {
+		currentType := channel.Type
+		desiredType := channelTypeFromPrivate(*mc.Private)
+		if currentType != desiredType {
+			p.add(&PlanAction{
+				Action:      PlanActionUpdateChannel,
+				Team:        mt.Name,
+				Channel:     mc.Name,
+				Field:       "privacy",
+				From:        string(currentType),
+				To:          string(desiredType),
+				Description: fmt.Sprintf("~ update channel %q privacy: %q -> %q", channelName, currentType, desiredType),
+				apply: func(c client.Client) error {
+					_, _, err := c.UpdateChannelPrivacy(channelID, desiredType)
+					return err
+				},
+			})
+		}
+	}
+}
+
+func buildPlanFromFlags(c client.Client, cmd *cobra.Command) ([]*PlanAction, error) {
+	file, _ := cmd.Flags().GetString("file")
+	prune, _ := cmd.Flags().GetBool("prune")
+
+	manifest, err := readStateManifest(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return newStatePlanner(c, prune).plan(manifest)
+}
+
+func planCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	actions, err := buildPlanFromFlags(c, cmd)
+	if err != nil {
+		return err
+	}
+
+	if len(actions) == 0 {
+		printer.Print("No changes. The server matches the manifest.")
+		return nil
+	}
+
+	for _, action := range actions {
+		printer.PrintT("{{.Description}}", action)
+	}
+	return nil
+}
+
+func applyCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	actions, err := buildPlanFromFlags(c, cmd)
+	if err != nil {
+		return err
+	}
+
+	if len(actions) == 0 {
+		printer.Print("No changes. The server matches the manifest.")
+		return nil
+	}
+
+	for i, action := range actions {
+		if err := action.apply(c); err != nil {
+			return fmt.Errorf("could not apply %q, %d of %d changes were applied: %w", strings.TrimLeft(action.Description, "+-~ "), i, len(actions), err)
+		}
+		printer.PrintT("{{.Description}}", action)
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) writeManifest(content string) string {
+	dir, err := ioutil.TempDir("", "mmctl-manifest-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "state.yaml")
+	s.Require().NoError(ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func newManifestCmd(file string, prune bool) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("file", file, "")
+	cmd.Flags().Bool("prune", prune, "")
+	return cmd
+}
+
+func (s *MmctlUnitTestSuite) TestReadStateManifest() {
+	s.Run("should parse members in both forms", func() {
+		path := s.writeManifest(`
+teams:
+  - name: team1
+    members:
+      - user1
+      - user: user2
+        admin: true
+    channels:
+      - name: channel1
+        header: ""
+        members: [user1]
+`)
+
+		manifest, err := readStateManifest(path)
+		s.Require().NoError(err)
+		s.Require().Len(manifest.Teams, 1)
+		s.Require().Equal([]ManifestMember{{User: "user1"}, {User: "user2", Admin: true}}, manifest.Teams[0].Members)
+		s.Require().NotNil(manifest.Teams[0].Channels[0].Header)
+		s.Require().Nil(manifest.Teams[0].Channels[0].Purpose)
+	})
+
+	s.Run("should fail on duplicated teams", func() {
+		path := s.writeManifest(`
+teams:
+  - name: team1
+  - name: team1
+`)
+
+		_, err := readStateManifest(path)
+		s.Require().EqualError(err, `team "team1" is defined more than once in the manifest`)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestPlanCmd() {
+	s.Run("should plan the creation of a new team with its channels and members", func() {
+		printer.Clean()
+		path := s.writeManifest(`
+teams:
+  - name: newteam
+    private: true
+    members:
+      - user: user1
+        admin: true
+    channels:
+      - name: channel1
+        members: [user1]
+`)
+		user := &model.User{Id: "user1id", Username: "user1"}
+
+		s.client.EXPECT().GetTeam("newteam", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().GetTeamByName("newteam", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().GetUserByEmail("user1", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().GetUserByUsername("user1", "").Return(user, &model.Response{}, nil).Times(1)
+
+		err := planCmdF(s.client, newManifestCmd(path, false), []string{})
+		s.Require().NoError(err)
+
+		lines := printer.GetLines()
+		s.Require().Len(lines, 5)
+		expected := []string{PlanActionCreateTeam, PlanActionAddTeamMember, PlanActionUpdateTeamMemberRole, PlanActionCreateChannel, PlanActionAddChannelMember}
+		for i, action := range expected {
+			s.Require().Equal(action, lines[i].(*PlanAction).Action)
+		}
+	})
+
+	s.Run("should plan updates and pruning for an existing team", func() {
+		printer.Clean()
+		path := s.writeManifest(`
+teams:
+  - name: team1
+    display_name: New Name
+    private: false
+    members: [user1]
+`)
+		team := &model.Team{Id: "team1id", Name: "team1", DisplayName: "Old Name", Type: model.TeamOpen}
+		user := &model.User{Id: "user1id", Username: "user1"}
+		other := &model.User{Id: "user2id", Username: "user2"}
+
+		s.client.EXPECT().GetTeam("team1", "").Return(team, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetTeamMembers(team.Id, 0, memberPageSize, "").Return([]*model.TeamMember{
+			{TeamId: team.Id, UserId: user.Id, SchemeAdmin: true},
+			{TeamId: team.Id, UserId: other.Id},
+		}, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetTeamMembers(team.Id, 1, memberPageSize, "").Return([]*model.TeamMember{}, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetUserByEmail("user1", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().GetUserByUsername("user1", "").Return(user, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetUsersByIds([]string{other.Id}).Return([]*model.User{other}, &model.Response{}, nil).Times(1)
+
+		err := planCmdF(s.client, newManifestCmd(path, true), []string{})
+		s.Require().NoError(err)
+
+		lines := printer.GetLines()
+		s.Require().Len(lines, 3)
+		s.Require().Equal(&PlanAction{
+			Action:      PlanActionUpdateTeam,
+			Team:        "team1",
+			Field:       "display_name",
+			From:        "Old Name",
+			To:          "New Name",
+			Description: `~ update team "team1" display_name: "Old Name" -> "New Name"`,
+		}, withoutApply(lines[0]))
+		s.Require().Equal(PlanActionUpdateTeamMemberRole, lines[1].(*PlanAction).Action)
+		s.Require().Equal(teamMemberRoles, lines[1].(*PlanAction).To)
+		s.Require().Equal(PlanActionRemoveTeamMember, lines[2].(*PlanAction).Action)
+		s.Require().Equal("user2", lines[2].(*PlanAction).User)
+	})
+
+	s.Run("should fail if a channel member is not a team member", func() {
+		printer.Clean()
+		path := s.writeManifest(`
+teams:
+  - name: newteam
+    members: [user1]
+    channels:
+      - name: channel1
+        members: [user2]
+`)
+		user1 := &model.User{Id: "user1id", Username: "user1"}
+		user2 := &model.User{Id: "user2id", Username: "user2"}
+
+		s.client.EXPECT().GetTeam("newteam", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().GetTeamByName("newteam", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().GetUserByEmail("user1", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().GetUserByUsername("user1", "").Return(user1, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetUserByEmail("user2", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().GetUserByUsername("user2", "").Return(user2, &model.Response{}, nil).Times(1)
+
+		err := planCmdF(s.client, newManifestCmd(path, false), []string{})
+		s.Require().EqualError(err, `user "user2" is a member of channel "channel1" but not of team "newteam"`)
+		s.Require().Empty(printer.GetLines())
+	})
+
+	s.Run("should accept members given with different identifiers", func() {
+		printer.Clean()
+		path := s.writeManifest(`
+teams:
+  - name: newteam
+    members: [user1@example.com]
+    channels:
+      - name: channel1
+        members: [user1]
+`)
+		user := &model.User{Id: "user1id", Username: "user1", Email: "user1@example.com"}
+
+		s.client.EXPECT().GetTeam("newteam", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().GetTeamByName("newteam", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().GetUserByEmail("user1@example.com", "").Return(user, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetUserByEmail("user1", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().GetUserByUsername("user1", "").Return(user, &model.Response{}, nil).Times(1)
+
+		err := planCmdF(s.client, newManifestCmd(path, false), []string{})
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 4)
+		s.Require().Equal(PlanActionAddChannelMember, printer.GetLines()[3].(*PlanAction).Action)
+	})
+
+	s.Run("should report no changes", func() {
+		printer.Clean()
+		path := s.writeManifest(`
+teams:
+  - name: team1
+    display_name: Team 1
+`)
+		team := &model.Team{Id: "team1id", Name: "team1", DisplayName: "Team 1"}
+
+		s.client.EXPECT().GetTeam("team1", "").Return(team, &model.Response{}, nil).Times(1)
+
+		err := planCmdF(s.client, newManifestCmd(path, false), []string{})
+		s.Require().NoError(err)
+		s.Require().Equal([]interface{}{"No changes. The server matches the manifest."}, printer.GetLines())
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestApplyCmd() {
+	s.Run("should create a team and its channel", func() {
+		printer.Clean()
+		path := s.writeManifest(`
+teams:
+  - name: newteam
+    display_name: New Team
+    channels:
+      - name: channel1
+        private: true
+        purpose: testing
+`)
+		createdTeam := &model.Team{Id: "newteamid", Name: "newteam"}
+
+		s.client.EXPECT().GetTeam("newteam", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().GetTeamByName("newteam", "").Return(nil, &model.Response{StatusCode: 404}, &model.AppError{}).Times(1)
+		s.client.EXPECT().CreateTeam(&model.Team{
+			Name:            "newteam",
+			DisplayName:     "New Team",
+			Type:            model.TeamOpen,
+			AllowOpenInvite: true,
+		}).Return(createdTeam, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().CreateChannel(&model.Channel{
+			TeamId:      createdTeam.Id,
+			Name:        "channel1",
+			DisplayName: "channel1",
+			Purpose:     "testing",
+			Type:        model.ChannelTypePrivate,
+		}).Return(&model.Channel{Id: "channel1id"}, &model.Response{}, nil).Times(1)
+
+		err := applyCmdF(s.client, newManifestCmd(path, false), []string{})
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 2)
+	})
+
+	s.Run("should stop on the first failed change", func() {
+		printer.Clean()
+		path := s.writeManifest(`
+teams:
+  - name: team1
+    private: true
+    description: new description
+`)
+		team := &model.Team{Id: "team1id", Name: "team1", Type: model.TeamOpen}
+
+		s.client.EXPECT().GetTeam("team1", "").Return(team, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().PatchTeam(team.Id, gomock.Any()).Return(nil, &model.Response{}, &model.AppError{Message: "patch failed"}).Times(1)
+
+		err := applyCmdF(s.client, newManifestCmd(path, false), []string{})
+		s.Require().Error(err)
+		s.Require().Contains(err.Error(), `could not apply "update team \"team1\" description: \"\" -> \"new description\"", 0 of 2 changes were applied`)
+		s.Require().Empty(printer.GetLines())
+	})
+}
+
+func withoutApply(line interface{}) *PlanAction {
+	action := *line.(*PlanAction)
+	action.apply = nil
+	return &action
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	argsFileFormatAuto  = "auto"
+	argsFileFormatLines = "lines"
+	argsFileFormatCSV   = "csv"
+	argsFileFormatJSON  = "json"
+)
+
+// fileArg is an argument of a command, which can be passed as a
+// positional argument or read from the --from-file file
+type fileArg struct {
+	Value string
+	// Source is the name of the file the argument was read from, and
+	// Line its line in the file. Both are empty for the positional
+	// arguments
+	Source string
+	Line   int
+}
+
+// wrap adds the line the argument was read from to the errors of the
+// arguments read from a file
+func (a fileArg) wrap(err error) error {
+	if err == nil || a.Line == 0 {
+		return err
+	}
+	return &ArgLineError{Source: a.Source, Line: a.Line, Err: err}
+}
+
+// addFromFileFlags adds the flags to read the arguments of a command
+// from a file
+func addFromFileFlags(cmd *cobra.Command) {
+	cmd.Flags().String("from-file", "", "reads the arguments from a file, or from the standard input if it is \"-\"")
+	cmd.Flags().String("from-file-format", argsFileFormatAuto, "format of the --from-file file [auto, lines, csv, json]. auto uses the file extension, and reads the file as a list with one argument per line if it isn't a JSON array")
+	cmd.Flags().String("from-file-column", "", "column of the CSV file, or field of the objects of the JSON array, that contains the arguments. Defaults to the first column of the CSV file")
+}
+
+// minimumArgsOrFile validates that the command receives the fixed
+// arguments and at least one more, unless the rest of the arguments are
+// read from the --from-file file
+func minimumArgsOrFile(fixed int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if fromFile, _ := cmd.Flags().GetString("from-file"); fromFile != "" {
+			return cobra.MinimumNArgs(fixed)(cmd, args)
+		}
+		return cobra.MinimumNArgs(fixed+1)(cmd, args)
+	}
+}
+
+// fileArgs returns the positional arguments followed by the ones read
+// from the --from-file file, if the flag is set
+func fileArgs(cmd *cobra.Command, args []string) ([]fileArg, error) {
+	result := make([]fileArg, 0, len(args))
+	for _, arg := range args {
+		result = append(result, fileArg{Value: arg})
+	}
+
+	path, _ := cmd.Flags().GetString("from-file")
+	if path == "" {
+		return result, nil
+	}
+	format, _ := cmd.Flags().GetString("from-file-format")
+	column, _ := cmd.Flags().GetString("from-file-column")
+
+	var r io.Reader = os.Stdin
+	source := "stdin"
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not open the arguments file: %w", err)
+		}
+		defer f.Close()
+		r = f
+		source = path
+	}
+
+	read, err := readArgsFile(r, source, format, column)
+	if err != nil {
+		return nil, err
+	}
+	return append(result, read...), nil
+}
+
+// readArgsFile reads the arguments from a list with one argument per
+// line, from a column of a CSV file with a header or from a JSON array
+// of strings or objects
+func readArgsFile(r io.Reader, source, format, column string) ([]fileArg, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the arguments file: %w", err)
+	}
+
+	if format == "" || format == argsFileFormatAuto {
+		format = detectArgsFileFormat(source, b, column)
+	}
+
+	switch format {
+	case argsFileFormatLines:
+		return readArgsLines(b, source)
+	case argsFileFormatCSV:
+		return readArgsCSV(b, source, column)
+	case argsFileFormatJSON:
+		return readArgsJSON(b, source, column)
+	default:
+		return nil, fmt.Errorf("invalid arguments file format %q, must be one of: %s", format, strings.Join([]string{argsFileFormatAuto, argsFileFormatLines, argsFileFormatCSV, argsFileFormatJSON}, ", "))
+	}
+}
+
+func detectArgsFileFormat(source string, b []byte, column string) string {
+	switch strings.ToLower(filepath.Ext(source)) {
+	case ".csv":
+		return argsFileFormatCSV
+	case ".json":
+		return argsFileFormatJSON
+	}
+
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '[' {
+		return argsFileFormatJSON
+	}
+	if column != "" {
+		return argsFileFormatCSV
+	}
+	return argsFileFormatLines
+}
+
+// readArgsLines reads one argument per line, skipping the empty lines
+// and the ones starting with #
+func readArgsLines(b []byte, source string) ([]fileArg, error) {
+	var args []fileArg
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for line := 1; scanner.Scan(); line++ {
+		value := strings.TrimSpace(scanner.Text())
+		if value == "" || strings.HasPrefix(value, "#") {
+			continue
+		}
+		args = append(args, fileArg{Value: value, Source: source, Line: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read the arguments file: %w", err)
+	}
+	return args, nil
+}
+
+func readArgsCSV(b []byte, source, column string) ([]fileArg, error) {
+	reader := csv.NewReader(bytes.NewReader(b))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid CSV file: %w", source, err)
+	}
+
+	index := 0
+	if column != "" {
+		index = -1
+		for i, name := range header {
+			if strings.TrimSpace(name) == column {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return nil, fmt.Errorf("%s: column %q not found in the CSV header", source, column)
+		}
+	}
+
+	var args []fileArg
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid CSV file: %w", source, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if index >= len(record) || strings.TrimSpace(record[index]) == "" {
+			return nil, fmt.Errorf("%s:%d: the row has no value in column %q", source, line, header[index])
+		}
+		args = append(args, fileArg{Value: strings.TrimSpace(record[index]), Source: source, Line: line})
+	}
+	return args, nil
+}
+
+// readArgsJSON reads a JSON array of strings, or of objects if the
+// column is set
+func readArgsJSON(b []byte, source, column string) ([]fileArg, error) {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, fmt.Errorf("%s: the file must contain a JSON array", source)
+	}
+
+	var args []fileArg
+	for decoder.More() {
+		// the offset points to the end of the previous element, so
+		// the whitespace before the element is skipped to find its line
+		offset := int(decoder.InputOffset())
+		for offset < len(b) && strings.ContainsRune(" \t\r\n,", rune(b[offset])) {
+			offset++
+		}
+		line := bytes.Count(b[:offset], []byte("\n")) + 1
+
+		var element interface{}
+		if err := decoder.Decode(&element); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid JSON: %w", source, line, err)
+		}
+
+		if object, ok := element.(map[string]interface{}); ok && column != "" {
+			element = object[column]
+		}
+		var value string
+		switch v := element.(type) {
+		case string:
+			value = strings.TrimSpace(v)
+		case json.Number:
+			value = v.String()
+		}
+		if value == "" {
+			if column != "" {
+				return nil, fmt.Errorf("%s:%d: the element must be an object with a %q string field", source, line, column)
+			}
+			return nil, fmt.Errorf("%s:%d: the element must be a non empty string", source, line)
+		}
+		args = append(args, fileArg{Value: value, Source: source, Line: line})
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("%s: invalid JSON: %w", source, err)
+	}
+	return args, nil
+}
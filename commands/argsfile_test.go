@@ -0,0 +1,160 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mattermost/mattermost-server/v6/model"
+)
+
+func (s *MmctlUnitTestSuite) TestReadArgsFile() {
+	testCases := []struct {
+		name     string
+		source   string
+		format   string
+		column   string
+		content  string
+		expected []fileArg
+		err      string
+	}{
+		{
+			name:    "list with comments and empty lines",
+			source:  "users.txt",
+			content: "# users\nuser1\n\n  user2  \n",
+			expected: []fileArg{
+				{Value: "user1", Source: "users.txt", Line: 2},
+				{Value: "user2", Source: "users.txt", Line: 4},
+			},
+		},
+		{
+			name:    "CSV column",
+			source:  "users.csv",
+			column:  "email",
+			content: "username,email\nuser1,user1@example.com\n\"user\n2\",user2@example.com\n",
+			expected: []fileArg{
+				{Value: "user1@example.com", Source: "users.csv", Line: 2},
+				{Value: "user2@example.com", Source: "users.csv", Line: 3},
+			},
+		},
+		{
+			name:    "CSV first column from stdin",
+			source:  "stdin",
+			format:  argsFileFormatCSV,
+			content: "username,email\nuser1,user1@example.com\n",
+			expected: []fileArg{
+				{Value: "user1", Source: "stdin", Line: 2},
+			},
+		},
+		{
+			name:    "CSV unknown column",
+			source:  "users.csv",
+			column:  "id",
+			content: "username,email\n",
+			err:     `users.csv: column "id" not found in the CSV header`,
+		},
+		{
+			name:    "CSV row without value",
+			source:  "users.csv",
+			column:  "email",
+			content: "username,email\nuser1,user1@example.com\nuser2,\n",
+			err:     `users.csv:3: the row has no value in column "email"`,
+		},
+		{
+			name:    "JSON array of strings detected from the content",
+			source:  "stdin",
+			content: "[\n  \"user1\",\n\n  \"user2\"\n]",
+			expected: []fileArg{
+				{Value: "user1", Source: "stdin", Line: 2},
+				{Value: "user2", Source: "stdin", Line: 4},
+			},
+		},
+		{
+			name:    "JSON array of objects",
+			source:  "users.json",
+			column:  "username",
+			content: "[\n  {\"username\": \"user1\"},\n  {\n    \"username\": \"user2\"\n  }\n]",
+			expected: []fileArg{
+				{Value: "user1", Source: "users.json", Line: 2},
+				{Value: "user2", Source: "users.json", Line: 3},
+			},
+		},
+		{
+			name:    "JSON element without the field",
+			source:  "users.json",
+			column:  "username",
+			content: "[\n  {\"username\": \"user1\"},\n  {\"email\": \"user2@example.com\"}\n]",
+			err:     `users.json:3: the element must be an object with a "username" string field`,
+		},
+		{
+			name:    "JSON that isn't an array",
+			source:  "users.json",
+			content: `{"username": "user1"}`,
+			err:     "users.json: the file must contain a JSON array",
+		},
+		{
+			name:    "invalid format",
+			source:  "users.txt",
+			format:  "xml",
+			content: "user1",
+			err:     `invalid arguments file format "xml", must be one of: auto, lines, csv, json`,
+		},
+	}
+
+	for _, tc := range testCases {
+		s.Run(tc.name, func() {
+			args, err := readArgsFile(strings.NewReader(tc.content), tc.source, tc.format, tc.column)
+			if tc.err != "" {
+				s.Require().EqualError(err, tc.err)
+				return
+			}
+			s.Require().NoError(err)
+			s.Require().Equal(tc.expected, args)
+		})
+	}
+}
+
+func (s *MmctlUnitTestSuite) TestGetUsersFromFileArgs() {
+	mockUser := model.User{Id: model.NewId(), Email: "user1@example.com"}
+	args := []fileArg{
+		{Value: mockUser.Email},
+		{Value: "user2@example.com", Source: "users.txt", Line: 3},
+	}
+
+	s.client.
+		EXPECT().
+		GetUserByEmail(mockUser.Email, "").
+		Return(&mockUser, &model.Response{}, nil).
+		Times(1)
+	s.client.
+		EXPECT().
+		GetUserByEmail("user2@example.com", "").
+		Return(nil, &model.Response{StatusCode: 404}, errors.New("not found")).
+		Times(1)
+	s.client.
+		EXPECT().
+		GetUserByUsername("user2@example.com", "").
+		Return(nil, &model.Response{StatusCode: 404}, errors.New("not found")).
+		Times(1)
+	s.client.
+		EXPECT().
+		GetUser("user2@example.com", "").
+		Return(nil, &model.Response{StatusCode: 404}, errors.New("not found")).
+		Times(1)
+
+	users, err := getUsersFromFileArgs(s.client, args)
+	s.Require().Equal([]*model.User{&mockUser}, users)
+
+	var merr *multierror.Error
+	s.Require().True(errors.As(err, &merr))
+	s.Require().Len(merr.Errors, 1)
+	s.Require().EqualError(merr.Errors[0], "users.txt:3: user user2@example.com not found")
+
+	var lineErr *ArgLineError
+	s.Require().True(errors.As(err, &lineErr))
+	s.Require().Equal(3, NewErrorEnvelope(merr.Errors[0]).Line)
+	s.Require().Equal("user2@example.com", NewErrorEnvelope(merr.Errors[0]).Argument)
+}
@@ -89,6 +89,17 @@ var CleanCmd = &cobra.Command{
 	RunE:    cleanCmdF,
 }
 
+var MigrateCmd = &cobra.Command{
+	Use:   "migrate --to [credentials store]",
+	Short: "Migrate the credentials to another store",
+	Long: `Moves all the stored credentials from the current credentials store into the one specified with the --to flag.
+After the migration, use the --credentials-store flag or the MMCTL_CREDENTIALS_STORE environment variable to select the new store.`,
+	Example: `  auth migrate --to keyring
+  auth migrate --from keyring --to encrypted-file`,
+	Args: cobra.NoArgs,
+	RunE: migrateCmdF,
+}
+
 func init() {
 	LoginCmd.Flags().StringP("name", "n", "", "Name for the credentials")
 	LoginCmd.Flags().StringP("username", "u", "", "Username for the credentials")
@@ -109,6 +120,10 @@ func init() {
 	RenewCmd.Flags().StringP("access-token-file", "t", "", "Access token file to be read to use instead of username/password")
 	RenewCmd.Flags().StringP("mfa-token", "m", "", "MFA token for the credentials")
 
+	MigrateCmd.Flags().String("from", "", "The credentials store to read the credentials from. Defaults to the current credentials store")
+	MigrateCmd.Flags().String("to", "", "The credentials store to write the credentials to [file, encrypted-file, keyring]")
+	_ = MigrateCmd.MarkFlagRequired("to")
+
 	AuthCmd.AddCommand(
 		LoginCmd,
 		CurrentCmd,
@@ -117,6 +132,7 @@ func init() {
 		RenewCmd,
 		DeleteCmd,
 		CleanCmd,
+		MigrateCmd,
 	)
 
 	RootCmd.AddCommand(AuthCmd)
@@ -426,3 +442,32 @@ func cleanCmdF(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+func migrateCmdF(cmd *cobra.Command, args []string) error {
+	fromName, _ := cmd.Flags().GetString("from")
+	if fromName == "" {
+		fromName = viper.GetString("credentials-store")
+	}
+	toName, _ := cmd.Flags().GetString("to")
+
+	from, err := NewCredentialsStore(fromName, resolveConfigFilePath())
+	if err != nil {
+		return err
+	}
+	to, err := NewCredentialsStore(toName, resolveConfigFilePath())
+	if err != nil {
+		return err
+	}
+
+	if from.Name() == to.Name() {
+		return errors.Errorf("credentials are already stored in the %q store", to.Name())
+	}
+
+	count, err := MigrateCredentials(from, to)
+	if err != nil {
+		return err
+	}
+
+	printer.Print(fmt.Sprintf("%d credentials migrated from the %q store to the %q store. Use --credentials-store %s or set MMCTL_CREDENTIALS_STORE=%s to use them", count, from.Name(), to.Name(), to.Name(), to.Name()))
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+const (
+	CredentialsStoreFile          = "file"
+	CredentialsStoreEncryptedFile = "encrypted-file"
+	CredentialsStoreKeyring       = "keyring"
+)
+
+// CredentialsStore is the backend where the credentials list is
+// persisted. The stores are selected through the --credentials-store
+// flag or the MMCTL_CREDENTIALS_STORE environment variable.
+type CredentialsStore interface {
+	// Name returns the identifier of the store
+	Name() string
+	// Read loads the full credentials list, including the auth tokens
+	Read() (*CredentialsList, error)
+	// Write replaces the stored credentials list with the given one
+	Write(credentialsList *CredentialsList) error
+	// Clean removes every stored credential
+	Clean() error
+}
+
+// CredentialsStoreNames returns the names of the available stores
+func CredentialsStoreNames() []string {
+	return []string{CredentialsStoreFile, CredentialsStoreEncryptedFile, CredentialsStoreKeyring}
+}
+
+// NewCredentialsStore returns the store identified by name, which
+// will use path as the location of its configuration file
+func NewCredentialsStore(name, path string) (CredentialsStore, error) {
+	switch name {
+	case CredentialsStoreFile, "":
+		return &fileCredentialsStore{path: path}, nil
+	case CredentialsStoreEncryptedFile:
+		return &encryptedFileCredentialsStore{path: path, passphrase: getCredentialsPassphrase}, nil
+	case CredentialsStoreKeyring:
+		return &keyringCredentialsStore{path: path, run: runSecretTool}, nil
+	default:
+		return nil, errors.Errorf("invalid credentials store %q, must be one of: %s", name, strings.Join(CredentialsStoreNames(), ", "))
+	}
+}
+
+func getCredentialsStore() (CredentialsStore, error) {
+	return NewCredentialsStore(viper.GetString("credentials-store"), resolveConfigFilePath())
+}
+
+// fileCredentialsStore keeps the credentials list, auth tokens
+// included, as plain JSON protected only by the file permissions.
+type fileCredentialsStore struct {
+	path string
+}
+
+func (s *fileCredentialsStore) Name() string {
+	return CredentialsStoreFile
+}
+
+func (s *fileCredentialsStore) Read() (*CredentialsList, error) {
+	return readCredentialsFile(s.path)
+}
+
+func (s *fileCredentialsStore) Write(credentialsList *CredentialsList) error {
+	return writeCredentialsFile(s.path, credentialsList)
+}
+
+func (s *fileCredentialsStore) Clean() error {
+	return removeCredentialsFile(s.path)
+}
+
+func readCredentialsFile(path string) (*CredentialsList, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, errors.WithMessage(err, "cannot read user credentials, maybe you need to use login first")
+	}
+
+	fileContents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "there was a problem reading the credentials file")
+	}
+
+	var credentialsList CredentialsList
+	if err := json.Unmarshal(fileContents, &credentialsList); err != nil {
+		return nil, errors.WithMessage(err, "there was a problem parsing the credentials file")
+	}
+
+	return &credentialsList, nil
+}
+
+func writeCredentialsFile(path string, credentialsList *CredentialsList) error {
+	marshaledCredentialsList, _ := json.MarshalIndent(credentialsList, "", "    ")
+
+	if err := ioutil.WriteFile(path, marshaledCredentialsList, 0600); err != nil {
+		return errors.WithMessage(err, "cannot save the credentials")
+	}
+
+	return nil
+}
+
+func removeCredentialsFile(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// sortedCredentialNames returns the names of the credentials list in
+// a stable order
+func sortedCredentialNames(credentialsList *CredentialsList) []string {
+	names := make([]string, 0, len(*credentialsList))
+	for name := range *credentialsList {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MigrateCredentials moves every credential from the source store
+// into the target one, removing the secrets left behind in the
+// source if it doesn't share its storage with the target.
+func MigrateCredentials(from, to CredentialsStore) (int, error) {
+	credentialsList, err := from.Read()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := to.Write(credentialsList); err != nil {
+		return 0, errors.WithMessagef(err, "cannot write the credentials into the %q store", to.Name())
+	}
+
+	// file based stores share the configuration file with the
+	// target, which has already been overwritten at this point
+	if from.Name() == CredentialsStoreKeyring && to.Name() != CredentialsStoreKeyring {
+		if ks, ok := from.(*keyringCredentialsStore); ok {
+			if err := ks.clearSecrets(sortedCredentialNames(credentialsList)); err != nil {
+				return 0, errors.WithMessage(err, "credentials were migrated but the keyring entries could not be removed")
+			}
+		}
+	}
+
+	return len(*credentialsList), nil
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+const (
+	encryptedCredentialsVersion = 1
+	encryptedCredentialsKDF     = "scrypt"
+	encryptedCredentialsCipher  = "aes-256-gcm"
+
+	scryptN       = 1 << 15
+	scryptR       = 8
+	scryptP       = 1
+	scryptKeyLen  = 32
+	scryptSaltLen = 16
+)
+
+// encryptedCredentials is the envelope written to disk by the
+// encrypted-file store
+type encryptedCredentials struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Cipher  string `json:"cipher"`
+	N       int    `json:"n"`
+	R       int    `json:"r"`
+	P       int    `json:"p"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// encryptedFileCredentialsStore keeps the credentials list in a file
+// encrypted with a key derived from a passphrase using scrypt.
+type encryptedFileCredentialsStore struct {
+	path       string
+	passphrase func() (string, error)
+}
+
+var cachedCredentialsPassphrase string
+
+// getCredentialsPassphrase returns the passphrase for the encrypted
+// credentials store, reading it from the MMCTL_CREDENTIALS_PASSPHRASE
+// environment variable or asking for it if running interactively
+func getCredentialsPassphrase() (string, error) {
+	if passphrase := viper.GetString("credentials-passphrase"); passphrase != "" {
+		return passphrase, nil
+	}
+
+	if cachedCredentialsPassphrase != "" {
+		return cachedCredentialsPassphrase, nil
+	}
+
+	//nolint:unconvert
+	if !term.IsTerminal(int(syscall.Stdin)) {
+		return "", errors.New("the encrypted credentials store requires a passphrase, please set the MMCTL_CREDENTIALS_PASSPHRASE environment variable")
+	}
+
+	fmt.Fprint(os.Stderr, "Credentials passphrase: ")
+	passphrase, err := getPasswordFromStdin()
+	if err != nil {
+		return "", errors.WithMessage(err, "couldn't read the credentials passphrase")
+	}
+	if passphrase == "" {
+		return "", errors.New("the credentials passphrase cannot be empty")
+	}
+
+	cachedCredentialsPassphrase = passphrase
+	return passphrase, nil
+}
+
+func (s *encryptedFileCredentialsStore) Name() string {
+	return CredentialsStoreEncryptedFile
+}
+
+func (s *encryptedFileCredentialsStore) Read() (*CredentialsList, error) {
+	if _, err := os.Stat(s.path); err != nil {
+		return nil, errors.WithMessage(err, "cannot read user credentials, maybe you need to use login first")
+	}
+
+	fileContents, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "there was a problem reading the credentials file")
+	}
+
+	var envelope encryptedCredentials
+	if err := json.Unmarshal(fileContents, &envelope); err != nil || envelope.Version == 0 {
+		return nil, errors.New("there was a problem parsing the credentials file, it doesn't look like an encrypted credentials file. Use \"auth migrate\" to convert it")
+	}
+
+	if envelope.Version != encryptedCredentialsVersion || envelope.KDF != encryptedCredentialsKDF || envelope.Cipher != encryptedCredentialsCipher {
+		return nil, errors.Errorf("unsupported encrypted credentials file: version %d, kdf %q, cipher %q", envelope.Version, envelope.KDF, envelope.Cipher)
+	}
+
+	passphrase, err := s.passphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newCredentialsAEAD(passphrase, envelope.Salt, envelope.N, envelope.R, envelope.P)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, envelope.Nonce, envelope.Data, nil)
+	if err != nil {
+		return nil, errors.New("cannot decrypt the credentials file, the passphrase may be wrong")
+	}
+
+	var credentialsList CredentialsList
+	if err := json.Unmarshal(plaintext, &credentialsList); err != nil {
+		return nil, errors.WithMessage(err, "there was a problem parsing the credentials file")
+	}
+
+	return &credentialsList, nil
+}
+
+func (s *encryptedFileCredentialsStore) Write(credentialsList *CredentialsList) error {
+	passphrase, err := s.passphrase()
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, scryptSaltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return errors.WithMessage(err, "cannot generate the encryption salt")
+	}
+
+	aead, err := newCredentialsAEAD(passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return errors.WithMessage(err, "cannot generate the encryption nonce")
+	}
+
+	plaintext, _ := json.Marshal(credentialsList)
+	envelope := encryptedCredentials{
+		Version: encryptedCredentialsVersion,
+		KDF:     encryptedCredentialsKDF,
+		Cipher:  encryptedCredentialsCipher,
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Salt:    salt,
+		Nonce:   nonce,
+		Data:    aead.Seal(nil, nonce, plaintext, nil),
+	}
+
+	b, _ := json.MarshalIndent(envelope, "", "    ")
+	if err := ioutil.WriteFile(s.path, b, 0600); err != nil {
+		return errors.WithMessage(err, "cannot save the credentials")
+	}
+
+	return nil
+}
+
+func (s *encryptedFileCredentialsStore) Clean() error {
+	return removeCredentialsFile(s.path)
+}
+
+func newCredentialsAEAD(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, scryptKeyLen)
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot derive the encryption key")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	keyringServiceAttr    = "service"
+	keyringServiceValue   = "mmctl"
+	keyringConnectionAttr = "connection"
+)
+
+// keyringCredentialsStore keeps the auth tokens in the system keyring
+// through the Secret Service D-Bus API, using the secret-tool
+// utility. The configuration file only stores the non sensitive
+// information of each credential.
+type keyringCredentialsStore struct {
+	path string
+	run  func(stdin string, args ...string) (string, error)
+}
+
+func runSecretTool(stdin string, args ...string) (string, error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return "", errors.New("cannot find the secret-tool binary, it is required to access the system keyring")
+	}
+
+	var stdout, stderr bytes.Buffer
+	c := exec.Command(path, args...) // nolint:gosec
+	c.Stdin = strings.NewReader(stdin)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+
+	return stdout.String(), nil
+}
+
+func (s *keyringCredentialsStore) Name() string {
+	return CredentialsStoreKeyring
+}
+
+func (s *keyringCredentialsStore) Read() (*CredentialsList, error) {
+	credentialsList, err := readCredentialsFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range sortedCredentialNames(credentialsList) {
+		token, err := s.run("", "lookup", keyringServiceAttr, keyringServiceValue, keyringConnectionAttr, name)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "cannot read the auth token for %q from the keyring", name)
+		}
+		(*credentialsList)[name].AuthToken = token
+	}
+
+	return credentialsList, nil
+}
+
+func (s *keyringCredentialsStore) Write(credentialsList *CredentialsList) error {
+	// entries removed from the list need to be removed from the
+	// keyring as well
+	if previous, err := readCredentialsFile(s.path); err == nil {
+		removed := []string{}
+		for _, name := range sortedCredentialNames(previous) {
+			if _, ok := (*credentialsList)[name]; !ok {
+				removed = append(removed, name)
+			}
+		}
+		if err := s.clearSecrets(removed); err != nil {
+			return err
+		}
+	}
+
+	metadata := CredentialsList{}
+	for _, name := range sortedCredentialNames(credentialsList) {
+		c := *(*credentialsList)[name]
+		if _, err := s.run(c.AuthToken, "store", "--label=mmctl credentials for "+name, keyringServiceAttr, keyringServiceValue, keyringConnectionAttr, name); err != nil {
+			return errors.WithMessagef(err, "cannot store the auth token for %q in the keyring", name)
+		}
+		c.AuthToken = ""
+		metadata[name] = &c
+	}
+
+	return writeCredentialsFile(s.path, &metadata)
+}
+
+func (s *keyringCredentialsStore) Clean() error {
+	if credentialsList, err := readCredentialsFile(s.path); err == nil {
+		if err := s.clearSecrets(sortedCredentialNames(credentialsList)); err != nil {
+			return err
+		}
+	}
+
+	return removeCredentialsFile(s.path)
+}
+
+func (s *keyringCredentialsStore) clearSecrets(names []string) error {
+	for _, name := range names {
+		if _, err := s.run("", "clear", keyringServiceAttr, keyringServiceValue, keyringConnectionAttr, name); err != nil {
+			return errors.WithMessagef(err, "cannot remove the auth token for %q from the keyring", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKeyring map[string]string
+
+func (k fakeKeyring) run(stdin string, args ...string) (string, error) {
+	name := args[len(args)-1]
+	switch args[0] {
+	case "store":
+		k[name] = stdin
+	case "lookup":
+		token, ok := k[name]
+		if !ok {
+			return "", errors.New("not found")
+		}
+		return token, nil
+	case "clear":
+		delete(k, name)
+	}
+	return "", nil
+}
+
+func testCredentialsList() *CredentialsList {
+	return &CredentialsList{
+		"local": {
+			Name:        "local",
+			Username:    "sysadmin",
+			AuthToken:   "local-token",
+			AuthMethod:  MethodPassword,
+			InstanceURL: "http://localhost:8065",
+			Active:      true,
+		},
+		"remote": {
+			Name:        "remote",
+			Username:    "admin",
+			AuthToken:   "remote-token",
+			AuthMethod:  MethodToken,
+			InstanceURL: "https://mattermost.example.com",
+		},
+	}
+}
+
+func TestCredentialsStores(t *testing.T) {
+	t.Run("should reject an unknown store", func(t *testing.T) {
+		_, err := NewCredentialsStore("vault", "config")
+		require.EqualError(t, err, `invalid credentials store "vault", must be one of: file, encrypted-file, keyring`)
+	})
+
+	t.Run("file store should save and read the credentials", func(t *testing.T) {
+		tmp, _ := ioutil.TempDir("", "mmctl-")
+		defer os.RemoveAll(tmp)
+		path := filepath.Join(tmp, "config")
+
+		store := &fileCredentialsStore{path: path}
+		require.NoError(t, store.Write(testCredentialsList()))
+
+		credentialsList, err := store.Read()
+		require.NoError(t, err)
+		require.Equal(t, testCredentialsList(), credentialsList)
+
+		require.NoError(t, store.Clean())
+		_, err = os.Stat(path)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("encrypted file store should not write the tokens in plain text", func(t *testing.T) {
+		tmp, _ := ioutil.TempDir("", "mmctl-")
+		defer os.RemoveAll(tmp)
+		path := filepath.Join(tmp, "config")
+
+		store := &encryptedFileCredentialsStore{path: path, passphrase: func() (string, error) { return "secret", nil }}
+		require.NoError(t, store.Write(testCredentialsList()))
+
+		b, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		require.NotContains(t, string(b), "local-token")
+		require.NotContains(t, string(b), "sysadmin")
+
+		credentialsList, err := store.Read()
+		require.NoError(t, err)
+		require.Equal(t, testCredentialsList(), credentialsList)
+	})
+
+	t.Run("encrypted file store should fail with a wrong passphrase", func(t *testing.T) {
+		tmp, _ := ioutil.TempDir("", "mmctl-")
+		defer os.RemoveAll(tmp)
+		path := filepath.Join(tmp, "config")
+
+		store := &encryptedFileCredentialsStore{path: path, passphrase: func() (string, error) { return "secret", nil }}
+		require.NoError(t, store.Write(testCredentialsList()))
+
+		store.passphrase = func() (string, error) { return "wrong", nil }
+		_, err := store.Read()
+		require.EqualError(t, err, "cannot decrypt the credentials file, the passphrase may be wrong")
+	})
+
+	t.Run("keyring store should keep the tokens out of the config file", func(t *testing.T) {
+		tmp, _ := ioutil.TempDir("", "mmctl-")
+		defer os.RemoveAll(tmp)
+		path := filepath.Join(tmp, "config")
+
+		keyring := fakeKeyring{}
+		store := &keyringCredentialsStore{path: path, run: keyring.run}
+		require.NoError(t, store.Write(testCredentialsList()))
+		require.Equal(t, fakeKeyring{"local": "local-token", "remote": "remote-token"}, keyring)
+
+		b, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		require.NotContains(t, string(b), "local-token")
+
+		credentialsList, err := store.Read()
+		require.NoError(t, err)
+		require.Equal(t, testCredentialsList(), credentialsList)
+
+		delete(*credentialsList, "remote")
+		require.NoError(t, store.Write(credentialsList))
+		require.Equal(t, fakeKeyring{"local": "local-token"}, keyring)
+
+		require.NoError(t, store.Clean())
+		require.Empty(t, keyring)
+	})
+
+	t.Run("should migrate the credentials between stores", func(t *testing.T) {
+		tmp, _ := ioutil.TempDir("", "mmctl-")
+		defer os.RemoveAll(tmp)
+		path := filepath.Join(tmp, "config")
+
+		fileStore := &fileCredentialsStore{path: path}
+		require.NoError(t, fileStore.Write(testCredentialsList()))
+
+		keyring := fakeKeyring{}
+		keyringStore := &keyringCredentialsStore{path: path, run: keyring.run}
+		count, err := MigrateCredentials(fileStore, keyringStore)
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		require.Len(t, keyring, 2)
+
+		encryptedStore := &encryptedFileCredentialsStore{path: path, passphrase: func() (string, error) { return "secret", nil }}
+		count, err = MigrateCredentials(keyringStore, encryptedStore)
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		require.Empty(t, keyring)
+
+		credentialsList, err := encryptedStore.Read()
+		require.NoError(t, err)
+		require.Equal(t, testCredentialsList(), credentialsList)
+	})
+}
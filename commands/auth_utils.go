@@ -4,7 +4,6 @@
 package commands
 
 import (
-	"encoding/json"
 	"io/ioutil"
 	"os"
 	"os/user"
@@ -99,23 +98,12 @@ func resolveConfigFilePath() string {
 }
 
 func ReadCredentialsList() (*CredentialsList, error) {
-	configPath := resolveConfigFilePath()
-
-	if _, err := os.Stat(configPath); err != nil {
-		return nil, errors.WithMessage(err, "cannot read user credentials, maybe you need to use login first")
-	}
-
-	fileContents, err := ioutil.ReadFile(configPath)
+	store, err := getCredentialsStore()
 	if err != nil {
-		return nil, errors.WithMessage(err, "there was a problem reading the credentials file")
-	}
-
-	var credentialsList CredentialsList
-	if err := json.Unmarshal(fileContents, &credentialsList); err != nil {
-		return nil, errors.WithMessage(err, "there was a problem parsing the credentials file")
+		return nil, err
 	}
 
-	return &credentialsList, nil
+	return store.Read()
 }
 
 func GetCurrentCredentials() (*Credentials, error) {
@@ -163,15 +151,12 @@ func SaveCredentials(credentials Credentials) error {
 }
 
 func SaveCredentialsList(credentialsList *CredentialsList) error {
-	configPath := resolveConfigFilePath()
-
-	marshaledCredentialsList, _ := json.MarshalIndent(credentialsList, "", "    ")
-
-	if err := ioutil.WriteFile(configPath, marshaledCredentialsList, 0600); err != nil {
-		return errors.WithMessage(err, "cannot save the credentials")
+	store, err := getCredentialsStore()
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return store.Write(credentialsList)
 }
 
 func SetCurrent(name string) error {
@@ -198,19 +183,12 @@ func SetCurrent(name string) error {
 }
 
 func CleanCredentials() error {
-	configFilePath := resolveConfigFilePath()
-
-	if _, err := os.Stat(configFilePath); err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
+	store, err := getCredentialsStore()
+	if err != nil {
 		return err
 	}
 
-	if err := os.Remove(configFilePath); err != nil {
-		return err
-	}
-	return nil
+	return store.Clean()
 }
 
 func SetUser(newUser *user.User) {
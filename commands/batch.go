@@ -0,0 +1,363 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+const (
+	BatchStatusSuccess = "success"
+	BatchStatusFailed  = "failed"
+	BatchStatusSkipped = "skipped"
+)
+
+const batchCommandName = "batch"
+
+var batchAssignmentRegexp = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+
+// sharedClient is set while running commands in-process, so they
+// reuse the already authenticated client instead of creating one
+var sharedClient client.Client
+
+var BatchCmd = &cobra.Command{
+	Use:   batchCommandName + " -f [commands file]",
+	Short: "Run a list of commands",
+	Long: `Runs a list of mmctl commands, one per line, reusing a single authenticated client for all of them.
+Lines are parsed with shell-like quoting, empty lines and lines starting with "#" are ignored and the "mmctl" prefix is optional.
+Variables can be defined with the --var flag or with "NAME=value" lines, and are expanded with "$NAME" or "${NAME}". Environment variables are expanded as well.
+Output flags, such as --json, apply to the whole batch and must be set on the batch command. The flags given to a line, including the global ones, only apply to that line.`,
+	Example: `  batch -f commands.txt
+  batch -f commands.txt --var team=myteam --continue-on-error
+  cat commands.txt | batch --json`,
+	Args: cobra.NoArgs,
+	RunE: withClient(batchCmdF),
+}
+
+func init() {
+	BatchCmd.Flags().StringP("file", "f", "-", "File with the commands to run. Use \"-\" to read them from the standard input")
+	BatchCmd.Flags().StringToString("var", map[string]string{}, "Variables available to the commands, in name=value form")
+	BatchCmd.Flags().Bool("continue-on-error", false, "Keep running the rest of the commands after one fails")
+
+	RootCmd.AddCommand(BatchCmd)
+}
+
+// BatchResult is the outcome of a line of the batch
+type BatchResult struct {
+	Line    int           `json:"line"`
+	Command string        `json:"command"`
+	Status  string        `json:"status"`
+	Error   string        `json:"error,omitempty"`
+	Output  []interface{} `json:"output,omitempty"`
+}
+
+type batchLine struct {
+	number int
+	text   string
+}
+
+// splitCommandLine splits a line into arguments, honoring single
+// and double quotes and backslash escapes
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg, escaped := false, false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped || quote != 0 {
+		return nil, errors.New("unterminated quote or escape sequence")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+func readBatchLines(r io.Reader) ([]batchLine, error) {
+	var lines []batchLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for i := 1; scanner.Scan(); i++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		lines = append(lines, batchLine{number: i, text: text})
+	}
+	return lines, scanner.Err()
+}
+
+// resetFlags restores the flags to their default values, so a
+// command run more than once in the same process doesn't keep the
+// values of previous runs
+func resetFlags(flags *pflag.FlagSet) {
+	flags.VisitAll(func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			_ = sv.Replace([]string{})
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+}
+
+// saveFlags returns a function that restores the flags to their
+// current values
+func saveFlags(flags *pflag.FlagSet) func() {
+	type savedFlag struct {
+		value   []string
+		changed bool
+	}
+	saved := map[string]savedFlag{}
+	flags.VisitAll(func(f *pflag.Flag) {
+		value := []string{f.Value.String()}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			value = sv.GetSlice()
+		}
+		saved[f.Name] = savedFlag{value: value, changed: f.Changed}
+	})
+
+	return func() {
+		flags.VisitAll(func(f *pflag.Flag) {
+			s := saved[f.Name]
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				_ = sv.Replace(s.value)
+			} else {
+				_ = f.Value.Set(s.value[0])
+			}
+			f.Changed = s.changed
+		})
+	}
+}
+
+// runPersistentPreRun runs the nearest persistent pre run hook of a
+// command, as cobra does. The hook of the root command only applies
+// the output flags, as the client, the tracing and the cassette of
+// the running command are reused
+func runPersistentPreRun(cmd *cobra.Command, args []string) error {
+	for p := cmd; p != nil; p = p.Parent() {
+		switch {
+		case p == RootCmd:
+			return applyOutputFlags(cmd)
+		case p.PersistentPreRunE != nil:
+			return p.PersistentPreRunE(cmd, args)
+		case p.PersistentPreRun != nil:
+			p.PersistentPreRun(cmd, args)
+			return nil
+		}
+	}
+	return nil
+}
+
+// executeCommandInProcess finds and runs a command of the mmctl tree
+// with the given arguments, reusing the client of the running
+// command. The persistent flags given to the command, such as the
+// output ones, only apply to it, so the caller must apply its own
+// output flags again once the output of the command is printed. If
+// prepare is not nil, it can modify the command flags and its
+// arguments before they are validated
+func executeCommandInProcess(args []string, prepare func(cmd *cobra.Command, args []string) ([]string, error)) error {
+	if len(args) > 0 && args[0] == RootCmd.Name() {
+		args = args[1:]
+	}
+
+	cmd, flags, err := RootCmd.Find(args)
+	if err != nil {
+		return err
+	}
+	if cmd == RootCmd || (cmd.RunE == nil && cmd.Run == nil) {
+		return fmt.Errorf("%q is not a runnable command", strings.Join(args, " "))
+	}
+
+	defer saveFlags(RootCmd.PersistentFlags())()
+
+	resetFlags(cmd.LocalNonPersistentFlags())
+	if err := cmd.ParseFlags(flags); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return cmd.Help()
+		}
+		return err
+	}
+	cmdArgs := cmd.Flags().Args()
+	if prepare != nil {
+		if cmdArgs, err = prepare(cmd, cmdArgs); err != nil {
+			return err
+		}
+	}
+	if err := cmd.ValidateArgs(cmdArgs); err != nil {
+		return err
+	}
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return err
+	}
+
+	printer.SetSingle(false)
+	if err := runPersistentPreRun(cmd, cmdArgs); err != nil {
+		return err
+	}
+	if cmd.PreRunE != nil {
+		if err := cmd.PreRunE(cmd, cmdArgs); err != nil {
+			return err
+		}
+	} else if cmd.PreRun != nil {
+		cmd.PreRun(cmd, cmdArgs)
+	}
+	if cmd.RunE != nil {
+		return cmd.RunE(cmd, cmdArgs)
+	}
+	cmd.Run(cmd, cmdArgs)
+	return nil
+}
+
+func batchCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	file, _ := cmd.Flags().GetString("file")
+	vars, _ := cmd.Flags().GetStringToString("var")
+	continueOnError, _ := cmd.Flags().GetBool("continue-on-error")
+
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return fmt.Errorf("could not open the commands file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	lines, err := readBatchLines(r)
+	if err != nil {
+		return fmt.Errorf("could not read the commands: %w", err)
+	}
+
+	previousClient := sharedClient
+	sharedClient = c
+	defer func() { sharedClient = previousClient }()
+
+	expand := func(name string) string {
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return os.Getenv(name)
+	}
+
+	var results []*BatchResult
+	var result *multierror.Error
+	failed := false
+	// the variable assignments are not counted, so a batch where
+	// all the commands failed is not a partial failure
+	succeeded := 0
+	for _, line := range lines {
+		text := os.Expand(line.text, expand)
+		res := &BatchResult{Line: line.number, Command: text}
+		results = append(results, res)
+
+		if failed && !continueOnError {
+			res.Status = BatchStatusSkipped
+			continue
+		}
+
+		if m := batchAssignmentRegexp.FindStringSubmatch(text); m != nil {
+			value, err := splitCommandLine(m[2])
+			if err == nil && len(value) <= 1 {
+				vars[m[1]] = strings.Join(value, "")
+				res.Status = BatchStatusSuccess
+				continue
+			}
+		}
+
+		err := runBatchLine(text)
+		// the output flags of the line only apply to its own output
+		_ = applyOutputFlags(cmd)
+		res.Output = printer.GetLines()
+		errorLines := printer.GetErrorLines()
+		printer.Clean()
+		for _, errorLine := range errorLines {
+			printer.PrintError(fmt.Sprintf("line %d: %s", line.number, errorLine))
+		}
+		if err != nil {
+			failed = true
+			res.Status = BatchStatusFailed
+			res.Error = err.Error()
+			result = multierror.Append(result, fmt.Errorf("line %d: %w", line.number, err))
+			continue
+		}
+		res.Status = BatchStatusSuccess
+		succeeded++
+	}
+
+	for _, res := range results {
+		printer.PrintT(`{{.Line}}: {{.Status}}: {{.Command}}{{if .Error}}
+    error: {{.Error}}{{end}}{{range .Output}}
+    {{.}}{{end}}`, res)
+	}
+
+	if result == nil {
+		return nil
+	}
+	if !continueOnError {
+		return result.Errors[0]
+	}
+	return partialFailure(result, succeeded)
+}
+
+func runBatchLine(text string) error {
+	args, err := splitCommandLine(text)
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 && args[0] == RootCmd.Name() {
+		args = args[1:]
+	}
+	if len(args) > 0 && args[0] == batchCommandName {
+		return errors.New("batch commands cannot be nested")
+	}
+
+	return executeCommandInProcess(args, nil)
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestSplitCommandLine() {
+	testCases := []struct {
+		line     string
+		expected []string
+	}{
+		{`team list`, []string{"team", "list"}},
+		{`  team   rename  myteam --display-name "My Team"  `, []string{"team", "rename", "myteam", "--display-name", "My Team"}},
+		{`post create myteam:town-square --message 'it\'s'`, nil},
+		{`post create myteam:town-square --message "it's \"quoted\""`, []string{"post", "create", "myteam:town-square", "--message", `it's "quoted"`}},
+		{`user create --password a\ b`, []string{"user", "create", "--password", "a b"}},
+		{`channel rename myteam:chan --display-name ""`, []string{"channel", "rename", "myteam:chan", "--display-name", ""}},
+	}
+
+	for _, tc := range testCases {
+		args, err := splitCommandLine(tc.line)
+		if tc.expected == nil {
+			s.Require().Error(err, tc.line)
+			continue
+		}
+		s.Require().NoError(err, tc.line)
+		s.Require().Equal(tc.expected, args, tc.line)
+	}
+}
+
+func (s *MmctlUnitTestSuite) TestBatchCmd() {
+	s.setTestOutputFlags()
+
+	writeBatchFile := func(content string) string {
+		dir, err := ioutil.TempDir("", "mmctl-batch-")
+		s.Require().NoError(err)
+		s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+		path := filepath.Join(dir, "commands.txt")
+		s.Require().NoError(ioutil.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	newBatchCmd := func(file string, continueOnError bool, vars map[string]string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("file", file, "")
+		cmd.Flags().StringToString("var", vars, "")
+		cmd.Flags().Bool("continue-on-error", continueOnError, "")
+		return cmd
+	}
+
+	s.Run("should run every command with the shared client and expand variables", func() {
+		printer.Clean()
+		path := writeBatchFile(`# rename the team
+mmctl team rename $team --display-name "New Name"
+
+NAME=other
+team rename ${NAME} --display-name Other
+`)
+		team := &model.Team{Id: "teamid", Name: "myteam"}
+		other := &model.Team{Id: "otherid", Name: "other"}
+
+		s.client.EXPECT().GetTeam("myteam", "").Return(team, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().UpdateTeam(&model.Team{Id: "teamid", Name: "myteam", DisplayName: "New Name"}).Return(team, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetTeam("other", "").Return(other, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().UpdateTeam(&model.Team{Id: "otherid", Name: "other", DisplayName: "Other"}).Return(other, &model.Response{}, nil).Times(1)
+
+		err := batchCmdF(s.client, newBatchCmd(path, false, map[string]string{"team": "myteam"}), []string{})
+		s.Require().NoError(err)
+
+		lines := printer.GetLines()
+		s.Require().Len(lines, 3)
+		s.Require().Equal(&BatchResult{Line: 2, Command: `mmctl team rename myteam --display-name "New Name"`, Status: BatchStatusSuccess, Output: []interface{}{"'myteam' team renamed"}}, lines[0])
+		s.Require().Equal(&BatchResult{Line: 4, Command: "NAME=other", Status: BatchStatusSuccess}, lines[1])
+		s.Require().Equal(BatchStatusSuccess, lines[2].(*BatchResult).Status)
+		s.Require().Nil(sharedClient)
+	})
+
+	s.Run("should skip the rest of the commands after a failure", func() {
+		printer.Clean()
+		path := writeBatchFile(`team list
+team unknown-subcommand-with-args --flag
+team list`)
+
+		s.client.EXPECT().GetAllTeams("", 0, APILimitMaximum).Return(nil, &model.Response{}, errors.New("mock error")).Times(1)
+
+		err := batchCmdF(s.client, newBatchCmd(path, false, map[string]string{}), []string{})
+		s.Require().EqualError(err, "line 1: mock error")
+
+		lines := printer.GetLines()
+		s.Require().Len(lines, 3)
+		s.Require().Equal(BatchStatusFailed, lines[0].(*BatchResult).Status)
+		s.Require().Equal(BatchStatusSkipped, lines[1].(*BatchResult).Status)
+		s.Require().Equal(BatchStatusSkipped, lines[2].(*BatchResult).Status)
+	})
+
+	s.Run("should continue after a failure if requested", func() {
+		printer.Clean()
+		path := writeBatchFile(`team list
+batch -f other.txt
+team list`)
+
+		s.client.EXPECT().GetAllTeams("", 0, APILimitMaximum).Return(nil, &model.Response{}, errors.New("mock error")).Times(1)
+		s.client.EXPECT().GetAllTeams("", 0, APILimitMaximum).Return([]*model.Team{{Name: "team1"}}, &model.Response{}, nil).Times(1)
+
+		err := batchCmdF(s.client, newBatchCmd(path, true, map[string]string{}), []string{})
+		s.Require().Error(err)
+		s.Require().Equal(ExitCodePartialFailure, ExitCodeForError(err))
+		s.Require().Contains(err.Error(), "line 2: batch commands cannot be nested")
+	})
+
+	s.Run("should apply the persistent flags of a line only to it", func() {
+		printer.Clean()
+		path := writeBatchFile(`team list --json --query [0].name
+team list --format xml
+team list`)
+
+		s.client.EXPECT().GetAllTeams("", 0, APILimitMaximum).Return([]*model.Team{{Name: "team1"}}, &model.Response{}, nil).Times(2)
+
+		err := batchCmdF(s.client, newBatchCmd(path, true, map[string]string{}), []string{})
+		s.Require().EqualError(err, "1 error occurred:\n\t* line 2: invalid format \"xml\", must be one of: "+strings.Join(printer.Formats(), ", ")+"\n\n")
+		s.Require().Equal(ExitCodePartialFailure, ExitCodeForError(err))
+
+		lines := printer.GetLines()
+		s.Require().Len(lines, 3)
+		s.Require().Equal(BatchStatusSuccess, lines[2].(*BatchResult).Status)
+		s.Require().False(viper.GetBool("json"))
+		s.Require().Empty(viper.GetString("query"))
+		s.Require().Equal(printer.FormatJSON, viper.GetString("format"))
+		s.Require().False(RootCmd.PersistentFlags().Lookup("json").Changed)
+	})
+
+	s.Run("should fail the local only commands without stopping the batch", func() {
+		printer.Clean()
+		path := writeBatchFile(`user deleteall
+team list`)
+
+		s.client.EXPECT().GetAllTeams("", 0, APILimitMaximum).Return([]*model.Team{{Name: "team1"}}, &model.Response{}, nil).Times(1)
+
+		err := batchCmdF(s.client, newBatchCmd(path, true, map[string]string{}), []string{})
+		s.Require().EqualError(err, "1 error occurred:\n\t* line 1: this command can only be run in local mode\n\n")
+		s.Require().Equal(BatchStatusSuccess, printer.GetLines()[1].(*BatchResult).Status)
+	})
+}
+
+// setTestOutputFlags adds the output flags of the root command, which
+// are only added when mmctl runs, so the commands run in-process can
+// be given their own output flags. Their format is json, the one of
+// the printer in the tests
+func (s *MmctlUnitTestSuite) setTestOutputFlags() {
+	names := []string{"format", "json", "template", "columns", "query", "quiet"}
+	flags := RootCmd.PersistentFlags()
+	if flags.Lookup("format") == nil {
+		flags.String("format", printer.FormatJSON, "")
+		flags.Bool("json", false, "")
+		flags.String("template", "", "")
+		flags.StringSlice("columns", nil, "")
+		flags.String("query", "", "")
+		flags.Bool("quiet", false, "")
+	}
+	for _, name := range names {
+		_ = viper.BindPFlag(name, flags.Lookup(name))
+	}
+
+	// the other tests expect the plain format of mmctl
+	s.T().Cleanup(func() {
+		plain := pflag.NewFlagSet("", pflag.ContinueOnError)
+		plain.String("format", printer.FormatPlain, "")
+		_ = viper.BindPFlag("format", plain.Lookup("format"))
+	})
+}
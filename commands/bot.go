@@ -26,18 +26,19 @@ var CreateBotCmd = &cobra.Command{
 	Short:   "Create bot",
 	Long:    "Create bot.",
 	Example: `  bot create testbot`,
-	PreRun:  disableLocalPrecheck,
+	PreRunE: disableLocalPrecheck,
 	RunE:    withClient(botCreateCmdF),
 	Args:    cobra.ExactArgs(1),
 }
 
 var UpdateBotCmd = &cobra.Command{
-	Use:     "update [username]",
-	Short:   "Update bot",
-	Long:    "Update bot information.",
-	Example: `  bot update testbot --username newbotusername`,
-	RunE:    withClient(botUpdateCmdF),
-	Args:    cobra.ExactArgs(1),
+	Use:               "update [username]",
+	Short:             "Update bot",
+	Long:              "Update bot information.",
+	Example:           `  bot update testbot --username newbotusername`,
+	ValidArgsFunction: validArgs(completeBots, completeNone),
+	RunE:              withClient(botUpdateCmdF),
+	Args:              cobra.ExactArgs(1),
 }
 
 var ListBotCmd = &cobra.Command{
@@ -50,30 +51,33 @@ var ListBotCmd = &cobra.Command{
 }
 
 var DisableBotCmd = &cobra.Command{
-	Use:     "disable [username]",
-	Short:   "Disable bot",
-	Long:    "Disable an enabled bot",
-	Example: `  bot disable testbot`,
-	RunE:    withClient(botDisableCmdF),
-	Args:    cobra.MinimumNArgs(1),
+	Use:               "disable [username]",
+	Short:             "Disable bot",
+	Long:              "Disable an enabled bot",
+	Example:           `  bot disable testbot`,
+	ValidArgsFunction: validArgs(completeBots),
+	RunE:              withClient(botDisableCmdF),
+	Args:              cobra.MinimumNArgs(1),
 }
 
 var EnableBotCmd = &cobra.Command{
-	Use:     "enable [username]",
-	Short:   "Enable bot",
-	Long:    "Enable a disabled bot",
-	Example: `  bot enable testbot`,
-	RunE:    withClient(botEnableCmdF),
-	Args:    cobra.MinimumNArgs(1),
+	Use:               "enable [username]",
+	Short:             "Enable bot",
+	Long:              "Enable a disabled bot",
+	Example:           `  bot enable testbot`,
+	ValidArgsFunction: validArgs(completeBots),
+	RunE:              withClient(botEnableCmdF),
+	Args:              cobra.MinimumNArgs(1),
 }
 
 var AssignBotCmd = &cobra.Command{
-	Use:     "assign [bot-username] [new-owner-username]",
-	Short:   "Assign bot",
-	Long:    "Assign the ownership of a bot to another user",
-	Example: `  bot assign testbot user2`,
-	RunE:    withClient(botAssignCmdF),
-	Args:    cobra.ExactArgs(2),
+	Use:               "assign [bot-username] [new-owner-username]",
+	Short:             "Assign bot",
+	Long:              "Assign the ownership of a bot to another user",
+	Example:           `  bot assign testbot user2`,
+	ValidArgsFunction: validArgs(completeBots, completeUsers, completeNone),
+	RunE:              withClient(botAssignCmdF),
+	Args:              cobra.ExactArgs(2),
 }
 
 func init() {
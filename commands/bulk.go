@@ -0,0 +1,209 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+const (
+	bulkProgressWidth    = 30
+	bulkProgressInterval = 100 * time.Millisecond
+)
+
+// errBulkSkipped is returned by the tasks of the bulk commands for
+// the targets that don't need any change
+var errBulkSkipped = errors.New("skipped")
+
+// bulkOutput is where the progress and the summary of the bulk
+// commands are written
+var bulkOutput io.Writer = os.Stderr
+
+// bulkTask processes a target of a bulk command. Tasks run
+// concurrently, so instead of using the printer they return a
+// function that prints their output, which is called in the order of
+// the targets once all of them have been processed
+type bulkTask func(target string) (print func(), err error)
+
+// bulkReport contains the outcome of a bulk command
+type bulkReport struct {
+	Succeeded int
+	Skipped   int
+	// Failed contains the targets that failed, in the order they
+	// were passed
+	Failed []string
+	errs   []error
+}
+
+// errors returns the errors of the targets that failed, or nil if
+// all of them succeeded
+func (r *bulkReport) errors() error {
+	return r.errorsMatching(func(error) bool { return true })
+}
+
+// errorsMatching returns the errors of the targets that failed for
+// which match returns true, or nil if there are none. It allows the
+// commands that only fail for some errors to report the rest of them
+// without failing
+func (r *bulkReport) errorsMatching(match func(error) bool) error {
+	var result *multierror.Error
+	for _, err := range r.errs {
+		if match(err) {
+			result = multierror.Append(result, err)
+		}
+	}
+	if result == nil {
+		return nil
+	}
+	return partialFailure(result, r.Succeeded)
+}
+
+func (r *bulkReport) String() string {
+	return fmt.Sprintf("%d succeeded, %d failed, %d skipped", r.Succeeded, len(r.Failed), r.Skipped)
+}
+
+// addBulkFlags adds the flags that control how the targets of a bulk
+// command are read and processed
+func addBulkFlags(cmd *cobra.Command) {
+	addFromFileFlags(cmd)
+	cmd.Flags().Int("parallel", 1, "number of targets processed concurrently")
+	cmd.Flags().String("failures-file", "", "writes the targets that failed to a file, one per line, so they can be retried with --from-file")
+}
+
+type bulkResult struct {
+	print func()
+	err   error
+}
+
+// runBulk runs the task for every target using the number of workers
+// set with the --parallel flag, showing the progress on terminals.
+// Once all the targets are processed, it prints their output and
+// writes the targets that failed to the --failures-file. The summary
+// is only printed when the --parallel or --failures-file flags are
+// used or the progress is shown, so the output of the commands
+// doesn't change otherwise.
+// The errors of the targets read from a file contain their line
+func runBulk(cmd *cobra.Command, noun string, targets []fileArg, task bulkTask) (*bulkReport, error) {
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	if parallel < 1 {
+		parallel = 1
+	}
+	if parallel > len(targets) {
+		parallel = len(targets)
+	}
+
+	results := make([]bulkResult, len(targets))
+	indexes := make(chan int)
+	done := make(chan int)
+	for w := 0; w < parallel; w++ {
+		go func() {
+			for i := range indexes {
+				printResult, err := task(targets[i].Value)
+				results[i] = bulkResult{print: printResult, err: err}
+				done <- i
+			}
+		}()
+	}
+	go func() {
+		for i := range targets {
+			indexes <- i
+		}
+		close(indexes)
+	}()
+
+	quiet := viper.GetBool("quiet")
+	progress := newBulkProgress(bulkOutput, noun, len(targets), !quiet && len(targets) > 1)
+	for range targets {
+		i := <-done
+		progress.add(results[i].err != nil && !errors.Is(results[i].err, errBulkSkipped))
+	}
+	progress.finish()
+
+	report := &bulkReport{}
+	for i, result := range results {
+		if result.print != nil {
+			result.print()
+		}
+		switch {
+		case result.err == nil:
+			report.Succeeded++
+		case errors.Is(result.err, errBulkSkipped):
+			report.Skipped++
+		default:
+			report.Failed = append(report.Failed, targets[i].Value)
+			report.errs = append(report.errs, targets[i].wrap(result.err))
+		}
+	}
+
+	path, _ := cmd.Flags().GetString("failures-file")
+	if !quiet && len(targets) > 1 && (progress.enabled || cmd.Flags().Changed("parallel") || path != "") {
+		fmt.Fprintln(bulkOutput, report.String())
+	}
+
+	if path != "" {
+		var sb strings.Builder
+		for _, target := range report.Failed {
+			sb.WriteString(target + "\n")
+		}
+		if err := os.WriteFile(path, []byte(sb.String()), 0600); err != nil {
+			return report, fmt.Errorf("could not write the failures file: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+// bulkProgress draws a progress bar on a terminal
+type bulkProgress struct {
+	w       io.Writer
+	noun    string
+	total   int
+	done    int
+	failed  int
+	enabled bool
+	last    time.Time
+}
+
+func newBulkProgress(w io.Writer, noun string, total int, enabled bool) *bulkProgress {
+	if f, ok := w.(*os.File); !ok || !term.IsTerminal(int(f.Fd())) {
+		enabled = false
+	}
+	return &bulkProgress{w: w, noun: noun, total: total, enabled: enabled}
+}
+
+func (p *bulkProgress) add(failed bool) {
+	p.done++
+	if failed {
+		p.failed++
+	}
+
+	if !p.enabled || p.done < p.total && time.Since(p.last) < bulkProgressInterval {
+		return
+	}
+	p.last = time.Now()
+	fmt.Fprintf(p.w, "\r%s", p.String())
+}
+
+func (p *bulkProgress) String() string {
+	filled := bulkProgressWidth * p.done / p.total
+	return fmt.Sprintf("[%s%s] %d/%d %s, %d failed",
+		strings.Repeat("=", filled), strings.Repeat(" ", bulkProgressWidth-filled), p.done, p.total, p.noun, p.failed)
+}
+
+// finish clears the progress bar, so it doesn't mix with the output
+func (p *bulkProgress) finish() {
+	if p.enabled {
+		fmt.Fprint(p.w, "\r\033[K")
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestRunBulk() {
+	newBulkCmd := func(parallel int, fromFile, failuresFile string) *cobra.Command {
+		cmd := &cobra.Command{}
+		addBulkFlags(cmd)
+		s.Require().NoError(cmd.Flags().Set("parallel", fmt.Sprint(parallel)))
+		s.Require().NoError(cmd.Flags().Set("from-file", fromFile))
+		s.Require().NoError(cmd.Flags().Set("failures-file", failuresFile))
+		return cmd
+	}
+
+	var output bytes.Buffer
+	bulkOutput = &output
+	s.T().Cleanup(func() { bulkOutput = os.Stderr })
+
+	dir, err := ioutil.TempDir("", "mmctl-bulk-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	s.Run("should print the output in order and report every outcome", func() {
+		printer.Clean()
+		output.Reset()
+
+		failuresFile := filepath.Join(dir, "failures.txt")
+		cmd := newBulkCmd(3, "", failuresFile)
+		targets, err := fileArgs(cmd, []string{"a", "b", "skip", "fail1", "c", "fail2"})
+		s.Require().NoError(err)
+
+		var running, maxRunning int32
+		var mu sync.Mutex
+		report, err := runBulk(cmd, "users", targets, func(target string) (func(), error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			mu.Lock()
+			if n > maxRunning {
+				maxRunning = n
+			}
+			mu.Unlock()
+
+			switch target {
+			case "skip":
+				return nil, errBulkSkipped
+			case "fail1", "fail2":
+				return func() { printer.PrintError(target + " failed") }, errors.New(target)
+			}
+			return func() { printer.Print(target) }, nil
+		})
+		s.Require().NoError(err)
+		s.Require().LessOrEqual(maxRunning, int32(3))
+
+		s.Require().Equal(3, report.Succeeded)
+		s.Require().Equal(1, report.Skipped)
+		s.Require().Equal([]string{"fail1", "fail2"}, report.Failed)
+		s.Require().Equal([]interface{}{"a", "b", "c"}, printer.GetLines())
+		s.Require().Equal([]interface{}{"fail1 failed", "fail2 failed"}, printer.GetErrorLines())
+		s.Require().Equal("3 succeeded, 2 failed, 1 skipped\n", output.String())
+
+		var merr *multierror.Error
+		s.Require().True(errors.As(report.errors(), &merr))
+		s.Require().Len(merr.Errors, 2)
+		s.Require().Equal(ExitCodePartialFailure, ExitCodeForError(report.errors()))
+
+		b, err := ioutil.ReadFile(failuresFile)
+		s.Require().NoError(err)
+		s.Require().Equal("fail1\nfail2\n", string(b))
+	})
+
+	s.Run("should add the line to the errors of the targets read from a file", func() {
+		printer.Clean()
+		output.Reset()
+
+		fromFile := filepath.Join(dir, "targets.txt")
+		s.Require().NoError(ioutil.WriteFile(fromFile, []byte("# users\nb\n\n  c  \n"), 0600))
+		cmd := newBulkCmd(1, fromFile, "")
+
+		targets, err := fileArgs(cmd, []string{"a"})
+		s.Require().NoError(err)
+		s.Require().Len(targets, 3)
+
+		report, err := runBulk(cmd, "users", targets, func(target string) (func(), error) {
+			return nil, fmt.Errorf("%s failed", target)
+		})
+		s.Require().NoError(err)
+		s.Require().Equal([]string{"a", "b", "c"}, report.Failed)
+		s.Require().EqualError(report.errors().(*multierror.Error).Errors[2], fromFile+":4: c failed")
+		s.Require().Equal(ExitCodeError, ExitCodeForError(report.errors()))
+
+		s.Require().NoError(minimumArgsOrFile(1)(cmd, []string{"team"}))
+		s.Require().Error(minimumArgsOrFile(1)(newBulkCmd(1, "", ""), []string{"team"}))
+	})
+
+	s.Run("should not print a summary for a single target", func() {
+		printer.Clean()
+		output.Reset()
+
+		report, err := runBulk(newBulkCmd(4, "", ""), "users", []fileArg{{Value: "a"}}, func(string) (func(), error) {
+			return nil, nil
+		})
+		s.Require().NoError(err)
+		s.Require().Nil(report.errors())
+		s.Require().Empty(output.String())
+	})
+
+	s.Run("should only print a summary if the bulk flags are used", func() {
+		printer.Clean()
+		output.Reset()
+
+		cmd := &cobra.Command{}
+		addBulkFlags(cmd)
+		report, err := runBulk(cmd, "users", []fileArg{{Value: "a"}, {Value: "b"}}, func(target string) (func(), error) {
+			if target == "b" {
+				return nil, &NotFoundError{Msg: "b not found"}
+			}
+			return nil, errors.New("a failed")
+		})
+		s.Require().NoError(err)
+		s.Require().Empty(output.String())
+		s.Require().EqualError(report.errorsMatching(func(err error) bool {
+			var nfErr *NotFoundError
+			return errors.As(err, &nfErr)
+		}), "1 error occurred:\n\t* b not found\n\n")
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestUserDeactivateBulk() {
+	var output bytes.Buffer
+	bulkOutput = &output
+	s.T().Cleanup(func() { bulkOutput = os.Stderr })
+
+	dir, err := ioutil.TempDir("", "mmctl-bulk-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	printer.Clean()
+	fromFile := filepath.Join(dir, "users.txt")
+	failuresFile := filepath.Join(dir, "failures.txt")
+	s.Require().NoError(ioutil.WriteFile(fromFile, []byte("user1@example.com\nuser2@example.com\n"), 0600))
+
+	cmd := &cobra.Command{}
+	addBulkFlags(cmd)
+	s.Require().NoError(cmd.Flags().Set("parallel", "2"))
+	s.Require().NoError(cmd.Flags().Set("from-file", fromFile))
+	s.Require().NoError(cmd.Flags().Set("failures-file", failuresFile))
+
+	user1 := model.User{Id: model.NewId(), Email: "user1@example.com"}
+	s.client.
+		EXPECT().
+		GetUserByEmail(user1.Email, "").
+		Return(&user1, &model.Response{}, nil).
+		Times(1)
+	s.client.
+		EXPECT().
+		UpdateUserActive(user1.Id, false).
+		Return(&model.Response{}, nil).
+		Times(1)
+
+	user2 := model.User{Id: model.NewId(), Email: "user2@example.com"}
+	s.client.
+		EXPECT().
+		GetUserByEmail(user2.Email, "").
+		Return(&user2, &model.Response{}, nil).
+		Times(1)
+	s.client.
+		EXPECT().
+		UpdateUserActive(user2.Id, false).
+		Return(&model.Response{StatusCode: 500}, errors.New("mock error")).
+		Times(1)
+
+	err = userDeactivateCmdF(s.client, cmd, nil)
+	s.Require().Error(err)
+	s.Require().Equal(ExitCodePartialFailure, ExitCodeForError(err))
+	s.Require().Equal([]interface{}{"unable to change activation status of user: " + user2.Id}, printer.GetErrorLines())
+	s.Require().Equal("1 succeeded, 1 failed, 0 skipped\n", output.String())
+
+	b, err := ioutil.ReadFile(failuresFile)
+	s.Require().NoError(err)
+	s.Require().Equal("user2@example.com\n", string(b))
+}
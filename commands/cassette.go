@@ -0,0 +1,68 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+var (
+	// recorder stores the interactions of the clients if the
+	// --record flag is set
+	recorder *client.Recorder
+	// replayer answers the requests of the clients from a cassette
+	// if the --replay flag is set
+	replayer *client.ReplayTransport
+)
+
+// initCassette sets up recording or replaying the requests of the
+// clients. It must be called before the clients are created
+func initCassette() error {
+	recordPath := viper.GetString("record")
+	replayPath := viper.GetString("replay")
+
+	switch {
+	case recordPath != "" && replayPath != "":
+		return errors.New("the --record and --replay flags cannot be used together")
+	case replayPath != "":
+		cassette, err := client.LoadCassette(replayPath)
+		if err != nil {
+			return fmt.Errorf("could not load the cassette: %w", err)
+		}
+		replayer = client.NewReplayTransport(cassette)
+	case recordPath != "":
+		recorder = client.NewRecorder()
+	}
+
+	return nil
+}
+
+// finishCassette saves the recorded interactions and stops recording
+// or replaying
+func finishCassette() error {
+	defer func() {
+		recorder = nil
+		replayer = nil
+	}()
+
+	if replayer != nil {
+		if remaining := replayer.Remaining(); remaining > 0 {
+			printer.PrintWarning(fmt.Sprintf("%d recorded interactions were not replayed", remaining))
+		}
+	}
+
+	if recorder == nil {
+		return nil
+	}
+	if err := recorder.Cassette().Save(viper.GetString("record")); err != nil {
+		return fmt.Errorf("could not save the cassette: %w", err)
+	}
+	return nil
+}
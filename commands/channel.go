@@ -41,8 +41,9 @@ var ChannelRenameCmd = &cobra.Command{
 	Example: `  channel rename myteam:oldchannel --name 'new-channel' --display-name 'New Display Name'
   channel rename myteam:oldchannel --name 'new-channel'
   channel rename myteam:oldchannel --display-name 'New Display Name'`,
-	Args: cobra.ExactArgs(1),
-	RunE: withClient(renameChannelCmdF),
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeChannels, completeNone),
+	RunE:              withClient(renameChannelCmdF),
 }
 
 var RemoveChannelUsersCmd = &cobra.Command{
@@ -51,17 +52,19 @@ var RemoveChannelUsersCmd = &cobra.Command{
 	Long:  "Remove some users from channel",
 	Example: `  channel remove myteam:mychannel user@example.com username
   channel remove myteam:mychannel --all-users`,
-	Deprecated: "please use \"users remove\" instead",
-	RunE:       withClient(channelUsersRemoveCmdF),
+	Deprecated:        "please use \"users remove\" instead",
+	ValidArgsFunction: validArgs(completeChannels, completeUsers),
+	RunE:              withClient(channelUsersRemoveCmdF),
 }
 
 var AddChannelUsersCmd = &cobra.Command{
-	Use:        "add [channel] [users]",
-	Short:      "Add users to channel",
-	Long:       "Add some users to channel",
-	Example:    "  channel add myteam:mychannel user@example.com username",
-	Deprecated: "please use \"users add\" instead",
-	RunE:       withClient(channelUsersAddCmdF),
+	Use:               "add [channel] [users]",
+	Short:             "Add users to channel",
+	Long:              "Add some users to channel",
+	Example:           "  channel add myteam:mychannel user@example.com username",
+	Deprecated:        "please use \"users add\" instead",
+	ValidArgsFunction: validArgs(completeChannels, completeUsers),
+	RunE:              withClient(channelUsersAddCmdF),
 }
 
 var ArchiveChannelsCmd = &cobra.Command{
@@ -70,8 +73,10 @@ var ArchiveChannelsCmd = &cobra.Command{
 	Long: `Archive some channels.
 Archive a channel along with all related information including posts from the database.
 Channels can be specified by [team]:[channel]. ie. myteam:mychannel or by channel ID.`,
-	Example: "  channel archive myteam:mychannel",
-	RunE:    withClient(archiveChannelsCmdF),
+	Example: `  channel archive myteam:mychannel
+  channel archive --from-file channels.txt`,
+	ValidArgsFunction: validArgs(completeChannels),
+	RunE:              withClient(archiveChannelsCmdF),
 }
 
 var DeleteChannelsCmd = &cobra.Command{
@@ -79,9 +84,10 @@ var DeleteChannelsCmd = &cobra.Command{
 	Short: "Delete channels",
 	Long: `Permanently delete some channels.
 Permanently deletes one or multiple channels along with all related information including posts from the database.`,
-	Example: "  channel delete myteam:mychannel",
-	Args:    cobra.MinimumNArgs(1),
-	RunE:    withClient(deleteChannelsCmdF),
+	Example:           "  channel delete myteam:mychannel",
+	Args:              minimumArgsOrFile(0),
+	ValidArgsFunction: validArgs(completeChannels),
+	RunE:              withClient(deleteChannelsCmdF),
 }
 
 // ListChannelsCmd is a command which lists all the channels of team(s) in a server.
@@ -91,9 +97,10 @@ var ListChannelsCmd = &cobra.Command{
 	Long: `List all channels on specified teams.
 Archived channels are appended with ' (archived)'.
 Private channels the user is a member of or has access to are appended with ' (private)'.`,
-	Example: "  channel list myteam",
-	Args:    cobra.MinimumNArgs(1),
-	RunE:    withClient(listChannelsCmdF),
+	Example:           "  channel list myteam",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: validArgs(completeTeams),
+	RunE:              withClient(listChannelsCmdF),
 }
 
 var ModifyChannelCmd = &cobra.Command{
@@ -103,8 +110,9 @@ var ModifyChannelCmd = &cobra.Command{
 Channel can be specified by [team]:[channel]. ie. myteam:mychannel or by channel ID.`,
 	Example: `  channel modify myteam:mychannel --private
   channel modify channelId --public`,
-	Args: cobra.ExactArgs(1),
-	RunE: withClient(modifyChannelCmdF),
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeChannels, completeNone),
+	RunE:              withClient(modifyChannelCmdF),
 }
 
 var RestoreChannelsCmd = &cobra.Command{
@@ -113,8 +121,9 @@ var RestoreChannelsCmd = &cobra.Command{
 	Short:      "Restore some channels",
 	Long: `Restore a previously deleted channel
 Channels can be specified by [team]:[channel]. ie. myteam:mychannel or by channel ID.`,
-	Example: "  channel restore myteam:mychannel",
-	RunE:    withClient(unarchiveChannelsCmdF),
+	Example:           "  channel restore myteam:mychannel",
+	ValidArgsFunction: validArgs(completeChannels),
+	RunE:              withClient(unarchiveChannelsCmdF),
 }
 
 var UnarchiveChannelCmd = &cobra.Command{
@@ -122,8 +131,10 @@ var UnarchiveChannelCmd = &cobra.Command{
 	Short: "Unarchive some channels",
 	Long: `Unarchive a previously archived channel
 Channels can be specified by [team]:[channel]. ie. myteam:mychannel or by channel ID.`,
-	Example: "  channel unarchive myteam:mychannel",
-	RunE:    withClient(unarchiveChannelsCmdF),
+	Example: `  channel unarchive myteam:mychannel
+  channel unarchive --from-file channels.csv --from-file-column channel`,
+	ValidArgsFunction: validArgs(completeChannels),
+	RunE:              withClient(unarchiveChannelsCmdF),
 }
 
 var MakeChannelPrivateCmd = &cobra.Command{
@@ -132,9 +143,10 @@ var MakeChannelPrivateCmd = &cobra.Command{
 	Short:   "Set a channel's type to private",
 	Long: `Set the type of a channel from Public to Private.
 Channel can be specified by [team]:[channel]. ie. myteam:mychannel or by channel ID.`,
-	Example:    "  channel make-private myteam:mychannel",
-	Deprecated: "please use \"channel modify --private\" instead",
-	RunE:       withClient(makeChannelPrivateCmdF),
+	Example:           "  channel make-private myteam:mychannel",
+	Deprecated:        "please use \"channel modify --private\" instead",
+	ValidArgsFunction: validArgs(completeChannels, completeNone),
+	RunE:              withClient(makeChannelPrivateCmdF),
 }
 
 var SearchChannelCmd = &cobra.Command{
@@ -154,9 +166,11 @@ var MoveChannelCmd = &cobra.Command{
 	Long: `Moves the provided channels to the specified team.
 Validates that all users in the channel belong to the target team. Incoming/Outgoing webhooks are moved along with the channel.
 Channels can be specified by [team]:[channel]. ie. myteam:mychannel or by channel ID.`,
-	Example: "  channel move newteam oldteam:mychannel",
-	Args:    cobra.MinimumNArgs(2),
-	RunE:    withClient(moveChannelCmdF),
+	Example: `  channel move newteam oldteam:mychannel
+  channel move newteam --from-file channels.json --from-file-column id`,
+	Args:              minimumArgsOrFile(1),
+	ValidArgsFunction: validArgs(completeTeams, completeChannels),
+	RunE:              withClient(moveChannelCmdF),
 }
 
 func init() {
@@ -185,6 +199,12 @@ func init() {
 
 	DeleteChannelsCmd.Flags().Bool("confirm", false, "Confirm you really want to delete the channel and a DB backup has been performed.")
 
+	addFromFileFlags(ArchiveChannelsCmd)
+	addFromFileFlags(UnarchiveChannelCmd)
+	addFromFileFlags(RestoreChannelsCmd)
+	addFromFileFlags(MoveChannelCmd)
+	addFromFileFlags(DeleteChannelsCmd)
+
 	ChannelCmd.AddCommand(
 		ChannelCreateCmd,
 		RemoveChannelUsersCmd,
@@ -257,25 +277,29 @@ func createChannelCmdF(c client.Client, cmd *cobra.Command, args []string) error
 }
 
 func archiveChannelsCmdF(c client.Client, cmd *cobra.Command, args []string) error {
-	if len(args) < 1 {
+	channelArgs, err := fileArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	if len(channelArgs) < 1 {
 		return errors.New("enter at least one channel to archive")
 	}
 
-	channels := getChannelsFromChannelArgs(c, args)
-	var errors *multierror.Error
+	channels := getChannelsFromFileArgs(c, channelArgs)
+	var result *multierror.Error
 	for i, channel := range channels {
 		if channel == nil {
-			printer.PrintError("Unable to find channel '" + args[i] + "'")
-			errors = multierror.Append(errors, fmt.Errorf("unable to find channel %q", args[i]))
+			printer.PrintError(channelArgs[i].wrap(errors.New("Unable to find channel '" + channelArgs[i].Value + "'")).Error())
+			result = multierror.Append(result, channelArgs[i].wrap(fmt.Errorf("unable to find channel %q", channelArgs[i].Value)))
 			continue
 		}
 		if _, err := c.DeleteChannel(channel.Id); err != nil {
 			printer.PrintError("Unable to archive channel '" + channel.Name + "' error: " + err.Error())
-			errors = multierror.Append(errors, fmt.Errorf("unable to archive channel %q, error: %w", channel.Name, err))
+			result = multierror.Append(result, fmt.Errorf("unable to archive channel %q, error: %w", channel.Name, err))
 		}
 	}
 
-	return errors.ErrorOrNil()
+	return result.ErrorOrNil()
 }
 
 func getAllPublicChannelsForTeam(c client.Client, teamID string) ([]*model.Channel, error) {
@@ -364,18 +388,22 @@ func listChannelsCmdF(c client.Client, cmd *cobra.Command, args []string) error
 }
 
 func unarchiveChannelsCmdF(c client.Client, cmd *cobra.Command, args []string) error {
-	if len(args) < 1 {
+	channelArgs, err := fileArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	if len(channelArgs) < 1 {
 		return errors.New("enter at least one channel")
 	}
 
-	channels := getChannelsFromChannelArgs(c, args)
+	channels := getChannelsFromFileArgs(c, channelArgs)
 	for i, channel := range channels {
 		if channel == nil {
-			printer.PrintError("Unable to find channel '" + args[i] + "'")
+			printer.PrintError(channelArgs[i].wrap(errors.New("Unable to find channel '" + channelArgs[i].Value + "'")).Error())
 			continue
 		}
 		if _, _, err := c.RestoreChannel(channel.Id); err != nil {
-			printer.PrintError("Unable to unarchive channel '" + args[i] + "'. Error: " + err.Error())
+			printer.PrintError("Unable to unarchive channel '" + channelArgs[i].Value + "'. Error: " + err.Error())
 		}
 	}
 
@@ -529,12 +557,17 @@ func moveChannelCmdF(c client.Client, cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unable to find destination team %q", args[0])
 	}
 
+	channelArgs, err := fileArgs(cmd, args[1:])
+	if err != nil {
+		return err
+	}
+
 	var result *multierror.Error
 
-	channels := getChannelsFromChannelArgs(c, args[1:])
+	channels := getChannelsFromFileArgs(c, channelArgs)
 	for i, channel := range channels {
 		if channel == nil {
-			result = multierror.Append(result, fmt.Errorf("unable to find channel %q", args[i+1]))
+			result = multierror.Append(result, channelArgs[i].wrap(fmt.Errorf("unable to find channel %q", channelArgs[i].Value)))
 			continue
 		}
 
@@ -611,12 +644,17 @@ func deleteChannelsCmdF(c client.Client, cmd *cobra.Command, args []string) erro
 		}
 	}
 
+	channelArgs, err := fileArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+
 	var result *multierror.Error
 
-	channels := getChannelsFromChannelArgs(c, args)
+	channels := getChannelsFromFileArgs(c, channelArgs)
 	for i, channel := range channels {
 		if channel == nil {
-			result = multierror.Append(result, fmt.Errorf("unable to find channel '%s'", args[i]))
+			result = multierror.Append(result, channelArgs[i].wrap(fmt.Errorf("unable to find channel '%s'", channelArgs[i].Value)))
 			continue
 		}
 		if _, err := c.PermanentDeleteChannel(channel.Id); err != nil {
@@ -6,6 +6,8 @@ package commands
 import (
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/mattermost/mattermost-server/v6/model"
@@ -644,6 +646,48 @@ func (s *MmctlUnitTestSuite) TestArchiveChannelCmdF() {
 		s.Require().Error(err)
 		s.Require().Equal("Unable to find channel 'team:/../hello/channel-test'", printer.GetErrorLines()[0])
 	})
+
+	s.Run("Archive the channels of a file", func() {
+		printer.Clean()
+		fromFile := filepath.Join(s.T().TempDir(), "channels.txt")
+		s.Require().NoError(os.WriteFile(fromFile, []byte("# channels\nsome-channel\nsome-non-existing-channel\n"), 0600))
+
+		cmd := &cobra.Command{}
+		addFromFileFlags(cmd)
+		s.Require().NoError(cmd.Flags().Set("from-file", fromFile))
+
+		mockChannel1 := model.Channel{Id: channelID, Name: channelName}
+		mockChannel2 := model.Channel{Id: "some-channel-id", Name: "some-channel"}
+		s.client.
+			EXPECT().
+			GetChannel(channelName, "").
+			Return(&mockChannel1, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetChannel("some-channel", "").
+			Return(&mockChannel2, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetChannel("some-non-existing-channel", "").
+			Return(nil, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			DeleteChannel(channelID).
+			Return(&model.Response{StatusCode: http.StatusOK}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			DeleteChannel("some-channel-id").
+			Return(&model.Response{StatusCode: http.StatusOK}, nil).
+			Times(1)
+
+		err := archiveChannelsCmdF(s.client, cmd, []string{channelName})
+		s.Require().EqualError(err, "1 error occurred:\n\t* "+fromFile+`:3: unable to find channel "some-non-existing-channel"`+"\n\n")
+		s.Require().Equal([]interface{}{fromFile + ":3: Unable to find channel 'some-non-existing-channel'"}, printer.GetErrorLines())
+	})
 }
 
 func (s *MmctlUnitTestSuite) TestListChannelsCmd() {
@@ -2461,6 +2505,45 @@ func (s *MmctlUnitTestSuite) TestMoveChannelCmdF() {
 		s.Len(printer.GetErrorLines(), 0)
 	})
 
+	s.Run("Move the channels of a file to another team", func() {
+		printer.Clean()
+		fromFile := filepath.Join(s.T().TempDir(), "channels.csv")
+		s.Require().NoError(os.WriteFile(fromFile, []byte("name,id\nfirst,first-id\nmissing,missing-id\n"), 0600))
+
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("force", false, "")
+		addFromFileFlags(cmd)
+		s.Require().NoError(cmd.Flags().Set("from-file", fromFile))
+		s.Require().NoError(cmd.Flags().Set("from-file-column", "id"))
+
+		dstTeam := model.Team{Id: "destination-team-id", Name: "destination-team-name"}
+		channel := model.Channel{Id: "first-id", Name: "first", TeamId: "source-team-id"}
+		s.client.
+			EXPECT().
+			GetTeam(dstTeam.Id, "").
+			Return(&dstTeam, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetChannel(channel.Id, "").
+			Return(&channel, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetChannel("missing-id", "").
+			Return(nil, &model.Response{StatusCode: http.StatusNotFound}, errors.New("not found")).
+			Times(1)
+		s.client.
+			EXPECT().
+			MoveChannel(channel.Id, dstTeam.Id, false).
+			Return(&channel, &model.Response{}, nil).
+			Times(1)
+
+		err := moveChannelCmdF(s.client, cmd, []string{dstTeam.Id})
+		s.Require().EqualError(err, "1 error occurred:\n\t* "+fromFile+`:3: unable to find channel "missing-id"`+"\n\n")
+		s.Require().Equal([]interface{}{&channel}, printer.GetLines())
+	})
+
 	s.Run("Should fail for not being able to find the destination team", func() {
 		printer.Clean()
 
@@ -22,11 +22,13 @@ var ChannelUsersCmd = &cobra.Command{
 }
 
 var ChannelUsersAddCmd = &cobra.Command{
-	Use:     "add [channel] [users]",
-	Short:   "Add users to channel",
-	Long:    "Add some users to channel",
-	Example: "  channel users add myteam:mychannel user@example.com username",
-	RunE:    withClient(channelUsersAddCmdF),
+	Use:               "add [channel] [users]",
+	Short:             "Add users to channel",
+	Long:              "Add some users to channel",
+	Example:           "  channel users add myteam:mychannel user@example.com username",
+	ValidArgsFunction: validArgs(completeChannels, completeUsers),
+	Args:              minimumArgsOrFile(1),
+	RunE:              withClient(channelUsersAddCmdF),
 }
 
 var ChannelUsersRemoveCmd = &cobra.Command{
@@ -35,10 +37,12 @@ var ChannelUsersRemoveCmd = &cobra.Command{
 	Long:  "Remove some users from channel",
 	Example: `  channel users remove myteam:mychannel user@example.com username
   channel users remove myteam:mychannel --all-users`,
-	RunE: withClient(channelUsersRemoveCmdF),
+	ValidArgsFunction: validArgs(completeChannels, completeUsers),
+	RunE:              withClient(channelUsersRemoveCmdF),
 }
 
 func init() {
+	addBulkFlags(ChannelUsersAddCmd)
 	ChannelUsersRemoveCmd.Flags().Bool("all-users", false, "Remove all users from the indicated channel.")
 
 	ChannelUsersCmd.AddCommand(
@@ -50,7 +54,14 @@ func init() {
 }
 
 func channelUsersAddCmdF(c client.Client, cmd *cobra.Command, args []string) error {
-	if len(args) < 2 {
+	if len(args) < 1 {
+		return errors.New("not enough arguments")
+	}
+	targets, err := fileArgs(cmd, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
 		return errors.New("not enough arguments")
 	}
 
@@ -59,22 +70,24 @@ func channelUsersAddCmdF(c client.Client, cmd *cobra.Command, args []string) err
 		return errors.Errorf("unable to find channel %q", args[0])
 	}
 
-	users := getUsersFromUserArgs(c, args[1:])
-	for i, user := range users {
-		addUserToChannel(c, channel, user, args[i+1])
-	}
-
-	return nil
+	// the users that can't be added are reported without failing
+	_, err = runBulk(cmd, "users", targets, func(target string) (func(), error) {
+		return addUserToChannel(c, channel, target)
+	})
+	return err
 }
 
-func addUserToChannel(c client.Client, channel *model.Channel, user *model.User, userArg string) {
+func addUserToChannel(c client.Client, channel *model.Channel, userArg string) (func(), error) {
+	user := getUserFromUserArg(c, userArg)
 	if user == nil {
-		printer.PrintError("Can't find user '" + userArg + "'")
-		return
+		return func() { printer.PrintError("Can't find user '" + userArg + "'") }, errors.Errorf("can't find user '%s'", userArg)
 	}
 	if _, _, err := c.AddChannelMember(channel.Id, user.Id); err != nil {
-		printer.PrintError("Unable to add '" + userArg + "' to " + channel.Name + ". Error: " + err.Error())
+		return func() {
+			printer.PrintError("Unable to add '" + userArg + "' to " + channel.Name + ". Error: " + err.Error())
+		}, errors.Wrapf(err, "unable to add '%s' to %s", userArg, channel.Name)
 	}
+	return nil, nil
 }
 
 func channelUsersRemoveCmdF(c client.Client, cmd *cobra.Command, args []string) error {
@@ -78,6 +78,17 @@ func getChannelsFromArgs(c client.Client, channelArgs []string) ([]*model.Channe
 	return channels, result.ErrorOrNil()
 }
 
+// getChannelsFromFileArgs obtains the channels of the arguments returned
+// by fileArgs, in the same order. The channels that can't be found are
+// nil, as with getChannelsFromChannelArgs
+func getChannelsFromFileArgs(c client.Client, args []fileArg) []*model.Channel {
+	channels := make([]*model.Channel, 0, len(args))
+	for _, arg := range args {
+		channels = append(channels, getChannelFromChannelArg(c, arg.Value))
+	}
+	return channels
+}
+
 //nolint:golint,unused
 func getChannelFromArg(c client.Client, arg string) (*model.Channel, error) {
 	teamArg, channelArg := parseChannelArg(arg)
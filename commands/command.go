@@ -23,20 +23,22 @@ var CommandCmd = &cobra.Command{
 }
 
 var CommandCreateCmd = &cobra.Command{
-	Use:     "create [team]",
-	Short:   "Create a custom slash command",
-	Long:    `Create a custom slash command for the specified team.`,
-	Args:    cobra.MinimumNArgs(1),
-	Example: `  command create myteam --title MyCommand --description "My Command Description" --trigger-word mycommand --url http://localhost:8000/my-slash-handler --creator myusername --response-username my-bot-username --icon http://localhost:8000/my-slash-handler-bot-icon.png --autocomplete --post`,
-	RunE:    withClient(createCommandCmdF),
+	Use:               "create [team]",
+	Short:             "Create a custom slash command",
+	Long:              `Create a custom slash command for the specified team.`,
+	Args:              cobra.MinimumNArgs(1),
+	Example:           `  command create myteam --title MyCommand --description "My Command Description" --trigger-word mycommand --url http://localhost:8000/my-slash-handler --creator myusername --response-username my-bot-username --icon http://localhost:8000/my-slash-handler-bot-icon.png --autocomplete --post`,
+	ValidArgsFunction: validArgs(completeTeams, completeNone),
+	RunE:              withClient(createCommandCmdF),
 }
 
 var CommandListCmd = &cobra.Command{
-	Use:     "list [teams]",
-	Short:   "List all commands on specified teams.",
-	Long:    `List all commands on specified teams.`,
-	Example: ` command list myteam`,
-	RunE:    withClient(listCommandCmdF),
+	Use:               "list [teams]",
+	Short:             "List all commands on specified teams.",
+	Long:              `List all commands on specified teams.`,
+	Example:           ` command list myteam`,
+	ValidArgsFunction: validArgs(completeTeams),
+	RunE:              withClient(listCommandCmdF),
 }
 
 var CommandDeleteCmd = &cobra.Command{
@@ -68,12 +70,13 @@ var CommandModifyCmd = &cobra.Command{
 }
 
 var CommandMoveCmd = &cobra.Command{
-	Use:     "move [team] [commandID]",
-	Short:   "Move a slash command to a different team",
-	Long:    `Move a slash command to a different team. Commands can be specified by command ID.`,
-	Args:    cobra.ExactArgs(2),
-	Example: `  command move newteam commandID`,
-	RunE:    withClient(moveCommandCmdF),
+	Use:               "move [team] [commandID]",
+	Short:             "Move a slash command to a different team",
+	Long:              `Move a slash command to a different team. Commands can be specified by command ID.`,
+	Args:              cobra.ExactArgs(2),
+	Example:           `  command move newteam commandID`,
+	ValidArgsFunction: validArgs(completeTeams, completeNone),
+	RunE:              withClient(moveCommandCmdF),
 }
 
 var CommandShowCmd = &cobra.Command{
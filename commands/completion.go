@@ -11,7 +11,11 @@ import (
 
 var CompletionCmd = &cobra.Command{
 	Use:   "completion",
-	Short: "Generates autocompletion scripts for bash and zsh",
+	Short: "Generates autocompletion scripts for bash, zsh, fish and PowerShell",
+	Long: `Generates autocompletion scripts for bash, zsh, fish and PowerShell.
+
+The scripts complete the names of users, teams, channels, plugins, webhooks, bots, roles, jobs and exports by fetching them from the server of the current connection.
+To keep the completion fast, the names are cached on disk for five minutes. The duration of the cache can be changed with the MMCTL_COMPLETION_CACHE_TTL environment variable, for example MMCTL_COMPLETION_CACHE_TTL=1h`,
 }
 
 var BashCmd = &cobra.Command{
@@ -38,10 +42,38 @@ To configure your zsh shell to load completions for each session, add the above
 	RunE: zshCmdF,
 }
 
+var FishCmd = &cobra.Command{
+	Use:   "fish",
+	Short: "Generates the fish autocompletion scripts",
+	Long: `To load completion, run
+
+mmctl completion fish | source
+
+To configure your fish shell to load completions for each session, run
+
+mmctl completion fish > ~/.config/fish/completions/mmctl.fish
+`,
+	RunE: fishCmdF,
+}
+
+var PowerShellCmd = &cobra.Command{
+	Use:   "powershell",
+	Short: "Generates the PowerShell autocompletion scripts",
+	Long: `To load completion, run
+
+mmctl completion powershell | Out-String | Invoke-Expression
+
+To configure PowerShell to load completions for each session, add the output of the above command to your PowerShell profile
+`,
+	RunE: powerShellCmdF,
+}
+
 func init() {
 	CompletionCmd.AddCommand(
 		BashCmd,
 		ZshCmd,
+		FishCmd,
+		PowerShellCmd,
 	)
 
 	RootCmd.AddCommand(CompletionCmd)
@@ -202,3 +234,11 @@ __mmctl_bash_source <(__mmctl_convert_bash_to_zsh)
 
 	return nil
 }
+
+func fishCmdF(cmd *cobra.Command, args []string) error {
+	return RootCmd.GenFishCompletion(os.Stdout, true)
+}
+
+func powerShellCmdF(cmd *cobra.Command, args []string) error {
+	return RootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+}
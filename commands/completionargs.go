@@ -0,0 +1,368 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mattermost/mmctl/v6/client"
+)
+
+const (
+	completionCacheDefaultTTL = 5 * time.Minute
+	completionUsersLimit      = 100
+	completionJobsLimit       = 50
+)
+
+// completionSource fetches the names used to complete the arguments
+// of the commands from the server, caching them on disk so repeated
+// completions don't hit the server
+type completionSource struct {
+	dir       string
+	server    string
+	ttl       time.Duration
+	now       func() time.Time
+	newClient func() (client.Client, error)
+	c         client.Client
+}
+
+type completionCacheEntry struct {
+	Created time.Time `json:"created"`
+	Names   []string  `json:"names"`
+}
+
+// argCompleter returns the completions of an argument
+type argCompleter func(src *completionSource, toComplete string) ([]string, cobra.ShellCompDirective)
+
+var (
+	completeUsers    argCompleter = completeUserNames
+	completeTeams                 = cachedNames("teams", fetchTeamNames)
+	completeChannels argCompleter = completeChannelNames
+	completePlugins               = cachedNames("plugins", fetchPluginIDs)
+	completeWebhooks              = cachedNames("webhooks", fetchWebhookIDs)
+	completeBots                  = cachedNames("bots", fetchBotNames)
+	completeRoles                 = cachedNames("roles", fetchRoleNames)
+	completeExports               = cachedNames("exports", func(c client.Client) ([]string, error) {
+		names, _, err := c.ListExports()
+		return names, err
+	})
+	completeImports = cachedNames("imports", func(c client.Client) ([]string, error) {
+		names, _, err := c.ListImports()
+		return names, err
+	})
+
+	// completeNone disables the completion of an argument and
+	// completeFiles completes it with the files of the system
+	completeNone  argCompleter
+	completeFiles argCompleter = func(_ *completionSource, _ string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+)
+
+// validArgs returns a ValidArgsFunction that completes each
+// positional argument with the completer of its position. The last
+// completer is used for the rest of the arguments, so commands that
+// take a fixed number of them should end the list with completeNone
+func validArgs(completers ...argCompleter) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		completer := completerForArg(completers, args)
+		if completer == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		src, err := newCompletionSource()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return completer(src, toComplete)
+	}
+}
+
+func completerForArg(completers []argCompleter, args []string) argCompleter {
+	if len(args) < len(completers) {
+		return completers[len(args)]
+	}
+	return completers[len(completers)-1]
+}
+
+// completeJobs completes the ids of the most recent jobs of a type
+func completeJobs(jobType string) argCompleter {
+	return cachedNames("jobs-"+jobType, func(c client.Client) ([]string, error) {
+		jobs, _, err := c.GetJobsByType(jobType, 0, completionJobsLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(jobs))
+		for _, job := range jobs {
+			names = append(names, job.Id+"\t"+job.Status)
+		}
+		return names, nil
+	})
+}
+
+func newCompletionSource() (*completionSource, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	src := &completionSource{
+		dir: filepath.Join(cacheDir, "mmctl", "completion"),
+		ttl: viper.GetDuration("completion-cache-ttl"),
+		now: time.Now,
+	}
+
+	if viper.GetBool("local") {
+		socketPath := viper.GetString("local-socket-path")
+		src.server = "local:" + socketPath
+		src.newClient = func() (client.Client, error) {
+			return InitUnixClient(socketPath)
+		}
+		return src, nil
+	}
+
+	credentials, err := GetCurrentCredentials()
+	if err != nil {
+		return nil, err
+	}
+	src.server = credentials.Username + "@" + credentials.InstanceURL
+	src.newClient = func() (client.Client, error) {
+		c, _, err := InitClientWithCredentials(credentials, viper.GetBool("insecure-sha1-intermediate"), viper.GetBool("insecure-tls-version"))
+		return c, err
+	}
+	return src, nil
+}
+
+func (src *completionSource) client() (client.Client, error) {
+	if src.c != nil {
+		return src.c, nil
+	}
+
+	c, err := src.newClient()
+	if err != nil {
+		return nil, err
+	}
+	src.c = c
+	return c, nil
+}
+
+func (src *completionSource) cachePath(key string) string {
+	sum := sha256.Sum256([]byte(src.server + "\x00" + key))
+	return filepath.Join(src.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// names returns the names stored in the cache for the key, fetching
+// them from the server if they are missing or expired
+func (src *completionSource) names(key string, fetch func(c client.Client) ([]string, error)) ([]string, error) {
+	path := src.cachePath(key)
+	if b, err := os.ReadFile(path); err == nil {
+		var entry completionCacheEntry
+		if err := json.Unmarshal(b, &entry); err == nil && src.now().Sub(entry.Created) < src.ttl {
+			return entry.Names, nil
+		}
+	}
+
+	c, err := src.client()
+	if err != nil {
+		return nil, err
+	}
+	names, err := fetch(c)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	// a cache that can't be written only makes the completion slower
+	if b, err := json.Marshal(completionCacheEntry{Created: src.now(), Names: names}); err == nil {
+		if err := os.MkdirAll(src.dir, 0700); err == nil {
+			_ = os.WriteFile(path, b, 0600)
+		}
+	}
+	src.prune()
+
+	return names, nil
+}
+
+// prune removes the expired entries of the cache, as some keys, such
+// as the ones of the users, depend on what has been typed and would
+// otherwise pile up
+func (src *completionSource) prune() {
+	paths, err := filepath.Glob(filepath.Join(src.dir, "*.json"))
+	if err != nil {
+		return
+	}
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var entry completionCacheEntry
+		if err := json.Unmarshal(b, &entry); err != nil || src.now().Sub(entry.Created) >= src.ttl {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+func cachedNames(key string, fetch func(c client.Client) ([]string, error)) argCompleter {
+	return func(src *completionSource, toComplete string) ([]string, cobra.ShellCompDirective) {
+		names, err := src.names(key, fetch)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return filterByPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeUserNames completes usernames using the server autocomplete,
+// so only the users that match what has been typed are fetched
+func completeUserNames(src *completionSource, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := src.names("users-"+toComplete, func(c client.Client) ([]string, error) {
+		users, _, err := c.AutocompleteUsers(toComplete, completionUsersLimit, "")
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(users.Users))
+		for _, user := range users.Users {
+			names = append(names, user.Username)
+		}
+		return names, nil
+	})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return filterByPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeChannelNames completes channels in the [team]:[channel]
+// form. Until the team is typed, team names are completed instead
+func completeChannelNames(src *completionSource, toComplete string) ([]string, cobra.ShellCompDirective) {
+	i := strings.Index(toComplete, ":")
+	if i < 0 {
+		teams, directive := completeTeams(src, toComplete)
+		for j := range teams {
+			teams[j] += ":"
+		}
+		return teams, directive | cobra.ShellCompDirectiveNoSpace
+	}
+
+	teamName := toComplete[:i]
+	names, err := src.names("channels-"+teamName, func(c client.Client) ([]string, error) {
+		team, err := getTeamFromArg(c, teamName)
+		if err != nil {
+			return nil, err
+		}
+
+		fetchers := []func(page, perPage int, etag string) ([]*model.Channel, *model.Response, error){
+			func(page, perPage int, etag string) ([]*model.Channel, *model.Response, error) {
+				return c.GetPublicChannelsForTeam(team.Id, page, perPage, etag)
+			},
+			func(page, perPage int, etag string) ([]*model.Channel, *model.Response, error) {
+				return c.GetPrivateChannelsForTeam(team.Id, page, perPage, etag)
+			},
+		}
+
+		var names []string
+		for _, fetch := range fetchers {
+			channels, err := getPages(fetch, APILimitMaximum)
+			if err != nil {
+				return nil, err
+			}
+			for _, channel := range channels {
+				names = append(names, teamName+":"+channel.Name)
+			}
+		}
+		return names, nil
+	})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return filterByPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func fetchTeamNames(c client.Client) ([]string, error) {
+	teams, err := getPages(func(page, perPage int, etag string) ([]*model.Team, *model.Response, error) {
+		return c.GetAllTeams(etag, page, perPage)
+	}, APILimitMaximum)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(teams))
+	for _, team := range teams {
+		names = append(names, team.Name)
+	}
+	return names, nil
+}
+
+func fetchPluginIDs(c client.Client) ([]string, error) {
+	plugins, _, err := c.GetPlugins()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, plugin := range append(plugins.Active, plugins.Inactive...) {
+		names = append(names, plugin.Id+"\t"+plugin.Name)
+	}
+	return names, nil
+}
+
+func fetchWebhookIDs(c client.Client) ([]string, error) {
+	incoming, err := getPages(c.GetIncomingWebhooks, APILimitMaximum)
+	if err != nil {
+		return nil, err
+	}
+	outgoing, err := getPages(c.GetOutgoingWebhooks, APILimitMaximum)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(incoming)+len(outgoing))
+	for _, hook := range incoming {
+		names = append(names, hook.Id+"\t"+hook.DisplayName)
+	}
+	for _, hook := range outgoing {
+		names = append(names, hook.Id+"\t"+hook.DisplayName)
+	}
+	return names, nil
+}
+
+func fetchBotNames(c client.Client) ([]string, error) {
+	bots, err := getPages(c.GetBotsIncludeDeleted, APILimitMaximum)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(bots))
+	for _, bot := range bots {
+		names = append(names, bot.Username)
+	}
+	return names, nil
+}
+
+func fetchRoleNames(c client.Client) ([]string, error) {
+	roles, _, err := c.GetAllRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names, nil
+}
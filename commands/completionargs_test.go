@@ -0,0 +1,138 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/client"
+)
+
+func (s *MmctlUnitTestSuite) newTestCompletionSource() (*completionSource, *time.Time) {
+	now := time.Date(2022, 11, 1, 10, 0, 0, 0, time.UTC)
+	return &completionSource{
+		dir:       s.T().TempDir(),
+		server:    "admin@https://example.com",
+		ttl:       completionCacheDefaultTTL,
+		now:       func() time.Time { return now },
+		newClient: func() (client.Client, error) { return s.client, nil },
+	}, &now
+}
+
+func (s *MmctlUnitTestSuite) TestCompletionSourceNames() {
+	s.Run("should cache the names until they expire", func() {
+		src, now := s.newTestCompletionSource()
+		teams := []*model.Team{{Name: "team2"}, {Name: "team1"}}
+
+		s.client.EXPECT().GetAllTeams("", 0, APILimitMaximum).Return(teams, &model.Response{}, nil).Times(2)
+		s.client.EXPECT().GetAllTeams("", 1, APILimitMaximum).Return([]*model.Team{}, &model.Response{}, nil).Times(2)
+
+		names, directive := completeTeams(src, "")
+		s.Require().Equal([]string{"team1", "team2"}, names)
+		s.Require().Equal(cobra.ShellCompDirectiveNoFileComp, directive)
+
+		*now = now.Add(time.Minute)
+		names, _ = completeTeams(src, "team2")
+		s.Require().Equal([]string{"team2"}, names)
+
+		*now = now.Add(completionCacheDefaultTTL)
+		names, _ = completeTeams(src, "")
+		s.Require().Equal([]string{"team1", "team2"}, names)
+	})
+
+	s.Run("should keep the caches of different servers apart", func() {
+		src, _ := s.newTestCompletionSource()
+		other := *src
+		other.server = "admin@https://other.example.com"
+
+		s.client.EXPECT().ListExports().Return([]string{"export1.zip"}, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().ListExports().Return([]string{"export2.zip"}, &model.Response{}, nil).Times(1)
+
+		names, _ := completeExports(src, "")
+		s.Require().Equal([]string{"export1.zip"}, names)
+		names, _ = completeExports(&other, "")
+		s.Require().Equal([]string{"export2.zip"}, names)
+	})
+
+	s.Run("should return an error directive if the names can't be fetched", func() {
+		src, _ := s.newTestCompletionSource()
+
+		s.client.EXPECT().GetPlugins().Return(nil, &model.Response{}, errors.New("mock error")).Times(1)
+
+		names, directive := completePlugins(src, "")
+		s.Require().Empty(names)
+		s.Require().Equal(cobra.ShellCompDirectiveError, directive)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestCompleteChannelNames() {
+	team := &model.Team{Id: "teamid", Name: "myteam"}
+
+	s.Run("should complete the team until it is typed", func() {
+		src, _ := s.newTestCompletionSource()
+
+		s.client.EXPECT().GetAllTeams("", 0, APILimitMaximum).Return([]*model.Team{team, {Name: "other"}}, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetAllTeams("", 1, APILimitMaximum).Return([]*model.Team{}, &model.Response{}, nil).Times(1)
+
+		names, directive := completeChannels(src, "my")
+		s.Require().Equal([]string{"myteam:"}, names)
+		s.Require().Equal(cobra.ShellCompDirectiveNoFileComp|cobra.ShellCompDirectiveNoSpace, directive)
+	})
+
+	s.Run("should complete the public and private channels of the team", func() {
+		src, _ := s.newTestCompletionSource()
+
+		s.client.EXPECT().GetTeam("myteam", "").Return(team, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetPublicChannelsForTeam("teamid", 0, APILimitMaximum, "").Return([]*model.Channel{{Name: "town-square"}, {Name: "off-topic"}}, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetPublicChannelsForTeam("teamid", 1, APILimitMaximum, "").Return([]*model.Channel{}, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetPrivateChannelsForTeam("teamid", 0, APILimitMaximum, "").Return([]*model.Channel{{Name: "team-secrets"}}, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetPrivateChannelsForTeam("teamid", 1, APILimitMaximum, "").Return([]*model.Channel{}, &model.Response{}, nil).Times(1)
+
+		names, directive := completeChannels(src, "myteam:t")
+		s.Require().Equal([]string{"myteam:team-secrets", "myteam:town-square"}, names)
+		s.Require().Equal(cobra.ShellCompDirectiveNoFileComp, directive)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestCompleteUserNames() {
+	src, now := s.newTestCompletionSource()
+
+	users := &model.UserAutocomplete{Users: []*model.User{{Username: "bob"}, {Username: "bobby"}}}
+	s.client.EXPECT().AutocompleteUsers("bo", completionUsersLimit, "").Return(users, &model.Response{}, nil).Times(1)
+
+	names, _ := completeUsers(src, "bo")
+	s.Require().Equal([]string{"bob", "bobby"}, names)
+
+	// the second completion is served by the cache
+	names, _ = completeUsers(src, "bo")
+	s.Require().Equal([]string{"bob", "bobby"}, names)
+
+	s.Run("should remove the expired entries when writing the cache", func() {
+		*now = now.Add(completionCacheDefaultTTL)
+		s.client.EXPECT().AutocompleteUsers("al", completionUsersLimit, "").Return(&model.UserAutocomplete{Users: []*model.User{{Username: "alice"}}}, &model.Response{}, nil).Times(1)
+
+		names, _ := completeUsers(src, "al")
+		s.Require().Equal([]string{"alice"}, names)
+
+		paths, err := filepath.Glob(filepath.Join(src.dir, "*.json"))
+		s.Require().NoError(err)
+		s.Require().Equal([]string{src.cachePath("users-al")}, paths)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestCompleterForArg() {
+	completers := []argCompleter{completeChannels, completeUsers}
+
+	s.Require().NotNil(completerForArg(completers, []string{}))
+	s.Require().NotNil(completerForArg(completers, []string{"myteam:mychannel", "user1", "user2"}))
+	s.Require().Nil(completerForArg([]argCompleter{completeTeams, completeNone}, []string{"myteam"}))
+
+	_, directive := validArgs(completeTeams, completeNone)(ListTeamsCmd, []string{"myteam"}, "")
+	s.Require().Equal(cobra.ShellCompDirectiveNoFileComp, directive)
+}
@@ -9,7 +9,9 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -33,19 +35,22 @@ var ConfigCmd = &cobra.Command{
 }
 
 var ConfigGetCmd = &cobra.Command{
-	Use:     "get",
-	Short:   "Get config setting",
-	Long:    "Gets the value of a config setting by its name in dot notation.",
-	Example: `config get SqlSettings.DriverName`,
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(configGetCmdF),
+	Use:   "get",
+	Short: "Get config setting",
+	Long:  "Gets the value of a config setting by its name in dot notation. The name can be a whole section or subtree, or a glob pattern, in which case the matching settings are printed as a JSON document.",
+	Example: `  config get SqlSettings.DriverName
+  config get PluginSettings.Plugins
+  config get "ServiceSettings.*Timeout*"
+  config get "PluginSettings.Plugins.com.mattermost.*"`,
+	Args: cobra.ExactArgs(1),
+	RunE: withClient(configGetCmdF),
 }
 
 var ConfigSetCmd = &cobra.Command{
 	Use:     "set",
 	Short:   "Set config setting",
-	Long:    "Sets the value of a config setting by its name in dot notation. Accepts multiple values for array settings",
-	Example: "config set SqlSettings.DriverName mysql\nconfig set SqlSettings.DataSourceReplicas \"replica1\" \"replica2\"",
+	Long:    "Sets the value of a config setting by its name in dot notation. Accepts multiple values for array settings. The name can be a glob pattern to set all the matching settings. With --json, the value is a JSON literal, which allows to set whole sections, maps and structs, keeping the fields of the structs that the literal does not have. With --append and --remove, the values are added to or removed from an array setting.",
+	Example: "config set SqlSettings.DriverName mysql\nconfig set SqlSettings.DataSourceReplicas \"replica1\" \"replica2\"\nconfig set \"ServiceSettings.*Timeout\" 300\nconfig set --json PluginSettings.Plugins.com.mattermost.demo '{\"channel\": \"town-square\"}'\nconfig set --append ServiceSettings.TrustedProxyIPHeader X-Real-IP",
 	Args:    cobra.MinimumNArgs(2),
 	RunE:    withClient(configSetCmdF),
 }
@@ -71,8 +76,8 @@ var ConfigEditCmd = &cobra.Command{
 var ConfigResetCmd = &cobra.Command{
 	Use:     "reset",
 	Short:   "Reset config setting",
-	Long:    "Resets the value of a config setting by its name in dot notation or a setting section. Accepts multiple values for array settings.",
-	Example: "config reset SqlSettings.DriverName LogSettings",
+	Long:    "Resets the value of a config setting by its name in dot notation, a setting section or a subtree to its default. The names can be glob patterns. Accepts multiple values for array settings.",
+	Example: "config reset SqlSettings.DriverName LogSettings\nconfig reset \"ServiceSettings.*Timeout\" PluginSettings.Plugins.com.mattermost.demo",
 	Args:    cobra.MinimumNArgs(1),
 	RunE:    withClient(configResetCmdF),
 }
@@ -121,6 +126,10 @@ var ConfigSubpathCmd = &cobra.Command{
 }
 
 func init() {
+	ConfigSetCmd.Flags().Bool("json", false, "sets the value from a JSON literal")
+	ConfigSetCmd.Flags().Bool("append", false, "adds the values to an array setting, skipping the ones it already has")
+	ConfigSetCmd.Flags().Bool("remove", false, "removes the values from an array setting")
+
 	ConfigResetCmd.Flags().Bool("confirm", false, "confirm you really want to reset all configuration settings to its default value")
 
 	ConfigSubpathCmd.Flags().StringP("assets-dir", "a", "", "directory of the Mattermost assets in the local filesystem")
@@ -304,20 +313,229 @@ func setConfigValue(path []string, config *model.Config, newValue []string) erro
 	return setValue(path, reflect.ValueOf(config).Elem(), newValue[0])
 }
 
-func resetConfigValue(path []string, config *model.Config, newValue interface{}) error {
-	nv := reflect.ValueOf(newValue)
-	if nv.Kind() == reflect.Ptr {
-		switch nv.Elem().Kind() {
-		case reflect.Int:
-			return setValue(path, reflect.ValueOf(config).Elem(), strconv.Itoa(*newValue.(*int)))
-		case reflect.Bool:
-			return setValue(path, reflect.ValueOf(config).Elem(), strconv.FormatBool(*newValue.(*bool)))
-		default:
-			return setValue(path, reflect.ValueOf(config).Elem(), *newValue.(*string))
+// isConfigPattern returns whether a config path is a glob pattern
+func isConfigPattern(configPath string) bool {
+	return strings.ContainsAny(configPath, "*?[")
+}
+
+// matchConfigSettings returns the settings of the configurations whose
+// path matches a glob pattern, sorted by their path. The wildcards
+// match the dots too, so "ServiceSettings.*" matches all the section
+func matchConfigSettings(pattern string, configs ...*model.Config) ([]*configSetting, error) {
+	matches := map[string]*configSetting{}
+	for _, config := range configs {
+		for settingPath, setting := range flattenConfig(config) {
+			ok, err := path.Match(pattern, settingPath)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+			}
+			if ok && matches[settingPath] == nil {
+				matches[settingPath] = setting
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no config settings match %s", pattern)
+	}
+
+	settings := make([]*configSetting, 0, len(matches))
+	for _, setting := range matches {
+		settings = append(settings, setting)
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return strings.Join(settings[i].segments, ".") < strings.Join(settings[j].segments, ".")
+	})
+	return settings, nil
+}
+
+// resolveConfigPath splits a config path in its segments. Map keys
+// can have dots, like the ids of the plugins, so the longest key of
+// the map that matches the path is used, or all the rest of the path
+// if there is none
+func resolveConfigPath(config *model.Config, configPath string) []string {
+	parts := parseConfigPath(configPath)
+	root := reflect.ValueOf(config)
+	var segments []string
+	for len(parts) > 0 {
+		val := configValueAt(root, segments)
+		for (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) && !val.IsNil() {
+			val = val.Elem()
+		}
+
+		n := 1
+		if val.Kind() == reflect.Map {
+			for n = len(parts); n > 1; n-- {
+				if val.MapIndex(reflect.ValueOf(strings.Join(parts[:n], "."))).IsValid() {
+					break
+				}
+			}
+			if !val.MapIndex(reflect.ValueOf(parts[0])).IsValid() && n == 1 {
+				n = len(parts)
+			}
+		}
+		segments = append(segments, strings.Join(parts[:n], "."))
+		parts = parts[n:]
+	}
+	return segments
+}
+
+// configTargets returns the paths of the settings selected by a config
+// path, which can be a glob pattern
+func configTargets(config *model.Config, configPath string) ([][]string, error) {
+	if !isConfigPattern(configPath) {
+		return [][]string{resolveConfigPath(config, configPath)}, nil
+	}
+
+	settings, err := matchConfigSettings(configPath, config)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([][]string, len(settings))
+	for i, setting := range settings {
+		targets[i] = setting.segments
+	}
+	return targets, nil
+}
+
+func isConfigValueUnset(val reflect.Value) bool {
+	return !val.IsValid() || (val.Kind() == reflect.Ptr && val.IsNil())
+}
+
+// restrictedConfigSetting returns the path of a cloud restricted
+// setting that a value would change while the server hides it, or an
+// empty string if there is none
+func restrictedConfigSetting(config *model.Config, segments []string, value interface{}) string {
+	if cloudRestricted(config, segments) && isConfigValueUnset(configValueAt(reflect.ValueOf(config), segments)) {
+		return strings.Join(segments, ".")
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		for key, child := range object {
+			if restricted := restrictedConfigSetting(config, appendSegment(segments, key), child); restricted != "" {
+				return restricted
+			}
+		}
+	}
+	return ""
+}
+
+// setConfigJSON sets a path of the configuration from a JSON literal,
+// which can be a whole section, struct, map or slice
+func setConfigJSON(config *model.Config, segments []string, literal string) error {
+	root := reflect.ValueOf(config)
+	parent := configValueAt(root, segments[:len(segments)-1])
+	for (parent.Kind() == reflect.Ptr || parent.Kind() == reflect.Interface) && !parent.IsNil() {
+		parent = parent.Elem()
+	}
+
+	var typ reflect.Type
+	switch parent.Kind() {
+	case reflect.Struct:
+		field, ok := parent.Type().FieldByName(segments[len(segments)-1])
+		if !ok {
+			return ErrConfigInvalidPath
+		}
+		typ = field.Type
+	case reflect.Map:
+		if parent.IsNil() {
+			if !parent.CanSet() {
+				return ErrConfigInvalidPath
+			}
+			parent.Set(reflect.MakeMap(parent.Type()))
+		}
+		typ = parent.Type().Elem()
+	default:
+		return ErrConfigInvalidPath
+	}
+
+	// structs are decoded over their current value, so the fields that
+	// are not in the literal are kept
+	value := reflect.New(typ)
+	current := configValueAt(root, segments)
+	if base := typ; current.IsValid() {
+		if base.Kind() == reflect.Ptr {
+			base = base.Elem()
+		}
+		if base.Kind() == reflect.Struct {
+			value.Elem().Set(current)
+		}
+	}
+	if err := json.Unmarshal([]byte(literal), value.Interface()); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", strings.Join(segments, "."), err)
+	}
+	return setConfigValueAt(root, segments, value.Elem())
+}
+
+// updateConfigList adds the values to an array setting, or removes
+// them from it
+func updateConfigList(config *model.Config, segments []string, values []string, remove bool) error {
+	root := reflect.ValueOf(config)
+	current := configValueAt(root, segments)
+	if !current.IsValid() {
+		return ErrConfigInvalidPath
+	}
+	list, ok := current.Interface().([]string)
+	if !ok {
+		return fmt.Errorf("%s is not an array setting", strings.Join(segments, "."))
+	}
+
+	updated := []string{}
+	if remove {
+		for _, item := range list {
+			if !utils.StringInSlice(item, values) {
+				updated = append(updated, item)
+			}
+		}
+		for _, value := range values {
+			if !utils.StringInSlice(value, list) {
+				printer.PrintWarning(fmt.Sprintf("%s does not contain %q", strings.Join(segments, "."), value))
+			}
 		}
 	} else {
-		return setValue(path, reflect.ValueOf(config).Elem(), newValue)
+		updated = append(updated, list...)
+		for _, value := range values {
+			if !utils.StringInSlice(value, updated) {
+				updated = append(updated, value)
+			}
+		}
+	}
+	return setConfigValueAt(root, segments, reflect.ValueOf(updated))
+}
+
+// resetConfig sets the settings selected by a config path to the ones
+// of the default configuration, removing the map entries that it
+// doesn't have
+func resetConfig(config, defaultConfig *model.Config, configPath string) error {
+	pattern := isConfigPattern(configPath)
+	targets := [][]string{resolveConfigPath(config, configPath)}
+	if pattern {
+		settings, err := matchConfigSettings(configPath, config, defaultConfig)
+		if err != nil {
+			return err
+		}
+		targets = targets[:0]
+		for _, setting := range settings {
+			targets = append(targets, setting.segments)
+		}
+	}
+
+	root := reflect.ValueOf(config)
+	for _, segments := range targets {
+		current := configValueAt(root, segments)
+		defaultValue := configValueAt(reflect.ValueOf(defaultConfig), segments)
+		if !current.IsValid() && !defaultValue.IsValid() {
+			return errors.New("invalid key")
+		}
+		if cloudRestricted(config, segments) && isConfigValueUnset(current) {
+			if pattern {
+				continue
+			}
+			return fmt.Errorf("resetting this config path: %s is restricted in a cloud environment", configPath)
+		}
+		if err := setConfigValueAt(root, segments, defaultValue); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 func configGetCmdF(c client.Client, _ *cobra.Command, args []string) error {
@@ -329,6 +547,15 @@ func configGetCmdF(c client.Client, _ *cobra.Command, args []string) error {
 		return err
 	}
 
+	if isConfigPattern(args[0]) {
+		settings, mErr := matchConfigSettings(args[0], config)
+		if mErr != nil {
+			return mErr
+		}
+		printer.Print(unflattenConfig(settings))
+		return nil
+	}
+
 	path := strings.Split(args[0], ".")
 	val, ok := getValue(path, *config)
 	if !ok {
@@ -343,24 +570,74 @@ func configGetCmdF(c client.Client, _ *cobra.Command, args []string) error {
 	return nil
 }
 
-func configSetCmdF(c client.Client, _ *cobra.Command, args []string) error {
+func configSetCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	jsonFlag, _ := cmd.Flags().GetBool("json")
+	appendFlag, _ := cmd.Flags().GetBool("append")
+	removeFlag, _ := cmd.Flags().GetBool("remove")
+
+	modes := 0
+	for _, flag := range []bool{jsonFlag, appendFlag, removeFlag} {
+		if flag {
+			modes++
+		}
+	}
+	if modes > 1 {
+		return errors.New("only one of --json, --append and --remove can be used")
+	}
+	var literal interface{}
+	if jsonFlag {
+		if len(args) != 2 {
+			return errors.New("--json takes a single value")
+		}
+		if jErr := json.Unmarshal([]byte(args[1]), &literal); jErr != nil {
+			return errors.Wrap(jErr, "the value is not valid JSON")
+		}
+	}
+
 	config, _, err := c.GetConfig()
 	if err != nil {
 		return err
 	}
 
-	path := parseConfigPath(args[0])
-	if cErr := setConfigValue(path, config, args[1:]); cErr != nil {
-		if errors.Is(cErr, ErrConfigInvalidPath) && cloudRestricted(config, path) {
-			return fmt.Errorf("changing this config path: %s is restricted in a cloud environment", args[0])
+	previous := config.Clone()
+	if modes == 0 && !isConfigPattern(args[0]) {
+		path := parseConfigPath(args[0])
+		if cErr := setConfigValue(path, config, args[1:]); cErr != nil {
+			if errors.Is(cErr, ErrConfigInvalidPath) && cloudRestricted(config, path) {
+				return fmt.Errorf("changing this config path: %s is restricted in a cloud environment", args[0])
+			}
+
+			return cErr
 		}
+	} else {
+		targets, tErr := configTargets(config, args[0])
+		if tErr != nil {
+			return tErr
+		}
+		for _, segments := range targets {
+			if restricted := restrictedConfigSetting(config, segments, literal); restricted != "" {
+				return fmt.Errorf("changing this config path: %s is restricted in a cloud environment", restricted)
+			}
 
-		return cErr
+			var sErr error
+			switch {
+			case jsonFlag:
+				sErr = setConfigJSON(config, segments, args[1])
+			case appendFlag, removeFlag:
+				sErr = updateConfigList(config, segments, args[1:], removeFlag)
+			default:
+				sErr = setConfigValue(parseConfigPath(strings.Join(segments, ".")), config, args[1:])
+			}
+			if sErr != nil {
+				return sErr
+			}
+		}
 	}
 	newConfig, _, err := c.PatchConfig(config)
 	if err != nil {
 		return err
 	}
+	snapshotConfig(previous, "config set "+args[0])
 
 	printer.PrintT("Value changed successfully", newConfig)
 	return nil
@@ -377,6 +654,7 @@ func configPatchCmdF(c client.Client, _ *cobra.Command, args []string) error {
 		return err
 	}
 
+	previous := config.Clone()
 	if jErr := json.Unmarshal(configBytes, config); jErr != nil {
 		return jErr
 	}
@@ -385,6 +663,7 @@ func configPatchCmdF(c client.Client, _ *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	snapshotConfig(previous, "config patch "+args[0])
 
 	printer.PrintT("Config patched successfully", newConfig)
 	return nil
@@ -432,6 +711,7 @@ func configEditCmdF(c client.Client, _ *cobra.Command, _ []string) error {
 		return err
 	}
 
+	previous := config.Clone()
 	if jErr := json.Unmarshal(newConfigBytes, config); jErr != nil {
 		return jErr
 	}
@@ -440,6 +720,7 @@ func configEditCmdF(c client.Client, _ *cobra.Command, _ []string) error {
 	if err != nil {
 		return err
 	}
+	snapshotConfig(previous, "config edit")
 
 	printer.PrintT("Config updated successfully", newConfig)
 	return nil
@@ -463,21 +744,17 @@ func configResetCmdF(c client.Client, cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	previous := config.Clone()
 	for _, arg := range args {
-		path := parseConfigPath(arg)
-		defaultValue, ok := getValue(path, *defaultConfig)
-		if !ok {
-			return errors.New("invalid key")
-		}
-		nErr := resetConfigValue(path, config, defaultValue)
-		if nErr != nil {
-			return nErr
+		if rErr := resetConfig(config, defaultConfig, arg); rErr != nil {
+			return rErr
 		}
 	}
 	newConfig, _, err := c.UpdateConfig(config)
 	if err != nil {
 		return err
 	}
+	snapshotConfig(previous, "config reset "+strings.Join(args, " "))
 
 	printer.PrintT("Value/s reset successfully", newConfig)
 	return nil
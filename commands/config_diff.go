@@ -0,0 +1,458 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+const (
+	ConfigDiffAdded   = "added"
+	ConfigDiffRemoved = "removed"
+	ConfigDiffChanged = "changed"
+
+	configSourceServer   = "server"
+	configSourceSnapshot = "snapshot"
+	configSourceFile     = "file"
+)
+
+// configVolatilePaths are the settings that are expected to be
+// different between servers, as they depend on where the server runs
+var configVolatilePaths = []string{
+	"ServiceSettings.SiteURL",
+	"ServiceSettings.ListenAddress",
+	"ServiceSettings.ConnectionSecurity",
+	"ServiceSettings.TLSCertFile",
+	"ServiceSettings.TLSKeyFile",
+	"SqlSettings.DataSource",
+	"SqlSettings.DataSourceReplicas",
+	"SqlSettings.DataSourceSearchReplicas",
+	"FileSettings.Directory",
+	"LogSettings.FileLocation",
+	"NotificationLogSettings.FileLocation",
+	"ComplianceSettings.Directory",
+	"ExportSettings.Directory",
+	"ClusterSettings.ClusterName",
+	"ClusterSettings.OverrideHostname",
+	"ClusterSettings.NetworkInterface",
+	"ClusterSettings.BindAddress",
+	"ClusterSettings.AdvertiseAddress",
+	"MetricsSettings.ListenAddress",
+	"PluginSettings.Directory",
+	"PluginSettings.ClientDirectory",
+}
+
+var ConfigDiffCmd = &cobra.Command{
+	Use:   "diff [from source] <to source>",
+	Short: "Compare two configurations",
+	Long: `Compares the configurations of two sources and prints the settings that were added, removed or changed in the second one. When only one source is given, the configuration of the current server is compared with it. The sources can be:
+
+  server               the current server
+  server:<name>        a server of the credentials, by the name used with "auth login"
+  snapshot:<id>        a saved snapshot of a configuration
+  file:<path>, <path>  a JSON file with a configuration, like the output of "config show"
+
+The secret settings, like passwords and keys, and the volatile ones, which depend on where the server runs like the site URL or the database connection, are ignored unless --include-secrets or --include-volatile are set. Other settings can be ignored with --ignore.
+
+The differences can be written as a patch with --patch-file, which makes the first source equal to the second one when applied with "config patch". As patching replaces the entries of maps, a change in the settings of a plugin writes all the settings of the plugin. The other removed settings can't be expressed in a patch and are left out of it.`,
+	Example: `  config diff server:staging server:production
+  config diff production.json --unified
+  config diff snapshot:20221117-101500-4f2a server --ignore PluginSettings
+  config diff server:production server:staging --patch-file promote.json`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return configDiffCmdF(getClient, cmd, args)
+	},
+}
+
+func init() {
+	ConfigDiffCmd.Flags().Bool("include-secrets", false, "compares the secret settings, which servers return masked")
+	ConfigDiffCmd.Flags().Bool("include-volatile", false, "compares the settings that depend on where the server runs, like the site URL")
+	ConfigDiffCmd.Flags().StringArray("ignore", nil, "ignores a setting, or a section with all its settings, by its path in dot notation. Can be repeated")
+	ConfigDiffCmd.Flags().Bool("unified", false, "prints the differences in the unified diff format")
+	ConfigDiffCmd.Flags().String("patch-file", "", "writes the added and changed settings to a file that can be applied with \"config patch\"")
+
+	ConfigCmd.AddCommand(ConfigDiffCmd)
+}
+
+// ConfigDiffEntry is a setting that is different between two
+// configurations
+type ConfigDiffEntry struct {
+	Path   string      `json:"path"`
+	Change string      `json:"change"`
+	Old    interface{} `json:"old,omitempty"`
+	New    interface{} `json:"new,omitempty"`
+
+	setting *configSetting
+}
+
+// configSetting is a leaf value of a configuration
+type configSetting struct {
+	segments []string
+	value    interface{}
+	// mapKey is the index of the segment of the first map key in the
+	// path, or -1 if the setting is not inside a map
+	mapKey int
+}
+
+// flattenConfig walks the configuration like getValue does, returning
+// its settings by their path in dot notation. Unset settings are left
+// out, and slices are compared as a whole
+func flattenConfig(config *model.Config) map[string]*configSetting {
+	settings := map[string]*configSetting{}
+	flattenConfigValue(nil, -1, reflect.ValueOf(config), settings)
+	return settings
+}
+
+func flattenConfigValue(segments []string, mapKey int, val reflect.Value, settings map[string]*configSetting) {
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !val.IsNil() {
+			flattenConfigValue(segments, mapKey, val.Elem(), settings)
+		}
+	case reflect.Struct:
+		for i := 0; i < val.NumField(); i++ {
+			if field := val.Type().Field(i); field.IsExported() {
+				flattenConfigValue(appendSegment(segments, field.Name), mapKey, val.Field(i), settings)
+			}
+		}
+	case reflect.Map:
+		if mapKey < 0 {
+			mapKey = len(segments)
+		}
+		iter := val.MapRange()
+		for iter.Next() {
+			flattenConfigValue(appendSegment(segments, iter.Key().String()), mapKey, iter.Value(), settings)
+		}
+	case reflect.Slice:
+		value := val.Interface()
+		if val.Len() == 0 {
+			value = []interface{}{}
+		}
+		settings[strings.Join(segments, ".")] = &configSetting{segments: segments, value: value, mapKey: mapKey}
+	default:
+		settings[strings.Join(segments, ".")] = &configSetting{segments: segments, value: val.Interface(), mapKey: mapKey}
+	}
+}
+
+func appendSegment(segments []string, segment string) []string {
+	return append(append([]string{}, segments...), segment)
+}
+
+// unflattenConfig builds the JSON document of a set of settings
+func unflattenConfig(settings []*configSetting) map[string]interface{} {
+	doc := map[string]interface{}{}
+	for _, setting := range settings {
+		node := doc
+		for _, segment := range setting.segments[:len(setting.segments)-1] {
+			child, ok := node[segment].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				node[segment] = child
+			}
+			node = child
+		}
+		node[setting.segments[len(setting.segments)-1]] = setting.value
+	}
+	return doc
+}
+
+// configSecretPaths returns the settings that the server masks, found
+// by sanitizing a configuration with all its strings set
+func configSecretPaths() map[string]bool {
+	probe := &model.Config{}
+	probe.SetDefaults()
+	fillConfigStrings(reflect.ValueOf(probe))
+	probe.Sanitize()
+
+	secrets := map[string]bool{}
+	for path, setting := range flattenConfig(probe) {
+		if isConfigSecret(setting.value) {
+			secrets[path] = true
+		}
+	}
+	return secrets
+}
+
+func fillConfigStrings(val reflect.Value) {
+	switch val.Kind() {
+	case reflect.Ptr:
+		if !val.IsNil() {
+			fillConfigStrings(val.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < val.NumField(); i++ {
+			if val.Type().Field(i).IsExported() {
+				fillConfigStrings(val.Field(i))
+			}
+		}
+	case reflect.String:
+		val.SetString("secret")
+	case reflect.Slice:
+		if val.Type().Elem().Kind() == reflect.String {
+			val.Set(reflect.ValueOf([]string{"secret"}).Convert(val.Type()))
+		}
+	}
+}
+
+// isConfigSecret checks if a value is masked
+func isConfigSecret(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v == model.FakeSetting
+	case []string:
+		for _, s := range v {
+			if s == model.FakeSetting {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// configPathMatches checks if a path is one of the prefixes or a
+// setting of their sections
+func configPathMatches(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// diffConfigs returns the differences between the settings of two
+// configurations, sorted by path
+func diffConfigs(from, to map[string]*configSetting) []*ConfigDiffEntry {
+	var entries []*ConfigDiffEntry
+	for path, old := range from {
+		entry := &ConfigDiffEntry{Path: path, Old: old.value, setting: old}
+		if setting, ok := to[path]; !ok {
+			entry.Change = ConfigDiffRemoved
+		} else if !reflect.DeepEqual(old.value, setting.value) {
+			entry.Change = ConfigDiffChanged
+			entry.New = setting.value
+			entry.setting = setting
+		} else {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	for path, setting := range to {
+		if _, ok := from[path]; !ok {
+			entries = append(entries, &ConfigDiffEntry{Path: path, Change: ConfigDiffAdded, New: setting.value, setting: setting})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries
+}
+
+// loadConfigSource reads the configuration of a source of the diff
+func loadConfigSource(source string, currentClient func() (client.Client, error)) (*model.Config, error) {
+	kind, value, _ := strings.Cut(source, ":")
+	switch {
+	case source == configSourceServer:
+		c, err := currentClient()
+		if err != nil {
+			return nil, err
+		}
+		config, response, err := c.GetConfig()
+		if err != nil {
+			return nil, fmt.Errorf("could not get the configuration of the current server: %w", ExtractErrorFromResponse(response, err))
+		}
+		return config, nil
+	case kind == configSourceServer:
+		credentials, err := GetCredentials(value)
+		if err != nil {
+			return nil, err
+		}
+		c, _, err := InitClientWithCredentials(credentials, viper.GetBool("insecure-sha1-intermediate"), viper.GetBool("insecure-tls-version"))
+		if err != nil {
+			return nil, err
+		}
+		config, response, err := c.GetConfig()
+		if err != nil {
+			return nil, fmt.Errorf("could not get the configuration of server %s: %w", value, ExtractErrorFromResponse(response, err))
+		}
+		return config, nil
+	case kind == configSourceSnapshot:
+		snapshot, err := readConfigSnapshot(value)
+		if err != nil {
+			return nil, err
+		}
+		return snapshot.Config, nil
+	case kind == configSourceFile:
+		source = value
+	}
+
+	b, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the configuration file: %w", err)
+	}
+	var config model.Config
+	if err := json.Unmarshal(b, &config); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", source, err)
+	}
+	return &config, nil
+}
+
+func configDiffCmdF(currentClient func() (client.Client, error), cmd *cobra.Command, args []string) error {
+	includeSecrets, _ := cmd.Flags().GetBool("include-secrets")
+	includeVolatile, _ := cmd.Flags().GetBool("include-volatile")
+	ignore, _ := cmd.Flags().GetStringArray("ignore")
+	unified, _ := cmd.Flags().GetBool("unified")
+	patchFile, _ := cmd.Flags().GetString("patch-file")
+
+	sources := args
+	if len(sources) == 1 {
+		sources = []string{configSourceServer, args[0]}
+	}
+
+	var settings [2]map[string]*configSetting
+	for i, source := range sources {
+		config, err := loadConfigSource(source, currentClient)
+		if err != nil {
+			return err
+		}
+		settings[i] = flattenConfig(config)
+	}
+
+	if !includeVolatile {
+		ignore = append(ignore, configVolatilePaths...)
+	}
+	secrets := map[string]bool{}
+	if !includeSecrets {
+		secrets = configSecretPaths()
+	}
+	for _, side := range settings {
+		for path, setting := range side {
+			if configPathMatches(path, ignore) {
+				delete(side, path)
+			}
+			if !includeSecrets && (secrets[path] || isConfigSecret(setting.value)) {
+				delete(settings[0], path)
+				delete(settings[1], path)
+			}
+		}
+	}
+
+	entries := diffConfigs(settings[0], settings[1])
+	if patchFile != "" {
+		if err := writeConfigPatch(patchFile, entries, settings[1]); err != nil {
+			return err
+		}
+	}
+
+	if unified {
+		diff, err := unifiedConfigDiff(sources, settings)
+		if err != nil {
+			return err
+		}
+		if diff != "" {
+			printer.SetNoNewline(true)
+			printer.Print(diff)
+		}
+		return nil
+	}
+
+	printConfigDiffEntries(entries)
+	return nil
+}
+
+func printConfigDiffEntries(entries []*ConfigDiffEntry) {
+	printer.SetTemplateFunc("json", func(v interface{}) string {
+		b, _ := json.Marshal(v)
+		return string(b)
+	})
+	for _, entry := range entries {
+		printer.PrintT(`{{if eq .Change "added"}}+ {{.Path}}: {{json .New}}{{else if eq .Change "removed"}}- {{.Path}}: {{json .Old}}{{else}}~ {{.Path}}: {{json .Old}} -> {{json .New}}{{end}}`, entry)
+	}
+}
+
+// unifiedConfigDiff compares the JSON documents of the settings of two
+// configurations
+func unifiedConfigDiff(sources []string, settings [2]map[string]*configSetting) (string, error) {
+	var docs [2]string
+	for i, side := range settings {
+		list := make([]*configSetting, 0, len(side))
+		for _, setting := range side {
+			list = append(list, setting)
+		}
+		b, err := json.MarshalIndent(unflattenConfig(list), "", "  ")
+		if err != nil {
+			return "", err
+		}
+		docs[i] = string(b) + "\n"
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(docs[0]),
+		B:        difflib.SplitLines(docs[1]),
+		FromFile: sources[0],
+		ToFile:   sources[1],
+		Context:  3,
+	})
+}
+
+// writeConfigPatch writes the added and changed settings to a file in
+// the format of "config patch". As patching replaces the entries of the
+// maps, like the settings of a plugin, the changes inside a map entry
+// are written with all the settings of the entry
+func writeConfigPatch(path string, entries []*ConfigDiffEntry, to map[string]*configSetting) error {
+	var settings []*configSetting
+	entryPrefixes := map[string]bool{}
+	removed := 0
+	for _, entry := range entries {
+		if entry.setting.mapKey < 0 {
+			if entry.Change == ConfigDiffRemoved {
+				removed++
+			} else {
+				settings = append(settings, entry.setting)
+			}
+			continue
+		}
+
+		prefix := strings.Join(entry.setting.segments[:entry.setting.mapKey+1], ".")
+		if entryPrefixes[prefix] {
+			continue
+		}
+		entryPrefixes[prefix] = true
+		found := false
+		for settingPath, setting := range to {
+			if configPathMatches(settingPath, []string{prefix}) {
+				settings = append(settings, setting)
+				found = true
+			}
+		}
+		if !found {
+			removed++
+		}
+	}
+	if removed > 0 {
+		printer.PrintWarning(fmt.Sprintf("%d removed settings can't be expressed in the patch and were left out of it", removed))
+	}
+
+	b, err := json.MarshalIndent(unflattenConfig(settings), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0600); err != nil {
+		return fmt.Errorf("could not write the patch file: %w", err)
+	}
+	return nil
+}
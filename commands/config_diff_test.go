@@ -0,0 +1,164 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestConfigSecretPaths() {
+	secrets := configSecretPaths()
+	s.Require().True(secrets["SqlSettings.DataSource"])
+	s.Require().True(secrets["LdapSettings.BindPassword"])
+	s.Require().True(secrets["SqlSettings.DataSourceReplicas"])
+	s.Require().False(secrets["ServiceSettings.SiteURL"])
+}
+
+func (s *MmctlUnitTestSuite) TestConfigDiffCmd() {
+	dir, err := ioutil.TempDir("", "mmctl-config-diff-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	newConfig := func() *model.Config {
+		config := &model.Config{}
+		config.SetDefaults()
+		config.PluginSettings.Plugins = map[string]map[string]interface{}{
+			"com.mattermost.demo": {"enabled": true},
+		}
+		return config
+	}
+
+	server := newConfig()
+	*server.ServiceSettings.SiteURL = "https://staging.example.com"
+	*server.TeamSettings.SiteName = "Staging"
+	*server.EmailSettings.SMTPPassword = model.FakeSetting
+
+	target := newConfig()
+	*target.ServiceSettings.SiteURL = "https://example.com"
+	*target.TeamSettings.SiteName = "Production"
+	*target.EmailSettings.SMTPPassword = "password"
+	target.PluginSettings.Plugins["com.mattermost.demo"]["channel"] = "town-square"
+	target.SqlSettings.DataSourceReplicas = []string{"replica"}
+	target.ServiceSettings.AllowCorsFrom = nil
+
+	path := filepath.Join(dir, "production.json")
+	b, err := json.Marshal(target)
+	s.Require().NoError(err)
+	s.Require().NoError(ioutil.WriteFile(path, b, 0600))
+
+	currentClient := func() (client.Client, error) { return s.client, nil }
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("include-secrets", false, "")
+		cmd.Flags().Bool("include-volatile", false, "")
+		cmd.Flags().StringArray("ignore", nil, "")
+		cmd.Flags().Bool("unified", false, "")
+		cmd.Flags().String("patch-file", "", "")
+		return cmd
+	}
+
+	s.Run("should print the changed settings and write the patch", func() {
+		printer.Clean()
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(server, &model.Response{}, nil).
+			Times(1)
+
+		patchFile := filepath.Join(dir, "patch.json")
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("patch-file", patchFile))
+		err := configDiffCmdF(currentClient, cmd, []string{path})
+		s.Require().NoError(err)
+
+		lines := printer.GetLines()
+		s.Require().Len(lines, 3)
+		s.Require().Equal("PluginSettings.Plugins.com.mattermost.demo.channel", lines[0].(*ConfigDiffEntry).Path)
+		s.Require().Equal(ConfigDiffAdded, lines[0].(*ConfigDiffEntry).Change)
+		s.Require().Equal("town-square", lines[0].(*ConfigDiffEntry).New)
+		s.Require().Equal("ServiceSettings.AllowCorsFrom", lines[1].(*ConfigDiffEntry).Path)
+		s.Require().Equal(ConfigDiffRemoved, lines[1].(*ConfigDiffEntry).Change)
+		s.Require().Equal(ConfigDiffChanged, lines[2].(*ConfigDiffEntry).Change)
+		s.Require().Equal("Staging", lines[2].(*ConfigDiffEntry).Old)
+		s.Require().Equal("Production", lines[2].(*ConfigDiffEntry).New)
+
+		patch, err := ioutil.ReadFile(patchFile)
+		s.Require().NoError(err)
+		s.Require().JSONEq(`{
+  "PluginSettings": {"Plugins": {"com.mattermost.demo": {"enabled": true, "channel": "town-square"}}},
+  "TeamSettings": {"SiteName": "Production"}
+}`, string(patch))
+
+		config := newConfig()
+		s.Require().NoError(json.Unmarshal(patch, config))
+		s.Require().Equal("Production", *config.TeamSettings.SiteName)
+		s.Require().Equal(true, config.PluginSettings.Plugins["com.mattermost.demo"]["enabled"])
+	})
+
+	s.Run("should compare the secret and volatile settings when asked", func() {
+		printer.Clean()
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(server, &model.Response{}, nil).
+			Times(1)
+
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("include-volatile", "true"))
+		s.Require().NoError(cmd.Flags().Set("ignore", "PluginSettings"))
+		s.Require().NoError(cmd.Flags().Set("ignore", "ServiceSettings.AllowCorsFrom"))
+		err := configDiffCmdF(currentClient, cmd, []string{"server", "file:" + path})
+		s.Require().NoError(err)
+
+		var paths []string
+		for _, line := range printer.GetLines() {
+			paths = append(paths, line.(*ConfigDiffEntry).Path)
+		}
+		s.Require().Equal([]string{"ServiceSettings.SiteURL", "TeamSettings.SiteName"}, paths)
+	})
+
+	s.Run("should print a unified diff", func() {
+		printer.Clean()
+		s.T().Cleanup(func() { printer.SetNoNewline(false) })
+
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("unified", "true"))
+		s.Require().NoError(cmd.Flags().Set("ignore", "PluginSettings"))
+		s.Require().NoError(cmd.Flags().Set("ignore", "ServiceSettings"))
+		err := configDiffCmdF(currentClient, cmd, []string{path, path})
+		s.Require().NoError(err)
+		s.Require().Empty(printer.GetLines())
+
+		other := filepath.Join(dir, "other.json")
+		*target.TeamSettings.SiteName = "Other"
+		b, err := json.Marshal(target)
+		s.Require().NoError(err)
+		s.Require().NoError(ioutil.WriteFile(other, b, 0600))
+
+		err = configDiffCmdF(currentClient, cmd, []string{path, other})
+		s.Require().NoError(err)
+		lines := printer.GetLines()
+		s.Require().Len(lines, 1)
+		s.Require().Contains(lines[0], "--- "+path+"\n+++ "+other+"\n")
+		s.Require().Contains(lines[0], "-    \"SiteName\": \"Production\",\n+    \"SiteName\": \"Other\",\n")
+	})
+
+	s.Run("should fail with an unknown snapshot", func() {
+		viper.Set("config-snapshots-dir", dir)
+		s.T().Cleanup(func() { viper.Set("config-snapshots-dir", "") })
+
+		err := configDiffCmdF(currentClient, newCmd(), []string{"snapshot:missing", path})
+		s.Require().EqualError(err, "config snapshot missing not found")
+	})
+}
@@ -0,0 +1,547 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+const (
+	configEnvPrefix = "MM_"
+
+	configEnvFormatEnv        = "env"
+	configEnvFormatDotenv     = "dotenv"
+	configEnvFormatKubernetes = "k8s"
+
+	kubernetesKindConfigMap = "ConfigMap"
+	kubernetesKindSecret    = "Secret"
+)
+
+var ConfigExportEnvCmd = &cobra.Command{
+	Use:   "export-env [source]",
+	Short: "Export a config as environment variables",
+	Long: `Exports the settings of a configuration as the MM_* environment variables that the server reads, like MM_SQLSETTINGS_DRIVERNAME for SqlSettings.DriverName. The source can be any of the ones of "config diff", and defaults to the current server.
+
+The variables are written in one of these formats, chosen with --env-format:
+  env:    shell lines, with the values quoted when needed
+  dotenv: a file for the --env-file flag of docker, with the values as they are
+  k8s:    a Kubernetes ConfigMap, and a Secret for the secret settings
+
+The secret settings, like passwords and keys, are never written with the rest. They are written to the file of --secrets-output if it is set, and left out otherwise. The secrets of the server are masked, so they can only be exported from a file. The settings in maps, like the ones of the plugins, can't be set with environment variables and are left out.
+
+When a global output format other than plain is used, like --json, the variables are printed as a list of names and values in that format instead.`,
+	Example: `  config export-env --non-default
+  config export-env config.json --env-format dotenv --secrets-output secrets.env
+  config export-env config.json --env-format k8s --name mattermost --secrets-output secret.yaml`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return configExportEnvCmdF(getClient, cmd, args)
+	},
+}
+
+var ConfigImportEnvCmd = &cobra.Command{
+	Use:   "import-env [files...]",
+	Short: "Import a config from environment variables",
+	Long: `Reads MM_* environment variables from shell lines, docker env files or Kubernetes ConfigMaps and Secrets, and prints the config patch that sets their settings, to be used with "config patch".
+
+The secret settings are written to the file of --secrets-output as a separate patch if it is set, and left out otherwise.`,
+	Example: `  config import-env mattermost.env > patch.json
+  config import-env configmap.yaml secret.yaml --secrets-output secrets.json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: configImportEnvCmdF,
+}
+
+func init() {
+	ConfigExportEnvCmd.Flags().String("env-format", configEnvFormatEnv, "format of the variables [env, dotenv, k8s]")
+	ConfigExportEnvCmd.Flags().Bool("non-default", false, "exports only the settings that don't have their default value")
+	ConfigExportEnvCmd.Flags().String("secrets-output", "", "file to write the secret settings to")
+	ConfigExportEnvCmd.Flags().String("name", "mattermost-config", "name of the Kubernetes ConfigMap and Secret")
+
+	ConfigImportEnvCmd.Flags().String("secrets-output", "", "file to write the patch of the secret settings to")
+
+	ConfigCmd.AddCommand(
+		ConfigExportEnvCmd,
+		ConfigImportEnvCmd,
+	)
+}
+
+// configEnvVar is an environment variable of a setting, with the line
+// of the file that it was read from
+type configEnvVar struct {
+	name  string
+	value string
+	line  int
+}
+
+// ConfigEnvVariable is an exported environment variable, printed when
+// a global output format other than plain is used
+type ConfigEnvVariable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// configEnvSetting is a setting that can be set with an environment
+// variable
+type configEnvSetting struct {
+	segments []string
+	typ      reflect.Type
+}
+
+// kubernetesConfigObject is a Kubernetes ConfigMap or Secret
+type kubernetesConfigObject struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Type       string            `yaml:"type,omitempty"`
+	Data       map[string]string `yaml:"data,omitempty"`
+	StringData map[string]string `yaml:"stringData,omitempty"`
+}
+
+var shellSafeValue = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+
+func configEnvName(segments []string) string {
+	return configEnvPrefix + strings.ToUpper(strings.Join(segments, "_"))
+}
+
+// configEnvSettings returns the settings by the name of their
+// environment variable. The settings in maps and the slices of structs
+// can't be set with environment variables, so they are left out
+func configEnvSettings() map[string]*configEnvSetting {
+	settings := map[string]*configEnvSetting{}
+	addConfigEnvSettings(nil, reflect.TypeOf(model.Config{}), settings)
+	return settings
+}
+
+func addConfigEnvSettings(segments []string, t reflect.Type, settings map[string]*configEnvSetting) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.IsExported() && field.Tag.Get("json") != "-" {
+				addConfigEnvSettings(appendSegment(segments, field.Name), field.Type, settings)
+			}
+		}
+	case reflect.Map, reflect.Interface:
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			settings[configEnvName(segments)] = &configEnvSetting{segments: segments, typ: t}
+		}
+	default:
+		settings[configEnvName(segments)] = &configEnvSetting{segments: segments, typ: t}
+	}
+}
+
+// configEnvValue returns the value of a setting as the server reads it
+// from an environment variable, with the items of the slices separated
+// by spaces
+func configEnvValue(val reflect.Value) (string, bool) {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return "", false
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() || (val.Kind() == reflect.Slice && val.IsNil()) {
+		return "", false
+	}
+	if list, ok := val.Interface().([]string); ok {
+		return strings.Join(list, " "), true
+	}
+	return fmt.Sprint(val.Interface()), true
+}
+
+// exportConfigEnv returns the environment variables of the settings of
+// a configuration sorted by name, split in the regular and the secret
+// ones. The settings that the server masked are left out and counted
+func exportConfigEnv(config *model.Config, nonDefault bool) (vars, secrets []*configEnvVar, masked int) {
+	defaultConfig := &model.Config{}
+	defaultConfig.SetDefaults()
+	secretPaths := configSecretPaths()
+
+	settings := configEnvSettings()
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		segments := settings[name].segments
+		val := configValueAt(reflect.ValueOf(config), segments)
+		value, ok := configEnvValue(val)
+		if !ok {
+			continue
+		}
+		if nonDefault {
+			if defaultValue, _ := configEnvValue(configValueAt(reflect.ValueOf(defaultConfig), segments)); value == defaultValue {
+				continue
+			}
+		}
+
+		envVar := &configEnvVar{name: name, value: value}
+		switch {
+		case isConfigSecret(reflect.Indirect(val).Interface()):
+			masked++
+		case secretPaths[strings.Join(segments, ".")]:
+			secrets = append(secrets, envVar)
+		default:
+			vars = append(vars, envVar)
+		}
+	}
+	return vars, secrets, masked
+}
+
+// renderConfigEnv writes the environment variables in a format. The
+// secret ones are written as a Secret in the Kubernetes format
+func renderConfigEnv(vars []*configEnvVar, format, name string, secret bool) (string, error) {
+	var b strings.Builder
+	switch format {
+	case configEnvFormatEnv:
+		for _, envVar := range vars {
+			value := envVar.value
+			if !shellSafeValue.MatchString(value) {
+				value = "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+			}
+			fmt.Fprintf(&b, "%s=%s\n", envVar.name, value)
+		}
+	case configEnvFormatDotenv:
+		for _, envVar := range vars {
+			if strings.ContainsAny(envVar.value, "\r\n") {
+				return "", fmt.Errorf("%s can't be written to a .env file, as its value has line breaks", envVar.name)
+			}
+			fmt.Fprintf(&b, "%s=%s\n", envVar.name, envVar.value)
+		}
+	case configEnvFormatKubernetes:
+		data := map[string]string{}
+		for _, envVar := range vars {
+			data[envVar.name] = envVar.value
+		}
+		object := &kubernetesConfigObject{APIVersion: "v1", Kind: kubernetesKindConfigMap, Data: data}
+		if secret {
+			object = &kubernetesConfigObject{APIVersion: "v1", Kind: kubernetesKindSecret, Type: "Opaque", StringData: data}
+		}
+		object.Metadata.Name = name
+		out, err := yaml.Marshal(object)
+		if err != nil {
+			return "", err
+		}
+		b.Write(out)
+	default:
+		return "", fmt.Errorf("invalid format %q, must be one of: env, dotenv, k8s", format)
+	}
+	return b.String(), nil
+}
+
+func configExportEnvCmdF(currentClient func() (client.Client, error), cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("env-format")
+	nonDefault, _ := cmd.Flags().GetBool("non-default")
+	secretsOutput, _ := cmd.Flags().GetString("secrets-output")
+	name, _ := cmd.Flags().GetString("name")
+	if format == "" {
+		format = configEnvFormatEnv
+	}
+
+	source := configSourceServer
+	if len(args) > 0 {
+		source = args[0]
+	}
+	config, err := loadConfigSource(source, currentClient)
+	if err != nil {
+		return err
+	}
+
+	vars, secrets, masked := exportConfigEnv(config, nonDefault)
+	out, err := renderConfigEnv(vars, format, name, false)
+	if err != nil {
+		return err
+	}
+
+	if masked > 0 {
+		printer.PrintWarning(fmt.Sprintf("%d secret settings are masked in the source and were left out", masked))
+	}
+	if len(secrets) > 0 {
+		if secretsOutput == "" {
+			printer.PrintWarning(fmt.Sprintf("%d secret settings were left out, use --secrets-output to export them", len(secrets)))
+		} else {
+			secretsOut, rErr := renderConfigEnv(secrets, format, name, true)
+			if rErr != nil {
+				return rErr
+			}
+			if wErr := os.WriteFile(secretsOutput, []byte(secretsOut), 0600); wErr != nil {
+				return fmt.Errorf("could not write the secret settings: %w", wErr)
+			}
+		}
+	}
+
+	if outputFormat := viper.GetString("format"); (outputFormat != printer.FormatPlain && outputFormat != "") || viper.GetBool("json") || viper.GetString("template") != "" {
+		for _, envVar := range vars {
+			printer.PrintT("{{.Name}}={{.Value}}", &ConfigEnvVariable{Name: envVar.name, Value: envVar.value})
+		}
+		return nil
+	}
+
+	printer.SetNoNewline(true)
+	printer.Print(out)
+	return nil
+}
+
+// unquoteEnvValue removes the shell single quotes or the double quotes
+// of a value, if it has them
+func unquoteEnvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", errors.New("invalid double quoted value")
+		}
+		return unquoted, nil
+	case strings.HasPrefix(value, "'"):
+		// a single quote is written as '\'' inside single quotes
+		var b strings.Builder
+		for value != "" {
+			switch {
+			case strings.HasPrefix(value, `\'`):
+				b.WriteByte('\'')
+				value = value[2:]
+			case value[0] == '\'':
+				end := strings.IndexByte(value[1:], '\'')
+				if end < 0 {
+					return "", errors.New("unterminated single quoted value")
+				}
+				b.WriteString(value[1 : end+1])
+				value = value[end+2:]
+			default:
+				return "", errors.New("invalid single quoted value")
+			}
+		}
+		return b.String(), nil
+	}
+	return value, nil
+}
+
+func readConfigEnvLines(source string, b []byte) ([]*configEnvVar, error) {
+	var vars []*configEnvVar
+	var result *multierror.Error
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			result = multierror.Append(result, &ArgLineError{Source: source, Line: i + 1, Err: errors.New("expected NAME=value")})
+			continue
+		}
+		value, err := unquoteEnvValue(value)
+		if err != nil {
+			result = multierror.Append(result, &ArgLineError{Source: source, Line: i + 1, Err: err})
+			continue
+		}
+		vars = append(vars, &configEnvVar{name: strings.TrimSpace(name), value: value, line: i + 1})
+	}
+	return vars, result.ErrorOrNil()
+}
+
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// readConfigEnvKubernetes reads the variables of the ConfigMaps and
+// Secrets of a YAML document stream
+func readConfigEnvKubernetes(source string, b []byte) ([]*configEnvVar, error) {
+	var vars []*configEnvVar
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", source, err)
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		object := doc.Content[0]
+
+		// the data of the Secrets is encoded in base64
+		fields := map[string]bool{}
+		switch kind := yamlMappingValue(object, "kind"); {
+		case kind != nil && kind.Value == kubernetesKindConfigMap:
+			fields["data"] = false
+		case kind != nil && kind.Value == kubernetesKindSecret:
+			fields["data"] = true
+			fields["stringData"] = false
+		default:
+			return nil, &ArgLineError{Source: source, Line: object.Line, Err: errors.New("expected a ConfigMap or a Secret")}
+		}
+
+		for _, field := range []string{"data", "stringData"} {
+			encoded, ok := fields[field]
+			data := yamlMappingValue(object, field)
+			if !ok || data == nil {
+				continue
+			}
+			for i := 0; i+1 < len(data.Content); i += 2 {
+				key, value := data.Content[i], data.Content[i+1]
+				envVar := &configEnvVar{name: key.Value, value: value.Value, line: key.Line}
+				if encoded {
+					decoded, err := base64.StdEncoding.DecodeString(value.Value)
+					if err != nil {
+						return nil, &ArgLineError{Source: source, Line: key.Line, Err: errors.New("invalid base64 value")}
+					}
+					envVar.value = string(decoded)
+				}
+				vars = append(vars, envVar)
+			}
+		}
+	}
+	return vars, nil
+}
+
+// readConfigEnvFile reads the variables of a file, which can be a YAML
+// document of Kubernetes objects or have a variable on each line
+func readConfigEnvFile(path string) ([]*configEnvVar, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "---" || strings.HasPrefix(line, "apiVersion:") || strings.HasPrefix(line, "kind:") {
+			return readConfigEnvKubernetes(path, b)
+		}
+		break
+	}
+	return readConfigEnvLines(path, b)
+}
+
+func parseConfigEnvValue(typ reflect.Type, value string) (interface{}, error) {
+	var parsed interface{}
+	var err error
+	switch typ.Kind() {
+	case reflect.Bool:
+		parsed, err = strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err = strconv.ParseInt(value, 10, typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		parsed, err = strconv.ParseFloat(value, typ.Bits())
+	case reflect.Slice:
+		parsed = strings.Fields(value)
+	default:
+		parsed = value
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q, must be of type %s", value, typ.Kind())
+	}
+	return parsed, nil
+}
+
+// importConfigEnv builds the config patches of the variables of a
+// file, split in the regular and the secret settings
+func importConfigEnv(source string, vars []*configEnvVar, settings map[string]*configEnvSetting, patch, secrets []*configSetting) ([]*configSetting, []*configSetting, error) {
+	secretPaths := configSecretPaths()
+	var result *multierror.Error
+	for _, envVar := range vars {
+		if !strings.HasPrefix(envVar.name, configEnvPrefix) {
+			printer.PrintWarning(fmt.Sprintf("%s:%d: %s is not a Mattermost variable and is ignored", source, envVar.line, envVar.name))
+			continue
+		}
+		setting, ok := settings[envVar.name]
+		if !ok {
+			printer.PrintWarning(fmt.Sprintf("%s:%d: %s is not a config setting and is ignored", source, envVar.line, envVar.name))
+			continue
+		}
+
+		value, err := parseConfigEnvValue(setting.typ, envVar.value)
+		if err != nil {
+			result = multierror.Append(result, &ArgLineError{Source: source, Line: envVar.line, Err: fmt.Errorf("%s: %w", envVar.name, err)})
+			continue
+		}
+
+		imported := &configSetting{segments: setting.segments, value: value, mapKey: -1}
+		if secretPaths[strings.Join(setting.segments, ".")] {
+			secrets = append(secrets, imported)
+		} else {
+			patch = append(patch, imported)
+		}
+	}
+	return patch, secrets, result.ErrorOrNil()
+}
+
+func configImportEnvCmdF(cmd *cobra.Command, args []string) error {
+	secretsOutput, _ := cmd.Flags().GetString("secrets-output")
+
+	settings := configEnvSettings()
+	var patch, secrets []*configSetting
+	var result *multierror.Error
+	for _, path := range args {
+		// the variables of the valid lines are imported too, so all the
+		// errors of the file are reported at once
+		vars, err := readConfigEnvFile(path)
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+		if patch, secrets, err = importConfigEnv(path, vars, settings, patch, secrets); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+	if err := result.ErrorOrNil(); err != nil {
+		return err
+	}
+
+	if len(secrets) > 0 {
+		if secretsOutput == "" {
+			printer.PrintWarning(fmt.Sprintf("%d secret settings were left out, use --secrets-output to import them", len(secrets)))
+		} else {
+			b, err := json.MarshalIndent(unflattenConfig(secrets), "", "  ")
+			if err != nil {
+				return err
+			}
+			if wErr := os.WriteFile(secretsOutput, append(b, '\n'), 0600); wErr != nil {
+				return fmt.Errorf("could not write the secret settings: %w", wErr)
+			}
+		}
+	}
+
+	printer.SetSingle(true)
+	printer.SetFormat(printer.FormatJSON)
+	printer.Print(unflattenConfig(patch))
+	return nil
+}
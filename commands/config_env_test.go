@@ -0,0 +1,222 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestConfigExportEnvCmd() {
+	s.T().Cleanup(func() { printer.SetNoNewline(false) })
+	dir := s.T().TempDir()
+
+	config := &model.Config{}
+	config.SetDefaults()
+	*config.ServiceSettings.SiteURL = "https://example.com"
+	*config.TeamSettings.SiteName = "Bob's team"
+	*config.TeamSettings.MaxUsersPerTeam = 100
+	*config.SqlSettings.DataSource = "postgres://mmuser:secret@db/mattermost"
+	config.SqlSettings.DataSourceReplicas = []string{"postgres://replica1", "postgres://replica2"}
+	config.PluginSettings.Plugins["com.mattermost.demo"] = map[string]interface{}{"enabled": true}
+
+	path := filepath.Join(dir, "config.json")
+	b, err := json.Marshal(config)
+	s.Require().NoError(err)
+	s.Require().NoError(os.WriteFile(path, b, 0600))
+
+	currentClient := func() (client.Client, error) { return s.client, nil }
+	newCmd := func(format string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("env-format", format, "")
+		cmd.Flags().Bool("non-default", true, "")
+		cmd.Flags().String("secrets-output", "", "")
+		cmd.Flags().String("name", "mattermost-config", "")
+		return cmd
+	}
+
+	s.Run("should export the non default settings of a file with the secrets apart", func() {
+		printer.Clean()
+		secretsPath := filepath.Join(dir, "secrets.env")
+		cmd := newCmd(configEnvFormatEnv)
+		s.Require().NoError(cmd.Flags().Set("secrets-output", secretsPath))
+
+		err := configExportEnvCmdF(currentClient, cmd, []string{path})
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 1)
+		out := printer.GetLines()[0].(string)
+		s.Require().Contains(out, "MM_SERVICESETTINGS_SITEURL=https://example.com\n")
+		s.Require().Contains(out, `MM_TEAMSETTINGS_SITENAME='Bob'\''s team'`+"\n")
+		s.Require().Contains(out, "MM_TEAMSETTINGS_MAXUSERSPERTEAM=100\n")
+		s.Require().NotContains(out, "MM_SERVICESETTINGS_ENABLEDEVELOPER")
+		s.Require().NotContains(out, "MM_SQLSETTINGS_DATASOURCE")
+		s.Require().NotContains(out, "PLUGINS")
+
+		secrets, err := os.ReadFile(secretsPath)
+		s.Require().NoError(err)
+		s.Require().Contains(string(secrets), "MM_SQLSETTINGS_DATASOURCE=postgres://mmuser:secret@db/mattermost\n")
+		s.Require().Contains(string(secrets), "MM_SQLSETTINGS_DATASOURCEREPLICAS='postgres://replica1 postgres://replica2'\n")
+
+		info, err := os.Stat(secretsPath)
+		s.Require().NoError(err)
+		s.Require().Equal(os.FileMode(0600), info.Mode().Perm())
+	})
+
+	s.Run("should export the server config as a kubernetes config map", func() {
+		printer.Clean()
+		serverConfig := config.Clone()
+		serverConfig.Sanitize()
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(serverConfig, &model.Response{}, nil).
+			Times(1)
+
+		err := configExportEnvCmdF(currentClient, newCmd(configEnvFormatKubernetes), nil)
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 1)
+		out := printer.GetLines()[0].(string)
+		s.Require().Contains(out, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n    name: mattermost-config\ndata:\n")
+		s.Require().Contains(out, "    MM_SERVICESETTINGS_SITEURL: https://example.com\n")
+		s.Require().Contains(out, `    MM_TEAMSETTINGS_MAXUSERSPERTEAM: "100"`+"\n")
+		s.Require().NotContains(out, "MM_SQLSETTINGS_DATASOURCE")
+	})
+
+	s.Run("should print the variables in the global output format", func() {
+		printer.Clean()
+		// the format flag is bound instead of set, as set values can't
+		// be unset and would override the flag in other tests
+		setFormat := func(format string) {
+			flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+			flags.String("format", format, "")
+			_ = viper.BindPFlag("format", flags.Lookup("format"))
+		}
+		setFormat(printer.FormatJSON)
+		defer setFormat(printer.FormatPlain)
+
+		err := configExportEnvCmdF(currentClient, newCmd(configEnvFormatDotenv), []string{path})
+		s.Require().NoError(err)
+		s.Require().Contains(printer.GetLines(), &ConfigEnvVariable{Name: "MM_SERVICESETTINGS_SITEURL", Value: "https://example.com"})
+		s.Require().Contains(printer.GetLines(), &ConfigEnvVariable{Name: "MM_TEAMSETTINGS_MAXUSERSPERTEAM", Value: "100"})
+		s.Require().NotContains(printer.GetLines(), &ConfigEnvVariable{Name: "MM_SQLSETTINGS_DATASOURCE", Value: "postgres://mmuser:secret@db/mattermost"})
+	})
+
+	s.Run("should fail with an invalid format", func() {
+		printer.Clean()
+		err := configExportEnvCmdF(currentClient, newCmd("xml"), []string{path})
+		s.Require().EqualError(err, `invalid format "xml", must be one of: env, dotenv, k8s`)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestConfigImportEnvCmd() {
+	dir := s.T().TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		s.Require().NoError(os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("secrets-output", "", "")
+		return cmd
+	}
+
+	s.Run("should import shell lines with the secrets apart", func() {
+		printer.Clean()
+		path := writeFile("mattermost.env", `# Mattermost
+export MM_SERVICESETTINGS_SITEURL=https://example.com
+MM_TEAMSETTINGS_SITENAME='Bob'\''s team'
+MM_TEAMSETTINGS_MAXUSERSPERTEAM="100"
+MM_SERVICESETTINGS_ENABLEDEVELOPER=true
+MM_SQLSETTINGS_DATASOURCEREPLICAS=postgres://replica1 postgres://replica2
+MM_SERVICEENVIRONMENT=production
+HOME=/root
+`)
+		secretsPath := filepath.Join(dir, "secrets.json")
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("secrets-output", secretsPath))
+
+		err := configImportEnvCmdF(cmd, []string{path})
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 1)
+		patch, err := json.Marshal(printer.GetLines()[0])
+		s.Require().NoError(err)
+		s.Require().JSONEq(`{
+  "ServiceSettings": {"SiteURL": "https://example.com", "EnableDeveloper": true},
+  "TeamSettings": {"SiteName": "Bob's team", "MaxUsersPerTeam": 100}
+}`, string(patch))
+
+		secrets, err := os.ReadFile(secretsPath)
+		s.Require().NoError(err)
+		s.Require().JSONEq(`{"SqlSettings": {"DataSourceReplicas": ["postgres://replica1", "postgres://replica2"]}}`, string(secrets))
+
+		config := &model.Config{}
+		s.Require().NoError(json.Unmarshal(patch, config))
+		s.Require().Equal(100, *config.TeamSettings.MaxUsersPerTeam)
+	})
+
+	s.Run("should import kubernetes config maps and secrets", func() {
+		printer.Clean()
+		configMap := writeFile("configmap.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: mattermost-config
+data:
+  MM_SERVICESETTINGS_SITEURL: https://example.com
+  MM_TEAMSETTINGS_MAXUSERSPERTEAM: "100"
+`)
+		secret := writeFile("secret.yaml", `---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: mattermost-config
+data:
+  MM_SQLSETTINGS_DATASOURCE: cG9zdGdyZXM6Ly9kYg==
+stringData:
+  MM_EMAILSETTINGS_SMTPPASSWORD: password
+`)
+		secretsPath := filepath.Join(dir, "k8s-secrets.json")
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("secrets-output", secretsPath))
+
+		err := configImportEnvCmdF(cmd, []string{configMap, secret})
+		s.Require().NoError(err)
+		patch, err := json.Marshal(printer.GetLines()[0])
+		s.Require().NoError(err)
+		s.Require().JSONEq(`{
+  "ServiceSettings": {"SiteURL": "https://example.com"},
+  "TeamSettings": {"MaxUsersPerTeam": 100}
+}`, string(patch))
+
+		secrets, err := os.ReadFile(secretsPath)
+		s.Require().NoError(err)
+		s.Require().JSONEq(`{
+  "SqlSettings": {"DataSource": "postgres://db"},
+  "EmailSettings": {"SMTPPassword": "password"}
+}`, string(secrets))
+	})
+
+	s.Run("should fail with the lines that have invalid values", func() {
+		printer.Clean()
+		path := writeFile("invalid.env", `MM_SERVICESETTINGS_SITEURL=https://example.com
+MM_TEAMSETTINGS_MAXUSERSPERTEAM=many
+MM_TEAMSETTINGS_SITENAME='unterminated
+`)
+
+		err := configImportEnvCmdF(newCmd(), []string{path})
+		s.Require().Error(err)
+		s.Require().Contains(err.Error(), path+":2: MM_TEAMSETTINGS_MAXUSERSPERTEAM: invalid value \"many\", must be of type int")
+		s.Require().Contains(err.Error(), path+":3: unterminated single quoted value")
+		s.Require().Len(printer.GetLines(), 0)
+	})
+}
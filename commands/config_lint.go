@@ -0,0 +1,450 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+const (
+	ConfigLintSeverityError   = "error"
+	ConfigLintSeverityWarning = "warning"
+	ConfigLintSeverityInfo    = "info"
+
+	configLintFailOnNone = "none"
+)
+
+var configLintSeverities = map[string]int{
+	ConfigLintSeverityInfo:    0,
+	ConfigLintSeverityWarning: 1,
+	ConfigLintSeverityError:   2,
+}
+
+var ConfigValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validate a config file",
+	Long: `Validates a JSON configuration file without contacting a server. The file is checked for unknown settings and values of the wrong type, and then validated like the server does when the configuration is saved. The settings missing from the file take their default values.
+
+The command fails when the file is not valid, so it can be used in deployment pipelines.`,
+	Example: `  config validate config.json`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    configValidateCmdF,
+}
+
+var ConfigLintCmd = &cobra.Command{
+	Use:   "lint [source]",
+	Short: "Check a config for security and operational issues",
+	Long: `Checks a configuration against a set of rules of security and operational best practices. The source can be any of the ones of "config diff", and defaults to the current server.
+
+Each finding has the id of its rule and a severity: error, warning or info. The command fails when there are findings with the severity of --fail-on or higher, so it can be used in deployment pipelines. Rules can be disabled with --disable, and --list-rules prints all of them.`,
+	Example: `  config lint
+  config lint config.json --fail-on warning
+  config lint server:production --disable developer-mode --disable public-links`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return configLintCmdF(getClient, cmd, args)
+	},
+}
+
+func init() {
+	ConfigLintCmd.Flags().StringArray("disable", nil, "disables a rule by its id. Can be repeated")
+	ConfigLintCmd.Flags().String("fail-on", ConfigLintSeverityError, "minimum severity of the findings that make the command fail [error, warning, info, none]")
+	ConfigLintCmd.Flags().Bool("list-rules", false, "prints the rules instead of checking a configuration")
+
+	ConfigCmd.AddCommand(
+		ConfigValidateCmd,
+		ConfigLintCmd,
+	)
+}
+
+// ConfigLintFinding is an issue found in a configuration
+type ConfigLintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ConfigLintRule is a check of a configuration
+type ConfigLintRule struct {
+	ID          string `json:"id"`
+	Severity    string `json:"severity"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+
+	// check returns the message of the finding, or an empty string if
+	// the configuration follows the rule
+	check func(config *model.Config) string
+}
+
+// configLintRules are the rules of "config lint", sorted by id
+var configLintRules = []*ConfigLintRule{
+	{
+		ID:          "developer-mode",
+		Severity:    ConfigLintSeverityWarning,
+		Path:        "ServiceSettings.EnableDeveloper",
+		Description: "The developer mode shows internal errors to the users",
+		check: func(config *model.Config) string {
+			if *config.ServiceSettings.EnableDeveloper {
+				return "the developer mode is enabled"
+			}
+			return ""
+		},
+	},
+	{
+		ID:          "file-storage-local-in-cluster",
+		Severity:    ConfigLintSeverityError,
+		Path:        "FileSettings.DriverName",
+		Description: "The nodes of a cluster need a shared file storage",
+		check: func(config *model.Config) string {
+			if *config.ClusterSettings.Enable && *config.FileSettings.DriverName == model.ImageDriverLocal {
+				return "the files are stored in the local disk of each node of the cluster"
+			}
+			return ""
+		},
+	},
+	{
+		ID:          "insecure-outgoing-connections",
+		Severity:    ConfigLintSeverityError,
+		Path:        "ServiceSettings.EnableInsecureOutgoingConnections",
+		Description: "Outgoing HTTPS connections must verify the certificates of the servers",
+		check: func(config *model.Config) string {
+			if *config.ServiceSettings.EnableInsecureOutgoingConnections {
+				return "the certificates of outgoing HTTPS connections are not verified"
+			}
+			return ""
+		},
+	},
+	{
+		ID:          "insecure-smtp",
+		Severity:    ConfigLintSeverityWarning,
+		Path:        "EmailSettings.ConnectionSecurity",
+		Description: "The connection to a remote SMTP server should be encrypted",
+		check: func(config *model.Config) string {
+			server := *config.EmailSettings.SMTPServer
+			if !*config.EmailSettings.SendEmailNotifications || server == "" || server == "localhost" || server == "127.0.0.1" {
+				return ""
+			}
+			if *config.EmailSettings.ConnectionSecurity == model.ConnSecurityNone {
+				return fmt.Sprintf("the emails are sent to SMTP server %s without encryption", server)
+			}
+			return ""
+		},
+	},
+	{
+		ID:          "missing-site-url",
+		Severity:    ConfigLintSeverityError,
+		Path:        "ServiceSettings.SiteURL",
+		Description: "The site URL is needed by links, notifications and integrations",
+		check: func(config *model.Config) string {
+			if *config.ServiceSettings.SiteURL == "" {
+				return "the site URL is not set"
+			}
+			return ""
+		},
+	},
+	{
+		ID:          "mfa-not-enforced",
+		Severity:    ConfigLintSeverityInfo,
+		Path:        "ServiceSettings.EnforceMultifactorAuthentication",
+		Description: "Multi-factor authentication should be required when it is enabled",
+		check: func(config *model.Config) string {
+			if *config.ServiceSettings.EnableMultifactorAuthentication && !*config.ServiceSettings.EnforceMultifactorAuthentication {
+				return "multi-factor authentication is enabled but not required"
+			}
+			return ""
+		},
+	},
+	{
+		ID:          "open-signup",
+		Severity:    ConfigLintSeverityWarning,
+		Path:        "TeamSettings.EnableOpenServer",
+		Description: "Anyone that can reach the server can create an account",
+		check: func(config *model.Config) string {
+			if *config.TeamSettings.EnableUserCreation && *config.TeamSettings.EnableOpenServer && *config.TeamSettings.RestrictCreationToDomains == "" {
+				return "anyone can sign up without an invitation"
+			}
+			return ""
+		},
+	},
+	{
+		ID:          "public-links",
+		Severity:    ConfigLintSeverityInfo,
+		Path:        "FileSettings.EnablePublicLink",
+		Description: "Public links give access to files without authentication",
+		check: func(config *model.Config) string {
+			if *config.FileSettings.EnablePublicLink {
+				return "public links to files are enabled"
+			}
+			return ""
+		},
+	},
+	{
+		ID:          "rate-limit-disabled",
+		Severity:    ConfigLintSeverityWarning,
+		Path:        "RateLimitSettings.Enable",
+		Description: "The API should be rate limited unless a proxy does it",
+		check: func(config *model.Config) string {
+			if !*config.RateLimitSettings.Enable {
+				return "the API is not rate limited"
+			}
+			return ""
+		},
+	},
+	{
+		ID:          "site-url-http",
+		Severity:    ConfigLintSeverityWarning,
+		Path:        "ServiceSettings.SiteURL",
+		Description: "The site should be served over HTTPS",
+		check: func(config *model.Config) string {
+			siteURL, err := url.Parse(*config.ServiceSettings.SiteURL)
+			if err == nil && siteURL.Scheme == "http" {
+				return "the site is served over HTTP"
+			}
+			return ""
+		},
+	},
+	{
+		ID:          "testing-mode",
+		Severity:    ConfigLintSeverityWarning,
+		Path:        "ServiceSettings.EnableTesting",
+		Description: "The testing commands must not be available in production",
+		check: func(config *model.Config) string {
+			if *config.ServiceSettings.EnableTesting {
+				return "the testing commands are enabled"
+			}
+			return ""
+		},
+	},
+	{
+		ID:          "weak-password-policy",
+		Severity:    ConfigLintSeverityWarning,
+		Path:        "PasswordSettings",
+		Description: "Passwords should have at least 10 characters with letters, numbers and symbols",
+		check: func(config *model.Config) string {
+			var weaknesses []string
+			if minimum := *config.PasswordSettings.MinimumLength; minimum < 10 {
+				weaknesses = append(weaknesses, fmt.Sprintf("a minimum length of %d", minimum))
+			}
+			for name, required := range map[string]*bool{
+				"lowercase letters": config.PasswordSettings.Lowercase,
+				"uppercase letters": config.PasswordSettings.Uppercase,
+				"numbers":           config.PasswordSettings.Number,
+				"symbols":           config.PasswordSettings.Symbol,
+			} {
+				if !*required {
+					weaknesses = append(weaknesses, "no "+name)
+				}
+			}
+			if len(weaknesses) == 0 {
+				return ""
+			}
+			sort.Strings(weaknesses)
+			return "the passwords require " + strings.Join(weaknesses, ", ")
+		},
+	},
+}
+
+func printConfigLintFindings(findings []*ConfigLintFinding) {
+	for _, finding := range findings {
+		printer.PrintT("{{.Severity}} {{.Rule}}: {{.Message}}{{if .Path}} ({{.Path}}){{end}}", finding)
+	}
+}
+
+// unknownConfigSettings returns the paths of the settings of a JSON
+// document that are not part of the type of the configuration
+func unknownConfigSettings(doc map[string]interface{}, t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field.Type
+	}
+
+	var unknown []string
+	for key, value := range doc {
+		fieldType, ok := fields[strings.ToLower(key)]
+		if !ok {
+			unknown = append(unknown, prefix+key)
+			continue
+		}
+		if child, ok := value.(map[string]interface{}); ok {
+			unknown = append(unknown, unknownConfigSettings(child, fieldType, prefix+key+".")...)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// validateConfigFile checks a JSON configuration, returning its
+// findings and the configuration with the defaults set if it could be
+// decoded
+func validateConfigFile(b []byte) ([]*ConfigLintFinding, *model.Config) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return []*ConfigLintFinding{{Rule: "invalid-json", Severity: ConfigLintSeverityError, Message: err.Error()}}, nil
+	}
+
+	var findings []*ConfigLintFinding
+	for _, path := range unknownConfigSettings(doc, reflect.TypeOf(model.Config{}), "") {
+		findings = append(findings, &ConfigLintFinding{Rule: "unknown-setting", Severity: ConfigLintSeverityWarning, Path: path, Message: "the setting is not known and will be ignored"})
+	}
+
+	var config model.Config
+	if err := json.Unmarshal(b, &config); err != nil {
+		finding := &ConfigLintFinding{Rule: "invalid-type", Severity: ConfigLintSeverityError, Message: err.Error()}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			finding.Path = typeErr.Field
+			finding.Message = fmt.Sprintf("the value must be of type %s, not %s", typeErr.Type, typeErr.Value)
+		}
+		return append(findings, finding), nil
+	}
+
+	config.SetDefaults()
+	if appErr := config.IsValid(); appErr != nil {
+		message := appErr.Message
+		if appErr.DetailedError != "" {
+			message += ": " + appErr.DetailedError
+		}
+		findings = append(findings, &ConfigLintFinding{Rule: "invalid-value", Severity: ConfigLintSeverityError, Message: message})
+	}
+	return findings, &config
+}
+
+func configValidateCmdF(_ *cobra.Command, args []string) error {
+	b, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("could not read the configuration file: %w", err)
+	}
+
+	findings, _ := validateConfigFile(b)
+	printConfigLintFindings(findings)
+	for _, finding := range findings {
+		if finding.Severity == ConfigLintSeverityError {
+			return fmt.Errorf("the configuration file %s is not valid", args[0])
+		}
+	}
+	if len(findings) == 0 {
+		printer.Print(fmt.Sprintf("The configuration file %s is valid", args[0]))
+	}
+	return nil
+}
+
+// disabledConfigLintRules returns the set of the disabled rules,
+// failing if any of them doesn't exist
+func disabledConfigLintRules(disabled []string) (map[string]bool, error) {
+	rules := map[string]bool{}
+	for _, rule := range configLintRules {
+		rules[rule.ID] = true
+	}
+	skip := map[string]bool{}
+	for _, id := range disabled {
+		if !rules[id] {
+			return nil, fmt.Errorf("unknown rule %q, use --list-rules to print the rules", id)
+		}
+		skip[id] = true
+	}
+	return skip, nil
+}
+
+// lintConfig checks the configuration against the rules that are not
+// disabled. The configuration must have its defaults set
+func lintConfig(config *model.Config, disabled map[string]bool) []*ConfigLintFinding {
+	var findings []*ConfigLintFinding
+	for _, rule := range configLintRules {
+		if disabled[rule.ID] {
+			continue
+		}
+		if message := rule.check(config); message != "" {
+			findings = append(findings, &ConfigLintFinding{Rule: rule.ID, Severity: rule.Severity, Path: rule.Path, Message: message})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return configLintSeverities[findings[i].Severity] > configLintSeverities[findings[j].Severity]
+	})
+	return findings
+}
+
+func configLintCmdF(currentClient func() (client.Client, error), cmd *cobra.Command, args []string) error {
+	disabled, _ := cmd.Flags().GetStringArray("disable")
+	failOn, _ := cmd.Flags().GetString("fail-on")
+	listRules, _ := cmd.Flags().GetBool("list-rules")
+	if failOn == "" {
+		failOn = ConfigLintSeverityError
+	}
+	if _, ok := configLintSeverities[failOn]; !ok && failOn != configLintFailOnNone {
+		return fmt.Errorf("invalid severity %q, must be one of: error, warning, info, none", failOn)
+	}
+
+	if listRules {
+		for _, rule := range configLintRules {
+			printer.PrintT("{{.ID}} ({{.Severity}}): {{.Description}}", rule)
+		}
+		return nil
+	}
+
+	skip, err := disabledConfigLintRules(disabled)
+	if err != nil {
+		return err
+	}
+
+	source := configSourceServer
+	if len(args) > 0 {
+		source = args[0]
+	}
+	config, err := loadConfigSource(source, currentClient)
+	if err != nil {
+		return err
+	}
+	config.SetDefaults()
+
+	findings := lintConfig(config, skip)
+	if len(findings) == 0 {
+		printer.Print("No issues found")
+		return nil
+	}
+	printConfigLintFindings(findings)
+
+	if failOn == configLintFailOnNone {
+		return nil
+	}
+	failed := 0
+	for _, finding := range findings {
+		if configLintSeverities[finding.Severity] >= configLintSeverities[failOn] {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d findings with severity %s or higher", failed, failOn)
+	}
+	return nil
+}
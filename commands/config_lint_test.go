@@ -0,0 +1,171 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestConfigValidateCmd() {
+	dir := s.T().TempDir()
+	writeConfig := func(content string) string {
+		path := filepath.Join(dir, "config.json")
+		s.Require().NoError(os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	s.Run("should accept a valid file", func() {
+		printer.Clean()
+		path := writeConfig(`{"ServiceSettings": {"SiteURL": "https://example.com"}}`)
+
+		err := configValidateCmdF(&cobra.Command{}, []string{path})
+		s.Require().NoError(err)
+		s.Require().Equal([]interface{}{"The configuration file " + path + " is valid"}, printer.GetLines())
+	})
+
+	s.Run("should warn about unknown settings", func() {
+		printer.Clean()
+		path := writeConfig(`{
+  "ServiceSettings": {"SiteURL": "https://example.com", "SiteUrl2": "typo"},
+  "PluginSettings": {"Plugins": {"com.mattermost.demo": {"anything": true}}},
+  "UnknownSettings": {}
+}`)
+
+		err := configValidateCmdF(&cobra.Command{}, []string{path})
+		s.Require().NoError(err)
+		lines := printer.GetLines()
+		s.Require().Len(lines, 2)
+		s.Require().Equal("ServiceSettings.SiteUrl2", lines[0].(*ConfigLintFinding).Path)
+		s.Require().Equal("UnknownSettings", lines[1].(*ConfigLintFinding).Path)
+		s.Require().Equal(ConfigLintSeverityWarning, lines[1].(*ConfigLintFinding).Severity)
+	})
+
+	s.Run("should fail with values of the wrong type", func() {
+		printer.Clean()
+		path := writeConfig(`{"ServiceSettings": {"ReadTimeout": "300"}}`)
+
+		err := configValidateCmdF(&cobra.Command{}, []string{path})
+		s.Require().Error(err)
+		lines := printer.GetLines()
+		s.Require().Len(lines, 1)
+		s.Require().Equal("invalid-type", lines[0].(*ConfigLintFinding).Rule)
+		s.Require().Equal("ServiceSettings.ReadTimeout", lines[0].(*ConfigLintFinding).Path)
+	})
+
+	s.Run("should fail with invalid values", func() {
+		printer.Clean()
+		path := writeConfig(`{"ServiceSettings": {"SiteURL": "not a url"}}`)
+
+		err := configValidateCmdF(&cobra.Command{}, []string{path})
+		s.Require().Error(err)
+		lines := printer.GetLines()
+		s.Require().Len(lines, 1)
+		s.Require().Equal("invalid-value", lines[0].(*ConfigLintFinding).Rule)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestConfigLintCmd() {
+	newConfig := func() *model.Config {
+		config := &model.Config{}
+		config.SetDefaults()
+		*config.ServiceSettings.SiteURL = "https://example.com"
+		*config.RateLimitSettings.Enable = true
+		*config.PasswordSettings.MinimumLength = 12
+		*config.PasswordSettings.Lowercase = true
+		*config.PasswordSettings.Uppercase = true
+		*config.PasswordSettings.Number = true
+		*config.PasswordSettings.Symbol = true
+		*config.TeamSettings.EnableOpenServer = false
+		*config.FileSettings.EnablePublicLink = false
+		return config
+	}
+
+	currentClient := func() (client.Client, error) { return s.client, nil }
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().StringArray("disable", nil, "")
+		cmd.Flags().String("fail-on", ConfigLintSeverityError, "")
+		cmd.Flags().Bool("list-rules", false, "")
+		return cmd
+	}
+	rules := func() []string {
+		var ids []string
+		for _, line := range printer.GetLines() {
+			ids = append(ids, line.(*ConfigLintFinding).Rule)
+		}
+		return ids
+	}
+
+	s.Run("should not find issues in a hardened config", func() {
+		printer.Clean()
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(newConfig(), &model.Response{}, nil).
+			Times(1)
+
+		err := configLintCmdF(currentClient, newCmd(), nil)
+		s.Require().NoError(err)
+		s.Require().Equal([]interface{}{"No issues found"}, printer.GetLines())
+	})
+
+	s.Run("should sort the findings by severity and fail on errors", func() {
+		printer.Clean()
+		config := newConfig()
+		*config.ServiceSettings.SiteURL = "http://example.com"
+		*config.ServiceSettings.EnableInsecureOutgoingConnections = true
+		*config.ClusterSettings.Enable = true
+		*config.FileSettings.EnablePublicLink = true
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(config, &model.Response{}, nil).
+			Times(1)
+
+		err := configLintCmdF(currentClient, newCmd(), nil)
+		s.Require().EqualError(err, "2 findings with severity error or higher")
+		s.Require().Equal([]string{
+			"file-storage-local-in-cluster",
+			"insecure-outgoing-connections",
+			"site-url-http",
+			"public-links",
+		}, rules())
+	})
+
+	s.Run("should lint a file with disabled rules and a lower threshold", func() {
+		printer.Clean()
+		config := newConfig()
+		*config.ServiceSettings.EnableDeveloper = true
+		*config.ServiceSettings.EnableTesting = true
+		*config.PasswordSettings.Symbol = false
+		b, err := json.Marshal(config)
+		s.Require().NoError(err)
+		path := filepath.Join(s.T().TempDir(), "config.json")
+		s.Require().NoError(os.WriteFile(path, b, 0600))
+
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("disable", "testing-mode"))
+		s.Require().NoError(cmd.Flags().Set("fail-on", ConfigLintSeverityWarning))
+		err = configLintCmdF(currentClient, cmd, []string{path})
+		s.Require().EqualError(err, "2 findings with severity warning or higher")
+		s.Require().Equal([]string{"developer-mode", "weak-password-policy"}, rules())
+		s.Require().Equal("the passwords require no symbols", printer.GetLines()[1].(*ConfigLintFinding).Message)
+	})
+
+	s.Run("should fail with an unknown rule", func() {
+		printer.Clean()
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("disable", "no-such-rule"))
+		err := configLintCmdF(currentClient, cmd, nil)
+		s.Require().EqualError(err, `unknown rule "no-such-rule", use --list-rules to print the rules`)
+	})
+}
@@ -0,0 +1,496 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+const (
+	configSnapshotsDefaultLimit = 100
+	configSnapshotLatest        = "latest"
+)
+
+var ConfigHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List the config snapshots",
+	Long: `Lists the snapshots of the configuration of the current server, newest first. A snapshot of the previous configuration is saved automatically when the configuration is changed successfully by the "config set", "config patch", "config edit", "config reset" and "config rollback" commands, and can be saved manually with "config snapshot save".
+
+The snapshots are stored in the snapshots directory of the mmctl configuration, which can be changed with the MMCTL_CONFIG_SNAPSHOTS_DIR environment variable. Only the newest automatic snapshots of each server are kept, 100 by default, which can be changed with the MMCTL_CONFIG_SNAPSHOTS_LIMIT environment variable. The manual snapshots are never removed.`,
+	Example: `  config history
+  config history --all`,
+	Args: cobra.NoArgs,
+	RunE: configHistoryCmdF,
+}
+
+var ConfigSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Management of config snapshots",
+}
+
+var ConfigSnapshotSaveCmd = &cobra.Command{
+	Use:     "save",
+	Short:   "Save a config snapshot",
+	Long:    "Saves a snapshot of the configuration of the current server, which is never removed automatically.",
+	Example: `  config snapshot save --reason "before the upgrade"`,
+	Args:    cobra.NoArgs,
+	RunE:    withClient(configSnapshotSaveCmdF),
+}
+
+var ConfigSnapshotShowCmd = &cobra.Command{
+	Use:   "show [snapshot]",
+	Short: "Show a config snapshot",
+	Long:  `Prints a snapshot with its configuration in JSON format. "latest" can be used to show the newest snapshot of the current server.`,
+	Example: `  config snapshot show 20221117-101500-4f2a
+  config snapshot show latest`,
+	Args: cobra.ExactArgs(1),
+	RunE: configSnapshotShowCmdF,
+}
+
+var ConfigRollbackCmd = &cobra.Command{
+	Use:   "rollback [snapshot]",
+	Short: "Roll back the config to a snapshot",
+	Long:  `Restores the configuration of the current server to the one of a snapshot, changing only the settings that are different. The secret settings are masked in the snapshots and are not restored. "latest" can be used to roll back to the newest snapshot, which undoes the last change made with mmctl.`,
+	Example: `  config rollback latest --dry-run
+  config rollback 20221117-101500-4f2a --confirm`,
+	Args: cobra.ExactArgs(1),
+	RunE: withClient(configRollbackCmdF),
+}
+
+func init() {
+	ConfigHistoryCmd.Flags().Bool("all", false, "lists the snapshots of all the servers")
+
+	ConfigSnapshotSaveCmd.Flags().String("reason", "", "description of the snapshot")
+
+	ConfigRollbackCmd.Flags().Bool("dry-run", false, "prints the settings that would change without applying them")
+	ConfigRollbackCmd.Flags().Bool("confirm", false, "confirms the rollback without asking")
+
+	ConfigSnapshotCmd.AddCommand(
+		ConfigSnapshotSaveCmd,
+		ConfigSnapshotShowCmd,
+	)
+	ConfigCmd.AddCommand(
+		ConfigHistoryCmd,
+		ConfigSnapshotCmd,
+		ConfigRollbackCmd,
+	)
+}
+
+// ConfigSnapshot is a copy of the configuration of a server, stored in
+// the snapshots directory
+type ConfigSnapshot struct {
+	ID        string        `json:"id"`
+	Server    string        `json:"server"`
+	CreatedAt int64         `json:"created_at"`
+	Reason    string        `json:"reason,omitempty"`
+	Automatic bool          `json:"automatic,omitempty"`
+	Config    *model.Config `json:"config,omitempty"`
+}
+
+// configSnapshotsDir returns the directory of the snapshots, which can
+// be changed with the MMCTL_CONFIG_SNAPSHOTS_DIR environment variable
+func configSnapshotsDir() string {
+	if dir := viper.GetString("config-snapshots-dir"); dir != "" {
+		return dir
+	}
+	return filepath.Join(getDefaultConfigHomePath(), configParent, "snapshots")
+}
+
+// configSnapshotServer returns the name of the current server in the
+// snapshots
+func configSnapshotServer() string {
+	if viper.GetBool("local") {
+		return "local:" + viper.GetString("local-socket-path")
+	}
+	credentials, err := GetCurrentCredentials()
+	if err != nil {
+		return ""
+	}
+	return credentials.InstanceURL
+}
+
+// lastConfigSnapshotAt is the creation time of the last snapshot saved
+// by this process, which keeps the snapshots saved in the same
+// millisecond in order
+var lastConfigSnapshotAt int64
+
+func saveConfigSnapshot(config *model.Config, reason string, automatic bool) (*ConfigSnapshot, error) {
+	dir := configSnapshotsDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("could not create the snapshots directory: %w", err)
+	}
+
+	now := time.Now()
+	createdAt := model.GetMillisForTime(now)
+	if createdAt <= lastConfigSnapshotAt {
+		createdAt = lastConfigSnapshotAt + 1
+	}
+	lastConfigSnapshotAt = createdAt
+	snapshot := &ConfigSnapshot{
+		ID:        now.UTC().Format("20060102-150405") + "-" + model.NewId()[:4],
+		Server:    configSnapshotServer(),
+		CreatedAt: createdAt,
+		Reason:    reason,
+		Automatic: automatic,
+		Config:    config,
+	}
+	b, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, snapshot.ID+".json"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the snapshot file: %w", err)
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("could not write the snapshot file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("could not write the snapshot file: %w", err)
+	}
+
+	if automatic {
+		if err := pruneConfigSnapshots(snapshot.Server); err != nil {
+			return nil, err
+		}
+	}
+	return snapshot, nil
+}
+
+// snapshotConfig saves a snapshot of the configuration that was
+// replaced by a change. It is called once the change succeeded, so
+// failed changes don't leave snapshots behind, and only warns if the
+// snapshot can't be saved
+func snapshotConfig(config *model.Config, reason string) {
+	if _, err := saveConfigSnapshot(config, reason, true); err != nil {
+		printer.PrintWarning("could not save a snapshot of the configuration: " + err.Error())
+	}
+}
+
+// listConfigSnapshots returns the snapshots without their
+// configuration, newest first
+func listConfigSnapshots() ([]*ConfigSnapshot, error) {
+	entries, err := os.ReadDir(configSnapshotsDir())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read the snapshots directory: %w", err)
+	}
+
+	var snapshots []*ConfigSnapshot
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(configSnapshotsDir(), entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("could not read config snapshot %s: %w", entry.Name(), err)
+		}
+		// the configuration is kept raw, so it isn't decoded
+		var header struct {
+			ConfigSnapshot
+			Config json.RawMessage `json:"config"`
+		}
+		if err := json.Unmarshal(b, &header); err != nil {
+			printer.PrintWarning(fmt.Sprintf("Skipping invalid config snapshot %s: %s", entry.Name(), err))
+			continue
+		}
+		snapshot := header.ConfigSnapshot
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].CreatedAt != snapshots[j].CreatedAt {
+			return snapshots[i].CreatedAt > snapshots[j].CreatedAt
+		}
+		return snapshots[i].ID > snapshots[j].ID
+	})
+	return snapshots, nil
+}
+
+// pruneConfigSnapshots removes the oldest automatic snapshots of a
+// server over the limit
+func pruneConfigSnapshots(server string) error {
+	limit := viper.GetInt("config-snapshots-limit")
+	if limit <= 0 {
+		return nil
+	}
+
+	snapshots, err := listConfigSnapshots()
+	if err != nil {
+		return err
+	}
+	kept := 0
+	for _, snapshot := range snapshots {
+		if snapshot.Server != server || !snapshot.Automatic {
+			continue
+		}
+		if kept++; kept <= limit {
+			continue
+		}
+		if err := os.Remove(filepath.Join(configSnapshotsDir(), snapshot.ID+".json")); err != nil {
+			return fmt.Errorf("could not remove config snapshot %s: %w", snapshot.ID, err)
+		}
+	}
+	return nil
+}
+
+// readConfigSnapshot reads a snapshot by its id, or the newest one of
+// the current server if the id is "latest"
+func readConfigSnapshot(id string) (*ConfigSnapshot, error) {
+	if id == configSnapshotLatest {
+		snapshots, err := listConfigSnapshots()
+		if err != nil {
+			return nil, err
+		}
+		server := configSnapshotServer()
+		id = ""
+		for _, snapshot := range snapshots {
+			if snapshot.Server == server {
+				id = snapshot.ID
+				break
+			}
+		}
+		if id == "" {
+			return nil, fmt.Errorf("there are no config snapshots of the current server")
+		}
+	}
+	if id == "" || filepath.Base(id) != id {
+		return nil, fmt.Errorf("invalid config snapshot id %q", id)
+	}
+
+	b, err := os.ReadFile(filepath.Join(configSnapshotsDir(), id+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrEntityNotFound{Type: "config snapshot", ID: id}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read config snapshot %s: %w", id, err)
+	}
+
+	var snapshot ConfigSnapshot
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		return nil, fmt.Errorf("invalid config snapshot %s: %w", id, err)
+	}
+	if snapshot.Config == nil {
+		return nil, fmt.Errorf("config snapshot %s has no configuration", id)
+	}
+	return &snapshot, nil
+}
+
+func configHistoryCmdF(cmd *cobra.Command, _ []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+
+	snapshots, err := listConfigSnapshots()
+	if err != nil {
+		return err
+	}
+	server := configSnapshotServer()
+
+	printer.SetTemplateFunc("time", func(millis int64) string {
+		return model.GetTimeForMillis(millis).Format(time.RFC3339)
+	})
+	found := false
+	for _, snapshot := range snapshots {
+		if !all && snapshot.Server != server {
+			continue
+		}
+		found = true
+		tpl := `{{.ID}}  {{time .CreatedAt}}  {{.Reason}}`
+		if all {
+			tpl = `{{.ID}}  {{time .CreatedAt}}  {{.Server}}  {{.Reason}}`
+		}
+		printer.PrintT(tpl, snapshot)
+	}
+	if !found {
+		printer.Print("There are no config snapshots")
+	}
+	return nil
+}
+
+func configSnapshotSaveCmdF(c client.Client, cmd *cobra.Command, _ []string) error {
+	reason, _ := cmd.Flags().GetString("reason")
+
+	config, response, err := c.GetConfig()
+	if err != nil {
+		return ExtractErrorFromResponse(response, err)
+	}
+	snapshot, err := saveConfigSnapshot(config, reason, false)
+	if err != nil {
+		return err
+	}
+
+	snapshot.Config = nil
+	printer.PrintT("Config snapshot {{.ID}} saved", snapshot)
+	return nil
+}
+
+func configSnapshotShowCmdF(_ *cobra.Command, args []string) error {
+	printer.SetSingle(true)
+	printer.SetFormat(printer.FormatJSON)
+
+	snapshot, err := readConfigSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+	printer.Print(snapshot)
+	return nil
+}
+
+// configValueAt returns the value of a path of a configuration, which
+// is invalid if the path is not set
+func configValueAt(val reflect.Value, segments []string) reflect.Value {
+	for _, segment := range segments {
+		for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+			if val.IsNil() {
+				return reflect.Value{}
+			}
+			val = val.Elem()
+		}
+		switch val.Kind() {
+		case reflect.Struct:
+			val = val.FieldByName(segment)
+		case reflect.Map:
+			val = val.MapIndex(reflect.ValueOf(segment))
+		default:
+			return reflect.Value{}
+		}
+		if !val.IsValid() {
+			return val
+		}
+	}
+	return val
+}
+
+// setConfigValueAt sets the field or the map entry of a path of a
+// configuration, removing it if the value is invalid. The path can go
+// through structs, but only its last segment can be a map key
+func setConfigValueAt(val reflect.Value, segments []string, value reflect.Value) error {
+	parent := configValueAt(val, segments[:len(segments)-1])
+	for (parent.Kind() == reflect.Ptr || parent.Kind() == reflect.Interface) && !parent.IsNil() {
+		parent = parent.Elem()
+	}
+	last := segments[len(segments)-1]
+
+	switch {
+	case parent.Kind() == reflect.Struct:
+		field := parent.FieldByName(last)
+		if !field.IsValid() || !field.CanSet() {
+			return ErrConfigInvalidPath
+		}
+		if !value.IsValid() {
+			value = reflect.Zero(field.Type())
+		}
+		field.Set(value)
+	case parent.Kind() == reflect.Map:
+		if parent.IsNil() {
+			if !value.IsValid() {
+				return nil
+			}
+			return ErrConfigInvalidPath
+		}
+		parent.SetMapIndex(reflect.ValueOf(last), value)
+	default:
+		return ErrConfigInvalidPath
+	}
+	return nil
+}
+
+// rollbackConfig changes the configuration to the one of the snapshot,
+// returning the settings that were changed. The settings inside maps,
+// like the ones of the plugins, are restored with their whole entry
+func rollbackConfig(config, snapshot *model.Config) ([]*ConfigDiffEntry, error) {
+	settings := [2]map[string]*configSetting{flattenConfig(config), flattenConfig(snapshot)}
+	for _, side := range settings {
+		for path, setting := range side {
+			if isConfigSecret(setting.value) {
+				delete(settings[0], path)
+				delete(settings[1], path)
+			}
+		}
+	}
+
+	entries := diffConfigs(settings[0], settings[1])
+	restored := map[string]bool{}
+	for _, entry := range entries {
+		segments := entry.setting.segments
+		if entry.setting.mapKey >= 0 {
+			segments = segments[:entry.setting.mapKey+1]
+		}
+		path := strings.Join(segments, ".")
+		if restored[path] {
+			continue
+		}
+		restored[path] = true
+
+		value := configValueAt(reflect.ValueOf(snapshot), segments)
+		if err := setConfigValueAt(reflect.ValueOf(config), segments, value); err != nil {
+			return nil, fmt.Errorf("could not restore %s: %w", path, err)
+		}
+	}
+	return entries, nil
+}
+
+func configRollbackCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	confirm, _ := cmd.Flags().GetBool("confirm")
+
+	snapshot, err := readConfigSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+	if server := configSnapshotServer(); snapshot.Server != server {
+		return fmt.Errorf("config snapshot %s belongs to server %s, use \"config diff\" and \"config patch\" to copy settings between servers", snapshot.ID, snapshot.Server)
+	}
+
+	config, response, err := c.GetConfig()
+	if err != nil {
+		return ExtractErrorFromResponse(response, err)
+	}
+	previous := config.Clone()
+
+	entries, err := rollbackConfig(config, snapshot.Config)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		printer.Print(fmt.Sprintf("The configuration is already equal to config snapshot %s", snapshot.ID))
+		return nil
+	}
+
+	printConfigDiffEntries(entries)
+	if dryRun {
+		return nil
+	}
+
+	if !confirm {
+		if err := getConfirmation(fmt.Sprintf("Are you sure you want to roll back %d settings to config snapshot %s?", len(entries), snapshot.ID), false); err != nil {
+			return err
+		}
+	}
+
+	if _, response, err := c.UpdateConfig(config); err != nil {
+		return ExtractErrorFromResponse(response, err)
+	}
+	snapshotConfig(previous, "config rollback "+snapshot.ID)
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestConfigSnapshots() {
+	// the local flag is bound instead of set, as set values can't be
+	// unset and would override the flag in other tests
+	setLocal := func(local bool) {
+		flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+		flags.Bool("local", local, "")
+		_ = viper.BindPFlag("local", flags.Lookup("local"))
+	}
+	setLocal(true)
+	s.T().Cleanup(func() {
+		setLocal(false)
+		viper.Set("config-snapshots-limit", configSnapshotsDefaultLimit)
+	})
+
+	newConfig := func(siteName string) *model.Config {
+		config := &model.Config{}
+		config.SetDefaults()
+		*config.TeamSettings.SiteName = siteName
+		return config
+	}
+
+	s.Run("should save a snapshot before changing the config", func() {
+		printer.Clean()
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(newConfig("Before"), &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			PatchConfig(gomock.Any()).
+			Return(newConfig("After"), &model.Response{}, nil).
+			Times(1)
+
+		err := configSetCmdF(s.client, &cobra.Command{}, []string{"TeamSettings.SiteName", "After"})
+		s.Require().NoError(err)
+
+		snapshot, err := readConfigSnapshot(configSnapshotLatest)
+		s.Require().NoError(err)
+		s.Require().Equal("config set TeamSettings.SiteName", snapshot.Reason)
+		s.Require().Equal("local:"+viper.GetString("local-socket-path"), snapshot.Server)
+		s.Require().True(snapshot.Automatic)
+		s.Require().Equal("Before", *snapshot.Config.TeamSettings.SiteName)
+	})
+
+	s.Run("should not save a snapshot if the config couldn't be changed", func() {
+		printer.Clean()
+		before, err := listConfigSnapshots()
+		s.Require().NoError(err)
+
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(newConfig("Before"), &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			UpdateConfig(gomock.Any()).
+			Return(nil, &model.Response{}, errors.New("invalid config")).
+			Times(1)
+
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("confirm", true, "")
+		err = configResetCmdF(s.client, cmd, []string{"TeamSettings"})
+		s.Require().EqualError(err, "invalid config")
+
+		after, err := listConfigSnapshots()
+		s.Require().NoError(err)
+		s.Require().Equal(before, after)
+	})
+
+	s.Run("should keep the newest automatic snapshots and the manual ones", func() {
+		printer.Clean()
+		viper.Set("config-snapshots-limit", 2)
+
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(newConfig("Manual"), &model.Response{}, nil).
+			Times(1)
+		cmd := &cobra.Command{}
+		cmd.Flags().String("reason", "before the upgrade", "")
+		s.Require().NoError(configSnapshotSaveCmdF(s.client, cmd, nil))
+		manual := printer.GetLines()[0].(*ConfigSnapshot)
+
+		for _, siteName := range []string{"First", "Second", "Third"} {
+			snapshotConfig(newConfig(siteName), "config edit")
+		}
+
+		snapshots, err := listConfigSnapshots()
+		s.Require().NoError(err)
+		var ids []string
+		for _, snapshot := range snapshots {
+			ids = append(ids, snapshot.ID)
+			s.Require().Nil(snapshot.Config)
+		}
+		s.Require().Len(ids, 3)
+		s.Require().Contains(ids, manual.ID)
+
+		latest, err := readConfigSnapshot(configSnapshotLatest)
+		s.Require().NoError(err)
+		s.Require().Equal("Third", *latest.Config.TeamSettings.SiteName)
+
+		printer.Clean()
+		cmd = &cobra.Command{}
+		cmd.Flags().Bool("all", false, "")
+		s.Require().NoError(configHistoryCmdF(cmd, nil))
+		s.Require().Len(printer.GetLines(), 3)
+	})
+
+	s.Run("should roll back the settings that changed", func() {
+		printer.Clean()
+		before := newConfig("Before")
+		before.PluginSettings.Plugins = map[string]map[string]interface{}{
+			"com.mattermost.demo": {"enabled": true},
+		}
+		*before.SqlSettings.DataSource = model.FakeSetting
+		saved, err := saveConfigSnapshot(before, "", false)
+		s.Require().NoError(err)
+
+		current := newConfig("After")
+		current.PluginSettings.Plugins = map[string]map[string]interface{}{
+			"com.mattermost.demo":  {"enabled": true, "channel": "town-square"},
+			"com.mattermost.other": {"enabled": false},
+		}
+		*current.SqlSettings.DataSource = "postgres://mmuser@localhost/mattermost"
+
+		expected := current.Clone()
+		*expected.TeamSettings.SiteName = "Before"
+		expected.PluginSettings.Plugins = map[string]map[string]interface{}{
+			"com.mattermost.demo": {"enabled": true},
+		}
+
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(current, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			UpdateConfig(expected).
+			Return(expected, &model.Response{}, nil).
+			Times(1)
+
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("confirm", true, "")
+		err = configRollbackCmdF(s.client, cmd, []string{saved.ID})
+		s.Require().NoError(err)
+
+		var paths []string
+		for _, line := range printer.GetLines() {
+			paths = append(paths, line.(*ConfigDiffEntry).Path)
+		}
+		s.Require().Equal([]string{
+			"PluginSettings.Plugins.com.mattermost.demo.channel",
+			"PluginSettings.Plugins.com.mattermost.other.enabled",
+			"TeamSettings.SiteName",
+		}, paths)
+
+		latest, err := readConfigSnapshot(configSnapshotLatest)
+		s.Require().NoError(err)
+		s.Require().Equal("config rollback "+saved.ID, latest.Reason)
+		s.Require().Equal("After", *latest.Config.TeamSettings.SiteName)
+	})
+
+	s.Run("should not roll back a snapshot of another server", func() {
+		setLocal(false)
+		snapshot, err := saveConfigSnapshot(newConfig("Other"), "", false)
+		s.Require().NoError(err)
+		setLocal(true)
+
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("dry-run", true, "")
+		err = configRollbackCmdF(s.client, cmd, []string{snapshot.ID})
+		s.Require().Error(err)
+		s.Require().Contains(err.Error(), "belongs to server")
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	"github.com/mattermost/mattermost-server/v6/model"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
@@ -281,6 +282,57 @@ func (s *MmctlUnitTestSuite) TestConfigGetCmd() {
 		s.Require().Len(printer.GetLines(), 0)
 		s.Require().Len(printer.GetErrorLines(), 0)
 	})
+
+	s.Run("Get the settings that match a pattern", func() {
+		outputConfig := &model.Config{}
+		outputConfig.SetDefaults()
+		outputConfig.PluginSettings.Plugins = map[string]map[string]interface{}{
+			"com.mattermost.demo": {"channel": "town-square"},
+			"com.example.other":   {"enabled": true},
+		}
+
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(outputConfig, &model.Response{}, nil).
+			Times(2)
+
+		printer.Clean()
+		err := configGetCmdF(s.client, &cobra.Command{}, []string{"ServiceSettings.*Timeout"})
+		s.Require().Nil(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Require().Equal(map[string]interface{}{
+			"ServiceSettings": map[string]interface{}{"ReadTimeout": 300, "WriteTimeout": 300, "IdleTimeout": 60},
+		}, printer.GetLines()[0])
+
+		printer.Clean()
+		err = configGetCmdF(s.client, &cobra.Command{}, []string{"PluginSettings.Plugins.com.mattermost.*"})
+		s.Require().Nil(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Require().Equal(map[string]interface{}{
+			"PluginSettings": map[string]interface{}{
+				"Plugins": map[string]interface{}{
+					"com.mattermost.demo": map[string]interface{}{"channel": "town-square"},
+				},
+			},
+		}, printer.GetLines()[0])
+	})
+
+	s.Run("Get error if no setting matches a pattern", func() {
+		printer.Clean()
+		outputConfig := &model.Config{}
+		outputConfig.SetDefaults()
+
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(outputConfig, &model.Response{}, nil).
+			Times(1)
+
+		err := configGetCmdF(s.client, &cobra.Command{}, []string{"ServiceSettings.*Missing"})
+		s.Require().EqualError(err, "no config settings match ServiceSettings.*Missing")
+		s.Require().Len(printer.GetLines(), 0)
+	})
 }
 
 func (s *MmctlUnitTestSuite) TestConfigSetCmd() {
@@ -585,6 +637,149 @@ func (s *MmctlUnitTestSuite) TestConfigSetCmd() {
 		s.Require().Len(printer.GetLines(), 0)
 		s.Require().Len(printer.GetErrorLines(), 0)
 	})
+
+	newSetCmd := func(flag string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("json", flag == "json", "")
+		cmd.Flags().Bool("append", flag == "append", "")
+		cmd.Flags().Bool("remove", flag == "remove", "")
+		return cmd
+	}
+
+	s.Run("Set the settings that match a pattern", func() {
+		printer.Clean()
+		defaultConfig := &model.Config{}
+		defaultConfig.SetDefaults()
+		inputConfig := &model.Config{}
+		inputConfig.SetDefaults()
+		*inputConfig.ServiceSettings.ReadTimeout = 120
+		*inputConfig.ServiceSettings.WriteTimeout = 120
+		*inputConfig.ServiceSettings.IdleTimeout = 120
+
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(defaultConfig, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			PatchConfig(inputConfig).
+			Return(inputConfig, &model.Response{}, nil).
+			Times(1)
+
+		err := configSetCmdF(s.client, newSetCmd(""), []string{"ServiceSettings.*Timeout", "120"})
+		s.Require().Nil(err)
+		s.Require().Len(printer.GetLines(), 1)
+	})
+
+	s.Run("Set map entries and structs from JSON literals", func() {
+		defaultConfig := &model.Config{}
+		defaultConfig.SetDefaults()
+		defaultConfig.PluginSettings.Plugins["com.mattermost.demo"] = map[string]interface{}{"channel": "off-topic"}
+
+		inputConfig := &model.Config{}
+		inputConfig.SetDefaults()
+		inputConfig.PluginSettings.Plugins["com.mattermost.demo"] = map[string]interface{}{"channel": "off-topic"}
+		inputConfig.PluginSettings.Plugins["com.mattermost.other"] = map[string]interface{}{"enabled": true, "users": []interface{}{"admin"}}
+		*inputConfig.RateLimitSettings.Enable = true
+		*inputConfig.RateLimitSettings.PerSec = 20
+
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(defaultConfig, &model.Response{}, nil).
+			Times(2)
+		s.client.
+			EXPECT().
+			PatchConfig(gomock.Any()).
+			DoAndReturn(func(config *model.Config) (*model.Config, *model.Response, error) {
+				return config, &model.Response{}, nil
+			}).
+			Times(2)
+
+		printer.Clean()
+		err := configSetCmdF(s.client, newSetCmd("json"), []string{"PluginSettings.Plugins.com.mattermost.other", `{"enabled": true, "users": ["admin"]}`})
+		s.Require().Nil(err)
+
+		printer.Clean()
+		err = configSetCmdF(s.client, newSetCmd("json"), []string{"RateLimitSettings", `{"Enable": true, "PerSec": 20}`})
+		s.Require().Nil(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Require().Equal(inputConfig, printer.GetLines()[0])
+	})
+
+	s.Run("Append and remove values of an array setting", func() {
+		defaultConfig := &model.Config{}
+		defaultConfig.SetDefaults()
+		defaultConfig.SqlSettings.DataSourceReplicas = []string{"a", "b"}
+
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(defaultConfig, &model.Response{}, nil).
+			Times(2)
+		s.client.
+			EXPECT().
+			PatchConfig(gomock.Any()).
+			DoAndReturn(func(config *model.Config) (*model.Config, *model.Response, error) {
+				return config, &model.Response{}, nil
+			}).
+			Times(2)
+
+		printer.Clean()
+		err := configSetCmdF(s.client, newSetCmd("append"), []string{"SqlSettings.DataSourceReplicas", "b", "c"})
+		s.Require().Nil(err)
+		s.Require().Equal([]string{"a", "b", "c"}, defaultConfig.SqlSettings.DataSourceReplicas)
+
+		printer.Clean()
+		err = configSetCmdF(s.client, newSetCmd("remove"), []string{"SqlSettings.DataSourceReplicas", "a", "x"})
+		s.Require().Nil(err)
+		s.Require().Equal([]string{"b", "c"}, defaultConfig.SqlSettings.DataSourceReplicas)
+	})
+
+	s.Run("Should fail to append to a setting that is not an array", func() {
+		printer.Clean()
+		defaultConfig := &model.Config{}
+		defaultConfig.SetDefaults()
+
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(defaultConfig, &model.Response{}, nil).
+			Times(1)
+
+		err := configSetCmdF(s.client, newSetCmd("append"), []string{"SqlSettings.DriverName", "postgres"})
+		s.Require().EqualError(err, "SqlSettings.DriverName is not an array setting")
+		s.Require().Len(printer.GetLines(), 0)
+	})
+
+	s.Run("Set a JSON literal with a cloud restricted config path", func() {
+		printer.Clean()
+		defaultConfig := &model.Config{}
+		defaultConfig.SetDefaults()
+		js, err := defaultConfig.ToJSONFiltered(model.ConfigAccessTagType, model.ConfigAccessTagCloudRestrictable)
+		s.Require().NoError(err)
+		defaultConfig = model.ConfigFromJSON(bytes.NewBuffer(js))
+
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(defaultConfig, &model.Response{}, nil).
+			Times(1)
+
+		err = configSetCmdF(s.client, newSetCmd("json"), []string{"ServiceSettings", `{"EnableDeveloper": true}`})
+		s.Require().EqualError(err, "changing this config path: ServiceSettings.EnableDeveloper is restricted in a cloud environment")
+		s.Require().Len(printer.GetLines(), 0)
+	})
+
+	s.Run("Should fail with more than one mode", func() {
+		printer.Clean()
+		cmd := newSetCmd("json")
+		s.Require().NoError(cmd.Flags().Set("append", "true"))
+
+		err := configSetCmdF(s.client, cmd, []string{"SqlSettings.DataSourceReplicas", "a"})
+		s.Require().EqualError(err, "only one of --json, --append and --remove can be used")
+	})
 }
 
 func (s *MmctlUnitTestSuite) TestConfigPatchCmd() {
@@ -733,6 +928,62 @@ func (s *MmctlUnitTestSuite) TestConfigResetCmd() {
 		s.Require().Len(printer.GetLines(), 0)
 		s.Require().Len(printer.GetErrorLines(), 0)
 	})
+
+	s.Run("Reset a map entry and the settings that match a pattern", func() {
+		printer.Clean()
+		args := []string{"PluginSettings.Plugins.com.mattermost.demo", "ServiceSettings.*Timeout"}
+		currentConfig := &model.Config{}
+		currentConfig.SetDefaults()
+		currentConfig.PluginSettings.Plugins = map[string]map[string]interface{}{
+			"com.mattermost.demo":  {"channel": "town-square"},
+			"com.mattermost.other": {"enabled": true},
+		}
+		*currentConfig.ServiceSettings.ReadTimeout = 10
+		*currentConfig.ServiceSettings.IdleTimeout = 20
+
+		expectedConfig := &model.Config{}
+		expectedConfig.SetDefaults()
+		expectedConfig.PluginSettings.Plugins = map[string]map[string]interface{}{
+			"com.mattermost.other": {"enabled": true},
+		}
+
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(currentConfig, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			UpdateConfig(expectedConfig).
+			Return(expectedConfig, &model.Response{}, nil).
+			Times(1)
+
+		resetCmd := &cobra.Command{}
+		resetCmd.Flags().Bool("confirm", true, "")
+		err := configResetCmdF(s.client, resetCmd, args)
+		s.Require().Nil(err)
+		s.Require().Len(printer.GetLines(), 1)
+	})
+
+	s.Run("Should fail to reset a cloud restricted config path", func() {
+		printer.Clean()
+		defaultConfig := &model.Config{}
+		defaultConfig.SetDefaults()
+		js, err := defaultConfig.ToJSONFiltered(model.ConfigAccessTagType, model.ConfigAccessTagCloudRestrictable)
+		s.Require().NoError(err)
+		defaultConfig = model.ConfigFromJSON(bytes.NewBuffer(js))
+
+		s.client.
+			EXPECT().
+			GetConfig().
+			Return(defaultConfig, &model.Response{}, nil).
+			Times(1)
+
+		resetCmd := &cobra.Command{}
+		resetCmd.Flags().Bool("confirm", true, "")
+		err = configResetCmdF(s.client, resetCmd, []string{"ServiceSettings.EnableDeveloper"})
+		s.Require().EqualError(err, "resetting this config path: ServiceSettings.EnableDeveloper is restricted in a cloud environment")
+	})
 }
 
 func (s *MmctlUnitTestSuite) TestConfigShowCmd() {
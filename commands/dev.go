@@ -0,0 +1,137 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/fakeserver"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+var DevCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Development tools",
+	Long:  "Tools to develop and test the scripts that use mmctl.",
+}
+
+var FakeServerCmd = &cobra.Command{
+	Use:   "fake-server",
+	Short: "Run an in-memory fake server",
+	Long: `Runs a fake server that implements the users, teams, channels, posts, config and jobs endpoints of the API in memory, so scripts can be tested without a Mattermost server. The state of the server is lost when it stops.
+
+An admin user is created when the server starts, and mmctl can log in with its credentials or with the access token set with --admin-token. If --socket is set, the server also listens on a unix socket that can be used with the --local flag, running every request as the admin user.`,
+	Example: `  dev fake-server --address localhost:8065 --admin-token mytoken
+  dev fake-server --socket /tmp/mmctl-fake.sock`,
+	Args: cobra.NoArgs,
+	RunE: fakeServerCmdF,
+}
+
+func init() {
+	FakeServerCmd.Flags().String("address", "localhost:8065", "address to listen on")
+	FakeServerCmd.Flags().String("socket", "", "path of a unix socket to listen on for local mode connections")
+	FakeServerCmd.Flags().String("admin-username", fakeserver.DefaultAdminUsername, "username of the admin user")
+	FakeServerCmd.Flags().String("admin-password", fakeserver.DefaultAdminPassword, "password of the admin user")
+	FakeServerCmd.Flags().String("admin-token", "", "access token of the admin user")
+	FakeServerCmd.Flags().String("server-version", "", "server version reported to the clients. Defaults to the version of mmctl")
+
+	DevCmd.AddCommand(FakeServerCmd)
+	RootCmd.AddCommand(DevCmd)
+}
+
+func fakeServerCmdF(cmd *cobra.Command, _ []string) error {
+	address, _ := cmd.Flags().GetString("address")
+	socketPath, _ := cmd.Flags().GetString("socket")
+	opts := fakeserver.Options{}
+	opts.AdminUsername, _ = cmd.Flags().GetString("admin-username")
+	opts.AdminPassword, _ = cmd.Flags().GetString("admin-password")
+	opts.AdminToken, _ = cmd.Flags().GetString("admin-token")
+	opts.Version, _ = cmd.Flags().GetString("server-version")
+	if opts.Version == "" && Version != "unspecified" {
+		opts.Version = Version
+	}
+
+	fake := fakeserver.New(opts)
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("could not listen on %s: %w", address, err)
+	}
+	servers := map[*http.Server]net.Listener{
+		{Handler: fake.Handler(), ReadHeaderTimeout: time.Minute}: listener,
+	}
+
+	if socketPath != "" {
+		socketListener, err := listenUnixSocket(socketPath)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		defer os.Remove(socketPath)
+		servers[&http.Server{Handler: fake.LocalHandler(), ReadHeaderTimeout: time.Minute}] = socketListener
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, len(servers))
+	for server, l := range servers {
+		go func(server *http.Server, l net.Listener) {
+			errs <- server.Serve(l)
+		}(server, l)
+	}
+
+	printer.PrintT("Fake server listening on http://{{.}}", listener.Addr().String())
+	if socketPath != "" {
+		printer.PrintT("Local mode socket: {{.}}", socketPath)
+	}
+	if err := printer.Flush(); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+	case err = <-errs:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for server := range servers {
+		_ = server.Shutdown(shutdownCtx)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// listenUnixSocket listens on a socket with the permissions the local
+// mode expects, replacing the socket of a previous run
+func listenUnixSocket(path string) (net.Listener, error) {
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
@@ -4,12 +4,32 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/mattermost/mattermost-server/v6/model"
 )
 
+// Exit codes returned by mmctl. Scripts rely on these values, so
+// existing codes must never change
+const (
+	ExitCodeSuccess         = 0
+	ExitCodeError           = 1
+	ExitCodeBadRequest      = 2
+	ExitCodeUnauthorized    = 3
+	ExitCodeForbidden       = 4
+	ExitCodeNotFound        = 5
+	ExitCodeConflict        = 6
+	ExitCodeServerError     = 7
+	ExitCodeNetworkError    = 8
+	ExitCodeVersionMismatch = 9
+	ExitCodePartialFailure  = 10
+)
+
 // ErrEntityNotFound is thrown when an entity (user, team, etc.)
 // is not found, returning the id sent by arguments
 type ErrEntityNotFound struct {
@@ -21,32 +41,318 @@ func (e ErrEntityNotFound) Error() string {
 	return fmt.Sprintf("%s %s not found", e.Type, e.ID)
 }
 
+// ArgLineError is the error of an argument read from a file, with
+// the line it was read from
+type ArgLineError struct {
+	Source string
+	Line   int
+	Err    error
+}
+
+func (e *ArgLineError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.Source, e.Line, e.Err.Error())
+}
+
+func (e *ArgLineError) Unwrap() error {
+	return e.Err
+}
+
+// PartialFailureError is returned by the commands that process
+// several elements when some of them failed and at least one of
+// them succeeded
+type PartialFailureError struct {
+	Err *multierror.Error
+}
+
+func (e *PartialFailureError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialFailureError) Unwrap() error {
+	return e.Err
+}
+
+// partialFailure returns the errors of the elements that failed,
+// marked as a partial failure if any of the elements succeeded
+func partialFailure(result *multierror.Error, succeeded int) error {
+	if result.ErrorOrNil() == nil {
+		return nil
+	}
+	if succeeded == 0 {
+		return result
+	}
+	return &PartialFailureError{Err: result}
+}
+
 type NotFoundError struct {
 	Msg string
+	err error
 }
 
 func (e *NotFoundError) Error() string {
 	return e.Msg
 }
 
+func (e *NotFoundError) Unwrap() error {
+	return e.err
+}
+
 type BadRequestError struct {
 	Msg string
+	err error
 }
 
 func (e *BadRequestError) Error() string {
 	return e.Msg
 }
 
+func (e *BadRequestError) Unwrap() error {
+	return e.err
+}
+
+type UnauthorizedError struct {
+	Msg string
+	err error
+}
+
+func (e *UnauthorizedError) Error() string {
+	return e.Msg
+}
+
+func (e *UnauthorizedError) Unwrap() error {
+	return e.err
+}
+
+type ForbiddenError struct {
+	Msg string
+	err error
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Msg
+}
+
+func (e *ForbiddenError) Unwrap() error {
+	return e.err
+}
+
+type ConflictError struct {
+	Msg string
+	err error
+}
+
+func (e *ConflictError) Error() string {
+	return e.Msg
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.err
+}
+
+type ServerError struct {
+	Msg string
+	err error
+}
+
+func (e *ServerError) Error() string {
+	return e.Msg
+}
+
+func (e *ServerError) Unwrap() error {
+	return e.err
+}
+
+// NetworkError is returned when the server couldn't be reached, or
+// the connection couldn't be established securely
+type NetworkError struct {
+	Msg string
+	err error
+}
+
+func (e *NetworkError) Error() string {
+	return e.Msg
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.err
+}
+
+// VersionMismatchError is returned when the server version doesn't
+// match the mmctl one and the strict mode is enabled
+type VersionMismatchError struct {
+	Msg string
+}
+
+func (e *VersionMismatchError) Error() string {
+	return e.Msg
+}
+
 // ExtractErrorFromResponse extracts the error from the response,
 // encapsulating it if matches the common cases, such as when it's
 // not found, and when we've made a bad request
 func ExtractErrorFromResponse(r *model.Response, err error) error {
-	switch r.StatusCode {
-	case http.StatusNotFound:
-		return &NotFoundError{Msg: err.Error()}
-	case http.StatusBadRequest:
-		return &BadRequestError{Msg: err.Error()}
+	if r == nil {
+		if isNetworkError(err) {
+			return &NetworkError{Msg: err.Error(), err: err}
+		}
+		return err
+	}
+
+	switch {
+	case r.StatusCode == http.StatusNotFound:
+		return &NotFoundError{Msg: err.Error(), err: err}
+	case r.StatusCode == http.StatusBadRequest:
+		return &BadRequestError{Msg: err.Error(), err: err}
+	case r.StatusCode == http.StatusUnauthorized:
+		return &UnauthorizedError{Msg: err.Error(), err: err}
+	case r.StatusCode == http.StatusForbidden:
+		return &ForbiddenError{Msg: err.Error(), err: err}
+	case r.StatusCode == http.StatusConflict:
+		return &ConflictError{Msg: err.Error(), err: err}
+	case r.StatusCode >= http.StatusInternalServerError:
+		return &ServerError{Msg: err.Error(), err: err}
 	default:
 		return err
 	}
 }
+
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return errors.As(err, &urlErr) || errors.As(err, &opErr) || errors.As(err, &dnsErr)
+}
+
+func exitCodeForStatus(statusCode int) int {
+	switch {
+	case statusCode == http.StatusNotFound:
+		return ExitCodeNotFound
+	case statusCode == http.StatusBadRequest:
+		return ExitCodeBadRequest
+	case statusCode == http.StatusUnauthorized:
+		return ExitCodeUnauthorized
+	case statusCode == http.StatusForbidden:
+		return ExitCodeForbidden
+	case statusCode == http.StatusConflict:
+		return ExitCodeConflict
+	case statusCode >= http.StatusInternalServerError:
+		return ExitCodeServerError
+	default:
+		return ExitCodeError
+	}
+}
+
+// ExitCodeForError returns the exit code that mmctl should use
+// when finishing with the given error
+func ExitCodeForError(err error) int {
+	if err == nil {
+		return ExitCodeSuccess
+	}
+
+	var (
+		partialErr         *PartialFailureError
+		multiErr           *multierror.Error
+		entityNotFoundErr  ErrEntityNotFound
+		notFoundErr        *NotFoundError
+		badRequestErr      *BadRequestError
+		unauthorizedErr    *UnauthorizedError
+		forbiddenErr       *ForbiddenError
+		conflictErr        *ConflictError
+		serverErr          *ServerError
+		networkErr         *NetworkError
+		versionMismatchErr *VersionMismatchError
+		appErr             *model.AppError
+	)
+
+	switch {
+	case errors.As(err, &partialErr):
+		return ExitCodePartialFailure
+	case errors.As(err, &multiErr):
+		return exitCodeForErrors(multiErr.Errors)
+	case errors.As(err, &versionMismatchErr):
+		return ExitCodeVersionMismatch
+	case errors.As(err, &notFoundErr), errors.As(err, &entityNotFoundErr):
+		return ExitCodeNotFound
+	case errors.As(err, &badRequestErr):
+		return ExitCodeBadRequest
+	case errors.As(err, &unauthorizedErr):
+		return ExitCodeUnauthorized
+	case errors.As(err, &forbiddenErr):
+		return ExitCodeForbidden
+	case errors.As(err, &conflictErr):
+		return ExitCodeConflict
+	case errors.As(err, &serverErr):
+		return ExitCodeServerError
+	case errors.As(err, &networkErr), isNetworkError(err):
+		return ExitCodeNetworkError
+	case errors.As(err, &appErr):
+		// errors coming straight from the client without going
+		// through ExtractErrorFromResponse
+		return exitCodeForStatus(appErr.StatusCode)
+	default:
+		return ExitCodeError
+	}
+}
+
+// exitCodeForErrors returns the exit code shared by all the errors,
+// or the generic one if they have different codes
+func exitCodeForErrors(errs []error) int {
+	if len(errs) == 0 {
+		return ExitCodeError
+	}
+	code := ExitCodeForError(errs[0])
+	for _, err := range errs[1:] {
+		if ExitCodeForError(err) != code {
+			return ExitCodeError
+		}
+	}
+	return code
+}
+
+// ErrorEnvelope is the JSON representation of an error, printed to
+// the standard error when the json format is used
+type ErrorEnvelope struct {
+	Code      int              `json:"code"`
+	Message   string           `json:"message"`
+	Status    int              `json:"status,omitempty"`
+	ErrorID   string           `json:"error_id,omitempty"`
+	RequestID string           `json:"request_id,omitempty"`
+	Argument  string           `json:"argument,omitempty"`
+	Line      int              `json:"line,omitempty"`
+	Errors    []*ErrorEnvelope `json:"errors,omitempty"`
+}
+
+// NewErrorEnvelope builds the envelope for an error, gathering the
+// details of the server response if they are available
+func NewErrorEnvelope(err error) *ErrorEnvelope {
+	envelope := &ErrorEnvelope{
+		Code:    ExitCodeForError(err),
+		Message: err.Error(),
+	}
+
+	var appErr *model.AppError
+	if errors.As(err, &appErr) {
+		envelope.Status = appErr.StatusCode
+		envelope.ErrorID = appErr.Id
+		envelope.RequestID = appErr.RequestId
+	}
+
+	var entityNotFoundErr ErrEntityNotFound
+	if errors.As(err, &entityNotFoundErr) {
+		envelope.Argument = entityNotFoundErr.ID
+	}
+
+	var lineErr *ArgLineError
+	if errors.As(err, &lineErr) {
+		envelope.Line = lineErr.Line
+	}
+
+	var multiErr *multierror.Error
+	if errors.As(err, &multiErr) {
+		for _, e := range multiErr.Errors {
+			envelope.Errors = append(envelope.Errors, NewErrorEnvelope(e))
+		}
+	}
+
+	return envelope
+}
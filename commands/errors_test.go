@@ -0,0 +1,81 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExitCodeForError(t *testing.T) {
+	appErr := func(status int) error {
+		return model.NewAppError("where", "error.id", nil, "", status)
+	}
+
+	testCases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"no error", nil, ExitCodeSuccess},
+		{"generic error", errors.New("some error"), ExitCodeError},
+		{"bad request", ExtractErrorFromResponse(&model.Response{StatusCode: http.StatusBadRequest}, appErr(http.StatusBadRequest)), ExitCodeBadRequest},
+		{"unauthorized", ExtractErrorFromResponse(&model.Response{StatusCode: http.StatusUnauthorized}, appErr(http.StatusUnauthorized)), ExitCodeUnauthorized},
+		{"forbidden", ExtractErrorFromResponse(&model.Response{StatusCode: http.StatusForbidden}, appErr(http.StatusForbidden)), ExitCodeForbidden},
+		{"not found", ExtractErrorFromResponse(&model.Response{StatusCode: http.StatusNotFound}, appErr(http.StatusNotFound)), ExitCodeNotFound},
+		{"entity not found", ErrEntityNotFound{Type: "user", ID: "john"}, ExitCodeNotFound},
+		{"conflict", ExtractErrorFromResponse(&model.Response{StatusCode: http.StatusConflict}, appErr(http.StatusConflict)), ExitCodeConflict},
+		{"server error", ExtractErrorFromResponse(&model.Response{StatusCode: http.StatusBadGateway}, appErr(http.StatusBadGateway)), ExitCodeServerError},
+		{"network error", ExtractErrorFromResponse(nil, &url.Error{Op: "Get", URL: "http://localhost", Err: errors.New("connection refused")}), ExitCodeNetworkError},
+		{"wrapped app error", fmt.Errorf("could not do it: %w", appErr(http.StatusForbidden)), ExitCodeForbidden},
+		{"version mismatch", &VersionMismatchError{Msg: "mismatch"}, ExitCodeVersionMismatch},
+		{"partial failure", &PartialFailureError{Err: multierror.Append(nil, ErrEntityNotFound{Type: "user", ID: "john"})}, ExitCodePartialFailure},
+		{"all failed with the same code", multierror.Append(nil, ErrEntityNotFound{Type: "user", ID: "john"}, ExtractErrorFromResponse(&model.Response{StatusCode: http.StatusNotFound}, appErr(http.StatusNotFound))), ExitCodeNotFound},
+		{"all failed with different codes", multierror.Append(nil, ErrEntityNotFound{Type: "user", ID: "john"}, appErr(http.StatusForbidden)), ExitCodeError},
+		{"partial failure of a batch line", multierror.Append(nil, fmt.Errorf("line 1: %w", &PartialFailureError{Err: multierror.Append(nil, errors.New("some error"))})), ExitCodePartialFailure},
+		{"partial failure helper", partialFailure(multierror.Append(nil, appErr(http.StatusForbidden)), 0), ExitCodeForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ExitCodeForError(tc.err))
+		})
+	}
+}
+
+func TestNewErrorEnvelope(t *testing.T) {
+	t.Run("should include the server error details", func(t *testing.T) {
+		appErr := model.NewAppError("GetUser", "app.user.missing_account.const", nil, "", http.StatusNotFound)
+		appErr.RequestId = "request-id"
+
+		envelope := NewErrorEnvelope(ExtractErrorFromResponse(&model.Response{StatusCode: http.StatusNotFound}, appErr))
+		require.Equal(t, &ErrorEnvelope{
+			Code:      ExitCodeNotFound,
+			Message:   appErr.Error(),
+			Status:    http.StatusNotFound,
+			ErrorID:   "app.user.missing_account.const",
+			RequestID: "request-id",
+		}, envelope)
+	})
+
+	t.Run("should include the failed arguments of bulk commands", func(t *testing.T) {
+		var result *multierror.Error
+		result = multierror.Append(result, ErrEntityNotFound{Type: "user", ID: "john"})
+		result = multierror.Append(result, ErrEntityNotFound{Type: "user", ID: "jane"})
+
+		envelope := NewErrorEnvelope(partialFailure(result, 1))
+		require.Equal(t, ExitCodePartialFailure, envelope.Code)
+		require.Len(t, envelope.Errors, 2)
+		require.Equal(t, "john", envelope.Errors[0].Argument)
+		require.Equal(t, ExitCodeNotFound, envelope.Errors[0].Code)
+		require.Equal(t, "jane", envelope.Errors[1].Argument)
+	})
+}
@@ -35,17 +35,19 @@ var ExportDownloadCmd = &cobra.Command{
   
   # or if you only indicate the name, the path would match it
   $ mmctl export download sample_export.zip`,
-	Args: cobra.MinimumNArgs(1),
-	RunE: withClient(exportDownloadCmdF),
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: validArgs(completeExports, completeFiles, completeNone),
+	RunE:              withClient(exportDownloadCmdF),
 }
 
 var ExportDeleteCmd = &cobra.Command{
-	Use:     "delete [exportname]",
-	Aliases: []string{"rm"},
-	Example: "  export delete export_file.zip",
-	Short:   "Delete export file",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(exportDeleteCmdF),
+	Use:               "delete [exportname]",
+	Aliases:           []string{"rm"},
+	Example:           "  export delete export_file.zip",
+	Short:             "Delete export file",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeExports),
+	RunE:              withClient(exportDeleteCmdF),
 }
 
 var ExportListCmd = &cobra.Command{
@@ -71,19 +73,21 @@ var ExportJobListCmd = &cobra.Command{
 }
 
 var ExportJobShowCmd = &cobra.Command{
-	Use:     "show [exportJobID]",
-	Example: "  export job show o98rj3ur83dp5dppfyk5yk6osy",
-	Short:   "Show export job",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(exportJobShowCmdF),
+	Use:               "show [exportJobID]",
+	Example:           "  export job show o98rj3ur83dp5dppfyk5yk6osy",
+	Short:             "Show export job",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeJobs(model.JobTypeExportProcess), completeNone),
+	RunE:              withClient(exportJobShowCmdF),
 }
 
 var ExportJobCancelCmd = &cobra.Command{
-	Use:     "cancel [exportJobID]",
-	Example: "  export job cancel o98rj3ur83dp5dppfyk5yk6osy",
-	Short:   "Cancel export job",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(exportJobCancelCmdF),
+	Use:               "cancel [exportJobID]",
+	Example:           "  export job cancel o98rj3ur83dp5dppfyk5yk6osy",
+	Short:             "Cancel export job",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeJobs(model.JobTypeExportProcess), completeNone),
+	RunE:              withClient(exportJobCancelCmdF),
 }
 
 func init() {
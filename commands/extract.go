@@ -43,11 +43,12 @@ var ExtractJobListCmd = &cobra.Command{
 }
 
 var ExtractJobShowCmd = &cobra.Command{
-	Use:     "show [extractJobID]",
-	Example: " extract job show f3d68qkkm7n8xgsfxwuo498rah",
-	Short:   "Show extract job",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(extractJobShowCmdF),
+	Use:               "show [extractJobID]",
+	Example:           " extract job show f3d68qkkm7n8xgsfxwuo498rah",
+	Short:             "Show extract job",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeJobs(model.JobTypeExtractContent), completeNone),
+	RunE:              withClient(extractJobShowCmdF),
 }
 
 func init() {
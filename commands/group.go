@@ -34,37 +34,41 @@ var ChannelGroupCmd = &cobra.Command{
 }
 
 var ChannelGroupEnableCmd = &cobra.Command{
-	Use:     "enable [team]:[channel]",
-	Short:   "Enables group constrains in the specified channel",
-	Example: "  group channel enable myteam:mychannel",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(channelGroupEnableCmdF),
+	Use:               "enable [team]:[channel]",
+	Short:             "Enables group constrains in the specified channel",
+	Example:           "  group channel enable myteam:mychannel",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeChannels, completeNone),
+	RunE:              withClient(channelGroupEnableCmdF),
 }
 
 var ChannelGroupDisableCmd = &cobra.Command{
-	Use:     "disable [team]:[channel]",
-	Short:   "Disables group constrains in the specified channel",
-	Example: "  group channel disable myteam:mychannel",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(channelGroupDisableCmdF),
+	Use:               "disable [team]:[channel]",
+	Short:             "Disables group constrains in the specified channel",
+	Example:           "  group channel disable myteam:mychannel",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeChannels, completeNone),
+	RunE:              withClient(channelGroupDisableCmdF),
 }
 
 // ChannelGroupStatusCmd is a command which outputs group constrain status for a channel
 var ChannelGroupStatusCmd = &cobra.Command{
-	Use:     "status [team]:[channel]",
-	Short:   "Show's the group constrain status for the specified channel",
-	Example: "  group channel status myteam:mychannel",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(channelGroupStatusCmdF),
+	Use:               "status [team]:[channel]",
+	Short:             "Show's the group constrain status for the specified channel",
+	Example:           "  group channel status myteam:mychannel",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeChannels, completeNone),
+	RunE:              withClient(channelGroupStatusCmdF),
 }
 
 var ChannelGroupListCmd = &cobra.Command{
-	Use:     "list [team]:[channel]",
-	Short:   "List channel groups",
-	Long:    "List the groups associated with a channel",
-	Example: "  group channel list myteam:mychannel",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(channelGroupListCmdF),
+	Use:               "list [team]:[channel]",
+	Short:             "List channel groups",
+	Long:              "List the groups associated with a channel",
+	Example:           "  group channel list myteam:mychannel",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeChannels, completeNone),
+	RunE:              withClient(channelGroupListCmdF),
 }
 
 var TeamGroupCmd = &cobra.Command{
@@ -73,36 +77,40 @@ var TeamGroupCmd = &cobra.Command{
 }
 
 var TeamGroupEnableCmd = &cobra.Command{
-	Use:     "enable [team]",
-	Short:   "Enables group constrains in the specified team",
-	Example: "  group team enable myteam",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(teamGroupEnableCmdF),
+	Use:               "enable [team]",
+	Short:             "Enables group constrains in the specified team",
+	Example:           "  group team enable myteam",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeTeams, completeNone),
+	RunE:              withClient(teamGroupEnableCmdF),
 }
 
 var TeamGroupDisableCmd = &cobra.Command{
-	Use:     "disable [team]",
-	Short:   "Disables group constrains in the specified team",
-	Example: "  group team disable myteam",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(teamGroupDisableCmdF),
+	Use:               "disable [team]",
+	Short:             "Disables group constrains in the specified team",
+	Example:           "  group team disable myteam",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeTeams, completeNone),
+	RunE:              withClient(teamGroupDisableCmdF),
 }
 
 var TeamGroupStatusCmd = &cobra.Command{
-	Use:     "status [team]",
-	Short:   "Show's the group constrain status for the specified team",
-	Example: "  group team status myteam",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(teamGroupStatusCmdF),
+	Use:               "status [team]",
+	Short:             "Show's the group constrain status for the specified team",
+	Example:           "  group team status myteam",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeTeams, completeNone),
+	RunE:              withClient(teamGroupStatusCmdF),
 }
 
 var TeamGroupListCmd = &cobra.Command{
-	Use:     "list [team]",
-	Short:   "List team groups",
-	Long:    "List the groups associated with a team",
-	Example: "  group team list myteam",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(teamGroupListCmdF),
+	Use:               "list [team]",
+	Short:             "List team groups",
+	Long:              "List the groups associated with a team",
+	Example:           "  group team list myteam",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeTeams, completeNone),
+	RunE:              withClient(teamGroupListCmdF),
 }
 
 var UserGroupCmd = &cobra.Command{
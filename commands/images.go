@@ -0,0 +1,119 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"  // registers the GIF decoder
+	_ "image/jpeg" // registers the JPEG decoder
+	_ "image/png"  // registers the PNG decoder
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v6/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// maxImageFileSize and maxImageResolution are the default values
+	// of the MaxFileSize and MaxImageResolution settings of the server
+	maxImageFileSize   = 100 * 1024 * 1024
+	maxImageResolution = 7680 * 4320
+)
+
+// imageExtensions are the extensions of the files taken from the
+// image directories
+var imageExtensions = []string{".png", ".jpg", ".jpeg", ".gif"}
+
+// readImageFile reads an image to upload, checking that it is a PNG,
+// JPEG or GIF image that the server accepts
+func readImageFile(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the image: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory, not an image", path)
+	}
+	if info.Size() > maxImageFileSize {
+		return nil, fmt.Errorf("the image %s is too large, it has %d bytes and the maximum is %d", path, info.Size(), maxImageFileSize)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the image: %w", err)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a PNG, JPEG or GIF image", path)
+	}
+	if config.Width*config.Height > maxImageResolution {
+		return nil, fmt.Errorf("the resolution of the image %s is too high, it has %dx%d pixels and the maximum is %d pixels", path, config.Width, config.Height, maxImageResolution)
+	}
+	return data, nil
+}
+
+// imageDirTargets returns the images of a directory as bulk targets,
+// and the names they are mapped to, which are their file names without
+// the extension
+func imageDirTargets(dir string) (targets []fileArg, names map[string]string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read the images directory: %w", err)
+	}
+
+	names = map[string]string{}
+	files := map[string]string{}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || !utils.StringInSlice(ext, imageExtensions) {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if other, ok := files[name]; ok {
+			return nil, nil, fmt.Errorf("the images %s and %s are both mapped to %q", other, entry.Name(), name)
+		}
+		files[name] = entry.Name()
+
+		path := filepath.Join(dir, entry.Name())
+		names[path] = name
+		targets = append(targets, fileArg{Value: path})
+	}
+	if len(targets) == 0 {
+		return nil, nil, fmt.Errorf("there are no images in %s, their extension must be one of: %s", dir, strings.Join(imageExtensions, ", "))
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Value < targets[j].Value })
+	return targets, names, nil
+}
+
+// imageSetArgs accepts a target and an image, or no arguments when
+// the images are read from the directory of the --dir flag
+func imageSetArgs(cmd *cobra.Command, args []string) error {
+	if dir, _ := cmd.Flags().GetString("dir"); dir != "" {
+		return cobra.NoArgs(cmd, args)
+	}
+	return cobra.ExactArgs(2)(cmd, args)
+}
+
+// imageSetTargets returns the images to upload and the targets they
+// are mapped to, either from the arguments or from the --dir flag
+func imageSetTargets(cmd *cobra.Command, args []string) ([]fileArg, map[string]string, error) {
+	if dir, _ := cmd.Flags().GetString("dir"); dir != "" {
+		return imageDirTargets(dir)
+	}
+	return []fileArg{{Value: args[1]}}, map[string]string{args[1]: args[0]}, nil
+}
+
+// addImageSetFlags adds the flags of the commands that upload images
+// in bulk from a directory
+func addImageSetFlags(cmd *cobra.Command, target string) {
+	cmd.Flags().String("dir", "", "uploads every PNG, JPEG and GIF image of a directory to the "+target+" named like the file without the extension")
+	cmd.Flags().Int("parallel", 1, "number of images uploaded concurrently")
+	cmd.Flags().String("failures-file", "", "writes the images that failed to a file, one per line")
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// writeTestPNG writes a PNG image of the given size and returns its
+// contents
+func (s *MmctlUnitTestSuite) writeTestPNG(path string, width, height int) []byte {
+	var b bytes.Buffer
+	s.Require().NoError(png.Encode(&b, image.NewRGBA(image.Rect(0, 0, width, height))))
+	s.Require().NoError(ioutil.WriteFile(path, b.Bytes(), 0600))
+	return b.Bytes()
+}
+
+func (s *MmctlUnitTestSuite) TestReadImageFile() {
+	dir, err := ioutil.TempDir("", "mmctl-images-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	s.Run("should read a valid image", func() {
+		path := filepath.Join(dir, "valid.png")
+		expected := s.writeTestPNG(path, 16, 16)
+
+		data, err := readImageFile(path)
+		s.Require().NoError(err)
+		s.Require().Equal(expected, data)
+	})
+
+	s.Run("should reject files that aren't images", func() {
+		path := filepath.Join(dir, "text.png")
+		s.Require().NoError(ioutil.WriteFile(path, []byte("not an image"), 0600))
+
+		_, err := readImageFile(path)
+		s.Require().EqualError(err, path+" is not a PNG, JPEG or GIF image")
+	})
+
+	s.Run("should reject images with a too high resolution", func() {
+		path := filepath.Join(dir, "large.png")
+		s.writeTestPNG(path, 8000, 4320)
+
+		_, err := readImageFile(path)
+		s.Require().EqualError(err, "the resolution of the image "+path+" is too high, it has 8000x4320 pixels and the maximum is 33177600 pixels")
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestImageDirTargets() {
+	dir, err := ioutil.TempDir("", "mmctl-images-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	for _, name := range []string{"bob.JPG", "alice.png", "notes.txt", ".hidden.png"} {
+		s.Require().NoError(ioutil.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+	s.Require().NoError(os.Mkdir(filepath.Join(dir, "carol.png"), 0700))
+
+	targets, names, err := imageDirTargets(dir)
+	s.Require().NoError(err)
+	s.Require().Equal([]fileArg{{Value: filepath.Join(dir, "alice.png")}, {Value: filepath.Join(dir, "bob.JPG")}}, targets)
+	s.Require().Equal(map[string]string{filepath.Join(dir, "alice.png"): "alice", filepath.Join(dir, "bob.JPG"): "bob"}, names)
+
+	s.Require().NoError(ioutil.WriteFile(filepath.Join(dir, "alice.gif"), nil, 0600))
+	_, _, err = imageDirTargets(dir)
+	s.Require().EqualError(err, `the images alice.gif and alice.png are both mapped to "alice"`)
+}
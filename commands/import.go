@@ -71,19 +71,21 @@ var ImportJobListCmd = &cobra.Command{
 }
 
 var ImportJobShowCmd = &cobra.Command{
-	Use:     "show [importJobID]",
-	Example: " import job show f3d68qkkm7n8xgsfxwuo498rah",
-	Short:   "Show import job",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(importJobShowCmdF),
+	Use:               "show [importJobID]",
+	Example:           " import job show f3d68qkkm7n8xgsfxwuo498rah",
+	Short:             "Show import job",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeJobs(model.JobTypeImportProcess), completeNone),
+	RunE:              withClient(importJobShowCmdF),
 }
 
 var ImportProcessCmd = &cobra.Command{
-	Use:     "process [importname]",
-	Example: "  import process 35uy6cwrqfnhdx3genrhqqznxc_import.zip",
-	Short:   "Start an import job",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(importProcessCmdF),
+	Use:               "process [importname]",
+	Example:           "  import process 35uy6cwrqfnhdx3genrhqqznxc_import.zip",
+	Short:             "Start an import job",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeImports, completeNone),
+	RunE:              withClient(importProcessCmdF),
 }
 
 var ImportValidateCmd = &cobra.Command{
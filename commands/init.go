@@ -63,55 +63,68 @@ func CheckVersionMatch(version, serverVersion string) (bool, error) {
 
 func withClient(fn func(c client.Client, cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		if viper.GetBool("local") {
-			c, err := InitUnixClient(viper.GetString("local-socket-path"))
-			if err != nil {
-				return err
-			}
-			printer.SetServerAddres("local instance")
-			return fn(c, cmd, args)
+		c, err := getClient()
+		if err != nil {
+			return err
 		}
+		return fn(c, cmd, args)
+	}
+}
+
+// getClient returns the client of the current server, or of the local
+// instance if the --local flag is set
+func getClient() (client.Client, error) {
+	if sharedClient != nil {
+		return sharedClient, nil
+	}
 
-		c, serverVersion, err := InitClient(viper.GetBool("insecure-sha1-intermediate"), viper.GetBool("insecure-tls-version"))
+	if viper.GetBool("local") {
+		c, err := InitUnixClient(viper.GetString("local-socket-path"))
 		if err != nil {
-			return err
+			return nil, err
 		}
+		printer.SetServerAddres("local instance")
+		return c, nil
+	}
+
+	c, serverVersion, err := InitClient(viper.GetBool("insecure-sha1-intermediate"), viper.GetBool("insecure-tls-version"))
+	if err != nil {
+		return nil, err
+	}
 
-		if Version != "unspecified" { // unspecified version indicates that we are on dev mode.
-			valid, err := CheckVersionMatch(Version, serverVersion)
-			if err != nil {
-				return fmt.Errorf("could not check version mismatch: %w", err)
+	if Version != "unspecified" { // unspecified version indicates that we are on dev mode.
+		valid, err := CheckVersionMatch(Version, serverVersion)
+		if err != nil {
+			return nil, fmt.Errorf("could not check version mismatch: %w", err)
+		}
+		if !valid {
+			if viper.GetBool("strict") {
+				return nil, &VersionMismatchError{Msg: "server version " + serverVersion + " doesn't match with mmctl version " + Version + ". Strict flag is set, so the command will not be run"}
 			}
-			if !valid {
-				if viper.GetBool("strict") {
-					printer.PrintError("ERROR: server version " + serverVersion + " doesn't match with mmctl version " + Version + ". Strict flag is set, so the command will not be run")
-					os.Exit(1)
-				}
-				if !viper.GetBool("suppress-warnings") {
-					printer.PrintWarning("server version " + serverVersion + " doesn't match mmctl version " + Version)
-				}
+			if !viper.GetBool("suppress-warnings") {
+				printer.PrintWarning("server version " + serverVersion + " doesn't match mmctl version " + Version)
 			}
 		}
-
-		printer.SetServerAddres(c.APIURL)
-		return fn(c, cmd, args)
 	}
+
+	printer.SetServerAddres(c.APIURL)
+	return c, nil
 }
 
-func localOnlyPrecheck(cmd *cobra.Command, args []string) {
+func localOnlyPrecheck(cmd *cobra.Command, args []string) error {
 	local := viper.GetBool("local")
 	if !local {
-		fmt.Fprintln(os.Stderr, "This command can only be run in local mode")
-		os.Exit(1)
+		return errors.New("this command can only be run in local mode")
 	}
+	return nil
 }
 
-func disableLocalPrecheck(cmd *cobra.Command, args []string) {
+func disableLocalPrecheck(cmd *cobra.Command, args []string) error {
 	local := viper.GetBool("local")
 	if local {
-		fmt.Fprintln(os.Stderr, "This command cannot be run in local mode")
-		os.Exit(1)
+		return errors.New("this command cannot be run in local mode")
 	}
+	return nil
 }
 
 func isValidChain(chain []*x509.Certificate) bool {
@@ -154,15 +167,36 @@ func NewAPIv4Client(instanceURL string, allowInsecureSHA1, allowInsecureTLS bool
 	}
 
 	client.HTTPClient = &http.Client{
-		Transport: &http.Transport{
+		Transport: newClientTransport(&http.Transport{
 			TLSClientConfig: tlsConfig,
 			Proxy:           http.ProxyFromEnvironment,
-		},
+		}),
 	}
 
 	return client
 }
 
+// newClientTransport wraps the transport of the clients so they retry
+// the requests that fail because of rate limits or temporary errors,
+// tracing every attempt if tracing is enabled. When recording, the
+// final response of each request is stored, and when replaying, the
+// responses come from the cassette instead of the base transport
+func newClientTransport(base http.RoundTripper) http.RoundTripper {
+	if replayer != nil {
+		base = replayer
+	}
+	if tracer != nil {
+		base = tracer.Transport(base)
+	}
+	if replayer == nil {
+		base = client.NewRetryTransport(base, viper.GetInt("max-retries"), viper.GetDuration("retry-timeout"))
+	}
+	if recorder != nil {
+		base = recorder.Transport(base)
+	}
+	return base
+}
+
 func InitClientWithUsernameAndPassword(username, password, instanceURL string, allowInsecureSHA1, allowInsecureTLS bool) (*model.Client4, string, error) {
 	client := NewAPIv4Client(instanceURL, allowInsecureSHA1, allowInsecureTLS)
 
@@ -217,17 +251,22 @@ func InitWebSocketClient() (*model.WebSocketClient, error) {
 }
 
 func InitUnixClient(socketPath string) (*model.Client4, error) {
-	if err := checkValidSocket(socketPath); err != nil {
-		return nil, err
+	// there is no need for the socket if the responses are replayed
+	if replayer == nil {
+		if err := checkValidSocket(socketPath); err != nil {
+			return nil, err
+		}
 	}
 
-	return model.NewAPIv4SocketClient(socketPath), nil
+	c := model.NewAPIv4SocketClient(socketPath)
+	c.HTTPClient.Transport = newClientTransport(c.HTTPClient.Transport)
+	return c, nil
 }
 
 func checkInsecureTLSError(err error, allowInsecureTLS bool) error {
 	if (strings.Contains(err.Error(), "tls: protocol version not supported") ||
 		strings.Contains(err.Error(), "tls: server selected unsupported protocol version")) && !allowInsecureTLS {
-		return errors.New("won't perform action through an insecure TLS connection. Please add --insecure-tls-version to bypass this check")
+		return &NetworkError{Msg: "won't perform action through an insecure TLS connection. Please add --insecure-tls-version to bypass this check", err: err}
 	}
 	return err
 }
@@ -16,12 +16,12 @@ import (
 )
 
 var IntegrityCmd = &cobra.Command{
-	Use:    "integrity",
-	Short:  "Check database records integrity.",
-	Long:   "Perform a relational integrity check which returns information about any orphaned record found.",
-	Args:   cobra.NoArgs,
-	PreRun: localOnlyPrecheck,
-	RunE:   withClient(integrityCmdF),
+	Use:     "integrity",
+	Short:   "Check database records integrity.",
+	Long:    "Perform a relational integrity check which returns information about any orphaned record found.",
+	Args:    cobra.NoArgs,
+	PreRunE: localOnlyPrecheck,
+	RunE:    withClient(integrityCmdF),
 }
 
 func init() {
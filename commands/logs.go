@@ -34,8 +34,8 @@ func init() {
 func logsCmdF(c client.Client, cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed("format") || cmd.Flags().Changed("json") {
 		return fmt.Errorf("the %q and %q flags cannot be used with this command", "--format", "--json")
-	} else if viper.GetString("format") == printer.FormatJSON {
-		return fmt.Errorf("json formatting cannot be applied on this command. Please check the value of %q", "MMCTL_FORMAT")
+	} else if format := viper.GetString("format"); format != printer.FormatPlain && format != "" {
+		return fmt.Errorf("%s formatting cannot be applied on this command. Please check the value of %q", format, "MMCTL_FORMAT")
 	}
 
 	number, _ := cmd.Flags().GetInt("number")
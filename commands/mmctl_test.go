@@ -9,6 +9,7 @@ import (
 	"github.com/mattermost/mmctl/v6/printer"
 
 	"github.com/golang/mock/gomock"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/mattermost/mattermost-server/v6/api4"
@@ -26,6 +27,8 @@ func (s *MmctlUnitTestSuite) SetupTest() {
 	printer.Clean()
 	printer.SetFormat(printer.FormatJSON)
 
+	viper.Set("config-snapshots-dir", s.T().TempDir())
+
 	s.mockCtrl = gomock.NewController(s.T())
 	s.client = mocks.NewMockClient(s.mockCtrl)
 }
@@ -42,6 +45,7 @@ type MmctlE2ETestSuite struct {
 func (s *MmctlE2ETestSuite) SetupTest() {
 	printer.Clean()
 	printer.SetFormat(printer.FormatJSON)
+	viper.Set("config-snapshots-dir", s.T().TempDir())
 }
 
 func (s *MmctlE2ETestSuite) TearDownTest() {
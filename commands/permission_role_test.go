@@ -133,7 +133,7 @@ func (s *MmctlUnitTestSuite) TestAssignUsersCmd() {
 		args := []string{mockRole.Name, mockUser1.Username, notFoundUser.Username, mockUser2.Username}
 		err := assignUsersCmdF(s.client, &cobra.Command{}, args)
 		s.Require().NotNil(err)
-		s.Require().Equal(expectedError.ErrorOrNil(), err)
+		s.Require().Equal(&PartialFailureError{Err: expectedError}, err)
 	})
 
 	s.Run("Assigning to a non-existent role", func() {
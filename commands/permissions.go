@@ -25,8 +25,9 @@ var AddPermissionsCmd = &cobra.Command{
 	Long:  `Add one or more permissions to an existing role (Only works in Enterprise Edition).`,
 	Example: `  permissions add system_user list_open_teams
   permissions add system_manager sysconsole_read_user_management_channels`,
-	Args: cobra.MinimumNArgs(2),
-	RunE: withClient(addPermissionsCmdF),
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: validArgs(completeRoles, completeNone),
+	RunE:              withClient(addPermissionsCmdF),
 }
 
 var RemovePermissionsCmd = &cobra.Command{
@@ -35,18 +36,20 @@ var RemovePermissionsCmd = &cobra.Command{
 	Long:  `Remove one or more permissions from an existing role (Only works in Enterprise Edition).`,
 	Example: `  permissions remove system_user list_open_teams
   permissions remove system_manager sysconsole_read_user_management_channels`,
-	Args: cobra.MinimumNArgs(2),
-	RunE: withClient(removePermissionsCmdF),
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: validArgs(completeRoles, completeNone),
+	RunE:              withClient(removePermissionsCmdF),
 }
 
 var ShowRoleCmd = &cobra.Command{
-	Use:        "show <role_name>",
-	Deprecated: "please use \"role show\" instead",
-	Short:      "Show the role information",
-	Long:       "Show all the information about a role.",
-	Example:    `  permissions show system_user`,
-	Args:       cobra.ExactArgs(1),
-	RunE:       withClient(showRoleCmdF),
+	Use:               "show <role_name>",
+	Deprecated:        "please use \"role show\" instead",
+	Short:             "Show the role information",
+	Long:              "Show all the information about a role.",
+	Example:           `  permissions show system_user`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeRoles, completeNone),
+	RunE:              withClient(showRoleCmdF),
 }
 
 var ResetCmd = &cobra.Command{
@@ -55,8 +58,9 @@ var ResetCmd = &cobra.Command{
 	Long:  "Reset the given role's permissions to the set that was originally released with",
 	Example: `  # Reset the permissions of the 'system_read_only_admin' role.
   $ mmctl permissions reset system_read_only_admin`,
-	Args: cobra.ExactArgs(1),
-	RunE: withClient(resetPermissionsCmdF),
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeRoles, completeNone),
+	RunE:              withClient(resetPermissionsCmdF),
 }
 
 func init() {
@@ -14,7 +14,6 @@ import (
 	"github.com/mattermost/mmctl/v6/client"
 	"github.com/mattermost/mmctl/v6/printer"
 
-	"github.com/hashicorp/go-multierror"
 	"github.com/spf13/cobra"
 )
 
@@ -24,18 +23,20 @@ var RoleCmd = &cobra.Command{
 }
 
 var ShowCmd = &cobra.Command{
-	Use:     "show <role_name>",
-	Short:   "Show the role information",
-	Long:    "Show all the information about a role.",
-	Example: `  permissions show system_user`,
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(showRoleCmdF),
+	Use:               "show <role_name>",
+	Short:             "Show the role information",
+	Long:              "Show all the information about a role.",
+	Example:           `  permissions show system_user`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeRoles, completeNone),
+	RunE:              withClient(showRoleCmdF),
 }
 
 var AssignCmd = &cobra.Command{
 	Use:   "assign <role_name> <username...>",
 	Short: "Assign users to role (EE Only)",
-	Long:  "Assign users to a role by username (Only works in Enterprise Edition).",
+	Long: `Assign users to a role by username (Only works in Enterprise Edition).
+The users that can't be found or assigned don't stop the rest from being assigned. The command fails with the partial failure exit code if some of them were assigned.`,
 	Example: `  # Assign users with usernames 'john.doe' and 'jane.doe' to the role named 'system_admin'.
   permissions assign system_admin john.doe jane.doe
   
@@ -43,8 +44,9 @@ var AssignCmd = &cobra.Command{
   permissions assign system_manager john.doe jane.doe
   permissions assign system_user_manager john.doe jane.doe
   permissions assign system_read_only_admin john.doe jane.doe`,
-	Args: cobra.MinimumNArgs(2),
-	RunE: withClient(assignUsersCmdF),
+	Args:              minimumArgsOrFile(1),
+	ValidArgsFunction: validArgs(completeRoles, completeUsers),
+	RunE:              withClient(assignUsersCmdF),
 }
 
 var UnassignCmd = &cobra.Command{
@@ -58,11 +60,14 @@ var UnassignCmd = &cobra.Command{
   permissions unassign system_manager john.doe jane.doe
   permissions unassign system_user_manager john.doe jane.doe
   permissions unassign system_read_only_admin john.doe jane.doe`,
-	Args: cobra.MinimumNArgs(2),
-	RunE: withClient(unassignUsersCmdF),
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: validArgs(completeRoles, completeUsers),
+	RunE:              withClient(unassignUsersCmdF),
 }
 
 func init() {
+	addBulkFlags(AssignCmd)
+
 	RoleCmd.AddCommand(
 		AssignCmd,
 		UnassignCmd,
@@ -162,37 +167,35 @@ func assignUsersCmdF(c client.Client, cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	users := getUsersFromUserArgs(c, args[1:])
+	targets, err := fileArgs(cmd, args[1:])
+	if err != nil {
+		return err
+	}
 
-	var errs *multierror.Error
-	for i, user := range users {
+	report, err := runBulk(cmd, "users", targets, func(target string) (func(), error) {
+		user := getUserFromUserArg(c, target)
 		if user == nil {
-			printer.PrintError("Couldn't find user '" + args[i+1] + "'.")
-			errs = multierror.Append(errs, fmt.Errorf("couldn't find user '%s'", args[i+1]))
-			continue
+			return func() { printer.PrintError("Couldn't find user '" + target + "'.") }, fmt.Errorf("couldn't find user '%s'", target)
 		}
 
-		var userHasRequestedRole bool
 		startingRoles := strings.Fields(user.Roles)
 		for _, roleName := range startingRoles {
 			if roleName == role.Name {
-				userHasRequestedRole = true
+				return nil, errBulkSkipped
 			}
 		}
 
-		if userHasRequestedRole {
-			continue
-		}
-
 		userRoles := startingRoles
 		userRoles = append(userRoles, role.Name)
-		_, err = c.UpdateUserRoles(user.Id, strings.Join(userRoles, " "))
-		if err != nil {
-			return err
+		if _, err := c.UpdateUserRoles(user.Id, strings.Join(userRoles, " ")); err != nil {
+			return nil, err
 		}
+		return nil, nil
+	})
+	if err != nil {
+		return err
 	}
-
-	return errs.ErrorOrNil()
+	return report.errors()
 }
 
 func unassignUsersCmdF(c client.Client, cmd *cobra.Command, args []string) error {
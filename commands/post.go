@@ -24,11 +24,12 @@ var PostCmd = &cobra.Command{
 }
 
 var PostCreateCmd = &cobra.Command{
-	Use:     "create",
-	Short:   "Create a post",
-	Example: `  post create myteam:mychannel --message "some text for the post"`,
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(postCreateCmdF),
+	Use:               "create",
+	Short:             "Create a post",
+	Example:           `  post create myteam:mychannel --message "some text for the post"`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeChannels, completeNone),
+	RunE:              withClient(postCreateCmdF),
 }
 
 var PostListCmd = &cobra.Command{
@@ -36,8 +37,9 @@ var PostListCmd = &cobra.Command{
 	Short: "List posts for a channel",
 	Example: `  post list myteam:mychannel
   post list myteam:mychannel --number 20`,
-	Args: cobra.ExactArgs(1),
-	RunE: withClient(postListCmdF),
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeChannels, completeNone),
+	RunE:              withClient(postListCmdF),
 }
 
 const (
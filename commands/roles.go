@@ -31,8 +31,9 @@ var RolesSystemAdminCmd = &cobra.Command{
 
   # Or promote multiple users at the same time
   $ mmctl roles system-admin john_doe jane_doe`,
-	RunE: withClient(rolesSystemAdminCmdF),
-	Args: cobra.MinimumNArgs(1),
+	ValidArgsFunction: validArgs(completeUsers),
+	RunE:              withClient(rolesSystemAdminCmdF),
+	Args:              cobra.MinimumNArgs(1),
 }
 
 var RolesMemberCmd = &cobra.Command{
@@ -44,8 +45,9 @@ var RolesMemberCmd = &cobra.Command{
 
   # Or demote multiple users at the same time
   $ mmctl roles member john_doe jane_doe`,
-	RunE: withClient(rolesMemberCmdF),
-	Args: cobra.MinimumNArgs(1),
+	ValidArgsFunction: validArgs(completeUsers),
+	RunE:              withClient(rolesMemberCmdF),
+	Args:              cobra.MinimumNArgs(1),
 }
 
 func init() {
@@ -4,6 +4,8 @@
 package commands
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime/debug"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/mattermost/mattermost-server/v6/model"
 
+	"github.com/mattermost/mmctl/v6/client"
 	"github.com/mattermost/mmctl/v6/printer"
 )
 
@@ -21,6 +24,8 @@ func Run(args []string) error {
 	viper.SetEnvPrefix("mmctl")
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.SetDefault("local-socket-path", model.LocalModeSocketPath)
+	viper.SetDefault("completion-cache-ttl", completionCacheDefaultTTL)
+	viper.SetDefault("config-snapshots-limit", configSnapshotsDefaultLimit)
 	viper.AutomaticEnv()
 
 	RootCmd.PersistentFlags().String("config", filepath.Join(xdgConfigHomeVar, configParent, configFileName), "path to the configuration file")
@@ -28,19 +33,42 @@ func Run(args []string) error {
 	RootCmd.PersistentFlags().String("config-path", xdgConfigHomeVar, "path to the configuration directory.")
 	_ = viper.BindPFlag("config-path", RootCmd.PersistentFlags().Lookup("config-path"))
 	_ = RootCmd.PersistentFlags().MarkHidden("config-path")
+	RootCmd.PersistentFlags().String("credentials-store", CredentialsStoreFile, "the backend used to store the credentials [file, encrypted-file, keyring]")
+	_ = viper.BindPFlag("credentials-store", RootCmd.PersistentFlags().Lookup("credentials-store"))
 	RootCmd.PersistentFlags().Bool("suppress-warnings", false, "disables printing warning messages")
 	_ = viper.BindPFlag("suppress-warnings", RootCmd.PersistentFlags().Lookup("suppress-warnings"))
-	RootCmd.PersistentFlags().String("format", "plain", "the format of the command output [plain, json]")
+	RootCmd.PersistentFlags().String("format", printer.FormatPlain, "the format of the command output ["+strings.Join(printer.Formats(), ", ")+"]")
 	_ = viper.BindPFlag("format", RootCmd.PersistentFlags().Lookup("format"))
-	_ = RootCmd.PersistentFlags().MarkHidden("format")
+	RootCmd.PersistentFlags().StringSlice("columns", nil, "comma separated list of the columns to print with the table, csv and tsv formats")
+	_ = viper.BindPFlag("columns", RootCmd.PersistentFlags().Lookup("columns"))
+	RootCmd.PersistentFlags().String("template", "", "go template used to print each element of the output, implies --format template")
+	_ = viper.BindPFlag("template", RootCmd.PersistentFlags().Lookup("template"))
 	RootCmd.PersistentFlags().Bool("json", false, "the output format will be in json format")
 	_ = viper.BindPFlag("json", RootCmd.PersistentFlags().Lookup("json"))
+	RootCmd.PersistentFlags().String("query", "", "JMESPath expression evaluated over the structured output before printing it")
+	_ = viper.BindPFlag("query", RootCmd.PersistentFlags().Lookup("query"))
 	RootCmd.PersistentFlags().Bool("strict", false, "will only run commands if the mmctl version matches the server one")
 	_ = viper.BindPFlag("strict", RootCmd.PersistentFlags().Lookup("strict"))
 	RootCmd.PersistentFlags().Bool("insecure-sha1-intermediate", false, "allows to use insecure TLS protocols, such as SHA-1")
 	_ = viper.BindPFlag("insecure-sha1-intermediate", RootCmd.PersistentFlags().Lookup("insecure-sha1-intermediate"))
 	RootCmd.PersistentFlags().Bool("insecure-tls-version", false, "allows to use TLS versions 1.0 and 1.1")
 	_ = viper.BindPFlag("insecure-tls-version", RootCmd.PersistentFlags().Lookup("insecure-tls-version"))
+	RootCmd.PersistentFlags().Int("max-retries", client.DefaultMaxRetries, "number of times a request is retried when it is rate limited or fails because of a temporary error")
+	_ = viper.BindPFlag("max-retries", RootCmd.PersistentFlags().Lookup("max-retries"))
+	RootCmd.PersistentFlags().Duration("retry-timeout", client.DefaultRetryTimeout, "maximum time spent waiting to retry a request")
+	_ = viper.BindPFlag("retry-timeout", RootCmd.PersistentFlags().Lookup("retry-timeout"))
+	RootCmd.PersistentFlags().Bool("trace", false, "logs every HTTP request and response to the standard error, with a summary at the end")
+	_ = viper.BindPFlag("trace", RootCmd.PersistentFlags().Lookup("trace"))
+	RootCmd.PersistentFlags().Bool("trace-bodies", false, "includes the request and response bodies in the trace")
+	_ = viper.BindPFlag("trace-bodies", RootCmd.PersistentFlags().Lookup("trace-bodies"))
+	RootCmd.PersistentFlags().String("trace-file", "", "writes the trace to a file instead of the standard error")
+	_ = viper.BindPFlag("trace-file", RootCmd.PersistentFlags().Lookup("trace-file"))
+	RootCmd.PersistentFlags().Bool("debug", false, "dumps every HTTP request and response, including their bodies. Same as --trace --trace-bodies")
+	_ = viper.BindPFlag("debug", RootCmd.PersistentFlags().Lookup("debug"))
+	RootCmd.PersistentFlags().String("record", "", "records the HTTP interactions with the server in a cassette file")
+	_ = viper.BindPFlag("record", RootCmd.PersistentFlags().Lookup("record"))
+	RootCmd.PersistentFlags().String("replay", "", "answers the requests with the responses of a cassette file instead of contacting the server")
+	_ = viper.BindPFlag("replay", RootCmd.PersistentFlags().Lookup("replay"))
 	RootCmd.PersistentFlags().Bool("local", false, "allows communicating with the server through a unix socket")
 	_ = viper.BindPFlag("local", RootCmd.PersistentFlags().Lookup("local"))
 	RootCmd.PersistentFlags().Bool("short-stat", false, "short stat will provide useful statistical data")
@@ -64,32 +92,109 @@ func Run(args []string) error {
 		}
 	}()
 
-	return RootCmd.Execute()
+	err := RootCmd.Execute()
+	if err != nil {
+		// the post run hooks don't run when the command fails, so the
+		// output of the elements of a bulk command that succeeded is
+		// flushed here
+		if len(printer.GetLines()) > 0 || len(printer.GetErrorLines()) > 0 {
+			_ = printer.Flush()
+		}
+		_ = finishTracing()
+		_ = finishCassette()
+		printRunError(err)
+	}
+	return err
+}
+
+// printRunError prints the error that made the command fail, as a
+// JSON object if the json format is being used
+func printRunError(err error) {
+	if viper.GetBool("json") || viper.GetString("format") == printer.FormatJSON {
+		b, _ := json.MarshalIndent(NewErrorEnvelope(err), "", "  ")
+		fmt.Fprintln(os.Stderr, string(b))
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err.Error())
 }
 
 var RootCmd = &cobra.Command{
-	Use:               "mmctl",
-	Short:             "Remote client for the Open Source, self-hosted Slack-alternative",
-	Long:              `Mattermost offers workplace messaging across web, PC and phones with archiving, search and integration with your existing systems. Documentation available at https://docs.mattermost.com`,
+	Use:   "mmctl",
+	Short: "Remote client for the Open Source, self-hosted Slack-alternative",
+	Long: `Mattermost offers workplace messaging across web, PC and phones with archiving, search and integration with your existing systems. Documentation available at https://docs.mattermost.com
+
+Exit codes:
+  0   the command succeeded
+  1   generic error
+  2   bad request, the server rejected the parameters (HTTP 400)
+  3   unauthorized, the credentials are invalid or expired (HTTP 401)
+  4   forbidden, the user lacks the required permissions (HTTP 403)
+  5   not found, the requested entity doesn't exist (HTTP 404)
+  6   conflict with the current state of the server (HTTP 409)
+  7   server error (HTTP 5xx)
+  8   network or TLS error, the server couldn't be reached
+  9   the server version doesn't match the mmctl one and --strict is set
+  10  partial failure, some of the elements of a bulk command failed and others succeeded
+
+When all the elements of a bulk command fail, the exit code is the one shared by their
+errors, or 1 if they have different codes.
+
+When the json format is used, errors are printed to the standard error as a JSON object
+containing the exit code, the HTTP status, the server error id, the request id and the
+argument that failed, if they are available.`,
 	DisableAutoGenTag: true,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		format := viper.GetString("format")
-		if viper.GetBool("disable-pager") {
-			printer.OverrideEnablePager(false)
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyOutputFlags(cmd); err != nil {
+			return err
 		}
-
-		printer.SetCommand(cmd)
-		isJSON := viper.GetBool("json")
-		if isJSON || format == printer.FormatJSON {
-			printer.SetFormat(printer.FormatJSON)
-		} else {
-			printer.SetFormat(printer.FormatPlain)
+		if err := initCassette(); err != nil {
+			return err
 		}
-		quiet := viper.GetBool("quiet")
-		printer.SetQuiet(quiet)
+		return initTracing()
 	},
-	PersistentPostRun: func(cmd *cobra.Command, args []string) {
-		_ = printer.Flush()
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		if err := printer.Flush(); err != nil {
+			return err
+		}
+		if err := finishCassette(); err != nil {
+			return err
+		}
+		return finishTracing()
 	},
-	SilenceUsage: true,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+// applyOutputFlags sets up the printer for a command with the output
+// flags, such as --format and --json
+func applyOutputFlags(cmd *cobra.Command) error {
+	format := viper.GetString("format")
+	if viper.GetBool("disable-pager") {
+		printer.OverrideEnablePager(false)
+	}
+
+	printer.SetCommand(cmd)
+	isJSON := viper.GetBool("json")
+	tpl := viper.GetString("template")
+	switch {
+	case isJSON:
+		printer.SetFormat(printer.FormatJSON)
+	case tpl != "":
+		if err := printer.SetTemplate(tpl); err != nil {
+			return err
+		}
+		printer.SetFormat(printer.FormatTemplate)
+	case format == printer.FormatTemplate:
+		return fmt.Errorf("the %q format requires the %q flag", printer.FormatTemplate, "--template")
+	case printer.IsValidFormat(format):
+		printer.SetFormat(format)
+	default:
+		return fmt.Errorf("invalid format %q, must be one of: %s", format, strings.Join(printer.Formats(), ", "))
+	}
+	printer.SetColumns(viper.GetStringSlice("columns"))
+	if err := printer.SetQuery(viper.GetString("query")); err != nil {
+		return err
+	}
+	printer.SetQuiet(viper.GetBool("quiet"))
+	return nil
 }
@@ -0,0 +1,720 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+const shellCommandName = "shell"
+
+const (
+	shellHistoryFileName = "shell_history"
+	// shellHistorySize is the number of entries that the shell keeps
+	// in memory to be browsed with the up and down keys
+	shellHistorySize     = 100
+	shellCompletionLimit = 50
+)
+
+const (
+	shellContextTeam    = "team"
+	shellContextChannel = "channel"
+	shellContextUser    = "user"
+)
+
+// shellContextArg describes how the current team or channel of the
+// shell is passed to a command that omits it, either as its first
+// argument or as the value of a flag
+type shellContextArg struct {
+	kind string
+	flag string
+}
+
+var shellContextCommands = map[string]shellContextArg{
+	"channel create":       {kind: shellContextTeam, flag: "team"},
+	"channel list":         {kind: shellContextTeam},
+	"channel search":       {kind: shellContextTeam, flag: "team"},
+	"channel users add":    {kind: shellContextChannel},
+	"channel users remove": {kind: shellContextChannel},
+	"post create":          {kind: shellContextChannel},
+	"post list":            {kind: shellContextChannel},
+	"team users add":       {kind: shellContextTeam},
+	"team users remove":    {kind: shellContextTeam},
+}
+
+var shellBuiltins = []string{"exit", "help", "history", "quit", "use"}
+
+var ShellCmd = &cobra.Command{
+	Use:   shellCommandName,
+	Short: "Start an interactive shell",
+	Long: `Starts an interactive shell that runs mmctl commands reusing a single authenticated client.
+The shell keeps a current team and channel, set with "use team [team]" and "use channel [channel]", that commands such as "channel users add" or "post create" use when they are omitted.
+Pressing tab completes commands, flags and the names of teams, channels and users, which are fetched from the server. The history of the shell is stored next to the mmctl configuration file.
+Output flags, such as --json, apply to every command and must be set when starting the shell.`,
+	Example: `  shell
+  shell --local
+  echo "team list" | shell`,
+	Args: cobra.NoArgs,
+	RunE: withClient(shellCmdF),
+}
+
+func init() {
+	RootCmd.AddCommand(ShellCmd)
+}
+
+type shellSession struct {
+	c           client.Client
+	cmd         *cobra.Command
+	out         io.Writer
+	historyPath string
+	history     *shellHistory
+	// resolved caches whether the arguments of the commands are
+	// teams or channels
+	resolved map[string]bool
+	team     *model.Team
+	channel  *model.Channel
+}
+
+func shellCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	previousClient := sharedClient
+	sharedClient = c
+	defer func() {
+		sharedClient = previousClient
+		printer.SetCommand(cmd)
+	}()
+
+	s := &shellSession{
+		c:           c,
+		cmd:         cmd,
+		out:         os.Stdout,
+		historyPath: filepath.Join(filepath.Dir(resolveConfigFilePath()), shellHistoryFileName),
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return s.runScript(os.Stdin)
+	}
+	return s.runInteractive(os.Stdin, os.Stdout)
+}
+
+// shellKeyUp and shellKeyDown are the keys that the shell reader
+// passes to the terminal for the up and down arrows. They are in the
+// private use area of unicode, as the terminal handles the arrows
+// itself and its history can't be loaded
+const (
+	shellKeyUp   = '\ue000'
+	shellKeyDown = '\ue001'
+)
+
+var shellKeyReplacer = strings.NewReplacer(
+	"\x1b[A", string(shellKeyUp),
+	"\x1bOA", string(shellKeyUp),
+	"\x10", string(shellKeyUp),
+	"\x1b[B", string(shellKeyDown),
+	"\x1bOB", string(shellKeyDown),
+	"\x0e", string(shellKeyDown),
+)
+
+// shellKeyReader reads the input of the terminal, replacing the up
+// and down arrows and their control keys with the shell keys so the
+// key callback of the terminal receives them
+type shellKeyReader struct {
+	r       io.Reader
+	pending []byte
+}
+
+func (k *shellKeyReader) Read(p []byte) (int, error) {
+	if len(k.pending) == 0 {
+		buf := make([]byte, len(p))
+		n, err := k.r.Read(buf)
+		if n == 0 {
+			return 0, err
+		}
+		k.pending = []byte(shellKeyReplacer.Replace(string(buf[:n])))
+	}
+	n := copy(p, k.pending)
+	k.pending = k.pending[n:]
+	return n, nil
+}
+
+// shellTerminalIO is the connection of the terminal
+type shellTerminalIO struct {
+	io.Reader
+	io.Writer
+}
+
+func (s *shellSession) runInteractive(in *os.File, out io.Writer) error {
+	fd := int(in.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("could not set the terminal in raw mode: %w", err)
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	history, err := s.readHistory()
+	if err != nil {
+		printer.PrintWarning(fmt.Sprintf("could not read the shell history: %s", err))
+	}
+	s.history = newShellHistory(history)
+	t := term.NewTerminal(&shellTerminalIO{Reader: &shellKeyReader{r: in}, Writer: out}, "")
+	t.AutoCompleteCallback = s.handleKey
+	s.out = t
+
+	for {
+		if width, height, err := term.GetSize(fd); err == nil {
+			_ = t.SetSize(width, height)
+		}
+		t.SetPrompt(s.prompt())
+
+		line, err := t.ReadLine()
+		if errors.Is(err, io.EOF) {
+			fmt.Fprint(t, "\n")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		s.history.add(line)
+		if line == "" {
+			continue
+		}
+
+		// commands are run with the terminal in its normal mode, so
+		// their output is printed as usual
+		if err := term.Restore(fd, state); err != nil {
+			return err
+		}
+		s.out = out
+		if err := s.appendHistory(line); err != nil {
+			printer.PrintWarning(fmt.Sprintf("could not save the shell history: %s", err))
+		}
+		exit, err := s.runLine(line)
+		if err != nil {
+			printRunError(err)
+		}
+		if exit {
+			return nil
+		}
+		if _, err := term.MakeRaw(fd); err != nil {
+			return err
+		}
+		s.out = t
+	}
+}
+
+// runScript runs the lines read from a non interactive input, such
+// as a pipe
+func (s *shellSession) runScript(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		exit, err := s.runLine(line)
+		if err != nil {
+			printRunError(err)
+		}
+		if exit {
+			break
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *shellSession) prompt() string {
+	switch {
+	case s.channel != nil:
+		return fmt.Sprintf("mmctl [%s:%s]> ", s.team.Name, s.channel.Name)
+	case s.team != nil:
+		return fmt.Sprintf("mmctl [%s]> ", s.team.Name)
+	}
+	return "mmctl> "
+}
+
+// runLine runs a line of the shell, returning true if the shell
+// should exit
+func (s *shellSession) runLine(line string) (bool, error) {
+	args, err := splitCommandLine(line)
+	if err != nil {
+		return false, err
+	}
+	if len(args) > 0 && args[0] == RootCmd.Name() {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return false, nil
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return true, nil
+	case "help":
+		if len(args) > 1 {
+			return false, s.runCommand(append(args[1:], "--help"))
+		}
+		s.printHelp()
+		return false, nil
+	case "history":
+		return false, s.printHistory()
+	case "use":
+		return false, s.use(args[1:])
+	case shellCommandName:
+		return false, errors.New("the shell is already running")
+	}
+
+	return false, s.runCommand(args)
+}
+
+func (s *shellSession) runCommand(args []string) error {
+	printer.Clean()
+	err := executeCommandInProcess(args, s.prepareCommand)
+	if flushErr := printer.Flush(); err == nil {
+		err = flushErr
+	}
+	printer.Clean()
+	// the output flags of the command only apply to its own output
+	if s.cmd != nil {
+		_ = applyOutputFlags(s.cmd)
+	}
+	return err
+}
+
+func (s *shellSession) printHelp() {
+	fmt.Fprint(s.out, `Shell commands:
+  use team [team]        Set the current team
+  use channel [channel]  Set the current channel, as [channel] or [team]:[channel]
+  use none               Clear the current team and channel
+  history                Show the history of the shell
+  help [command]         Show this help, or the help of an mmctl command
+  exit, quit             Exit the shell
+
+Any other line runs an mmctl command, such as "team list".
+`)
+}
+
+func (s *shellSession) use(args []string) error {
+	if len(args) == 1 && args[0] == "none" {
+		s.team, s.channel = nil, nil
+		return nil
+	}
+	if len(args) != 2 {
+		return errors.New("usage: use team [team] | use channel [channel] | use none")
+	}
+
+	switch args[0] {
+	case shellContextTeam:
+		team, err := getTeamFromArg(s.c, args[1])
+		if err != nil {
+			return err
+		}
+		s.team, s.channel = team, nil
+		s.cacheResolved(shellContextTeam, true, team.Id, team.Name)
+	case shellContextChannel:
+		channelArg := args[1]
+		if !strings.Contains(channelArg, ":") && s.team != nil {
+			channelArg = s.team.Name + ":" + channelArg
+		}
+		channel, err := getChannelFromArg(s.c, channelArg)
+		if err != nil {
+			return err
+		}
+		if channel.TeamId == "" {
+			return fmt.Errorf("channel %q doesn't belong to a team", args[1])
+		}
+
+		team := s.team
+		if team == nil || team.Id != channel.TeamId {
+			if team, err = getTeamFromArg(s.c, channel.TeamId); err != nil {
+				return err
+			}
+		}
+		s.team, s.channel = team, channel
+		s.cacheResolved(shellContextTeam, true, team.Id, team.Name)
+		s.cacheResolved(shellContextChannel, true, channel.Id, team.Name+":"+channel.Name, team.Id+":"+channel.Id)
+	default:
+		return fmt.Errorf("unknown shell context %q, it must be either team or channel", args[0])
+	}
+
+	return nil
+}
+
+// prepareCommand adds the current team or channel to the arguments
+// or the flags of the commands that support it if they are omitted
+func (s *shellSession) prepareCommand(cmd *cobra.Command, args []string) ([]string, error) {
+	contextArg, ok := shellContextCommands[strings.TrimPrefix(cmd.CommandPath(), RootCmd.Name()+" ")]
+	if !ok {
+		return args, nil
+	}
+
+	var value string
+	switch {
+	case contextArg.kind == shellContextChannel && s.channel != nil:
+		value = s.team.Name + ":" + s.channel.Name
+	case contextArg.kind == shellContextTeam && s.team != nil:
+		value = s.team.Name
+	default:
+		return args, nil
+	}
+
+	if contextArg.flag != "" {
+		if cmd.Flags().Changed(contextArg.flag) {
+			return args, nil
+		}
+		return args, cmd.Flags().Set(contextArg.flag, value)
+	}
+
+	if len(args) > 0 && s.resolves(contextArg.kind, args[0]) {
+		return args, nil
+	}
+	return append([]string{value}, args...), nil
+}
+
+// resolves returns whether the argument is a team or a channel. The
+// lookups are cached, and the current team and channel are added to
+// the cache when they are set, so commands that name them don't
+// query the server
+func (s *shellSession) resolves(kind, arg string) bool {
+	key := kind + " " + arg
+	if found, ok := s.resolved[key]; ok {
+		return found
+	}
+
+	var err error
+	if kind == shellContextChannel {
+		_, err = getChannelFromArg(s.c, arg)
+	} else {
+		_, err = getTeamFromArg(s.c, arg)
+	}
+	s.cacheResolved(kind, err == nil, arg)
+	return err == nil
+}
+
+func (s *shellSession) cacheResolved(kind string, found bool, args ...string) {
+	if s.resolved == nil {
+		s.resolved = map[string]bool{}
+	}
+	for _, arg := range args {
+		s.resolved[kind+" "+arg] = found
+	}
+}
+
+func (s *shellSession) readHistory() ([]string, error) {
+	b, err := os.ReadFile(s.historyPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var history []string
+	for _, line := range strings.Split(string(b), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			history = append(history, line)
+		}
+	}
+	return history, nil
+}
+
+func (s *shellSession) appendHistory(line string) error {
+	if err := os.MkdirAll(filepath.Dir(s.historyPath), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *shellSession) printHistory() error {
+	history, err := s.readHistory()
+	if err != nil {
+		return fmt.Errorf("could not read the shell history: %w", err)
+	}
+	for i, line := range history {
+		fmt.Fprintf(s.out, "%5d  %s\n", i+1, line)
+	}
+	return nil
+}
+
+// shellHistory is the history of the interactive shell, which keeps
+// its last entries to be browsed with the up and down keys
+type shellHistory struct {
+	entries []string
+	// index is the entry being shown, or the length of the entries
+	// for the line being typed
+	index   int
+	pending string
+}
+
+func newShellHistory(entries []string) *shellHistory {
+	h := &shellHistory{}
+	for _, entry := range entries {
+		h.add(entry)
+	}
+	return h
+}
+
+// add appends a line to the history and stops browsing it
+func (h *shellHistory) add(line string) {
+	if line != "" {
+		h.entries = append(h.entries, line)
+		if len(h.entries) > shellHistorySize {
+			h.entries = h.entries[len(h.entries)-shellHistorySize:]
+		}
+	}
+	h.index = len(h.entries)
+	h.pending = ""
+}
+
+// previous returns the entry before the one being shown, keeping the
+// line being typed to return to it
+func (h *shellHistory) previous(line string) (string, bool) {
+	if h.index == 0 {
+		return "", false
+	}
+	if h.index == len(h.entries) {
+		h.pending = line
+	}
+	h.index--
+	return h.entries[h.index], true
+}
+
+// next returns the entry after the one being shown, or the line that
+// was being typed after the last one
+func (h *shellHistory) next() (string, bool) {
+	if h.index == len(h.entries) {
+		return "", false
+	}
+	h.index++
+	if h.index == len(h.entries) {
+		return h.pending, true
+	}
+	return h.entries[h.index], true
+}
+
+// handleKey is the key callback of the terminal, which browses the
+// history with the up and down keys and completes with tab
+func (s *shellSession) handleKey(line string, pos int, key rune) (string, int, bool) {
+	var entry string
+	var ok bool
+	switch key {
+	case shellKeyUp:
+		entry, ok = s.history.previous(line)
+	case shellKeyDown:
+		entry, ok = s.history.next()
+	default:
+		return s.autoComplete(line, pos, key)
+	}
+	if !ok {
+		return line, shellBytePos(line, pos), true
+	}
+	return entry, len(entry), true
+}
+
+// shellBytePos converts the position of the cursor given by the
+// terminal, which counts runes, into an offset in the line
+func shellBytePos(line string, pos int) int {
+	runes := []rune(line)
+	if pos > len(runes) {
+		pos = len(runes)
+	}
+	return len(string(runes[:pos]))
+}
+
+// autoComplete is the completion callback of the terminal, which
+// completes the word under the cursor when tab is pressed
+func (s *shellSession) autoComplete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	pos = shellBytePos(line, pos)
+	word, candidates := s.completions(line[:pos])
+	if len(candidates) == 0 {
+		return line, pos, true
+	}
+
+	completion := candidates[0] + " "
+	if len(candidates) > 1 {
+		completion = commonPrefix(candidates)
+		if completion == word {
+			fmt.Fprintln(s.out, strings.Join(candidates, "  "))
+			return line, pos, true
+		}
+	}
+
+	head := line[:pos-len(word)]
+	return head + completion + line[pos:], len(head) + len(completion), true
+}
+
+// completions returns the word being completed at the end of the
+// line and its possible completions
+func (s *shellSession) completions(line string) (string, []string) {
+	words := strings.Fields(line)
+	if line == "" || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+	word := words[len(words)-1]
+	previous := words[:len(words)-1]
+	if len(previous) > 0 && previous[0] == RootCmd.Name() {
+		previous = previous[1:]
+	}
+	if len(previous) > 0 && previous[0] == "help" {
+		previous = previous[1:]
+		if len(previous) == 0 {
+			return word, filterByPrefix(subcommandNames(RootCmd), word)
+		}
+	}
+
+	var candidates []string
+	switch {
+	case len(previous) == 0:
+		candidates = append(subcommandNames(RootCmd), shellBuiltins...)
+	case previous[0] == "use" && len(previous) == 1:
+		candidates = []string{shellContextChannel, "none", shellContextTeam}
+	case previous[0] == "use" && len(previous) == 2:
+		candidates = s.entityNames(previous[1], word)
+	case previous[0] == "use":
+		return word, nil
+	default:
+		cmd, rest, err := RootCmd.Find(previous)
+		if err != nil {
+			return word, nil
+		}
+		last := previous[len(previous)-1]
+		switch {
+		case strings.HasPrefix(word, "-"):
+			candidates = flagNames(cmd)
+		case last == "--"+shellContextTeam || last == "--"+shellContextChannel || last == "--"+shellContextUser:
+			candidates = s.entityNames(strings.TrimPrefix(last, "--"), word)
+		case len(rest) == 0 && cmd.HasAvailableSubCommands():
+			candidates = subcommandNames(cmd)
+		default:
+			candidates = s.entityNames("", word)
+		}
+	}
+
+	return word, filterByPrefix(candidates, word)
+}
+
+// entityNames fetches from the server the names of the teams,
+// channels or users that can complete the word. If kind is empty,
+// all of them are fetched
+func (s *shellSession) entityNames(kind, word string) []string {
+	var names []string
+
+	if kind == "" || kind == shellContextTeam {
+		if teams, _, err := s.c.GetAllTeams("", 0, APILimitMaximum); err == nil {
+			for _, team := range teams {
+				names = append(names, team.Name)
+			}
+		}
+	}
+
+	// channels are only qualified with their team if the word is,
+	// otherwise they are looked up in the current team
+	if kind == shellContextChannel || (kind == "" && strings.Contains(word, ":")) {
+		teamName, channelName := "", word
+		team := s.team
+		if i := strings.Index(word, ":"); i >= 0 {
+			teamName, channelName = word[:i], word[i+1:]
+			team, _ = getTeamFromArg(s.c, teamName)
+		}
+		if team != nil {
+			if channels, _, err := s.c.AutocompleteChannelsForTeam(team.Id, channelName); err == nil {
+				for _, channel := range channels {
+					if teamName != "" {
+						names = append(names, teamName+":"+channel.Name)
+						continue
+					}
+					names = append(names, channel.Name)
+				}
+			}
+		}
+	}
+
+	if kind == "" || kind == shellContextUser {
+		if users, _, err := s.c.AutocompleteUsers(word, shellCompletionLimit, ""); err == nil {
+			for _, user := range users.Users {
+				names = append(names, user.Username)
+			}
+		}
+	}
+
+	return names
+}
+
+func subcommandNames(cmd *cobra.Command) []string {
+	var names []string
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() && sub.Name() != shellCommandName {
+			names = append(names, sub.Name())
+		}
+	}
+	return names
+}
+
+func flagNames(cmd *cobra.Command) []string {
+	var names []string
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden {
+			names = append(names, "--"+f.Name)
+		}
+	})
+	cmd.InheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden {
+			names = append(names, "--"+f.Name)
+		}
+	})
+	return names
+}
+
+// filterByPrefix returns the sorted and unique candidates that start
+// with the prefix
+func filterByPrefix(candidates []string, prefix string) []string {
+	seen := map[string]bool{}
+	var filtered []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) && !seen[candidate] {
+			seen[candidate] = true
+			filtered = append(filtered, candidate)
+		}
+	}
+	sort.Strings(filtered)
+	return filtered
+}
+
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+)
+
+func (s *MmctlUnitTestSuite) TestShellUse() {
+	team := &model.Team{Id: "teamid", Name: "myteam"}
+	channel := &model.Channel{Id: "channelid", Name: "town-square", TeamId: "teamid"}
+
+	s.Run("should set the current team and channel", func() {
+		shell := &shellSession{c: s.client, out: &bytes.Buffer{}}
+
+		s.client.EXPECT().GetTeam("myteam", "").Return(team, &model.Response{}, nil).Times(2)
+		s.client.EXPECT().GetChannelByNameIncludeDeleted("town-square", "teamid", "").Return(channel, &model.Response{}, nil).Times(1)
+
+		exit, err := shell.runLine("use team myteam")
+		s.Require().NoError(err)
+		s.Require().False(exit)
+		s.Require().Equal("mmctl [myteam]> ", shell.prompt())
+
+		_, err = shell.runLine("use channel town-square")
+		s.Require().NoError(err)
+		s.Require().Equal("mmctl [myteam:town-square]> ", shell.prompt())
+
+		_, err = shell.runLine("use none")
+		s.Require().NoError(err)
+		s.Require().Equal("mmctl> ", shell.prompt())
+	})
+
+	s.Run("should fail for a channel without a team", func() {
+		shell := &shellSession{c: s.client, out: &bytes.Buffer{}}
+
+		s.client.EXPECT().GetChannel("dmchannel", "").Return(&model.Channel{Id: "dmchannel"}, &model.Response{}, nil).Times(1)
+
+		_, err := shell.runLine("use channel dmchannel")
+		s.Require().EqualError(err, `channel "dmchannel" doesn't belong to a team`)
+	})
+
+	s.Run("should exit and reject nested shells", func() {
+		shell := &shellSession{c: s.client, out: &bytes.Buffer{}}
+
+		exit, err := shell.runLine("mmctl shell")
+		s.Require().EqualError(err, "the shell is already running")
+		s.Require().False(exit)
+
+		exit, err = shell.runLine("exit")
+		s.Require().NoError(err)
+		s.Require().True(exit)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestShellPrepareCommand() {
+	team := &model.Team{Id: "teamid", Name: "myteam"}
+	channel := &model.Channel{Id: "channelid", Name: "town-square", TeamId: "teamid"}
+	shell := &shellSession{c: s.client, team: team, channel: channel}
+
+	s.Run("should add the current channel when it is omitted", func() {
+		args, err := shell.prepareCommand(PostListCmd, []string{})
+		s.Require().NoError(err)
+		s.Require().Equal([]string{"myteam:town-square"}, args)
+
+		s.client.EXPECT().GetChannel("user1", "").Return(nil, &model.Response{StatusCode: http.StatusNotFound}, errors.New("not found")).Times(1)
+
+		args, err = shell.prepareCommand(ChannelUsersAddCmd, []string{"user1"})
+		s.Require().NoError(err)
+		s.Require().Equal([]string{"myteam:town-square", "user1"}, args)
+	})
+
+	s.Run("should keep an explicit channel", func() {
+		other := &model.Channel{Id: "otherid", Name: "other", TeamId: "teamid"}
+		s.client.EXPECT().GetTeam("myteam", "").Return(team, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetChannelByNameIncludeDeleted("other", "teamid", "").Return(other, &model.Response{}, nil).Times(1)
+
+		args, err := shell.prepareCommand(ChannelUsersAddCmd, []string{"myteam:other", "user1"})
+		s.Require().NoError(err)
+		s.Require().Equal([]string{"myteam:other", "user1"}, args)
+
+		// the lookup is cached, so it isn't repeated
+		args, err = shell.prepareCommand(ChannelUsersAddCmd, []string{"myteam:other", "user2"})
+		s.Require().NoError(err)
+		s.Require().Equal([]string{"myteam:other", "user2"}, args)
+	})
+
+	s.Run("should not look up the current team when it is given", func() {
+		shell := &shellSession{c: s.client, out: &bytes.Buffer{}}
+		s.client.EXPECT().GetTeam("myteam", "").Return(team, &model.Response{}, nil).Times(1)
+
+		_, err := shell.runLine("use team myteam")
+		s.Require().NoError(err)
+
+		for _, teamArg := range []string{"myteam", "teamid"} {
+			args, err := shell.prepareCommand(TeamUsersAddCmd, []string{teamArg, "user1"})
+			s.Require().NoError(err)
+			s.Require().Equal([]string{teamArg, "user1"}, args)
+		}
+	})
+
+	s.Run("should set the team flag when it is omitted", func() {
+		defer resetFlags(ChannelCreateCmd.LocalNonPersistentFlags())
+
+		args, err := shell.prepareCommand(ChannelCreateCmd, []string{})
+		s.Require().NoError(err)
+		s.Require().Empty(args)
+		teamFlag, _ := ChannelCreateCmd.Flags().GetString("team")
+		s.Require().Equal("myteam", teamFlag)
+	})
+
+	s.Run("should not change commands without context", func() {
+		args, err := shell.prepareCommand(ListTeamsCmd, []string{})
+		s.Require().NoError(err)
+		s.Require().Empty(args)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestShellCompletions() {
+	team := &model.Team{Id: "teamid", Name: "myteam"}
+	shell := &shellSession{c: s.client, out: &bytes.Buffer{}}
+
+	s.Run("should complete commands and subcommands", func() {
+		word, candidates := shell.completions("chan")
+		s.Require().Equal("chan", word)
+		s.Require().Equal([]string{"channel"}, candidates)
+
+		line, pos, ok := shell.autoComplete("channel us", 10, '\t')
+		s.Require().True(ok)
+		s.Require().Equal("channel users ", line)
+		s.Require().Equal(14, pos)
+	})
+
+	s.Run("should complete after non ascii characters", func() {
+		s.client.EXPECT().GetAllTeams("", 0, APILimitMaximum).Return([]*model.Team{{Name: "équipe"}}, &model.Response{}, nil).Times(1)
+
+		// the position of the cursor counts runes
+		line, pos, ok := shell.autoComplete("use team é", 10, '\t')
+		s.Require().True(ok)
+		s.Require().Equal("use team équipe ", line)
+		s.Require().Equal(len("use team équipe "), pos)
+	})
+
+	s.Run("should complete flags", func() {
+		_, candidates := shell.completions("post create --mes")
+		s.Require().Equal([]string{"--message"}, candidates)
+	})
+
+	s.Run("should complete team names for the use command", func() {
+		s.client.EXPECT().GetAllTeams("", 0, APILimitMaximum).Return([]*model.Team{team, {Name: "other"}}, &model.Response{}, nil).Times(1)
+
+		_, candidates := shell.completions("use team my")
+		s.Require().Equal([]string{"myteam"}, candidates)
+	})
+
+	s.Run("should complete qualified channel names", func() {
+		s.client.EXPECT().GetAllTeams("", 0, APILimitMaximum).Return([]*model.Team{team}, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().GetTeam("myteam", "").Return(team, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().AutocompleteChannelsForTeam("teamid", "to").Return(model.ChannelList{{Name: "town-square"}}, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().AutocompleteUsers("myteam:to", shellCompletionLimit, "").Return(&model.UserAutocomplete{}, &model.Response{}, nil).Times(1)
+
+		_, candidates := shell.completions("post list myteam:to")
+		s.Require().Equal([]string{"myteam:town-square"}, candidates)
+	})
+
+	s.Run("should list the candidates if they have no common prefix", func() {
+		out := &bytes.Buffer{}
+		shell := &shellSession{c: s.client, out: out}
+		s.client.EXPECT().GetAllTeams("", 0, APILimitMaximum).Return([]*model.Team{team}, &model.Response{}, nil).Times(1)
+		s.client.EXPECT().AutocompleteUsers("", shellCompletionLimit, "").Return(&model.UserAutocomplete{Users: []*model.User{{Username: "alice"}}}, &model.Response{}, nil).Times(1)
+
+		line, pos, ok := shell.autoComplete("team users add ", 15, '\t')
+		s.Require().True(ok)
+		s.Require().Equal("team users add ", line)
+		s.Require().Equal(15, pos)
+		s.Require().Equal("alice  myteam\n", out.String())
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestShellHistory() {
+	shell := &shellSession{historyPath: filepath.Join(s.T().TempDir(), "mmctl", shellHistoryFileName)}
+
+	history, err := shell.readHistory()
+	s.Require().NoError(err)
+	s.Require().Empty(history)
+
+	s.Require().NoError(shell.appendHistory("team list"))
+	s.Require().NoError(shell.appendHistory("use team myteam"))
+
+	history, err = shell.readHistory()
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"team list", "use team myteam"}, history)
+}
+
+func (s *MmctlUnitTestSuite) TestShellHistoryKeys() {
+	shell := &shellSession{historyPath: filepath.Join(s.T().TempDir(), shellHistoryFileName)}
+	s.Require().NoError(os.WriteFile(shell.historyPath, []byte("team list\nuse team myteam\n\nchannel list\n"), 0600))
+	history, err := shell.readHistory()
+	s.Require().NoError(err)
+	shell.history = newShellHistory(history)
+
+	press := func(line string, key rune) string {
+		newLine, pos, ok := shell.handleKey(line, len(line), key)
+		s.Require().True(ok)
+		s.Require().Equal(len(newLine), pos)
+		return newLine
+	}
+
+	s.Run("should browse the entries from the last one and back to the typed line", func() {
+		line := press("user", shellKeyUp)
+		s.Require().Equal("channel list", line)
+		line = press(line, shellKeyUp)
+		s.Require().Equal("use team myteam", line)
+		line = press(line, shellKeyUp)
+		s.Require().Equal("team list", line)
+		s.Require().Equal("team list", press(line, shellKeyUp))
+
+		line = press(line, shellKeyDown)
+		s.Require().Equal("use team myteam", line)
+		line = press(line, shellKeyDown)
+		s.Require().Equal("channel list", line)
+		line = press(line, shellKeyDown)
+		s.Require().Equal("user", line)
+		s.Require().Equal("user", press(line, shellKeyDown))
+	})
+
+	s.Run("should add the read lines and keep the last entries", func() {
+		shell.history.add("post list")
+		s.Require().Equal("post list", press("", shellKeyUp))
+
+		for i := 0; i < shellHistorySize; i++ {
+			shell.history.add(fmt.Sprintf("team search %d", i))
+		}
+		s.Require().Len(shell.history.entries, shellHistorySize)
+		s.Require().Equal("team search 0", shell.history.entries[0])
+	})
+
+	s.Run("should read the arrows as the shell keys", func() {
+		r := &shellKeyReader{r: strings.NewReader("\x1b[Aab\x1bOB\x10")}
+		b, err := io.ReadAll(r)
+		s.Require().NoError(err)
+		s.Require().Equal(string(shellKeyUp)+"ab"+string(shellKeyDown)+string(shellKeyUp), string(b))
+	})
+}
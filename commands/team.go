@@ -38,9 +38,10 @@ var DeleteTeamsCmd = &cobra.Command{
 	Short: "Delete teams",
 	Long: `Permanently delete some teams.
 Permanently deletes a team along with all related information including posts from the database.`,
-	Example: "  team delete myteam",
-	Args:    cobra.MinimumNArgs(1),
-	RunE:    withClient(deleteTeamsCmdF),
+	Example:           "  team delete myteam",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: validArgs(completeTeams),
+	RunE:              withClient(deleteTeamsCmdF),
 }
 
 var ArchiveTeamsCmd = &cobra.Command{
@@ -48,18 +49,20 @@ var ArchiveTeamsCmd = &cobra.Command{
 	Short: "Archive teams",
 	Long: `Archive some teams.
 Archives a team along with all related information including posts from the database.`,
-	Example: "  team archive myteam",
-	Args:    cobra.MinimumNArgs(1),
-	RunE:    withClient(archiveTeamsCmdF),
+	Example:           "  team archive myteam",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: validArgs(completeTeams),
+	RunE:              withClient(archiveTeamsCmdF),
 }
 
 var RestoreTeamsCmd = &cobra.Command{
-	Use:     "restore [teams]",
-	Short:   "Restore teams",
-	Long:    "Restores archived teams.",
-	Example: "  team restore myteam",
-	Args:    cobra.MinimumNArgs(1),
-	RunE:    withClient(restoreTeamsCmdF),
+	Use:               "restore [teams]",
+	Short:             "Restore teams",
+	Long:              "Restores archived teams.",
+	Example:           "  team restore myteam",
+	Args:              minimumArgsOrFile(0),
+	ValidArgsFunction: validArgs(completeTeams),
+	RunE:              withClient(restoreTeamsCmdF),
 }
 
 var ListTeamsCmd = &cobra.Command{
@@ -81,21 +84,23 @@ var SearchTeamCmd = &cobra.Command{
 
 // RenameTeamCmd is the command to rename team along with its display name
 var RenameTeamCmd = &cobra.Command{
-	Use:     "rename [team]",
-	Short:   "Rename team",
-	Long:    "Rename an existing team",
-	Example: "  team rename old-team --display-name 'New Display Name'",
-	Args:    cobra.ExactArgs(1),
-	RunE:    withClient(renameTeamCmdF),
+	Use:               "rename [team]",
+	Short:             "Rename team",
+	Long:              "Rename an existing team",
+	Example:           "  team rename old-team --display-name 'New Display Name'",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeTeams, completeNone),
+	RunE:              withClient(renameTeamCmdF),
 }
 
 var ModifyTeamsCmd = &cobra.Command{
-	Use:     "modify [teams] [flag]",
-	Short:   "Modify teams",
-	Long:    "Modify teams' privacy setting to public or private",
-	Example: "  team modify myteam --private",
-	Args:    cobra.MinimumNArgs(1),
-	RunE:    withClient(modifyTeamsCmdF),
+	Use:               "modify [teams] [flag]",
+	Short:             "Modify teams",
+	Long:              "Modify teams' privacy setting to public or private",
+	Example:           "  team modify myteam --private",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: validArgs(completeTeams),
+	RunE:              withClient(modifyTeamsCmdF),
 }
 
 func init() {
@@ -109,6 +114,8 @@ func init() {
 	DeleteTeamsCmd.Flags().Bool("confirm", false, "Confirm you really want to delete the team and a DB backup has been performed.")
 	ArchiveTeamsCmd.Flags().Bool("confirm", false, "Confirm you really want to archive the team and a DB backup has been performed.")
 
+	addFromFileFlags(RestoreTeamsCmd)
+
 	ModifyTeamsCmd.Flags().Bool("private", false, "Modify team to be private.")
 	ModifyTeamsCmd.Flags().Bool("public", false, "Modify team to be public.")
 
@@ -359,12 +366,18 @@ func modifyTeamsCmdF(c client.Client, cmd *cobra.Command, args []string) error {
 }
 
 func restoreTeamsCmdF(c client.Client, cmd *cobra.Command, args []string) error {
-	teams := getTeamsFromTeamArgs(c, args)
+	teamArgs, err := fileArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+
 	var result *multierror.Error
-	for i, team := range teams {
+	for _, teamArg := range teamArgs {
+		team := getTeamFromTeamArg(c, teamArg.Value)
 		if team == nil {
-			result = multierror.Append(result, fmt.Errorf("unable to find team '%s'", args[i]))
-			printer.PrintError("Unable to find team '" + args[i] + "'")
+			err := teamArg.wrap(fmt.Errorf("unable to find team '%s'", teamArg.Value))
+			result = multierror.Append(result, err)
+			printer.PrintError(teamArg.wrap(errors.New("Unable to find team '" + teamArg.Value + "'")).Error())
 			continue
 		}
 		if rteam, _, err := c.RestoreTeam(team.Id); err != nil {
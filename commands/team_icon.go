@@ -0,0 +1,137 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+var TeamIconCmd = &cobra.Command{
+	Use:   "icon",
+	Short: "Management of team icons",
+}
+
+var TeamIconSetCmd = &cobra.Command{
+	Use:   "set [team] [image]",
+	Short: "Set the icon of teams",
+	Long: `Sets the icon of a team from a PNG, JPEG or GIF file.
+With --dir, every image of a directory is set as the icon of the team named like the file, e.g. engineering.png for the team engineering. The images are validated before being uploaded.`,
+	Example: `  team icon set engineering icon.png
+  team icon set --dir icons/`,
+	ValidArgsFunction: validArgs(completeTeams, completeNone),
+	Args:              imageSetArgs,
+	RunE:              withClient(teamIconSetCmdF),
+}
+
+var TeamIconGetCmd = &cobra.Command{
+	Use:   "get [team]",
+	Short: "Download the icon of a team",
+	Long:  "Downloads the icon of a team to a file, which defaults to the team name with the png extension.",
+	Example: `  team icon get engineering
+  team icon get engineering -o icon.png`,
+	ValidArgsFunction: validArgs(completeTeams, completeNone),
+	Args:              cobra.ExactArgs(1),
+	RunE:              withClient(teamIconGetCmdF),
+}
+
+var TeamIconRemoveCmd = &cobra.Command{
+	Use:               "remove [teams]",
+	Short:             "Remove the icon of teams",
+	Long:              "Removes the icon of teams, which are shown with their initials instead.",
+	Example:           "  team icon remove engineering sales",
+	ValidArgsFunction: validArgs(completeTeams),
+	Args:              minimumArgsOrFile(0),
+	RunE:              withClient(teamIconRemoveCmdF),
+}
+
+func init() {
+	addImageSetFlags(TeamIconSetCmd, "team")
+	TeamIconGetCmd.Flags().StringP("output", "o", "", "file where the icon is saved. Defaults to the team name with the png extension")
+	addBulkFlags(TeamIconRemoveCmd)
+
+	TeamIconCmd.AddCommand(
+		TeamIconSetCmd,
+		TeamIconGetCmd,
+		TeamIconRemoveCmd,
+	)
+	TeamCmd.AddCommand(TeamIconCmd)
+}
+
+func teamIconSetCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	targets, teams, err := imageSetTargets(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	report, err := runBulk(cmd, "images", targets, func(path string) (func(), error) {
+		data, err := readImageFile(path)
+		if err != nil {
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		team, err := getTeamFromArg(c, teams[path])
+		if err != nil {
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		if response, err := c.SetTeamIcon(team.Id, data); err != nil {
+			err = fmt.Errorf("could not set the icon of team %s: %w", team.Name, ExtractErrorFromResponse(response, err))
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		return func() { printer.Print("Icon of team " + team.Name + " set from " + path) }, nil
+	})
+	if err != nil {
+		return err
+	}
+	return report.errors()
+}
+
+func teamIconGetCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	team, err := getTeamFromArg(c, args[0])
+	if err != nil {
+		return err
+	}
+
+	data, response, err := c.GetTeamIcon(team.Id, "")
+	if err != nil {
+		return fmt.Errorf("could not get the icon of team %s: %w", team.Name, ExtractErrorFromResponse(response, err))
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		output = team.Name + ".png"
+	}
+	if err := os.WriteFile(output, data, 0600); err != nil {
+		return fmt.Errorf("could not save the team icon: %w", err)
+	}
+	printer.Print("Icon of team " + team.Name + " saved to " + output)
+	return nil
+}
+
+func teamIconRemoveCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	targets, err := fileArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	report, err := runBulk(cmd, "teams", targets, func(target string) (func(), error) {
+		team, err := getTeamFromArg(c, target)
+		if err != nil {
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		if response, err := c.RemoveTeamIcon(team.Id); err != nil {
+			err = fmt.Errorf("could not remove the icon of team %s: %w", team.Name, ExtractErrorFromResponse(response, err))
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		return func() { printer.Print("Icon of team " + team.Name + " removed") }, nil
+	})
+	if err != nil {
+		return err
+	}
+	return report.errors()
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestTeamIconCmds() {
+	dir, err := ioutil.TempDir("", "mmctl-icons-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	team := &model.Team{Id: model.NewId(), Name: "engineering"}
+
+	s.Run("should set the icon of a team", func() {
+		printer.Clean()
+		path := filepath.Join(dir, "icon.png")
+		data := s.writeTestPNG(path, 64, 64)
+
+		s.client.
+			EXPECT().
+			GetTeam(team.Name, "").
+			Return(nil, &model.Response{StatusCode: http.StatusNotFound}, errors.New("not found")).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetTeamByName(team.Name, "").
+			Return(team, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			SetTeamIcon(team.Id, data).
+			Return(&model.Response{}, nil).
+			Times(1)
+
+		cmd := &cobra.Command{}
+		addImageSetFlags(cmd, "team")
+		err := teamIconSetCmdF(s.client, cmd, []string{team.Name, path})
+		s.Require().NoError(err)
+		s.Require().Equal([]interface{}{"Icon of team engineering set from " + path}, printer.GetLines())
+	})
+
+	s.Run("should save the icon of a team", func() {
+		printer.Clean()
+		s.client.
+			EXPECT().
+			GetTeam(team.Id, "").
+			Return(team, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetTeamIcon(team.Id, "").
+			Return([]byte("icon"), &model.Response{}, nil).
+			Times(1)
+
+		path := filepath.Join(dir, "saved.png")
+		cmd := &cobra.Command{}
+		cmd.Flags().StringP("output", "o", path, "")
+		err := teamIconGetCmdF(s.client, cmd, []string{team.Id})
+		s.Require().NoError(err)
+
+		b, err := ioutil.ReadFile(path)
+		s.Require().NoError(err)
+		s.Require().Equal("icon", string(b))
+	})
+
+	s.Run("should remove the icon of a team", func() {
+		printer.Clean()
+		s.client.
+			EXPECT().
+			GetTeam(team.Id, "").
+			Return(team, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			RemoveTeamIcon(team.Id).
+			Return(&model.Response{StatusCode: http.StatusNotFound}, errors.New("no icon")).
+			Times(1)
+
+		err := teamIconRemoveCmdF(s.client, &cobra.Command{}, []string{team.Id})
+		s.Require().Error(err)
+		s.Require().Equal([]interface{}{"could not remove the icon of team engineering: no icon"}, printer.GetErrorLines())
+	})
+}
@@ -21,24 +21,28 @@ var TeamUsersCmd = &cobra.Command{
 }
 
 var TeamUsersRemoveCmd = &cobra.Command{
-	Use:     "remove [team] [users]",
-	Short:   "Remove users from team",
-	Long:    "Remove some users from team",
-	Example: "  team users remove myteam user@example.com username",
-	Args:    cobra.MinimumNArgs(2),
-	RunE:    withClient(teamUsersRemoveCmdF),
+	Use:               "remove [team] [users]",
+	Short:             "Remove users from team",
+	Long:              "Remove some users from team",
+	Example:           "  team users remove myteam user@example.com username",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: validArgs(completeTeams, completeUsers),
+	RunE:              withClient(teamUsersRemoveCmdF),
 }
 
 var TeamUsersAddCmd = &cobra.Command{
-	Use:     "add [team] [users]",
-	Short:   "Add users to team",
-	Long:    "Add some users to team",
-	Example: "  team users add myteam user@example.com username",
-	Args:    cobra.MinimumNArgs(2),
-	RunE:    withClient(teamUsersAddCmdF),
+	Use:               "add [team] [users]",
+	Short:             "Add users to team",
+	Long:              "Add some users to team",
+	Example:           "  team users add myteam user@example.com username",
+	Args:              minimumArgsOrFile(1),
+	ValidArgsFunction: validArgs(completeTeams, completeUsers),
+	RunE:              withClient(teamUsersAddCmdF),
 }
 
 func init() {
+	addBulkFlags(TeamUsersAddCmd)
+
 	TeamUsersCmd.AddCommand(
 		TeamUsersRemoveCmd,
 		TeamUsersAddCmd,
@@ -81,28 +85,40 @@ func removeUserFromTeam(c client.Client, team *model.Team, user *model.User, use
 }
 
 func teamUsersAddCmdF(c client.Client, cmd *cobra.Command, args []string) error {
-	var errs *multierror.Error
+	targets, err := fileArgs(cmd, args[1:])
+	if err != nil {
+		return err
+	}
+
 	team := getTeamFromTeamArg(c, args[0])
 	if team == nil {
 		return errors.New("Unable to find team '" + args[0] + "'")
 	}
 
-	users := getUsersFromUserArgs(c, args[1:])
-	for i, user := range users {
-		if user == nil {
-			userErr := fmt.Errorf("can't find user '%s'", args[i+1])
-			printer.PrintError(userErr.Error())
-			errs = multierror.Append(errs, userErr)
-			continue
-		}
-		addUserToTeam(c, team, user, args[i+1])
+	report, err := runBulk(cmd, "users", targets, func(target string) (func(), error) {
+		return addUserToTeam(c, team, target)
+	})
+	if err != nil {
+		return err
 	}
-
-	return errs.ErrorOrNil()
+	// only the users that can't be found make the command fail, the
+	// ones that can't be added are just reported
+	return report.errorsMatching(func(err error) bool {
+		var nfErr *NotFoundError
+		return errors.As(err, &nfErr)
+	})
 }
 
-func addUserToTeam(c client.Client, team *model.Team, user *model.User, userArg string) {
+func addUserToTeam(c client.Client, team *model.Team, userArg string) (func(), error) {
+	user := getUserFromUserArg(c, userArg)
+	if user == nil {
+		userErr := &NotFoundError{Msg: fmt.Sprintf("can't find user '%s'", userArg)}
+		return func() { printer.PrintError(userErr.Error()) }, userErr
+	}
 	if _, _, err := c.AddTeamMember(team.Id, user.Id); err != nil {
-		printer.PrintError("Unable to add '" + userArg + "' to " + team.Name + ". Error: " + err.Error())
+		return func() {
+			printer.PrintError("Unable to add '" + userArg + "' to " + team.Name + ". Error: " + err.Error())
+		}, fmt.Errorf("unable to add '%s' to %s: %w", userArg, team.Name, err)
 	}
+	return nil, nil
 }
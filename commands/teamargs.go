@@ -54,7 +54,6 @@ func getTeamsFromArgs(c client.Client, teamArgs []string) ([]*model.Team, error)
 	return teams, result.ErrorOrNil()
 }
 
-//nolint:golint,unused
 func getTeamFromArg(c client.Client, teamArg string) (*model.Team, error) {
 	if checkDots(teamArg) || checkSlash(teamArg) {
 		return nil, fmt.Errorf("invalid argument %q", teamArg)
@@ -19,12 +19,13 @@ var TokenCmd = &cobra.Command{
 }
 
 var GenerateUserTokenCmd = &cobra.Command{
-	Use:     "generate [user] [description]",
-	Short:   "Generate token for a user",
-	Long:    "Generate token for a user",
-	Example: "  generate testuser test-token",
-	RunE:    withClient(generateTokenForAUserCmdF),
-	Args:    cobra.ExactArgs(2),
+	Use:               "generate [user] [description]",
+	Short:             "Generate token for a user",
+	Long:              "Generate token for a user",
+	Example:           "  generate testuser test-token",
+	ValidArgsFunction: validArgs(completeUsers, completeNone),
+	RunE:              withClient(generateTokenForAUserCmdF),
+	Args:              cobra.ExactArgs(2),
 }
 
 var RevokeUserTokenCmd = &cobra.Command{
@@ -37,12 +38,13 @@ var RevokeUserTokenCmd = &cobra.Command{
 }
 
 var ListUserTokensCmd = &cobra.Command{
-	Use:     "list [user]",
-	Short:   "List users tokens",
-	Long:    "List the tokens of a user",
-	Example: "  user tokens testuser",
-	RunE:    withClient(listTokensOfAUserCmdF),
-	Args:    cobra.ExactArgs(1),
+	Use:               "list [user]",
+	Short:             "List users tokens",
+	Long:              "List the tokens of a user",
+	Example:           "  user tokens testuser",
+	ValidArgsFunction: validArgs(completeUsers, completeNone),
+	RunE:              withClient(listTokensOfAUserCmdF),
+	Args:              cobra.ExactArgs(1),
 }
 
 func init() {
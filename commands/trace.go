@@ -0,0 +1,60 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/viper"
+
+	"github.com/mattermost/mmctl/v6/client"
+)
+
+var (
+	// tracer logs the requests of the clients if tracing is enabled
+	tracer    *client.Tracer
+	traceFile *os.File
+)
+
+// initTracing enables tracing if the --trace or the --debug flags
+// are set. It must be called before the clients are created
+func initTracing() error {
+	debug := viper.GetBool("debug")
+	if !viper.GetBool("trace") && !debug {
+		return nil
+	}
+
+	var w io.Writer = os.Stderr
+	if path := viper.GetString("trace-file"); path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return fmt.Errorf("could not open the trace file: %w", err)
+		}
+		traceFile = f
+		w = f
+	}
+
+	tracer = client.NewTracer(w, debug || viper.GetBool("trace-bodies"))
+	return nil
+}
+
+// finishTracing prints the summary of the traced requests and stops
+// tracing
+func finishTracing() error {
+	if tracer == nil {
+		return nil
+	}
+
+	fmt.Fprintf(tracer.Writer, "trace summary: %s\n", tracer.Summary())
+	tracer = nil
+
+	if traceFile == nil {
+		return nil
+	}
+	err := traceFile.Close()
+	traceFile = nil
+	return err
+}
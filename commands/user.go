@@ -30,8 +30,9 @@ var UserActivateCmd = &cobra.Command{
 	Long:  "Activate users that have been deactivated.",
 	Example: `  user activate user@example.com
   user activate username`,
-	RunE: withClient(userActivateCmdF),
-	Args: cobra.MinimumNArgs(1),
+	ValidArgsFunction: validArgs(completeUsers),
+	RunE:              withClient(userActivateCmdF),
+	Args:              minimumArgsOrFile(0),
 }
 
 var UserDeactivateCmd = &cobra.Command{
@@ -39,9 +40,11 @@ var UserDeactivateCmd = &cobra.Command{
 	Short: "Deactivate users",
 	Long:  "Deactivate users. Deactivated users are immediately logged out of all sessions and are unable to log back in.",
 	Example: `  user deactivate user@example.com
-  user deactivate username`,
-	RunE: withClient(userDeactivateCmdF),
-	Args: cobra.MinimumNArgs(1),
+  user deactivate username
+  user deactivate --from-file users.txt --parallel 4 --failures-file failed.txt`,
+	ValidArgsFunction: validArgs(completeUsers),
+	RunE:              withClient(userDeactivateCmdF),
+	Args:              minimumArgsOrFile(0),
 }
 
 var UserCreateCmd = &cobra.Command{
@@ -70,33 +73,37 @@ You can invite a user to multiple teams by listing them.
 You can specify teams by name or ID.`,
 	Example: `  user invite user@example.com myteam
   user invite user@example.com myteam1 myteam2`,
-	RunE: withClient(userInviteCmdF),
+	ValidArgsFunction: validArgs(completeNone, completeTeams),
+	RunE:              withClient(userInviteCmdF),
 }
 
 var SendPasswordResetEmailCmd = &cobra.Command{
-	Use:     "reset-password [users]",
-	Aliases: []string{"reset_password"},
-	Short:   "Send users an email to reset their password",
-	Long:    "Send users an email to reset their password",
-	Example: "  user reset-password user@example.com",
-	RunE:    withClient(sendPasswordResetEmailCmdF),
+	Use:               "reset-password [users]",
+	Aliases:           []string{"reset_password"},
+	Short:             "Send users an email to reset their password",
+	Long:              "Send users an email to reset their password",
+	Example:           "  user reset-password user@example.com",
+	ValidArgsFunction: validArgs(completeUsers),
+	RunE:              withClient(sendPasswordResetEmailCmdF),
 }
 
 var UpdateUserEmailCmd = &cobra.Command{
-	Use:     "email [user] [new email]",
-	Short:   "Change email of the user",
-	Long:    "Change the email address associated with a user.",
-	Example: "  user email testuser user@example.com",
-	RunE:    withClient(updateUserEmailCmdF),
+	Use:               "email [user] [new email]",
+	Short:             "Change email of the user",
+	Long:              "Change the email address associated with a user.",
+	Example:           "  user email testuser user@example.com",
+	ValidArgsFunction: validArgs(completeUsers, completeNone),
+	RunE:              withClient(updateUserEmailCmdF),
 }
 
 var UpdateUsernameCmd = &cobra.Command{
-	Use:     "username [user] [new username]",
-	Short:   "Change username of the user",
-	Long:    "Change username of the user.",
-	Example: "  user username testuser newusername",
-	Args:    cobra.ExactArgs(2),
-	RunE:    withClient(updateUsernameCmdF),
+	Use:               "username [user] [new username]",
+	Short:             "Change username of the user",
+	Long:              "Change username of the user.",
+	Example:           "  user username testuser newusername",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: validArgs(completeUsers, completeNone),
+	RunE:              withClient(updateUsernameCmdF),
 }
 
 var ChangePasswordUserCmd = &cobra.Command{
@@ -118,8 +125,9 @@ var ChangePasswordUserCmd = &cobra.Command{
   # if you have system permissions, you can update the password with the already hashed new
   # password. The hashing method should be the same that the server uses internally
   $ mmctl user change-password john_doe --password HASHED_PASSWORD --hashed`,
-	Args: cobra.ExactArgs(1),
-	RunE: withClient(changePasswordUserCmdF),
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: validArgs(completeUsers, completeNone),
+	RunE:              withClient(changePasswordUserCmdF),
 }
 
 var ResetUserMfaCmd = &cobra.Command{
@@ -127,8 +135,9 @@ var ResetUserMfaCmd = &cobra.Command{
 	Short: "Turn off MFA",
 	Long: `Turn off multi-factor authentication for a user.
 If MFA enforcement is enabled, the user will be forced to re-enable MFA as soon as they log in.`,
-	Example: "  user resetmfa user@example.com",
-	RunE:    withClient(resetUserMfaCmdF),
+	Example:           "  user resetmfa user@example.com",
+	ValidArgsFunction: validArgs(completeUsers),
+	RunE:              withClient(resetUserMfaCmdF),
 }
 
 var DeleteUsersCmd = &cobra.Command{
@@ -136,9 +145,10 @@ var DeleteUsersCmd = &cobra.Command{
 	Short: "Delete users",
 	Long: `Permanently delete some users.
 Permanently deletes one or multiple users along with all related information including posts from the database.`,
-	Example: "  user delete user@example.com",
-	Args:    cobra.MinimumNArgs(1),
-	RunE:    withClient(deleteUsersCmdF),
+	Example:           "  user delete user@example.com",
+	Args:              minimumArgsOrFile(0),
+	ValidArgsFunction: validArgs(completeUsers),
+	RunE:              withClient(deleteUsersCmdF),
 }
 
 var DeleteAllUsersCmd = &cobra.Command{
@@ -147,7 +157,7 @@ var DeleteAllUsersCmd = &cobra.Command{
 	Long:    "Permanently delete all users and all related information including posts. This command can only be run in local mode.",
 	Example: "  user deleteall",
 	Args:    cobra.NoArgs,
-	PreRun:  localOnlyPrecheck,
+	PreRunE: localOnlyPrecheck,
 	RunE:    withClient(deleteAllUsersCmdF),
 }
 
@@ -169,30 +179,33 @@ var ListUsersCmd = &cobra.Command{
 }
 
 var VerifyUserEmailWithoutTokenCmd = &cobra.Command{
-	Use:     "verify [users]",
-	Short:   "Mark user's email as verified",
-	Long:    "Mark user's email as verified without requiring user to complete email verification path.",
-	Example: "  user verify user1",
-	RunE:    withClient(verifyUserEmailWithoutTokenCmdF),
-	Args:    cobra.MinimumNArgs(1),
+	Use:               "verify [users]",
+	Short:             "Mark user's email as verified",
+	Long:              "Mark user's email as verified without requiring user to complete email verification path.",
+	Example:           "  user verify user1",
+	ValidArgsFunction: validArgs(completeUsers),
+	RunE:              withClient(verifyUserEmailWithoutTokenCmdF),
+	Args:              minimumArgsOrFile(0),
 }
 
 var PromoteGuestToUserCmd = &cobra.Command{
-	Use:     "promote [guests]",
-	Short:   "Promote guests to users",
-	Long:    "Convert a guest into a regular user.",
-	Example: "  user promote guest1 guest2",
-	RunE:    withClient(promoteGuestToUserCmdF),
-	Args:    cobra.MinimumNArgs(1),
+	Use:               "promote [guests]",
+	Short:             "Promote guests to users",
+	Long:              "Convert a guest into a regular user.",
+	Example:           "  user promote guest1 guest2",
+	ValidArgsFunction: validArgs(completeUsers),
+	RunE:              withClient(promoteGuestToUserCmdF),
+	Args:              cobra.MinimumNArgs(1),
 }
 
 var DemoteUserToGuestCmd = &cobra.Command{
-	Use:     "demote [users]",
-	Short:   "Demote users to guests",
-	Long:    "Convert a regular user into a guest.",
-	Example: "  user demote user1 user2",
-	RunE:    withClient(demoteUserToGuestCmdF),
-	Args:    cobra.MinimumNArgs(1),
+	Use:               "demote [users]",
+	Short:             "Demote users to guests",
+	Long:              "Convert a regular user into a guest.",
+	Example:           "  user demote user1 user2",
+	ValidArgsFunction: validArgs(completeUsers),
+	RunE:              withClient(demoteUserToGuestCmdF),
+	Args:              cobra.MinimumNArgs(1),
 }
 
 var UserConvertCmd = &cobra.Command{
@@ -207,8 +220,9 @@ var UserConvertCmd = &cobra.Command{
 
   # you can convert a bot to a user specifying the email and password that the user will have after conversion
   $ mmctl user convert botusername --email new.email@email.com --password password --user`,
-	RunE: withClient(userConvertCmdF),
-	Args: cobra.MinimumNArgs(1),
+	ValidArgsFunction: validArgs(completeUsers),
+	RunE:              withClient(userConvertCmdF),
+	Args:              cobra.MinimumNArgs(1),
 }
 
 var MigrateAuthCmd = &cobra.Command{
@@ -270,6 +284,13 @@ func init() {
 	_ = UserCreateCmd.Flags().MarkDeprecated("email_verified", "please use email-verified instead")
 	UserCreateCmd.Flags().Bool("disable-welcome-email", false, "Optional. If supplied, the new user will not receive a welcome email. Defaults to false")
 
+	addFromFileFlags(ResetUserMfaCmd)
+	addFromFileFlags(DeleteUsersCmd)
+	addFromFileFlags(SearchUserCmd)
+	addBulkFlags(UserActivateCmd)
+	addBulkFlags(UserDeactivateCmd)
+	addBulkFlags(VerifyUserEmailWithoutTokenCmd)
+
 	DeleteUsersCmd.Flags().Bool("confirm", false, "Confirm you really want to delete the user and a DB backup has been performed")
 	DeleteAllUsersCmd.Flags().Bool("confirm", false, "Confirm you really want to delete the user and a DB backup has been performed")
 
@@ -359,30 +380,39 @@ Global Flags:
 	RootCmd.AddCommand(UserCmd)
 }
 
-func userActivateCmdF(c client.Client, command *cobra.Command, args []string) error {
-	return changeUsersActiveStatus(c, args, true)
+func userActivateCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	return changeUsersActiveStatus(c, cmd, args, true)
 }
 
-func changeUsersActiveStatus(c client.Client, userArgs []string, active bool) error {
-	var multiErr *multierror.Error
-	users, err := getUsersFromArgs(c, userArgs)
+func changeUsersActiveStatus(c client.Client, cmd *cobra.Command, userArgs []string, active bool) error {
+	targets, err := fileArgs(cmd, userArgs)
 	if err != nil {
-		printer.PrintError(err.Error())
-		multiErr = multierror.Append(multiErr, err)
+		return err
 	}
-	for _, user := range users {
-		if err := changeUserActiveStatus(c, user, active); err != nil {
-			printer.PrintError(err.Error())
-			multiErr = multierror.Append(multiErr, err)
+
+	report, err := runBulk(cmd, "users", targets, func(target string) (func(), error) {
+		user, err := getUserFromArg(c, target)
+		if err != nil {
+			return func() { printer.PrintError(err.Error()) }, err
 		}
+
+		err = changeUserActiveStatus(c, user, active)
+		return func() {
+			if !active && user.IsSSOUser() {
+				printer.Print("You must also deactivate user " + user.Id + " in the SSO provider or they will be reactivated on next login or sync.")
+			}
+			if err != nil {
+				printer.PrintError(err.Error())
+			}
+		}, err
+	})
+	if err != nil {
+		return err
 	}
-	return multiErr.ErrorOrNil()
+	return report.errors()
 }
 
 func changeUserActiveStatus(c client.Client, user *model.User, activate bool) error {
-	if !activate && user.IsSSOUser() {
-		printer.Print("You must also deactivate user " + user.Id + " in the SSO provider or they will be reactivated on next login or sync.")
-	}
 	if _, err := c.UpdateUserActive(user.Id, activate); err != nil {
 		return fmt.Errorf("unable to change activation status of user: %v", user.Id)
 	}
@@ -391,7 +421,7 @@ func changeUserActiveStatus(c client.Client, user *model.User, activate bool) er
 }
 
 func userDeactivateCmdF(c client.Client, cmd *cobra.Command, args []string) error {
-	return changeUsersActiveStatus(c, args, false)
+	return changeUsersActiveStatus(c, cmd, args, false)
 }
 
 func userCreateCmdF(c client.Client, cmd *cobra.Command, args []string) error {
@@ -615,13 +645,17 @@ func changePasswordUserCmdF(c client.Client, cmd *cobra.Command, args []string)
 	return nil
 }
 
-func resetUserMfaCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+func resetUserMfaCmdF(c client.Client, cmd *cobra.Command, userArgs []string) error {
+	args, err := fileArgs(cmd, userArgs)
+	if err != nil {
+		return err
+	}
 	if len(args) < 1 {
 		return errors.New("expected at least one argument. See help text for details")
 	}
 
 	var result *multierror.Error
-	users, err := getUsersFromArgs(c, args)
+	users, err := getUsersFromFileArgs(c, args)
 	if err != nil {
 		result = multierror.Append(result, err)
 	}
@@ -643,15 +677,16 @@ func deleteUsersCmdF(c client.Client, cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	users, err := getUsersFromArgs(c, args)
+	userArgs, err := fileArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	users, err := getUsersFromFileArgs(c, userArgs)
 	if err != nil {
 		printer.PrintError(err.Error())
 	}
-	for i, user := range users {
-		if user == nil {
-			printer.PrintError("Unable to find user '" + args[i] + "'")
-			continue
-		}
+	for _, user := range users {
 		if res, err := c.PermanentDeleteUser(user.Id); err != nil {
 			printer.PrintError("Unable to delete user '" + user.Username + "' error: " + err.Error())
 		} else {
@@ -682,14 +717,18 @@ func deleteAllUsersCmdF(c client.Client, cmd *cobra.Command, args []string) erro
 	return nil
 }
 
-func searchUserCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+func searchUserCmdF(c client.Client, cmd *cobra.Command, userArgs []string) error {
 	printer.SetSingle(true)
 
+	args, err := fileArgs(cmd, userArgs)
+	if err != nil {
+		return err
+	}
 	if len(args) < 1 {
 		return errors.New("expected at least one argument. See help text for details")
 	}
 
-	users, err := getUsersFromArgs(c, args)
+	users, err := getUsersFromFileArgs(c, args)
 	if err != nil {
 		printer.PrintError(err.Error())
 	}
@@ -777,20 +816,27 @@ func listUsersCmdF(c client.Client, command *cobra.Command, args []string) error
 }
 
 func verifyUserEmailWithoutTokenCmdF(c client.Client, cmd *cobra.Command, userArgs []string) error {
-	var result *multierror.Error
-	users, err := getUsersFromArgs(c, userArgs)
+	targets, err := fileArgs(cmd, userArgs)
 	if err != nil {
-		result = multierror.Append(result, err)
+		return err
 	}
 
-	for _, user := range users {
-		if newUser, _, err := c.VerifyUserEmailWithoutToken(user.Id); err != nil {
-			result = multierror.Append(result, fmt.Errorf("unable to verify user %s email: %w", user.Id, err))
-		} else {
-			printer.PrintT("User {{.Username}} verified", newUser)
+	report, err := runBulk(cmd, "users", targets, func(target string) (func(), error) {
+		user, err := getUserFromArg(c, target)
+		if err != nil {
+			return nil, err
+		}
+
+		newUser, _, err := c.VerifyUserEmailWithoutToken(user.Id)
+		if err != nil {
+			return nil, fmt.Errorf("unable to verify user %s email: %w", user.Id, err)
 		}
+		return func() { printer.PrintT("User {{.Username}} verified", newUser) }, nil
+	})
+	if err != nil {
+		return err
 	}
-	return result.ErrorOrNil()
+	return report.errors()
 }
 
 func userConvertCmdF(c client.Client, cmd *cobra.Command, userArgs []string) error {
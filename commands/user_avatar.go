@@ -0,0 +1,138 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+var UserAvatarCmd = &cobra.Command{
+	Use:   "avatar",
+	Short: "Management of user profile images",
+}
+
+var UserAvatarSetCmd = &cobra.Command{
+	Use:   "set [user] [image]",
+	Short: "Set the profile image of users",
+	Long: `Sets the profile image of a user from a PNG, JPEG or GIF file.
+With --dir, every image of a directory is set as the profile image of the user named like the file, e.g. john.doe.png for the user john.doe. The images are validated before being uploaded.`,
+	Example: `  user avatar set john.doe avatar.png
+  user avatar set --dir avatars/ --parallel 4`,
+	ValidArgsFunction: validArgs(completeUsers, completeNone),
+	Args:              imageSetArgs,
+	RunE:              withClient(userAvatarSetCmdF),
+}
+
+var UserAvatarGetCmd = &cobra.Command{
+	Use:   "get [user]",
+	Short: "Download the profile image of a user",
+	Long:  "Downloads the profile image of a user to a file, which defaults to the username with the png extension.",
+	Example: `  user avatar get john.doe
+  user avatar get john.doe -o avatar.png`,
+	ValidArgsFunction: validArgs(completeUsers, completeNone),
+	Args:              cobra.ExactArgs(1),
+	RunE:              withClient(userAvatarGetCmdF),
+}
+
+var UserAvatarResetCmd = &cobra.Command{
+	Use:   "reset [users]",
+	Short: "Reset the profile image of users",
+	Long:  "Replaces the profile image of users with the default one generated by the server.",
+	Example: `  user avatar reset john.doe
+  user avatar reset --from-file users.txt`,
+	ValidArgsFunction: validArgs(completeUsers),
+	Args:              minimumArgsOrFile(0),
+	RunE:              withClient(userAvatarResetCmdF),
+}
+
+func init() {
+	addImageSetFlags(UserAvatarSetCmd, "user")
+	UserAvatarGetCmd.Flags().StringP("output", "o", "", "file where the image is saved. Defaults to the username with the png extension")
+	addBulkFlags(UserAvatarResetCmd)
+
+	UserAvatarCmd.AddCommand(
+		UserAvatarSetCmd,
+		UserAvatarGetCmd,
+		UserAvatarResetCmd,
+	)
+	UserCmd.AddCommand(UserAvatarCmd)
+}
+
+func userAvatarSetCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	targets, users, err := imageSetTargets(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	report, err := runBulk(cmd, "images", targets, func(path string) (func(), error) {
+		data, err := readImageFile(path)
+		if err != nil {
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		user, err := getUserFromArg(c, users[path])
+		if err != nil {
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		if response, err := c.SetProfileImage(user.Id, data); err != nil {
+			err = fmt.Errorf("could not set the profile image of user %s: %w", user.Username, ExtractErrorFromResponse(response, err))
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		return func() { printer.Print("Profile image of user " + user.Username + " set from " + path) }, nil
+	})
+	if err != nil {
+		return err
+	}
+	return report.errors()
+}
+
+func userAvatarGetCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	user, err := getUserFromArg(c, args[0])
+	if err != nil {
+		return err
+	}
+
+	data, response, err := c.GetProfileImage(user.Id, "")
+	if err != nil {
+		return fmt.Errorf("could not get the profile image of user %s: %w", user.Username, ExtractErrorFromResponse(response, err))
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		output = user.Username + ".png"
+	}
+	if err := os.WriteFile(output, data, 0600); err != nil {
+		return fmt.Errorf("could not save the profile image: %w", err)
+	}
+	printer.Print("Profile image of user " + user.Username + " saved to " + output)
+	return nil
+}
+
+func userAvatarResetCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	targets, err := fileArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	report, err := runBulk(cmd, "users", targets, func(target string) (func(), error) {
+		user, err := getUserFromArg(c, target)
+		if err != nil {
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		if response, err := c.SetDefaultProfileImage(user.Id); err != nil {
+			err = fmt.Errorf("could not reset the profile image of user %s: %w", user.Username, ExtractErrorFromResponse(response, err))
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		return func() { printer.Print("Profile image of user " + user.Username + " reset") }, nil
+	})
+	if err != nil {
+		return err
+	}
+	return report.errors()
+}
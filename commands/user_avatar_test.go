@@ -0,0 +1,146 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestUserAvatarSetCmd() {
+	var output bytes.Buffer
+	bulkOutput = &output
+	s.T().Cleanup(func() { bulkOutput = os.Stderr })
+
+	dir, err := ioutil.TempDir("", "mmctl-avatars-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	alice := &model.User{Id: model.NewId(), Username: "alice"}
+	bob := &model.User{Id: model.NewId(), Username: "bob"}
+
+	s.Run("should set the profile image of a user", func() {
+		printer.Clean()
+		path := filepath.Join(dir, "avatar.png")
+		data := s.writeTestPNG(path, 32, 32)
+
+		s.client.
+			EXPECT().
+			GetUserByEmail("alice", "").
+			Return(nil, &model.Response{StatusCode: http.StatusNotFound}, errors.New("not found")).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetUserByUsername("alice", "").
+			Return(alice, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			SetProfileImage(alice.Id, data).
+			Return(&model.Response{}, nil).
+			Times(1)
+
+		cmd := &cobra.Command{}
+		addImageSetFlags(cmd, "user")
+		err := userAvatarSetCmdF(s.client, cmd, []string{"alice", path})
+		s.Require().NoError(err)
+		s.Require().Equal([]interface{}{"Profile image of user alice set from " + path}, printer.GetLines())
+	})
+
+	s.Run("should set the profile images of a directory", func() {
+		printer.Clean()
+		output.Reset()
+		avatars := filepath.Join(dir, "avatars")
+		s.Require().NoError(os.Mkdir(avatars, 0700))
+		data := s.writeTestPNG(filepath.Join(avatars, "bob.png"), 32, 32)
+		s.Require().NoError(ioutil.WriteFile(filepath.Join(avatars, "alice.jpg"), []byte("not an image"), 0600))
+
+		s.client.
+			EXPECT().
+			GetUserByEmail("bob", "").
+			Return(nil, &model.Response{StatusCode: http.StatusNotFound}, errors.New("not found")).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetUserByUsername("bob", "").
+			Return(bob, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			SetProfileImage(bob.Id, data).
+			Return(&model.Response{}, nil).
+			Times(1)
+
+		cmd := &cobra.Command{}
+		addImageSetFlags(cmd, "user")
+		s.Require().NoError(cmd.Flags().Set("dir", avatars))
+		s.Require().NoError(imageSetArgs(cmd, nil))
+		s.Require().Error(imageSetArgs(cmd, []string{"bob", "bob.png"}))
+
+		err := userAvatarSetCmdF(s.client, cmd, nil)
+		s.Require().Error(err)
+		s.Require().Equal(ExitCodePartialFailure, ExitCodeForError(err))
+		s.Require().Equal([]interface{}{"Profile image of user bob set from " + filepath.Join(avatars, "bob.png")}, printer.GetLines())
+		s.Require().Equal([]interface{}{filepath.Join(avatars, "alice.jpg") + " is not a PNG, JPEG or GIF image"}, printer.GetErrorLines())
+		s.Require().Empty(output.String())
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestUserAvatarGetCmd() {
+	dir, err := ioutil.TempDir("", "mmctl-avatars-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	printer.Clean()
+	user := &model.User{Id: model.NewId(), Username: "alice", Email: "alice@example.com"}
+	s.client.
+		EXPECT().
+		GetUserByEmail(user.Email, "").
+		Return(user, &model.Response{}, nil).
+		Times(1)
+	s.client.
+		EXPECT().
+		GetProfileImage(user.Id, "").
+		Return([]byte("image"), &model.Response{}, nil).
+		Times(1)
+
+	path := filepath.Join(dir, "out.png")
+	cmd := &cobra.Command{}
+	cmd.Flags().StringP("output", "o", path, "")
+	err = userAvatarGetCmdF(s.client, cmd, []string{user.Email})
+	s.Require().NoError(err)
+	s.Require().Equal([]interface{}{"Profile image of user alice saved to " + path}, printer.GetLines())
+
+	b, err := ioutil.ReadFile(path)
+	s.Require().NoError(err)
+	s.Require().Equal("image", string(b))
+}
+
+func (s *MmctlUnitTestSuite) TestUserAvatarResetCmd() {
+	printer.Clean()
+	user := &model.User{Id: model.NewId(), Username: "alice", Email: "alice@example.com"}
+	s.client.
+		EXPECT().
+		GetUserByEmail(user.Email, "").
+		Return(user, &model.Response{}, nil).
+		Times(1)
+	s.client.
+		EXPECT().
+		SetDefaultProfileImage(user.Id).
+		Return(&model.Response{}, nil).
+		Times(1)
+
+	err := userAvatarResetCmdF(s.client, &cobra.Command{}, []string{user.Email})
+	s.Require().NoError(err)
+	s.Require().Equal([]interface{}{"Profile image of user alice reset"}, printer.GetLines())
+}
@@ -61,7 +61,7 @@ func (s *MmctlE2ETestSuite) TestUserActivateCmd() {
 		s.Require().Error(err)
 		s.Require().Len(printer.GetLines(), 0)
 		s.Require().Len(printer.GetErrorLines(), 1)
-		s.Require().Equal("1 error occurred:\n\t* user nonexistent@email not found\n\n", printer.GetErrorLines()[0])
+		s.Require().Equal("user nonexistent@email not found", printer.GetErrorLines()[0])
 	})
 }
 
@@ -111,7 +111,7 @@ func (s *MmctlE2ETestSuite) TestUserDeactivateCmd() {
 		s.Require().Error(err)
 		s.Require().Len(printer.GetLines(), 0)
 		s.Require().Len(printer.GetErrorLines(), 1)
-		s.Require().Equal("1 error occurred:\n\t* user nonexistent@email not found\n\n", printer.GetErrorLines()[0])
+		s.Require().Equal("user nonexistent@email not found", printer.GetErrorLines()[0])
 	})
 }
 
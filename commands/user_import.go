@@ -0,0 +1,684 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/utils"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+const (
+	userCSVColumnUsername    = "username"
+	userCSVColumnEmail       = "email"
+	userCSVColumnFirstName   = "first_name"
+	userCSVColumnLastName    = "last_name"
+	userCSVColumnNickname    = "nickname"
+	userCSVColumnLocale      = "locale"
+	userCSVColumnAuthService = "auth_service"
+	userCSVColumnAuthData    = "auth_data"
+	userCSVColumnRoles       = "roles"
+	userCSVColumnTeams       = "teams"
+	userCSVColumnChannels    = "channels"
+	userCSVColumnGuest       = "guest"
+	userCSVColumnPassword    = "password"
+
+	UserImportActionCreate    = "create"
+	UserImportActionUpdate    = "update"
+	UserImportActionUnchanged = "unchanged"
+	UserImportActionFailed    = "failed"
+)
+
+var userCSVColumns = []string{
+	userCSVColumnUsername,
+	userCSVColumnEmail,
+	userCSVColumnFirstName,
+	userCSVColumnLastName,
+	userCSVColumnNickname,
+	userCSVColumnLocale,
+	userCSVColumnAuthService,
+	userCSVColumnAuthData,
+	userCSVColumnRoles,
+	userCSVColumnTeams,
+	userCSVColumnChannels,
+	userCSVColumnGuest,
+	userCSVColumnPassword,
+}
+
+var userCSVAuthServices = []string{
+	model.UserAuthServiceEmail,
+	model.UserAuthServiceGitlab,
+	model.UserAuthServiceLdap,
+	model.UserAuthServiceSaml,
+	model.ServiceGoogle,
+	model.ServiceOffice365,
+	model.ServiceOpenid,
+}
+
+var UserImportCSVCmd = &cobra.Command{
+	Use:   "import-csv [file]",
+	Short: "Create or update users from a CSV file",
+	Long: `Creates the users of a CSV file that don't exist and updates the ones that do, matching them by username, and adds them to the teams and channels of each row. A result is printed for every row, and the rows that fail don't stop the import of the rest.
+
+The first row of the file is a header with the names of the columns. Only the username and email columns are required:
+
+  username      username of the user
+  email         email of the user
+  first_name    first name
+  last_name     last name
+  nickname      nickname
+  locale        locale, e.g. en or fr
+  auth_service  authentication service [email, gitlab, ldap, saml, google, office365, openid]. Defaults to email
+  auth_data     identifier of the user in the authentication service, required if it isn't email
+  roles         system roles separated by spaces or semicolons, e.g. "system_admin". The system_user role is always kept
+  teams         teams to add the user to, separated by spaces or semicolons
+  channels      channels to add the user to, in the team:channel format, separated by spaces or semicolons. The user is also added to their teams
+  guest         true to make the user a guest, false to make a guest a regular user
+  password      password of the new users. If it is empty, new users that log in with email get a random password and a password reset email
+
+Empty cells leave the value of the existing users unchanged, and the password and authentication columns are only used to create users.`,
+	Example: `  user import-csv users.csv
+  user import-csv users.csv --dry-run
+  cat users.csv | user import-csv -`,
+	Args: cobra.ExactArgs(1),
+	RunE: withClient(userImportCSVCmdF),
+}
+
+func init() {
+	UserImportCSVCmd.Flags().Bool("dry-run", false, "validates the file and prints the changes of every row without applying them")
+
+	UserCmd.AddCommand(UserImportCSVCmd)
+}
+
+// UserImportResult is the outcome of the import of a row of the CSV file
+type UserImportResult struct {
+	Line        int      `json:"line"`
+	Username    string   `json:"username"`
+	Action      string   `json:"action"`
+	Changes     []string `json:"changes,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	Description string   `json:"description"`
+}
+
+// userCSVRow contains the values of a row of the CSV file. Empty values
+// are not managed
+type userCSVRow struct {
+	line        int
+	username    string
+	email       string
+	firstName   string
+	lastName    string
+	nickname    string
+	locale      string
+	authService string
+	authData    string
+	roles       []string
+	teams       []string
+	channels    []string
+	guest       *bool
+	password    string
+	// err is the validation error of the row
+	err error
+}
+
+func sameStringSet(a, b []string) bool {
+	a = utils.RemoveDuplicatesFromStringArray(a)
+	b = utils.RemoveDuplicatesFromStringArray(b)
+	if len(a) != len(b) {
+		return false
+	}
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitUserCSVList splits the lists of the cells, which can be
+// separated by spaces or semicolons
+func splitUserCSVList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == ' ' || r == '\t'
+	})
+}
+
+func readUserCSV(r io.Reader, source string) ([]*userCSVRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: the file is empty", source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid CSV file: %w", source, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !utils.StringInSlice(name, userCSVColumns) {
+			return nil, fmt.Errorf("%s: unknown column %q, must be one of: %s", source, name, strings.Join(userCSVColumns, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("%s: duplicated column %q", source, name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{userCSVColumnUsername, userCSVColumnEmail} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%s: the %q column is required", source, name)
+		}
+	}
+
+	var rows []*userCSVRow
+	usernames := map[string]int{}
+	emails := map[string]int{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid CSV file: %w", source, err)
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		line, _ := reader.FieldPos(0)
+		row := &userCSVRow{
+			line:        line,
+			username:    strings.ToLower(value(userCSVColumnUsername)),
+			email:       strings.ToLower(value(userCSVColumnEmail)),
+			firstName:   value(userCSVColumnFirstName),
+			lastName:    value(userCSVColumnLastName),
+			nickname:    value(userCSVColumnNickname),
+			locale:      value(userCSVColumnLocale),
+			authService: value(userCSVColumnAuthService),
+			authData:    value(userCSVColumnAuthData),
+			roles:       splitUserCSVList(value(userCSVColumnRoles)),
+			teams:       splitUserCSVList(value(userCSVColumnTeams)),
+			channels:    splitUserCSVList(value(userCSVColumnChannels)),
+			password:    value(userCSVColumnPassword),
+		}
+		// the roles replace the current ones, so the system_user
+		// role is always kept as user create does
+		if len(row.roles) > 0 && !utils.StringInSlice(model.SystemUserRoleId, row.roles) {
+			row.roles = append([]string{model.SystemUserRoleId}, row.roles...)
+		}
+		if guest := value(userCSVColumnGuest); guest != "" {
+			b, err := strconv.ParseBool(guest)
+			if err != nil {
+				row.err = fmt.Errorf("invalid guest value %q", guest)
+			}
+			row.guest = &b
+		}
+		if row.err == nil {
+			row.err = row.validate()
+		}
+		if row.err == nil {
+			if previous, ok := usernames[row.username]; ok {
+				row.err = fmt.Errorf("the username is also used in line %d", previous)
+			} else if previous, ok := emails[row.email]; ok {
+				row.err = fmt.Errorf("the email is also used in line %d", previous)
+			}
+		}
+		if _, ok := usernames[row.username]; !ok {
+			usernames[row.username] = row.line
+		}
+		if _, ok := emails[row.email]; !ok {
+			emails[row.email] = row.line
+		}
+
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (r *userCSVRow) validate() error {
+	if !model.IsValidUsername(r.username) {
+		return fmt.Errorf("invalid username %q", r.username)
+	}
+	if !model.IsValidEmail(r.email) {
+		return fmt.Errorf("invalid email %q", r.email)
+	}
+	if !model.IsValidLocale(r.locale) {
+		return fmt.Errorf("invalid locale %q", r.locale)
+	}
+	if r.authService != "" && !utils.StringInSlice(r.authService, userCSVAuthServices) {
+		return fmt.Errorf("invalid auth service %q, must be one of: %s", r.authService, strings.Join(userCSVAuthServices, ", "))
+	}
+	if r.authService != "" && r.authService != model.UserAuthServiceEmail && r.authData == "" {
+		return fmt.Errorf("the auth data is required for the %q auth service", r.authService)
+	}
+	if len(r.roles) > 0 {
+		if utils.StringInSlice(model.SystemGuestRoleId, r.roles) {
+			return fmt.Errorf("the %q role can't be set, use the guest column instead", model.SystemGuestRoleId)
+		}
+		if !model.IsValidUserRoles(strings.Join(r.roles, " ")) {
+			return fmt.Errorf("invalid roles %q", strings.Join(r.roles, " "))
+		}
+		if r.guest != nil && *r.guest {
+			return errors.New("guests can't have system roles")
+		}
+	}
+	for _, channel := range r.channels {
+		if teamArg, _ := parseChannelArg(channel); teamArg == "" {
+			return fmt.Errorf("invalid channel %q, must be in the team:channel format", channel)
+		}
+	}
+	return nil
+}
+
+// userImporter resolves the teams and channels of the rows once, and
+// plans and applies the changes of every row
+type userImporter struct {
+	c        client.Client
+	dryRun   bool
+	teams    map[string]*model.Team
+	channels map[string]*model.Channel
+}
+
+func (im *userImporter) getTeam(teamArg string) (*model.Team, error) {
+	if team, ok := im.teams[teamArg]; ok {
+		return team, nil
+	}
+	team, err := getTeamFromArg(im.c, teamArg)
+	if err != nil {
+		return nil, err
+	}
+	im.teams[teamArg] = team
+	return team, nil
+}
+
+func (im *userImporter) getChannel(channelArg string) (*model.Channel, error) {
+	if channel, ok := im.channels[channelArg]; ok {
+		return channel, nil
+	}
+	// the rows are validated to use the team:channel format, so the
+	// team is resolved through the cache instead of once per channel
+	teamArg, name := parseChannelArg(channelArg)
+	team, err := im.getTeam(teamArg)
+	if err != nil {
+		return nil, err
+	}
+	channel, response, err := im.c.GetChannelByNameIncludeDeleted(name, team.Id, "")
+	if err != nil {
+		err = ExtractErrorFromResponse(response, err)
+		var nfErr *NotFoundError
+		if errors.As(err, &nfErr) {
+			return nil, ErrEntityNotFound{Type: "channel", ID: channelArg}
+		}
+		return nil, err
+	}
+	im.channels[channelArg] = channel
+	return channel, nil
+}
+
+// findUser returns the user with the username of the row, or nil if it
+// doesn't exist, checking that the email of the row isn't used by
+// another user
+func (im *userImporter) findUser(row *userCSVRow) (*model.User, error) {
+	isNotFound := func(response *model.Response, err error) bool {
+		var nfErr *NotFoundError
+		return errors.As(ExtractErrorFromResponse(response, err), &nfErr)
+	}
+
+	user, response, err := im.c.GetUserByUsername(row.username, "")
+	if err != nil {
+		if !isNotFound(response, err) {
+			return nil, ExtractErrorFromResponse(response, err)
+		}
+		user = nil
+	}
+	if user != nil && user.Email == row.email {
+		return user, nil
+	}
+
+	other, response, err := im.c.GetUserByEmail(row.email, "")
+	if err != nil {
+		if !isNotFound(response, err) {
+			return nil, ExtractErrorFromResponse(response, err)
+		}
+		return user, nil
+	}
+	if user == nil || other.Id != user.Id {
+		return nil, fmt.Errorf("the email %q is used by the user %q", row.email, other.Username)
+	}
+	return user, nil
+}
+
+// userImportStep is a change of a row, with its description
+type userImportStep struct {
+	description string
+	apply       func(user *model.User) error
+}
+
+// plan returns the changes needed for the row. The user is nil if it
+// doesn't exist
+func (im *userImporter) plan(row *userCSVRow, user *model.User) ([]*userImportStep, error) {
+	var steps []*userImportStep
+
+	if user == nil {
+		steps = append(steps, im.planCreate(row))
+	} else {
+		if row.authService != "" && row.authService != user.AuthService && !(row.authService == model.UserAuthServiceEmail && user.AuthService == "") {
+			return nil, fmt.Errorf("the auth service of existing users can't be changed from %q to %q", user.AuthService, row.authService)
+		}
+		if step := im.planUpdate(row, user); step != nil {
+			steps = append(steps, step)
+		}
+	}
+
+	wasGuest := user != nil && user.IsGuest()
+	if row.guest != nil && *row.guest && !wasGuest {
+		steps = append(steps, &userImportStep{
+			description: "demote to guest",
+			apply: func(user *model.User) error {
+				_, err := im.c.DemoteUserToGuest(user.Id)
+				return err
+			},
+		})
+	}
+	if row.guest != nil && !*row.guest && wasGuest {
+		steps = append(steps, &userImportStep{
+			description: "promote to user",
+			apply: func(user *model.User) error {
+				_, err := im.c.PromoteGuestToUser(user.Id)
+				return err
+			},
+		})
+	}
+
+	if len(row.roles) > 0 {
+		roles := strings.Join(row.roles, " ")
+		current := []string{model.SystemUserRoleId}
+		if user != nil {
+			current = strings.Fields(user.Roles)
+		}
+		if !sameStringSet(current, row.roles) {
+			steps = append(steps, &userImportStep{
+				description: fmt.Sprintf("set roles %q", roles),
+				apply: func(user *model.User) error {
+					_, err := im.c.UpdateUserRoles(user.Id, roles)
+					return err
+				},
+			})
+		}
+	}
+
+	teamArgs := append([]string{}, row.teams...)
+	for _, channelArg := range row.channels {
+		if teamArg, _ := parseChannelArg(channelArg); !utils.StringInSlice(teamArg, teamArgs) {
+			teamArgs = append(teamArgs, teamArg)
+		}
+	}
+	// the memberships of the existing users are checked, so importing
+	// the same file again doesn't change anything
+	teamMember := map[string]bool{}
+	if user != nil && len(teamArgs) > 0 {
+		teams, response, err := im.c.GetTeamsForUser(user.Id, "")
+		if err != nil {
+			return nil, ExtractErrorFromResponse(response, err)
+		}
+		for _, team := range teams {
+			teamMember[team.Id] = true
+		}
+	}
+
+	for _, teamArg := range teamArgs {
+		team, err := im.getTeam(teamArg)
+		if err != nil {
+			return nil, err
+		}
+		if teamMember[team.Id] {
+			continue
+		}
+		steps = append(steps, &userImportStep{
+			description: fmt.Sprintf("add to team %q", team.Name),
+			apply: func(user *model.User) error {
+				_, _, err := im.c.AddTeamMember(team.Id, user.Id)
+				return err
+			},
+		})
+	}
+	channelMember := map[string]bool{}
+	loadedTeams := map[string]bool{}
+	for _, channelArg := range row.channels {
+		channel, err := im.getChannel(channelArg)
+		if err != nil {
+			return nil, err
+		}
+		if teamMember[channel.TeamId] && !loadedTeams[channel.TeamId] {
+			channels, response, err := im.c.GetChannelsForTeamForUser(channel.TeamId, user.Id, false, "")
+			if err != nil {
+				return nil, ExtractErrorFromResponse(response, err)
+			}
+			for _, c := range channels {
+				channelMember[c.Id] = true
+			}
+			loadedTeams[channel.TeamId] = true
+		}
+		if channelMember[channel.Id] {
+			continue
+		}
+		steps = append(steps, &userImportStep{
+			description: fmt.Sprintf("add to channel %q", channelArg),
+			apply: func(user *model.User) error {
+				_, _, err := im.c.AddChannelMember(channel.Id, user.Id)
+				return err
+			},
+		})
+	}
+
+	if user == nil && row.password == "" && (row.authService == "" || row.authService == model.UserAuthServiceEmail) {
+		steps = append(steps, &userImportStep{
+			description: "send password reset email",
+			apply: func(user *model.User) error {
+				_, err := im.c.SendPasswordResetEmail(user.Email)
+				return err
+			},
+		})
+	}
+
+	return steps, nil
+}
+
+func (im *userImporter) planCreate(row *userCSVRow) *userImportStep {
+	return &userImportStep{
+		description: "create user",
+		apply: func(user *model.User) error {
+			user.Username = row.username
+			user.Email = row.email
+			user.FirstName = row.firstName
+			user.LastName = row.lastName
+			user.Nickname = row.nickname
+			user.Locale = row.locale
+			user.Password = row.password
+			if row.authService != "" && row.authService != model.UserAuthServiceEmail {
+				user.AuthService = row.authService
+				user.AuthData = model.NewString(row.authData)
+				user.Password = ""
+			} else if user.Password == "" {
+				// the user sets the password with the reset email
+				user.Password = model.NewId() + "Aa1!"
+			}
+
+			created, _, err := im.c.CreateUser(user)
+			if err != nil {
+				return err
+			}
+			*user = *created
+			return nil
+		},
+	}
+}
+
+func (im *userImporter) planUpdate(row *userCSVRow, user *model.User) *userImportStep {
+	updated := user.DeepCopy()
+	var changes []string
+	for _, field := range []struct {
+		name    string
+		current *string
+		value   string
+	}{
+		{userCSVColumnEmail, &updated.Email, row.email},
+		{userCSVColumnFirstName, &updated.FirstName, row.firstName},
+		{userCSVColumnLastName, &updated.LastName, row.lastName},
+		{userCSVColumnNickname, &updated.Nickname, row.nickname},
+		{userCSVColumnLocale, &updated.Locale, row.locale},
+	} {
+		if field.value == "" || field.value == *field.current {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s %q -> %q", field.name, *field.current, field.value))
+		*field.current = field.value
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return &userImportStep{
+		description: "update " + strings.Join(changes, ", "),
+		apply: func(user *model.User) error {
+			saved, _, err := im.c.UpdateUser(updated)
+			if err != nil {
+				return err
+			}
+			*user = *saved
+			return nil
+		},
+	}
+}
+
+// importRow plans the changes of the row and applies them unless it
+// is a dry run. The returned error is the one of the row, if any
+func (im *userImporter) importRow(row *userCSVRow) (*UserImportResult, error) {
+	result := &UserImportResult{Line: row.line, Username: row.username}
+	fail := func(err error) (*UserImportResult, error) {
+		result.Action = UserImportActionFailed
+		result.Error = err.Error()
+		return result, err
+	}
+
+	if row.err != nil {
+		return fail(row.err)
+	}
+
+	user, err := im.findUser(row)
+	if err != nil {
+		return fail(err)
+	}
+
+	steps, err := im.plan(row, user)
+	if err != nil {
+		return fail(err)
+	}
+
+	switch {
+	case user == nil:
+		result.Action = UserImportActionCreate
+	case len(steps) > 0:
+		result.Action = UserImportActionUpdate
+	default:
+		result.Action = UserImportActionUnchanged
+	}
+
+	if user == nil {
+		user = &model.User{}
+	}
+
+	for _, step := range steps {
+		if !im.dryRun {
+			if err := step.apply(user); err != nil {
+				result.Changes = append(result.Changes, step.description+" failed")
+				return fail(fmt.Errorf("could not %s: %w", step.description, err))
+			}
+		}
+		result.Changes = append(result.Changes, step.description)
+	}
+	return result, nil
+}
+
+func (r *UserImportResult) describe(dryRun bool) {
+	verbs := map[string]string{
+		UserImportActionCreate:    "created",
+		UserImportActionUpdate:    "updated",
+		UserImportActionUnchanged: "unchanged",
+		UserImportActionFailed:    "failed",
+	}
+	if dryRun {
+		verbs[UserImportActionCreate] = "would create"
+		verbs[UserImportActionUpdate] = "would update"
+	}
+
+	r.Description = fmt.Sprintf("line %d: %s %s", r.Line, r.Username, verbs[r.Action])
+	if len(r.Changes) > 0 {
+		r.Description += ": " + strings.Join(r.Changes, ", ")
+	}
+	if r.Error != "" {
+		r.Description += ": " + r.Error
+	}
+}
+
+func userImportCSVCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	var r io.Reader = os.Stdin
+	source := "stdin"
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("could not open the CSV file: %w", err)
+		}
+		defer f.Close()
+		r = f
+		source = args[0]
+	}
+
+	rows, err := readUserCSV(r, source)
+	if err != nil {
+		return err
+	}
+
+	importer := &userImporter{
+		c:        c,
+		dryRun:   dryRun,
+		teams:    map[string]*model.Team{},
+		channels: map[string]*model.Channel{},
+	}
+	var result *multierror.Error
+	succeeded := 0
+	for _, row := range rows {
+		rowResult, err := importer.importRow(row)
+		if err != nil {
+			result = multierror.Append(result, &ArgLineError{Source: source, Line: row.line, Err: err})
+		} else {
+			succeeded++
+		}
+		rowResult.describe(dryRun)
+		printer.PrintT("{{.Description}}", rowResult)
+	}
+	return partialFailure(result, succeeded)
+}
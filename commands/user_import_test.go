@@ -0,0 +1,208 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestReadUserCSV() {
+	s.Run("should fail with invalid headers", func() {
+		_, err := readUserCSV(strings.NewReader("username,mail\n"), "users.csv")
+		s.Require().EqualError(err, `users.csv: unknown column "mail", must be one of: `+strings.Join(userCSVColumns, ", "))
+
+		_, err = readUserCSV(strings.NewReader("username,first_name\n"), "users.csv")
+		s.Require().EqualError(err, `users.csv: the "email" column is required`)
+	})
+
+	s.Run("should validate every row", func() {
+		content := `Username,Email,Roles,Teams,Channels,Guest,Auth_Service
+alice,alice@example.com,system_user system_admin,eng;sales,eng:backend,,
+bob,not-an-email,,,,,
+carol,carol@example.com,,,,maybe,
+dave,dave@example.com,system_user,,,true,
+erin,erin@example.com,,,backend,,
+frank,frank@example.com,,,,,ldap
+alice,other@example.com,,,,,
+`
+		rows, err := readUserCSV(strings.NewReader(content), "users.csv")
+		s.Require().NoError(err)
+		s.Require().Len(rows, 7)
+
+		s.Require().NoError(rows[0].err)
+		s.Require().Equal(2, rows[0].line)
+		s.Require().Equal([]string{"system_user", "system_admin"}, rows[0].roles)
+		s.Require().Equal([]string{"eng", "sales"}, rows[0].teams)
+		s.Require().Equal([]string{"eng:backend"}, rows[0].channels)
+
+		s.Require().EqualError(rows[1].err, `invalid email "not-an-email"`)
+		s.Require().EqualError(rows[2].err, `invalid guest value "maybe"`)
+		s.Require().EqualError(rows[3].err, "guests can't have system roles")
+		s.Require().EqualError(rows[4].err, `invalid channel "backend", must be in the team:channel format`)
+		s.Require().EqualError(rows[5].err, `the auth data is required for the "ldap" auth service`)
+		s.Require().EqualError(rows[6].err, "the username is also used in line 2")
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestUserImportCSVCmd() {
+	dir, err := ioutil.TempDir("", "mmctl-import-csv-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "users.csv")
+	s.Require().NoError(ioutil.WriteFile(path, []byte(`username,email,first_name,teams,channels,password
+alice,alice@example.com,Alice,,eng:backend,
+bob,bob@example.com,Bobby,eng,,
+`), 0600))
+
+	team := &model.Team{Id: model.NewId(), Name: "eng"}
+	channel := &model.Channel{Id: model.NewId(), TeamId: team.Id, Name: "backend"}
+	bob := &model.User{Id: model.NewId(), Username: "bob", Email: "bob@example.com", FirstName: "Bob", Roles: model.SystemUserRoleId}
+	notFound := func() (*model.User, *model.Response, error) {
+		return nil, &model.Response{StatusCode: http.StatusNotFound}, errors.New("not found")
+	}
+
+	expectLookups := func() {
+		s.client.
+			EXPECT().
+			GetUserByUsername("alice", "").
+			Return(notFound()).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetUserByEmail("alice@example.com", "").
+			Return(notFound()).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetUserByUsername("bob", "").
+			Return(bob, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetTeam("eng", "").
+			Return(team, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetChannelByNameIncludeDeleted("backend", team.Id, "").
+			Return(channel, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetTeamsForUser(bob.Id, "").
+			Return([]*model.Team{}, &model.Response{}, nil).
+			Times(1)
+	}
+
+	s.Run("dry run should not change anything", func() {
+		printer.Clean()
+		expectLookups()
+
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("dry-run", true, "")
+		err := userImportCSVCmdF(s.client, cmd, []string{path})
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 2)
+
+		alice := printer.GetLines()[0].(*UserImportResult)
+		s.Require().Equal(UserImportActionCreate, alice.Action)
+		s.Require().Equal([]string{"create user", `add to team "eng"`, `add to channel "eng:backend"`, "send password reset email"}, alice.Changes)
+		s.Require().Equal(`line 2: alice would create: create user, add to team "eng", add to channel "eng:backend", send password reset email`, alice.Description)
+
+		result := printer.GetLines()[1].(*UserImportResult)
+		s.Require().Equal(UserImportActionUpdate, result.Action)
+		s.Require().Equal([]string{`update first_name "Bob" -> "Bobby"`, `add to team "eng"`}, result.Changes)
+	})
+
+	s.Run("should create and update the users", func() {
+		printer.Clean()
+		expectLookups()
+
+		created := &model.User{Id: model.NewId(), Username: "alice", Email: "alice@example.com"}
+		s.client.
+			EXPECT().
+			CreateUser(gomock.Any()).
+			DoAndReturn(func(user *model.User) (*model.User, *model.Response, error) {
+				s.Require().Equal("alice", user.Username)
+				s.Require().Equal("Alice", user.FirstName)
+				s.Require().NotEmpty(user.Password)
+				return created, &model.Response{}, nil
+			}).
+			Times(1)
+		s.client.
+			EXPECT().
+			AddTeamMember(team.Id, created.Id).
+			Return(&model.TeamMember{}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			AddChannelMember(channel.Id, created.Id).
+			Return(&model.ChannelMember{}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			SendPasswordResetEmail(created.Email).
+			Return(&model.Response{}, nil).
+			Times(1)
+
+		s.client.
+			EXPECT().
+			UpdateUser(gomock.Any()).
+			DoAndReturn(func(user *model.User) (*model.User, *model.Response, error) {
+				s.Require().Equal(bob.Id, user.Id)
+				s.Require().Equal("Bobby", user.FirstName)
+				return user, &model.Response{}, nil
+			}).
+			Times(1)
+		s.client.
+			EXPECT().
+			AddTeamMember(team.Id, bob.Id).
+			Return(nil, &model.Response{StatusCode: http.StatusForbidden}, errors.New("forbidden")).
+			Times(1)
+
+		err := userImportCSVCmdF(s.client, &cobra.Command{}, []string{path})
+		s.Require().EqualError(err, "1 error occurred:\n\t* "+path+`:3: could not add to team "eng": forbidden`+"\n\n")
+		s.Require().Equal(ExitCodePartialFailure, ExitCodeForError(err))
+
+		s.Require().Len(printer.GetLines(), 2)
+		s.Require().Equal(UserImportActionCreate, printer.GetLines()[0].(*UserImportResult).Action)
+		result := printer.GetLines()[1].(*UserImportResult)
+		s.Require().Equal(UserImportActionFailed, result.Action)
+		s.Require().Equal([]string{`update first_name "Bob" -> "Bobby"`, `add to team "eng" failed`}, result.Changes)
+	})
+
+	s.Run("should keep the system_user role when setting the roles", func() {
+		printer.Clean()
+		rolesPath := filepath.Join(dir, "roles.csv")
+		s.Require().NoError(ioutil.WriteFile(rolesPath, []byte("username,email,roles\nbob,bob@example.com,system_admin\n"), 0600))
+
+		s.client.
+			EXPECT().
+			GetUserByUsername("bob", "").
+			Return(bob, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			UpdateUserRoles(bob.Id, "system_user system_admin").
+			Return(&model.Response{}, nil).
+			Times(1)
+
+		err := userImportCSVCmdF(s.client, &cobra.Command{}, []string{rolesPath})
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Require().Equal([]string{`set roles "system_user system_admin"`}, printer.GetLines()[0].(*UserImportResult).Changes)
+	})
+}
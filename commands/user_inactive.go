@@ -0,0 +1,317 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+const (
+	InactiveUserActionDeactivated     = "deactivated"
+	InactiveUserActionWouldDeactivate = "would deactivate"
+	InactiveUserActionFailed          = "failed"
+
+	inactiveUsersPerPage = 200
+	// userAuditsPerPage is the number of audits checked to find the
+	// last login, which are returned from the newest to the oldest
+	userAuditsPerPage = 200
+	userPostsPerPage  = 20
+	loginAuditAction  = "/users/login"
+)
+
+var UserInactiveCmd = &cobra.Command{
+	Use:   "inactive --since [duration]",
+	Short: "List and deactivate inactive users",
+	Long: `Lists the active users that haven't logged in, posted or used a session since the given time, printing their last activity. Bots, system admins and the excluded users are never listed.
+
+The last login is read from the audits of the user, the last session from their active sessions and the last post from a search in their teams, which only includes the channels visible to the user running the command. Users that were never active are considered inactive since they were created.
+
+With --deactivate the listed users are deactivated, and their ids are written to an undo file that can be passed to "user activate --from-file" to reactivate exactly those users.`,
+	Example: `  user inactive --since 90d
+  user inactive --since 12w --exclude alice,bob --format csv
+  user inactive --since 90d --exclude-file allowlist.txt --deactivate --dry-run
+  user inactive --since 90d --deactivate --undo-file undo.txt
+  user activate --from-file undo.txt`,
+	Args: cobra.NoArgs,
+	RunE: withClient(userInactiveCmdF),
+}
+
+func init() {
+	UserInactiveCmd.Flags().String("since", "", "users without activity for this long are inactive, e.g. 90d, 12w or 720h")
+	_ = UserInactiveCmd.MarkFlagRequired("since")
+	UserInactiveCmd.Flags().StringSlice("exclude", nil, "comma separated list of users that are never listed, as emails, usernames or ids")
+	UserInactiveCmd.Flags().String("exclude-file", "", "file with the users that are never listed, as a list, CSV or JSON file like the ones of --from-file")
+	UserInactiveCmd.Flags().Bool("deactivate", false, "deactivates the inactive users")
+	UserInactiveCmd.Flags().Bool("dry-run", false, "prints the users that --deactivate would deactivate without deactivating them")
+	UserInactiveCmd.Flags().String("undo-file", "", "file where the ids of the deactivated users are written. Required with --deactivate")
+
+	UserCmd.AddCommand(UserInactiveCmd)
+}
+
+// InactiveUser is a user without activity since the cutoff time. The
+// times are empty if the activity was never registered
+type InactiveUser struct {
+	ID           string `json:"id"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	LastLogin    string `json:"last_login"`
+	LastPost     string `json:"last_post"`
+	LastSession  string `json:"last_session"`
+	LastActivity string `json:"last_activity"`
+	InactiveDays int    `json:"inactive_days"`
+	Action       string `json:"action,omitempty"`
+}
+
+const inactiveUserTemplate = `{{if .Action}}{{.Action}}: {{end}}{{.Username}} ({{.Email}}) inactive for {{.InactiveDays}} days, last login: {{or .LastLogin "never"}}, last post: {{or .LastPost "never"}}, last session: {{or .LastSession "never"}}`
+
+// userActivity holds the times in milliseconds of the last activity of
+// a user, zero if it was never registered
+type userActivity struct {
+	lastLogin   int64
+	lastPost    int64
+	lastSession int64
+}
+
+func (a userActivity) last() int64 {
+	last := a.lastLogin
+	if a.lastPost > last {
+		last = a.lastPost
+	}
+	if a.lastSession > last {
+		last = a.lastSession
+	}
+	return last
+}
+
+// parseInactivityDuration parses a duration that, in addition to the
+// units of time.ParseDuration, can be expressed in days or weeks
+func parseInactivityDuration(value string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	switch {
+	case strings.HasSuffix(value, "d"), strings.HasSuffix(value, "w"):
+		unit := 24 * time.Hour
+		if strings.HasSuffix(value, "w") {
+			unit *= 7
+		}
+		var n int
+		n, err = strconv.Atoi(strings.TrimRight(value, "dw"))
+		d = time.Duration(n) * unit
+	default:
+		d, err = time.ParseDuration(value)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q, must be a positive number of days (90d), weeks (12w) or a duration like 720h", value)
+	}
+	return d, nil
+}
+
+func formatActivityTime(millis int64) string {
+	if millis == 0 {
+		return ""
+	}
+	return time.UnixMilli(millis).UTC().Format(time.RFC3339)
+}
+
+// getUserActivity returns the last activity of the user. The posts are
+// only searched if the user has no login or session after the cutoff,
+// as that already makes the user active
+func getUserActivity(c client.Client, user *model.User, cutoff int64) (userActivity, error) {
+	var activity userActivity
+
+	sessions, response, err := c.GetSessions(user.Id, "")
+	if err != nil {
+		return activity, fmt.Errorf("could not get the sessions of user %s: %w", user.Username, ExtractErrorFromResponse(response, err))
+	}
+	for _, session := range sessions {
+		if session.LastActivityAt > activity.lastSession {
+			activity.lastSession = session.LastActivityAt
+		}
+	}
+
+	audits, response, err := c.GetUserAudits(user.Id, 0, userAuditsPerPage, "")
+	if err != nil {
+		return activity, fmt.Errorf("could not get the audits of user %s: %w", user.Username, ExtractErrorFromResponse(response, err))
+	}
+	for _, audit := range audits {
+		if strings.HasSuffix(audit.Action, loginAuditAction) && strings.HasPrefix(audit.ExtraInfo, "success") && audit.CreateAt > activity.lastLogin {
+			activity.lastLogin = audit.CreateAt
+		}
+	}
+
+	if activity.last() >= cutoff {
+		return activity, nil
+	}
+
+	teams, response, err := c.GetTeamsForUser(user.Id, "")
+	if err != nil {
+		return activity, fmt.Errorf("could not get the teams of user %s: %w", user.Username, ExtractErrorFromResponse(response, err))
+	}
+	terms := "from:" + user.Username
+	includeDeleted := true
+	page, perPage := 0, userPostsPerPage
+	for _, team := range teams {
+		posts, response, err := c.SearchPostsWithParams(team.Id, &model.SearchParameter{
+			Terms:                  &terms,
+			IncludeDeletedChannels: &includeDeleted,
+			Page:                   &page,
+			PerPage:                &perPage,
+		})
+		if err != nil {
+			return activity, fmt.Errorf("could not search the posts of user %s: %w", user.Username, ExtractErrorFromResponse(response, err))
+		}
+		for _, post := range posts.Posts {
+			if post.UserId == user.Id && post.CreateAt > activity.lastPost {
+				activity.lastPost = post.CreateAt
+			}
+		}
+	}
+	return activity, nil
+}
+
+// getExcludedUsers returns the set of values of --exclude and
+// --exclude-file, which are matched against the ids, usernames and
+// emails of the users
+func getExcludedUsers(cmd *cobra.Command) (map[string]bool, error) {
+	excluded := map[string]bool{}
+	values, _ := cmd.Flags().GetStringSlice("exclude")
+	for _, value := range values {
+		excluded[strings.ToLower(strings.TrimSpace(value))] = true
+	}
+
+	path, _ := cmd.Flags().GetString("exclude-file")
+	if path == "" {
+		return excluded, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open the exclude file: %w", err)
+	}
+	defer f.Close()
+	args, err := readArgsFile(f, path, argsFileFormatAuto, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		excluded[strings.ToLower(arg.Value)] = true
+	}
+	return excluded, nil
+}
+
+func isExcludedFromInactive(user *model.User, excluded map[string]bool) bool {
+	return user.DeleteAt != 0 || user.IsBot || user.IsSystemAdmin() ||
+		excluded[user.Id] || excluded[strings.ToLower(user.Username)] || excluded[strings.ToLower(user.Email)]
+}
+
+func userInactiveCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	since, _ := cmd.Flags().GetString("since")
+	duration, err := parseInactivityDuration(since)
+	if err != nil {
+		return err
+	}
+	deactivate, _ := cmd.Flags().GetBool("deactivate")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	undoPath, _ := cmd.Flags().GetString("undo-file")
+	if dryRun && !deactivate {
+		return errors.New("the --dry-run flag can only be used with --deactivate")
+	}
+	if deactivate && !dryRun && undoPath == "" {
+		return errors.New("the --undo-file flag is required to deactivate users")
+	}
+
+	excluded, err := getExcludedUsers(cmd)
+	if err != nil {
+		return err
+	}
+
+	users, err := getPages(c.GetUsers, inactiveUsersPerPage)
+	if err != nil {
+		return fmt.Errorf("could not get the users: %w", err)
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-duration).UnixMilli()
+	var inactive []*model.User
+	var activities []userActivity
+	for _, user := range users {
+		if isExcludedFromInactive(user, excluded) || user.CreateAt >= cutoff {
+			continue
+		}
+		activity, err := getUserActivity(c, user, cutoff)
+		if err != nil {
+			return err
+		}
+		if activity.last() >= cutoff {
+			continue
+		}
+		inactive = append(inactive, user)
+		activities = append(activities, activity)
+	}
+
+	var undo *os.File
+	if deactivate && !dryRun && len(inactive) > 0 {
+		undo, err = os.OpenFile(undoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return fmt.Errorf("could not create the undo file: %w", err)
+		}
+		defer undo.Close()
+		fmt.Fprintf(undo, "# users deactivated by mmctl user inactive --since %s on %s\n", since, now.UTC().Format(time.RFC3339))
+		fmt.Fprintf(undo, "# reactivate them with: mmctl user activate --from-file %s\n", undoPath)
+	}
+
+	var result *multierror.Error
+	deactivated := 0
+	for i, user := range inactive {
+		activity := activities[i]
+		reference := activity.last()
+		if reference == 0 {
+			reference = user.CreateAt
+		}
+		inactiveUser := &InactiveUser{
+			ID:           user.Id,
+			Username:     user.Username,
+			Email:        user.Email,
+			LastLogin:    formatActivityTime(activity.lastLogin),
+			LastPost:     formatActivityTime(activity.lastPost),
+			LastSession:  formatActivityTime(activity.lastSession),
+			LastActivity: formatActivityTime(activity.last()),
+			InactiveDays: int(now.Sub(time.UnixMilli(reference)).Hours() / 24),
+		}
+
+		switch {
+		case dryRun:
+			inactiveUser.Action = InactiveUserActionWouldDeactivate
+		case deactivate:
+			if err := changeUserActiveStatus(c, user, false); err != nil {
+				inactiveUser.Action = InactiveUserActionFailed
+				result = multierror.Append(result, err)
+				printer.PrintError(err.Error())
+				break
+			}
+			inactiveUser.Action = InactiveUserActionDeactivated
+			deactivated++
+			if _, err := fmt.Fprintln(undo, user.Id); err != nil {
+				result = multierror.Append(result, fmt.Errorf("could not write user %s to the undo file: %w", user.Id, err))
+			}
+			if user.IsSSOUser() {
+				printer.PrintWarning("You must also deactivate user " + user.Id + " in the SSO provider or they will be reactivated on next login or sync.")
+			}
+		}
+		printer.PrintT(inactiveUserTemplate, inactiveUser)
+	}
+
+	return partialFailure(result, deactivated)
+}
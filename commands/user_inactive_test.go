@@ -0,0 +1,218 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestParseInactivityDuration() {
+	testCases := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"90d", 90 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"36h", 36 * time.Hour},
+	}
+	for _, tc := range testCases {
+		d, err := parseInactivityDuration(tc.value)
+		s.Require().NoError(err)
+		s.Require().Equal(tc.expected, d)
+	}
+
+	for _, value := range []string{"", "d", "-3d", "0h", "ninety"} {
+		_, err := parseInactivityDuration(value)
+		s.Require().Error(err, value)
+	}
+}
+
+func (s *MmctlUnitTestSuite) TestUserInactiveCmd() {
+	dir, err := ioutil.TempDir("", "mmctl-inactive-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	day := int64(24 * time.Hour / time.Millisecond)
+	now := model.GetMillis()
+	old := now - 400*day
+
+	admin := &model.User{Id: model.NewId(), Username: "admin", Roles: "system_user system_admin", CreateAt: old}
+	bot := &model.User{Id: model.NewId(), Username: "bot", Roles: "system_user", IsBot: true, CreateAt: old}
+	deactivated := &model.User{Id: model.NewId(), Username: "gone", Roles: "system_user", CreateAt: old, DeleteAt: old}
+	allowed := &model.User{Id: model.NewId(), Username: "allowed", Roles: "system_user", CreateAt: old}
+	recent := &model.User{Id: model.NewId(), Username: "recent", Roles: "system_user", CreateAt: now - day}
+	active := &model.User{Id: model.NewId(), Username: "active", Roles: "system_user", CreateAt: old}
+	poster := &model.User{Id: model.NewId(), Username: "poster", Roles: "system_user", CreateAt: old}
+	idle := &model.User{Id: model.NewId(), Username: "idle", Email: "idle@example.com", Roles: "system_user", CreateAt: old}
+	never := &model.User{Id: model.NewId(), Username: "never", Email: "never@example.com", Roles: "system_user", CreateAt: old}
+	users := []*model.User{admin, bot, deactivated, allowed, recent, active, poster, idle, never}
+	team := &model.Team{Id: model.NewId(), Name: "team"}
+
+	expectActivity := func() {
+		s.client.
+			EXPECT().
+			GetUsers(0, inactiveUsersPerPage, "").
+			Return(users, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetUsers(1, inactiveUsersPerPage, "").
+			Return([]*model.User{}, &model.Response{}, nil).
+			Times(1)
+
+		s.client.
+			EXPECT().
+			GetSessions(active.Id, "").
+			Return([]*model.Session{{LastActivityAt: old}, {LastActivityAt: now - day}}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetUserAudits(active.Id, 0, userAuditsPerPage, "").
+			Return(model.Audits{}, &model.Response{}, nil).
+			Times(1)
+
+		for _, user := range []*model.User{poster, idle, never} {
+			s.client.
+				EXPECT().
+				GetSessions(user.Id, "").
+				Return([]*model.Session{}, &model.Response{}, nil).
+				Times(1)
+			s.client.
+				EXPECT().
+				GetTeamsForUser(user.Id, "").
+				Return([]*model.Team{team}, &model.Response{}, nil).
+				Times(1)
+		}
+		for _, user := range []*model.User{poster, never} {
+			s.client.
+				EXPECT().
+				GetUserAudits(user.Id, 0, userAuditsPerPage, "").
+				Return(model.Audits{}, &model.Response{}, nil).
+				Times(1)
+		}
+		s.client.
+			EXPECT().
+			GetUserAudits(idle.Id, 0, userAuditsPerPage, "").
+			Return(model.Audits{
+				{Action: "/api/v4/users/login", ExtraInfo: "attempt - login_id=idle", CreateAt: old + 20*day},
+				{Action: "/api/v4/users/login", ExtraInfo: "success session_user=" + idle.Id, CreateAt: old + 10*day},
+			}, &model.Response{}, nil).
+			Times(1)
+
+		s.client.
+			EXPECT().
+			SearchPostsWithParams(team.Id, gomock.Any()).
+			DoAndReturn(func(_ string, params *model.SearchParameter) (*model.PostList, *model.Response, error) {
+				list := model.NewPostList()
+				if *params.Terms == "from:poster" {
+					list.AddPost(&model.Post{Id: model.NewId(), UserId: poster.Id, CreateAt: now - 2*day})
+				}
+				if *params.Terms == "from:idle" {
+					list.AddPost(&model.Post{Id: model.NewId(), UserId: idle.Id, CreateAt: old + 5*day})
+				}
+				return list, &model.Response{}, nil
+			}).
+			Times(3)
+	}
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("since", "90d", "")
+		cmd.Flags().StringSlice("exclude", []string{"Allowed"}, "")
+		cmd.Flags().String("exclude-file", "", "")
+		cmd.Flags().Bool("deactivate", false, "")
+		cmd.Flags().Bool("dry-run", false, "")
+		cmd.Flags().String("undo-file", "", "")
+		return cmd
+	}
+
+	s.Run("should report the inactive users", func() {
+		printer.Clean()
+		expectActivity()
+
+		err := userInactiveCmdF(s.client, newCmd(), nil)
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 2)
+
+		report := printer.GetLines()[0].(*InactiveUser)
+		s.Require().Equal(idle.Id, report.ID)
+		s.Require().Equal(formatActivityTime(old+10*day), report.LastLogin)
+		s.Require().Equal(formatActivityTime(old+5*day), report.LastPost)
+		s.Require().Empty(report.LastSession)
+		s.Require().Equal(report.LastLogin, report.LastActivity)
+		s.Require().Equal(390, report.InactiveDays)
+		s.Require().Empty(report.Action)
+
+		report = printer.GetLines()[1].(*InactiveUser)
+		s.Require().Equal(never.Id, report.ID)
+		s.Require().Empty(report.LastActivity)
+		s.Require().Equal(400, report.InactiveDays)
+	})
+
+	s.Run("should require an undo file to deactivate users", func() {
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("deactivate", "true"))
+		err := userInactiveCmdF(s.client, cmd, nil)
+		s.Require().EqualError(err, "the --undo-file flag is required to deactivate users")
+	})
+
+	s.Run("dry run should not deactivate the users", func() {
+		printer.Clean()
+		expectActivity()
+
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("deactivate", "true"))
+		s.Require().NoError(cmd.Flags().Set("dry-run", "true"))
+		err := userInactiveCmdF(s.client, cmd, nil)
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 2)
+		s.Require().Equal(InactiveUserActionWouldDeactivate, printer.GetLines()[0].(*InactiveUser).Action)
+	})
+
+	s.Run("should deactivate the users and write the undo file", func() {
+		printer.Clean()
+		expectActivity()
+
+		s.client.
+			EXPECT().
+			UpdateUserActive(idle.Id, false).
+			Return(&model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			UpdateUserActive(never.Id, false).
+			Return(&model.Response{StatusCode: http.StatusInternalServerError}, errors.New("mock error")).
+			Times(1)
+
+		undoFile := filepath.Join(dir, "undo.txt")
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("deactivate", "true"))
+		s.Require().NoError(cmd.Flags().Set("undo-file", undoFile))
+		err := userInactiveCmdF(s.client, cmd, nil)
+		s.Require().Error(err)
+		s.Require().Equal(ExitCodePartialFailure, ExitCodeForError(err))
+		s.Require().Equal([]interface{}{"unable to change activation status of user: " + never.Id}, printer.GetErrorLines())
+		s.Require().Equal(InactiveUserActionDeactivated, printer.GetLines()[0].(*InactiveUser).Action)
+		s.Require().Equal(InactiveUserActionFailed, printer.GetLines()[1].(*InactiveUser).Action)
+
+		f, err := os.Open(undoFile)
+		s.Require().NoError(err)
+		defer f.Close()
+		args, err := readArgsFile(f, undoFile, argsFileFormatAuto, "")
+		s.Require().NoError(err)
+		s.Require().Len(args, 1)
+		s.Require().Equal(idle.Id, args[0].Value)
+	})
+}
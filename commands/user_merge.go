@@ -0,0 +1,453 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+const (
+	UserDuplicateReasonEmail    = "email"
+	UserDuplicateReasonUsername = "username"
+	UserDuplicateReasonAuthData = "auth_data"
+
+	UserMergeActionTeam       = "team"
+	UserMergeActionChannel    = "channel"
+	UserMergeActionGroup      = "group"
+	UserMergeActionBot        = "bot"
+	UserMergeActionToken      = "token"
+	UserMergeActionDeactivate = "deactivate"
+
+	usersMergePerPage = 200
+)
+
+var UserDuplicatesCmd = &cobra.Command{
+	Use:   "duplicates",
+	Short: "Find likely duplicated users",
+	Long: `Finds the users that are likely to be the same person, which usually happens after migrations. The users are grouped when they have:
+
+  - the same email, ignoring the case and the +tag of the local part
+  - the same username, ignoring the case, dots, dashes, underscores and trailing digits
+  - the same authentication data, even with different authentication services
+
+Bots are ignored, and so are deactivated users unless --include-deactivated is set. The duplicates can be merged with the "user merge" command.`,
+	Example: `  user duplicates
+  user duplicates --include-deactivated --json`,
+	Args: cobra.NoArgs,
+	RunE: withClient(userDuplicatesCmdF),
+}
+
+var UserMergeCmd = &cobra.Command{
+	Use:   "merge [from user] [to user]",
+	Short: "Merge a duplicated user into another one",
+	Long: `Moves the memberships and resources of a user to the surviving one and then deactivates the former. The following are moved:
+
+  - the memberships of teams and of their public and private channels
+  - the memberships of custom groups. LDAP groups are managed by the LDAP sync and are skipped
+  - the ownership of the bots
+  - the active personal access tokens. As the tokens can't change their owner, a new token with the same description is created for the surviving user and the old one is revoked. The new tokens are printed and can't be retrieved later
+
+Direct and group messages are not moved. The user is only deactivated when all the other steps succeed. Use --dry-run to print the plan without applying it.`,
+	Example: `  user merge alice2 alice --dry-run
+  user merge alice2@example.com alice@example.com --confirm`,
+	Args: cobra.ExactArgs(2),
+	RunE: withClient(userMergeCmdF),
+}
+
+func init() {
+	UserDuplicatesCmd.Flags().Bool("include-deactivated", false, "includes the deactivated users")
+
+	UserMergeCmd.Flags().Bool("dry-run", false, "prints the plan without applying it")
+	UserMergeCmd.Flags().Bool("confirm", false, "confirms the merge without asking")
+
+	UserCmd.AddCommand(
+		UserDuplicatesCmd,
+		UserMergeCmd,
+	)
+}
+
+// UserDuplicates is a group of users that are likely the same person
+type UserDuplicates struct {
+	Reason string   `json:"reason"`
+	Value  string   `json:"value"`
+	Users  []string `json:"users"`
+}
+
+// normalizeDuplicateEmail lowercases an email and removes the +tag of
+// its local part
+func normalizeDuplicateEmail(email string) string {
+	local, domain, ok := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	if !ok {
+		return ""
+	}
+	if i := strings.Index(local, "+"); i > 0 {
+		local = local[:i]
+	}
+	return local + "@" + domain
+}
+
+// normalizeDuplicateUsername lowercases a username and removes its
+// separators and trailing digits, so alice.smith2 and AliceSmith match
+func normalizeDuplicateUsername(username string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || r == '_' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(username))
+	return strings.TrimRight(normalized, "0123456789")
+}
+
+// findUserDuplicates groups the users by each of the duplicate
+// reasons, returning the groups with more than one user sorted by
+// reason and value
+func findUserDuplicates(users []*model.User) []*UserDuplicates {
+	keys := map[string]func(user *model.User) string{
+		UserDuplicateReasonEmail:    func(user *model.User) string { return normalizeDuplicateEmail(user.Email) },
+		UserDuplicateReasonUsername: func(user *model.User) string { return normalizeDuplicateUsername(user.Username) },
+		UserDuplicateReasonAuthData: func(user *model.User) string {
+			if user.AuthData == nil {
+				return ""
+			}
+			return strings.ToLower(*user.AuthData)
+		},
+	}
+
+	var duplicates []*UserDuplicates
+	for _, reason := range []string{UserDuplicateReasonEmail, UserDuplicateReasonUsername, UserDuplicateReasonAuthData} {
+		groups := map[string][]string{}
+		for _, user := range users {
+			if value := keys[reason](user); value != "" {
+				groups[value] = append(groups[value], user.Username)
+			}
+		}
+
+		var values []string
+		for value, usernames := range groups {
+			if len(usernames) > 1 {
+				values = append(values, value)
+			}
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			usernames := groups[value]
+			sort.Strings(usernames)
+			duplicates = append(duplicates, &UserDuplicates{Reason: reason, Value: value, Users: usernames})
+		}
+	}
+	return duplicates
+}
+
+func userDuplicatesCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	includeDeactivated, _ := cmd.Flags().GetBool("include-deactivated")
+
+	users, err := getPages(c.GetUsers, usersMergePerPage)
+	if err != nil {
+		return fmt.Errorf("could not get the users: %w", err)
+	}
+
+	var candidates []*model.User
+	for _, user := range users {
+		if user.IsBot || (user.DeleteAt != 0 && !includeDeactivated) {
+			continue
+		}
+		candidates = append(candidates, user)
+	}
+
+	duplicates := findUserDuplicates(candidates)
+	if len(duplicates) == 0 {
+		printer.Print("No duplicated users found")
+		return nil
+	}
+	printer.SetTemplateFunc("join", strings.Join)
+	for _, group := range duplicates {
+		printer.PrintT(`{{.Reason}} "{{.Value}}": {{join .Users ", "}}`, group)
+	}
+	return nil
+}
+
+// UserMergeStep is one of the changes of a merge
+type UserMergeStep struct {
+	Action      string `json:"action"`
+	Target      string `json:"target"`
+	Token       string `json:"token,omitempty"`
+	Description string `json:"description"`
+
+	verb   string
+	object string
+	apply  func(step *UserMergeStep) error
+}
+
+func (s *UserMergeStep) describe(dryRun bool) {
+	past := map[string]string{
+		"add":        "Added",
+		"transfer":   "Transferred",
+		"recreate":   "Recreated",
+		"deactivate": "Deactivated",
+	}
+	if dryRun {
+		s.Description = fmt.Sprintf("Would %s %s", s.verb, s.object)
+		return
+	}
+	s.Description = fmt.Sprintf("%s %s", past[s.verb], s.object)
+	if s.Token != "" {
+		s.Description += ": " + s.Token
+	}
+}
+
+// planUserTeamsMerge adds the steps to join the teams and channels of
+// the user being merged
+func planUserTeamsMerge(c client.Client, from, to *model.User) ([]*UserMergeStep, error) {
+	fromTeams, response, err := c.GetTeamsForUser(from.Id, "")
+	if err != nil {
+		return nil, fmt.Errorf("could not get the teams of user %s: %w", from.Username, ExtractErrorFromResponse(response, err))
+	}
+	toTeams, response, err := c.GetTeamsForUser(to.Id, "")
+	if err != nil {
+		return nil, fmt.Errorf("could not get the teams of user %s: %w", to.Username, ExtractErrorFromResponse(response, err))
+	}
+	memberOf := map[string]bool{}
+	for _, team := range toTeams {
+		memberOf[team.Id] = true
+	}
+
+	var steps []*UserMergeStep
+	for _, team := range fromTeams {
+		team := team
+		if !memberOf[team.Id] {
+			steps = append(steps, &UserMergeStep{
+				Action: UserMergeActionTeam,
+				Target: team.Name,
+				verb:   "add",
+				object: fmt.Sprintf("user %s to team %q", to.Username, team.Name),
+				apply: func(*UserMergeStep) error {
+					_, response, err := c.AddTeamMember(team.Id, to.Id)
+					return ExtractErrorFromResponse(response, err)
+				},
+			})
+		}
+
+		fromChannels, response, err := c.GetChannelsForTeamForUser(team.Id, from.Id, false, "")
+		if err != nil {
+			return nil, fmt.Errorf("could not get the channels of user %s in team %s: %w", from.Username, team.Name, ExtractErrorFromResponse(response, err))
+		}
+		joined := map[string]bool{}
+		if memberOf[team.Id] {
+			toChannels, response, err := c.GetChannelsForTeamForUser(team.Id, to.Id, false, "")
+			if err != nil {
+				return nil, fmt.Errorf("could not get the channels of user %s in team %s: %w", to.Username, team.Name, ExtractErrorFromResponse(response, err))
+			}
+			for _, channel := range toChannels {
+				joined[channel.Id] = true
+			}
+		}
+
+		for _, channel := range fromChannels {
+			channel := channel
+			if joined[channel.Id] || channel.Type == model.ChannelTypeDirect || channel.Type == model.ChannelTypeGroup {
+				continue
+			}
+			steps = append(steps, &UserMergeStep{
+				Action: UserMergeActionChannel,
+				Target: team.Name + ":" + channel.Name,
+				verb:   "add",
+				object: fmt.Sprintf("user %s to channel %q", to.Username, team.Name+":"+channel.Name),
+				apply: func(*UserMergeStep) error {
+					_, response, err := c.AddChannelMember(channel.Id, to.Id)
+					return ExtractErrorFromResponse(response, err)
+				},
+			})
+		}
+	}
+	return steps, nil
+}
+
+// planUserGroupsMerge adds the steps to join the custom groups of the
+// user being merged
+func planUserGroupsMerge(c client.Client, from, to *model.User) ([]*UserMergeStep, error) {
+	fromGroups, response, err := c.GetGroupsByUserId(from.Id)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the groups of user %s: %w", from.Username, ExtractErrorFromResponse(response, err))
+	}
+	toGroups, response, err := c.GetGroupsByUserId(to.Id)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the groups of user %s: %w", to.Username, ExtractErrorFromResponse(response, err))
+	}
+	memberOf := map[string]bool{}
+	for _, group := range toGroups {
+		memberOf[group.Id] = true
+	}
+
+	var steps []*UserMergeStep
+	for _, group := range fromGroups {
+		group := group
+		if memberOf[group.Id] {
+			continue
+		}
+		if group.Source != model.GroupSourceCustom {
+			printer.PrintWarning(fmt.Sprintf("Skipping %s group %q, its members are managed by the %s sync", group.Source, group.DisplayName, group.Source))
+			continue
+		}
+		steps = append(steps, &UserMergeStep{
+			Action: UserMergeActionGroup,
+			Target: group.DisplayName,
+			verb:   "add",
+			object: fmt.Sprintf("user %s to group %q", to.Username, group.DisplayName),
+			apply: func(*UserMergeStep) error {
+				_, response, err := c.UpsertGroupMembers(group.Id, &model.GroupModifyMembers{UserIds: []string{to.Id}})
+				return ExtractErrorFromResponse(response, err)
+			},
+		})
+	}
+	return steps, nil
+}
+
+// planUserResourcesMerge adds the steps to transfer the bots and the
+// personal access tokens of the user being merged
+func planUserResourcesMerge(c client.Client, from, to *model.User) ([]*UserMergeStep, error) {
+	bots, err := getPages(c.GetBotsIncludeDeleted, usersMergePerPage)
+	if err != nil {
+		return nil, fmt.Errorf("could not get the bots: %w", err)
+	}
+
+	var steps []*UserMergeStep
+	for _, bot := range bots {
+		bot := bot
+		if bot.OwnerId != from.Id {
+			continue
+		}
+		steps = append(steps, &UserMergeStep{
+			Action: UserMergeActionBot,
+			Target: bot.Username,
+			verb:   "transfer",
+			object: fmt.Sprintf("bot %q to user %s", bot.Username, to.Username),
+			apply: func(*UserMergeStep) error {
+				_, response, err := c.AssignBot(bot.UserId, to.Id)
+				return ExtractErrorFromResponse(response, err)
+			},
+		})
+	}
+
+	for page := 0; ; page++ {
+		tokens, response, err := c.GetUserAccessTokensForUser(from.Id, page, usersMergePerPage)
+		if err != nil {
+			return nil, fmt.Errorf("could not get the access tokens of user %s: %w", from.Username, ExtractErrorFromResponse(response, err))
+		}
+		for _, token := range tokens {
+			token := token
+			if !token.IsActive {
+				continue
+			}
+			steps = append(steps, &UserMergeStep{
+				Action: UserMergeActionToken,
+				Target: token.Description,
+				verb:   "recreate",
+				object: fmt.Sprintf("access token %q for user %s", token.Description, to.Username),
+				apply: func(step *UserMergeStep) error {
+					created, response, err := c.CreateUserAccessToken(to.Id, token.Description)
+					if err != nil {
+						return ExtractErrorFromResponse(response, err)
+					}
+					step.Token = created.Token
+					if response, err := c.RevokeUserAccessToken(token.Id); err != nil {
+						return fmt.Errorf("created token %s but could not revoke the old one: %w", created.Token, ExtractErrorFromResponse(response, err))
+					}
+					return nil
+				},
+			})
+		}
+		if len(tokens) < usersMergePerPage {
+			break
+		}
+	}
+	return steps, nil
+}
+
+func userMergeCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	confirm, _ := cmd.Flags().GetBool("confirm")
+
+	from, err := getUserFromArg(c, args[0])
+	if err != nil {
+		return err
+	}
+	to, err := getUserFromArg(c, args[1])
+	if err != nil {
+		return err
+	}
+	switch {
+	case from.Id == to.Id:
+		return fmt.Errorf("can't merge user %s into itself", from.Username)
+	case from.IsBot || to.IsBot:
+		return fmt.Errorf("bots can't be merged")
+	case to.DeleteAt != 0:
+		return fmt.Errorf("can't merge into user %s because it is deactivated", to.Username)
+	}
+
+	var steps []*UserMergeStep
+	for _, plan := range []func(client.Client, *model.User, *model.User) ([]*UserMergeStep, error){
+		planUserTeamsMerge,
+		planUserGroupsMerge,
+		planUserResourcesMerge,
+	} {
+		planned, err := plan(c, from, to)
+		if err != nil {
+			return err
+		}
+		steps = append(steps, planned...)
+	}
+	if from.DeleteAt == 0 {
+		steps = append(steps, &UserMergeStep{
+			Action: UserMergeActionDeactivate,
+			Target: from.Username,
+			verb:   "deactivate",
+			object: "user " + from.Username,
+			apply: func(*UserMergeStep) error {
+				return changeUserActiveStatus(c, from, false)
+			},
+		})
+	}
+
+	if dryRun {
+		for _, step := range steps {
+			step.describe(true)
+			printer.PrintT("{{.Description}}", step)
+		}
+		return nil
+	}
+
+	if !confirm {
+		if err := getConfirmation(fmt.Sprintf("Are you sure you want to merge user %s into %s?", from.Username, to.Username), false); err != nil {
+			return err
+		}
+	}
+
+	var result *multierror.Error
+	applied := 0
+	for _, step := range steps {
+		if step.Action == UserMergeActionDeactivate && result != nil {
+			printer.PrintError(fmt.Sprintf("User %s was not deactivated because some of the changes failed", from.Username))
+			break
+		}
+		if err := step.apply(step); err != nil {
+			err = fmt.Errorf("failed to %s %s: %w", step.verb, step.object, err)
+			printer.PrintError(err.Error())
+			result = multierror.Append(result, err)
+			continue
+		}
+		applied++
+		step.describe(false)
+		printer.PrintT("{{.Description}}", step)
+	}
+	return partialFailure(result, applied)
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestFindUserDuplicates() {
+	users := []*model.User{
+		{Username: "alice", Email: "alice@example.com", AuthData: model.NewString("CN=Alice")},
+		{Username: "alice.2", Email: "Alice+old@Example.com"},
+		{Username: "alice_smith", Email: "asmith@example.com", AuthData: model.NewString("cn=alice")},
+		{Username: "bob", Email: "bob@example.com", AuthData: model.NewString("")},
+		{Username: "carol", Email: "carol@example.com", AuthData: model.NewString("")},
+	}
+
+	s.Require().Equal([]*UserDuplicates{
+		{Reason: UserDuplicateReasonEmail, Value: "alice@example.com", Users: []string{"alice", "alice.2"}},
+		{Reason: UserDuplicateReasonUsername, Value: "alice", Users: []string{"alice", "alice.2"}},
+		{Reason: UserDuplicateReasonAuthData, Value: "cn=alice", Users: []string{"alice", "alice_smith"}},
+	}, findUserDuplicates(users))
+}
+
+func (s *MmctlUnitTestSuite) TestUserMergeCmd() {
+	from := &model.User{Id: model.NewId(), Username: "alice2", Email: "alice2@example.com"}
+	to := &model.User{Id: model.NewId(), Username: "alice", Email: "alice@example.com"}
+	eng := &model.Team{Id: model.NewId(), Name: "eng"}
+	sales := &model.Team{Id: model.NewId(), Name: "sales"}
+	backend := &model.Channel{Id: model.NewId(), Name: "backend", Type: model.ChannelTypeOpen}
+	town := &model.Channel{Id: model.NewId(), Name: "town-square", Type: model.ChannelTypeOpen}
+	direct := &model.Channel{Id: model.NewId(), Name: from.Id + "__" + to.Id, Type: model.ChannelTypeDirect}
+	custom := &model.Group{Id: model.NewId(), DisplayName: "Developers", Source: model.GroupSourceCustom}
+	bot := &model.Bot{UserId: model.NewId(), Username: "deploy", OwnerId: from.Id}
+	token := &model.UserAccessToken{Id: model.NewId(), UserId: from.Id, Description: "ci", IsActive: true}
+
+	expectPlan := func() {
+		for _, user := range []*model.User{from, to} {
+			s.client.
+				EXPECT().
+				GetUserByEmail(user.Email, "").
+				Return(user, &model.Response{}, nil).
+				Times(1)
+		}
+		s.client.
+			EXPECT().
+			GetTeamsForUser(from.Id, "").
+			Return([]*model.Team{eng, sales}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetTeamsForUser(to.Id, "").
+			Return([]*model.Team{eng}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetChannelsForTeamForUser(eng.Id, from.Id, false, "").
+			Return([]*model.Channel{town, backend, direct}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetChannelsForTeamForUser(eng.Id, to.Id, false, "").
+			Return([]*model.Channel{town, direct}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetChannelsForTeamForUser(sales.Id, from.Id, false, "").
+			Return([]*model.Channel{}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetGroupsByUserId(from.Id).
+			Return([]*model.Group{custom, {Id: model.NewId(), DisplayName: "LDAP developers", Source: model.GroupSourceLdap}}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetGroupsByUserId(to.Id).
+			Return([]*model.Group{}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetBotsIncludeDeleted(0, usersMergePerPage, "").
+			Return([]*model.Bot{bot, {UserId: model.NewId(), Username: "other", OwnerId: to.Id}}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetBotsIncludeDeleted(1, usersMergePerPage, "").
+			Return([]*model.Bot{}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetUserAccessTokensForUser(from.Id, 0, usersMergePerPage).
+			Return([]*model.UserAccessToken{token, {Id: model.NewId(), Description: "revoked", IsActive: false}}, &model.Response{}, nil).
+			Times(1)
+	}
+	newCmd := func(dryRun bool) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("dry-run", dryRun, "")
+		cmd.Flags().Bool("confirm", true, "")
+		return cmd
+	}
+	descriptions := func() []string {
+		var lines []string
+		for _, line := range printer.GetLines() {
+			lines = append(lines, line.(*UserMergeStep).Description)
+		}
+		return lines
+	}
+
+	s.Run("should print the plan of the merge", func() {
+		printer.Clean()
+		expectPlan()
+
+		err := userMergeCmdF(s.client, newCmd(true), []string{from.Email, to.Email})
+		s.Require().NoError(err)
+		s.Require().Equal([]string{
+			`Would add user alice to channel "eng:backend"`,
+			`Would add user alice to team "sales"`,
+			`Would add user alice to group "Developers"`,
+			`Would transfer bot "deploy" to user alice`,
+			`Would recreate access token "ci" for user alice`,
+			"Would deactivate user alice2",
+		}, descriptions())
+	})
+
+	s.Run("should not deactivate the user when a change fails", func() {
+		printer.Clean()
+		expectPlan()
+
+		s.client.
+			EXPECT().
+			AddChannelMember(backend.Id, to.Id).
+			Return(&model.ChannelMember{}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			AddTeamMember(sales.Id, to.Id).
+			Return(nil, &model.Response{}, errors.New("team is full")).
+			Times(1)
+		s.client.
+			EXPECT().
+			UpsertGroupMembers(custom.Id, &model.GroupModifyMembers{UserIds: []string{to.Id}}).
+			Return([]*model.GroupMember{}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			AssignBot(bot.UserId, to.Id).
+			Return(bot, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			CreateUserAccessToken(to.Id, "ci").
+			Return(&model.UserAccessToken{Id: model.NewId(), Token: "newtoken"}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			RevokeUserAccessToken(token.Id).
+			Return(&model.Response{}, nil).
+			Times(1)
+
+		err := userMergeCmdF(s.client, newCmd(false), []string{from.Email, to.Email})
+		s.Require().Error(err)
+		s.Require().Equal(ExitCodePartialFailure, ExitCodeForError(err))
+		s.Require().Equal([]string{
+			`Added user alice to channel "eng:backend"`,
+			`Added user alice to group "Developers"`,
+			`Transferred bot "deploy" to user alice`,
+			`Recreated access token "ci" for user alice: newtoken`,
+		}, descriptions())
+		s.Require().Equal([]interface{}{
+			`failed to add user alice to team "sales": team is full`,
+			"User alice2 was not deactivated because some of the changes failed",
+		}, printer.GetErrorLines())
+	})
+
+	s.Run("should fail to merge a user into itself", func() {
+		printer.Clean()
+		s.client.
+			EXPECT().
+			GetUserByEmail(to.Email, "").
+			Return(to, &model.Response{}, nil).
+			Times(2)
+
+		err := userMergeCmdF(s.client, newCmd(true), []string{to.Email, to.Email})
+		s.Require().EqualError(err, "can't merge user alice into itself")
+	})
+}
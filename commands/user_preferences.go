@@ -0,0 +1,405 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+var UserPreferencesCmd = &cobra.Command{
+	Use:   "preferences",
+	Short: "Management of user preferences",
+}
+
+var UserPreferencesGetCmd = &cobra.Command{
+	Use:   "get [users]",
+	Short: "Get the preferences of users",
+	Long:  "Prints the preferences of users, optionally only the ones of a category or a single preference.",
+	Example: `  user preferences get john.doe
+  user preferences get john.doe jane.doe --category display_settings
+  user preferences get --from-file users.txt --category display_settings --name collapsed_reply_threads --format csv`,
+	ValidArgsFunction: validArgs(completeUsers),
+	Args:              minimumArgsOrFile(0),
+	RunE:              withClient(userPreferencesGetCmdF),
+}
+
+var UserPreferencesSetCmd = &cobra.Command{
+	Use:   "set [users]",
+	Short: "Set preferences of users",
+	Long: `Sets a preference of users with the --category, --name and --value flags, or several ones from a JSON file with an array of objects with the category, name and value fields:
+
+  [
+    {"category": "display_settings", "name": "collapsed_reply_threads", "value": "on"},
+    {"category": "notifications", "name": "email_interval", "value": "900"}
+  ]
+
+The users that already have the values are skipped.`,
+	Example: `  user preferences set john.doe --category display_settings --name collapsed_reply_threads --value on
+  user preferences set --from-file users.txt --preferences-file preferences.json --parallel 4`,
+	ValidArgsFunction: validArgs(completeUsers),
+	Args:              minimumArgsOrFile(0),
+	RunE:              withClient(userPreferencesSetCmdF),
+}
+
+var UserPreferencesDeleteCmd = &cobra.Command{
+	Use:   "delete [users]",
+	Short: "Delete preferences of users",
+	Long:  "Deletes a preference of users with the --category and --name flags, or the preferences of a JSON file like the one of \"user preferences set\", whose values are ignored. Users use the default value of the deleted preferences, and the ones that don't have them are skipped.",
+	Example: `  user preferences delete john.doe --category display_settings --name collapsed_reply_threads
+  user preferences delete --from-file users.txt --preferences-file preferences.json`,
+	ValidArgsFunction: validArgs(completeUsers),
+	Args:              minimumArgsOrFile(0),
+	RunE:              withClient(userPreferencesDeleteCmdF),
+}
+
+var UserNotifyPropsCmd = &cobra.Command{
+	Use:   "notify-props",
+	Short: "Management of user notification settings",
+}
+
+var UserNotifyPropsSetCmd = &cobra.Command{
+	Use:   "set [users]",
+	Short: "Set notification settings of users",
+	Long: `Sets notification settings of users with --set key=value flags, or from a JSON file with an object of settings:
+
+  {"email": "false", "push": "mention", "desktop_threads": "all"}
+
+The rest of the notification settings of the users are kept, and the users that already have the values are skipped.`,
+	Example: `  user notify-props set john.doe --set email=false --set push=mention
+  user notify-props set --from-file users.txt --props-file notify-props.json --parallel 4`,
+	ValidArgsFunction: validArgs(completeUsers),
+	Args:              minimumArgsOrFile(0),
+	RunE:              withClient(userNotifyPropsSetCmdF),
+}
+
+func init() {
+	UserPreferencesGetCmd.Flags().String("category", "", "only prints the preferences of this category")
+	UserPreferencesGetCmd.Flags().String("name", "", "only prints the preference with this name. Requires --category")
+	addFromFileFlags(UserPreferencesGetCmd)
+
+	for _, cmd := range []*cobra.Command{UserPreferencesSetCmd, UserPreferencesDeleteCmd} {
+		cmd.Flags().String("category", "", "category of the preference")
+		cmd.Flags().String("name", "", "name of the preference")
+		cmd.Flags().String("preferences-file", "", "JSON file with the preferences, instead of --category and --name")
+		addBulkFlags(cmd)
+	}
+	UserPreferencesSetCmd.Flags().String("value", "", "value of the preference")
+
+	UserNotifyPropsSetCmd.Flags().StringArray("set", nil, "notification setting in the key=value format. Can be repeated")
+	UserNotifyPropsSetCmd.Flags().String("props-file", "", "JSON file with an object of notification settings, instead of --set")
+	addBulkFlags(UserNotifyPropsSetCmd)
+
+	UserPreferencesCmd.AddCommand(
+		UserPreferencesGetCmd,
+		UserPreferencesSetCmd,
+		UserPreferencesDeleteCmd,
+	)
+	UserNotifyPropsCmd.AddCommand(
+		UserNotifyPropsSetCmd,
+	)
+	UserCmd.AddCommand(
+		UserPreferencesCmd,
+		UserNotifyPropsCmd,
+	)
+}
+
+// UserPreference is a preference of a user
+type UserPreference struct {
+	Username string `json:"username"`
+	Category string `json:"category"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+}
+
+type preferenceKey struct {
+	category string
+	name     string
+}
+
+// readPreferencesFile reads a JSON array of preferences, checking that
+// all of them have a category, a name and, if required, a value
+func readPreferencesFile(path string, withValue bool) (model.Preferences, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the preferences file: %w", err)
+	}
+	var preferences model.Preferences
+	if err := json.Unmarshal(b, &preferences); err != nil {
+		return nil, fmt.Errorf("%s: the file must contain a JSON array of preferences: %w", path, err)
+	}
+	if len(preferences) == 0 {
+		return nil, fmt.Errorf("%s: the file doesn't contain any preference", path)
+	}
+	for i, preference := range preferences {
+		if preference.Category == "" || preference.Name == "" || withValue && preference.Value == "" {
+			fields := "a category and a name"
+			if withValue {
+				fields = "a category, a name and a value"
+			}
+			return nil, fmt.Errorf("%s: preference %d must have %s", path, i+1, fields)
+		}
+	}
+	return preferences, nil
+}
+
+// preferencesFromFlags returns the preferences of the --category,
+// --name and --value flags, or the ones of the --preferences-file file
+func preferencesFromFlags(cmd *cobra.Command, withValue bool) (model.Preferences, error) {
+	category, _ := cmd.Flags().GetString("category")
+	name, _ := cmd.Flags().GetString("name")
+	value, _ := cmd.Flags().GetString("value")
+
+	if path, _ := cmd.Flags().GetString("preferences-file"); path != "" {
+		if category != "" || name != "" || value != "" {
+			return nil, errors.New("the --preferences-file flag can't be used with the --category, --name and --value flags")
+		}
+		return readPreferencesFile(path, withValue)
+	}
+
+	if category == "" || name == "" {
+		return nil, errors.New("the --category and --name flags are required unless --preferences-file is set")
+	}
+	if withValue && value == "" {
+		return nil, errors.New("the --value flag is required unless --preferences-file is set")
+	}
+	return model.Preferences{{Category: category, Name: name, Value: value}}, nil
+}
+
+func userPreferencesGetCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	category, _ := cmd.Flags().GetString("category")
+	name, _ := cmd.Flags().GetString("name")
+	if name != "" && category == "" {
+		return errors.New("the --name flag requires the --category flag")
+	}
+
+	targets, err := fileArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	users, err := getUsersFromFileArgs(c, targets)
+	var result *multierror.Error
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	for _, user := range users {
+		var preferences model.Preferences
+		var response *model.Response
+		var err error
+		switch {
+		case name != "":
+			var preference *model.Preference
+			preference, response, err = c.GetPreferenceByCategoryAndName(user.Id, category, name)
+			var nfErr *NotFoundError
+			if err != nil && errors.As(ExtractErrorFromResponse(response, err), &nfErr) {
+				continue
+			}
+			if preference != nil {
+				preferences = model.Preferences{*preference}
+			}
+		case category != "":
+			preferences, response, err = c.GetPreferencesByCategory(user.Id, category)
+		default:
+			preferences, response, err = c.GetPreferences(user.Id)
+		}
+		if err != nil {
+			err = fmt.Errorf("could not get the preferences of user %s: %w", user.Username, ExtractErrorFromResponse(response, err))
+			result = multierror.Append(result, err)
+			printer.PrintError(err.Error())
+			continue
+		}
+
+		sort.Slice(preferences, func(i, j int) bool {
+			if preferences[i].Category != preferences[j].Category {
+				return preferences[i].Category < preferences[j].Category
+			}
+			return preferences[i].Name < preferences[j].Name
+		})
+		for _, preference := range preferences {
+			printer.PrintT("{{.Username}} {{.Category}} {{.Name}}: {{.Value}}", &UserPreference{
+				Username: user.Username,
+				Category: preference.Category,
+				Name:     preference.Name,
+				Value:    preference.Value,
+			})
+		}
+	}
+	return result.ErrorOrNil()
+}
+
+// userPreferencesTask returns the bulk task that sets or deletes the
+// preferences of a user, skipping the users that don't need changes
+func userPreferencesTask(c client.Client, preferences model.Preferences, remove bool) bulkTask {
+	return func(target string) (func(), error) {
+		user, err := getUserFromArg(c, target)
+		if err != nil {
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+
+		current, response, err := c.GetPreferences(user.Id)
+		if err != nil {
+			err = fmt.Errorf("could not get the preferences of user %s: %w", user.Username, ExtractErrorFromResponse(response, err))
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		values := map[preferenceKey]string{}
+		for _, preference := range current {
+			values[preferenceKey{preference.Category, preference.Name}] = preference.Value
+		}
+
+		var changes model.Preferences
+		for _, preference := range preferences {
+			value, ok := values[preferenceKey{preference.Category, preference.Name}]
+			if remove && !ok || !remove && ok && value == preference.Value {
+				continue
+			}
+			preference.UserId = user.Id
+			changes = append(changes, preference)
+		}
+		if len(changes) == 0 {
+			return nil, errBulkSkipped
+		}
+
+		verb := "updated"
+		if remove {
+			verb = "deleted"
+			response, err = c.DeletePreferences(user.Id, changes)
+		} else {
+			response, err = c.UpdatePreferences(user.Id, changes)
+		}
+		if err != nil {
+			err = fmt.Errorf("could not update the preferences of user %s: %w", user.Username, ExtractErrorFromResponse(response, err))
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		names := make([]string, len(changes))
+		for i, preference := range changes {
+			names[i] = preference.Category + ":" + preference.Name
+		}
+		return func() {
+			printer.Print(fmt.Sprintf("Preferences of user %s %s: %s", user.Username, verb, strings.Join(names, ", ")))
+		}, nil
+	}
+}
+
+func userPreferencesSetCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	return runUserPreferencesCmd(c, cmd, args, false)
+}
+
+func userPreferencesDeleteCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	return runUserPreferencesCmd(c, cmd, args, true)
+}
+
+func runUserPreferencesCmd(c client.Client, cmd *cobra.Command, args []string, remove bool) error {
+	preferences, err := preferencesFromFlags(cmd, !remove)
+	if err != nil {
+		return err
+	}
+	targets, err := fileArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	report, err := runBulk(cmd, "users", targets, userPreferencesTask(c, preferences, remove))
+	if err != nil {
+		return err
+	}
+	return report.errors()
+}
+
+// notifyPropsFromFlags returns the notification settings of the --set
+// flags, or the ones of the --props-file file
+func notifyPropsFromFlags(cmd *cobra.Command) (map[string]string, error) {
+	values, _ := cmd.Flags().GetStringArray("set")
+	path, _ := cmd.Flags().GetString("props-file")
+	if path != "" && len(values) > 0 {
+		return nil, errors.New("the --props-file flag can't be used with the --set flag")
+	}
+
+	props := map[string]string{}
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the notification settings file: %w", err)
+		}
+		var raw map[string]interface{}
+		if err := json.Unmarshal(b, &raw); err != nil {
+			return nil, fmt.Errorf("%s: the file must contain a JSON object of notification settings: %w", path, err)
+		}
+		for key, value := range raw {
+			switch v := value.(type) {
+			case string:
+				props[key] = v
+			case bool, float64:
+				props[key] = fmt.Sprint(v)
+			default:
+				return nil, fmt.Errorf("%s: the value of %q must be a string, a boolean or a number", path, key)
+			}
+		}
+	}
+	for _, value := range values {
+		key, propValue, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid notification setting %q, must be in the key=value format", value)
+		}
+		props[key] = propValue
+	}
+
+	if len(props) == 0 {
+		return nil, errors.New("expected notification settings with the --set or --props-file flags")
+	}
+	return props, nil
+}
+
+func userNotifyPropsSetCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	props, err := notifyPropsFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	targets, err := fileArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	report, err := runBulk(cmd, "users", targets, func(target string) (func(), error) {
+		user, err := getUserFromArg(c, target)
+		if err != nil {
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+
+		// the patch replaces all the notification settings, so the
+		// new ones are merged with the current ones
+		notifyProps := model.CopyStringMap(user.NotifyProps)
+		changed := false
+		for key, value := range props {
+			if current, ok := notifyProps[key]; !ok || current != value {
+				notifyProps[key] = value
+				changed = true
+			}
+		}
+		if !changed {
+			return nil, errBulkSkipped
+		}
+
+		if _, response, err := c.PatchUser(user.Id, &model.UserPatch{NotifyProps: notifyProps}); err != nil {
+			err = fmt.Errorf("could not update the notification settings of user %s: %w", user.Username, ExtractErrorFromResponse(response, err))
+			return func() { printer.PrintError(err.Error()) }, err
+		}
+		return func() { printer.Print("Notification settings of user " + user.Username + " updated") }, nil
+	})
+	if err != nil {
+		return err
+	}
+	return report.errors()
+}
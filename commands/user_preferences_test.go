@@ -0,0 +1,235 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestPreferencesFromFlags() {
+	dir, err := ioutil.TempDir("", "mmctl-preferences-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	newCmd := func(flags map[string]string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("category", "", "")
+		cmd.Flags().String("name", "", "")
+		cmd.Flags().String("value", "", "")
+		cmd.Flags().String("preferences-file", "", "")
+		for name, value := range flags {
+			s.Require().NoError(cmd.Flags().Set(name, value))
+		}
+		return cmd
+	}
+
+	s.Run("should read the preference from the flags", func() {
+		preferences, err := preferencesFromFlags(newCmd(map[string]string{"category": "display_settings", "name": "use_military_time", "value": "true"}), true)
+		s.Require().NoError(err)
+		s.Require().Equal(model.Preferences{{Category: "display_settings", Name: "use_military_time", Value: "true"}}, preferences)
+
+		_, err = preferencesFromFlags(newCmd(map[string]string{"category": "display_settings", "name": "use_military_time"}), true)
+		s.Require().EqualError(err, "the --value flag is required unless --preferences-file is set")
+
+		_, err = preferencesFromFlags(newCmd(map[string]string{"name": "use_military_time"}), false)
+		s.Require().EqualError(err, "the --category and --name flags are required unless --preferences-file is set")
+	})
+
+	s.Run("should read the preferences from a file", func() {
+		path := filepath.Join(dir, "preferences.json")
+		s.Require().NoError(ioutil.WriteFile(path, []byte(`[
+  {"category": "display_settings", "name": "collapsed_reply_threads", "value": "on"},
+  {"category": "notifications", "name": "email_interval", "value": "900"}
+]`), 0600))
+
+		preferences, err := preferencesFromFlags(newCmd(map[string]string{"preferences-file": path}), true)
+		s.Require().NoError(err)
+		s.Require().Len(preferences, 2)
+		s.Require().Equal("email_interval", preferences[1].Name)
+
+		_, err = preferencesFromFlags(newCmd(map[string]string{"preferences-file": path, "category": "display_settings"}), true)
+		s.Require().EqualError(err, "the --preferences-file flag can't be used with the --category, --name and --value flags")
+
+		s.Require().NoError(ioutil.WriteFile(path, []byte(`[{"category": "display_settings", "name": "collapsed_reply_threads"}]`), 0600))
+		_, err = preferencesFromFlags(newCmd(map[string]string{"preferences-file": path}), true)
+		s.Require().EqualError(err, path+": preference 1 must have a category, a name and a value")
+
+		preferences, err = preferencesFromFlags(newCmd(map[string]string{"preferences-file": path}), false)
+		s.Require().NoError(err)
+		s.Require().Len(preferences, 1)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestUserPreferencesGetCmd() {
+	printer.Clean()
+	user := &model.User{Id: model.NewId(), Username: "alice", Email: "alice@example.com"}
+
+	s.client.
+		EXPECT().
+		GetUserByEmail(user.Email, "").
+		Return(user, &model.Response{}, nil).
+		Times(1)
+	s.client.
+		EXPECT().
+		GetPreferencesByCategory(user.Id, "display_settings").
+		Return(model.Preferences{
+			{UserId: user.Id, Category: "display_settings", Name: "use_military_time", Value: "true"},
+			{UserId: user.Id, Category: "display_settings", Name: "collapsed_reply_threads", Value: "on"},
+		}, &model.Response{}, nil).
+		Times(1)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("category", "display_settings", "")
+	cmd.Flags().String("name", "", "")
+	err := userPreferencesGetCmdF(s.client, cmd, []string{user.Email})
+	s.Require().NoError(err)
+	s.Require().Equal([]interface{}{
+		&UserPreference{Username: "alice", Category: "display_settings", Name: "collapsed_reply_threads", Value: "on"},
+		&UserPreference{Username: "alice", Category: "display_settings", Name: "use_military_time", Value: "true"},
+	}, printer.GetLines())
+}
+
+func (s *MmctlUnitTestSuite) TestUserPreferencesSetCmd() {
+	var output bytes.Buffer
+	bulkOutput = &output
+	s.T().Cleanup(func() { bulkOutput = os.Stderr })
+
+	alice := &model.User{Id: model.NewId(), Username: "alice", Email: "alice@example.com"}
+	bob := &model.User{Id: model.NewId(), Username: "bob", Email: "bob@example.com"}
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("category", "display_settings", "")
+		cmd.Flags().String("name", "collapsed_reply_threads", "")
+		cmd.Flags().String("value", "on", "")
+		return cmd
+	}
+
+	s.Run("should set the preference of the users that don't have it", func() {
+		printer.Clean()
+		output.Reset()
+
+		for _, user := range []*model.User{alice, bob} {
+			s.client.
+				EXPECT().
+				GetUserByEmail(user.Email, "").
+				Return(user, &model.Response{}, nil).
+				Times(1)
+		}
+		s.client.
+			EXPECT().
+			GetPreferences(alice.Id).
+			Return(model.Preferences{{UserId: alice.Id, Category: "display_settings", Name: "collapsed_reply_threads", Value: "off"}}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetPreferences(bob.Id).
+			Return(model.Preferences{{UserId: bob.Id, Category: "display_settings", Name: "collapsed_reply_threads", Value: "on"}}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			UpdatePreferences(alice.Id, model.Preferences{{UserId: alice.Id, Category: "display_settings", Name: "collapsed_reply_threads", Value: "on"}}).
+			Return(&model.Response{}, nil).
+			Times(1)
+
+		err := userPreferencesSetCmdF(s.client, newCmd(), []string{alice.Email, bob.Email})
+		s.Require().NoError(err)
+		s.Require().Equal([]interface{}{"Preferences of user alice updated: display_settings:collapsed_reply_threads"}, printer.GetLines())
+		s.Require().Empty(output.String())
+	})
+
+	s.Run("should delete the preference of the users that have it", func() {
+		printer.Clean()
+		output.Reset()
+
+		s.client.
+			EXPECT().
+			GetUserByEmail(alice.Email, "").
+			Return(alice, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetPreferences(alice.Id).
+			Return(model.Preferences{{UserId: alice.Id, Category: "display_settings", Name: "collapsed_reply_threads", Value: "off"}}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			DeletePreferences(alice.Id, gomock.Len(1)).
+			Return(&model.Response{}, nil).
+			Times(1)
+
+		err := userPreferencesDeleteCmdF(s.client, newCmd(), []string{alice.Email})
+		s.Require().NoError(err)
+		s.Require().Equal([]interface{}{"Preferences of user alice deleted: display_settings:collapsed_reply_threads"}, printer.GetLines())
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestUserNotifyPropsSetCmd() {
+	var output bytes.Buffer
+	bulkOutput = &output
+	s.T().Cleanup(func() { bulkOutput = os.Stderr })
+
+	dir, err := ioutil.TempDir("", "mmctl-notify-props-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	newCmd := func(set []string, file string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().StringArray("set", set, "")
+		cmd.Flags().String("props-file", file, "")
+		return cmd
+	}
+
+	s.Run("should parse the notification settings", func() {
+		path := filepath.Join(dir, "props.json")
+		s.Require().NoError(ioutil.WriteFile(path, []byte(`{"email": false, "push": "mention"}`), 0600))
+		props, err := notifyPropsFromFlags(newCmd(nil, path))
+		s.Require().NoError(err)
+		s.Require().Equal(map[string]string{"email": "false", "push": "mention"}, props)
+
+		props, err = notifyPropsFromFlags(newCmd([]string{"mention_keys=alice,@team", "email=true"}, ""))
+		s.Require().NoError(err)
+		s.Require().Equal(map[string]string{"mention_keys": "alice,@team", "email": "true"}, props)
+
+		_, err = notifyPropsFromFlags(newCmd([]string{"email"}, ""))
+		s.Require().EqualError(err, `invalid notification setting "email", must be in the key=value format`)
+
+		_, err = notifyPropsFromFlags(newCmd(nil, ""))
+		s.Require().EqualError(err, "expected notification settings with the --set or --props-file flags")
+	})
+
+	s.Run("should merge the settings with the current ones", func() {
+		printer.Clean()
+		output.Reset()
+
+		alice := &model.User{Id: model.NewId(), Username: "alice", Email: "alice@example.com", NotifyProps: model.StringMap{"email": "true", "push": "mention"}}
+		bob := &model.User{Id: model.NewId(), Username: "bob", Email: "bob@example.com", NotifyProps: model.StringMap{"email": "false", "push": "all"}}
+		for _, user := range []*model.User{alice, bob} {
+			s.client.
+				EXPECT().
+				GetUserByEmail(user.Email, "").
+				Return(user, &model.Response{}, nil).
+				Times(1)
+		}
+		s.client.
+			EXPECT().
+			PatchUser(alice.Id, &model.UserPatch{NotifyProps: model.StringMap{"email": "false", "push": "mention"}}).
+			Return(alice, &model.Response{}, nil).
+			Times(1)
+
+		err := userNotifyPropsSetCmdF(s.client, newCmd([]string{"email=false"}, ""), []string{alice.Email, bob.Email})
+		s.Require().NoError(err)
+		s.Require().Equal([]interface{}{"Notification settings of user alice updated"}, printer.GetLines())
+		s.Require().Empty(output.String())
+		s.Require().Equal("true", alice.NotifyProps["email"])
+	})
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+const (
+	UserSessionTypeWeb    = "web"
+	UserSessionTypeMobile = "mobile"
+	UserSessionTypeOAuth  = "oauth"
+	UserSessionTypeToken  = "token"
+)
+
+var UserSessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Management of user sessions",
+}
+
+var UserSessionsListCmd = &cobra.Command{
+	Use:   "list [user]",
+	Short: "List the sessions of a user",
+	Long: `Lists the active sessions of a user from the most to the least recently used, with their device, platform, IP address, last activity and expiry.
+The IP address is read from the audits recorded by the session, and is empty if there are none.`,
+	Example: `  user sessions list john.doe
+  user sessions list john.doe --format table`,
+	ValidArgsFunction: validArgs(completeUsers, completeNone),
+	Args:              cobra.ExactArgs(1),
+	RunE:              withClient(userSessionsListCmdF),
+}
+
+var UserSessionsRevokeCmd = &cobra.Command{
+	Use:   "revoke [user] [session-ids]",
+	Short: "Revoke sessions of a user",
+	Long:  "Revokes the given sessions of a user, or all of them with --all. The user is logged out of the revoked sessions immediately.",
+	Example: `  user sessions revoke john.doe 1bxz5eo9f7ryuqhptnmgzsymbe
+  user sessions revoke john.doe --all`,
+	ValidArgsFunction: validArgs(completeUsers, completeNone),
+	Args:              cobra.MinimumNArgs(1),
+	RunE:              withClient(userSessionsRevokeCmdF),
+}
+
+var UserSessionsRevokeAllCmd = &cobra.Command{
+	Use:     "revoke-all",
+	Short:   "Revoke the sessions of all users",
+	Long:    "Revokes the sessions of every user of the server, including the one running the command, so everybody has to log in again.",
+	Example: "  user sessions revoke-all --confirm",
+	Args:    cobra.NoArgs,
+	RunE:    withClient(userSessionsRevokeAllCmdF),
+}
+
+func init() {
+	UserSessionsRevokeCmd.Flags().Bool("all", false, "revokes all the sessions of the user")
+	UserSessionsRevokeAllCmd.Flags().Bool("confirm", false, "confirm you really want to revoke the sessions of all users")
+
+	UserSessionsCmd.AddCommand(
+		UserSessionsListCmd,
+		UserSessionsRevokeCmd,
+		UserSessionsRevokeAllCmd,
+	)
+	UserCmd.AddCommand(UserSessionsCmd)
+}
+
+// UserSession is the printable version of a session. The expiry is
+// empty for the sessions that don't expire
+type UserSession struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	Device       string `json:"device"`
+	Platform     string `json:"platform"`
+	OS           string `json:"os"`
+	Browser      string `json:"browser"`
+	IP           string `json:"ip"`
+	CreatedAt    string `json:"created_at"`
+	LastActivity string `json:"last_activity"`
+	ExpiresAt    string `json:"expires_at"`
+}
+
+const userSessionTemplate = `{{.ID}} ({{.Type}}) platform: {{or .Platform "unknown"}}, device: {{or .Device "none"}}, ip: {{or .IP "unknown"}}, last activity: {{.LastActivity}}, expires: {{or .ExpiresAt "never"}}`
+
+func sessionType(session *model.Session) string {
+	switch {
+	case session.Props[model.SessionPropType] == model.SessionTypeUserAccessToken:
+		return UserSessionTypeToken
+	case session.IsOAuth:
+		return UserSessionTypeOAuth
+	case session.IsMobileApp():
+		return UserSessionTypeMobile
+	default:
+		return UserSessionTypeWeb
+	}
+}
+
+func newUserSession(session *model.Session, ip string) *UserSession {
+	return &UserSession{
+		ID:           session.Id,
+		Type:         sessionType(session),
+		Device:       session.DeviceId,
+		Platform:     session.Props[model.SessionPropPlatform],
+		OS:           session.Props[model.SessionPropOs],
+		Browser:      session.Props[model.SessionPropBrowser],
+		IP:           ip,
+		CreatedAt:    formatActivityTime(session.CreateAt),
+		LastActivity: formatActivityTime(session.LastActivityAt),
+		ExpiresAt:    formatActivityTime(session.ExpiresAt),
+	}
+}
+
+func userSessionsListCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	user, err := getUserFromArg(c, args[0])
+	if err != nil {
+		return err
+	}
+
+	sessions, response, err := c.GetSessions(user.Id, "")
+	if err != nil {
+		return fmt.Errorf("could not get the sessions of user %s: %w", args[0], ExtractErrorFromResponse(response, err))
+	}
+
+	audits, response, err := c.GetUserAudits(user.Id, 0, userAuditsPerPage, "")
+	if err != nil {
+		return fmt.Errorf("could not get the audits of user %s: %w", args[0], ExtractErrorFromResponse(response, err))
+	}
+	// the audits are sorted from the newest to the oldest, so the
+	// latest address of every session is kept
+	ips := map[string]string{}
+	for _, audit := range audits {
+		if _, ok := ips[audit.SessionId]; !ok && audit.SessionId != "" && audit.IpAddress != "" {
+			ips[audit.SessionId] = audit.IpAddress
+		}
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastActivityAt > sessions[j].LastActivityAt
+	})
+	for _, session := range sessions {
+		printer.PrintT(userSessionTemplate, newUserSession(session, ips[session.Id]))
+	}
+	return nil
+}
+
+func userSessionsRevokeCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	all, _ := cmd.Flags().GetBool("all")
+	sessionIDs := args[1:]
+	if all && len(sessionIDs) > 0 {
+		return errors.New("the session ids can't be used with the --all flag")
+	}
+	if !all && len(sessionIDs) == 0 {
+		return errors.New("expected at least one session id or the --all flag")
+	}
+
+	user, err := getUserFromArg(c, args[0])
+	if err != nil {
+		return err
+	}
+
+	if all {
+		if response, err := c.RevokeAllSessions(user.Id); err != nil {
+			return fmt.Errorf("could not revoke the sessions of user %s: %w", args[0], ExtractErrorFromResponse(response, err))
+		}
+		printer.Print("All sessions of user " + user.Username + " revoked")
+		return nil
+	}
+
+	var result *multierror.Error
+	revoked := 0
+	for _, sessionID := range sessionIDs {
+		if response, err := c.RevokeSession(user.Id, sessionID); err != nil {
+			err = fmt.Errorf("could not revoke session %s: %w", sessionID, ExtractErrorFromResponse(response, err))
+			result = multierror.Append(result, err)
+			printer.PrintError(err.Error())
+			continue
+		}
+		revoked++
+		printer.Print("Session " + sessionID + " revoked")
+	}
+	return partialFailure(result, revoked)
+}
+
+func userSessionsRevokeAllCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	confirmFlag, _ := cmd.Flags().GetBool("confirm")
+	if !confirmFlag {
+		if err := getConfirmation("Are you sure you want to revoke the sessions of all users?", false); err != nil {
+			return err
+		}
+	}
+
+	if response, err := c.RevokeSessionsFromAllUsers(); err != nil {
+		return fmt.Errorf("could not revoke the sessions of all users: %w", ExtractErrorFromResponse(response, err))
+	}
+	printer.Print("All sessions revoked")
+	return nil
+}
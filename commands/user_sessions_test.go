@@ -0,0 +1,165 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestUserSessionsListCmd() {
+	user := &model.User{Id: model.NewId(), Username: "john.doe", Email: "john@example.com"}
+
+	s.Run("should list the sessions from the most recently used", func() {
+		printer.Clean()
+
+		web := &model.Session{Id: model.NewId(), LastActivityAt: 1000, ExpiresAt: 5000, Props: model.StringMap{
+			model.SessionPropPlatform: "Linux",
+			model.SessionPropBrowser:  "Firefox/100.0",
+		}}
+		mobile := &model.Session{Id: model.NewId(), LastActivityAt: 3000, DeviceId: "android_rn:1234", Props: model.StringMap{
+			model.SessionPropPlatform: "Android",
+		}}
+		token := &model.Session{Id: model.NewId(), LastActivityAt: 2000, Props: model.StringMap{
+			model.SessionPropType: model.SessionTypeUserAccessToken,
+		}}
+
+		s.client.
+			EXPECT().
+			GetUserByEmail(user.Username, "").
+			Return(nil, &model.Response{StatusCode: http.StatusNotFound}, errors.New("not found")).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetUserByUsername(user.Username, "").
+			Return(user, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetSessions(user.Id, "").
+			Return([]*model.Session{web, mobile, token}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetUserAudits(user.Id, 0, userAuditsPerPage, "").
+			Return(model.Audits{
+				{SessionId: web.Id, IpAddress: "10.0.0.2"},
+				{SessionId: web.Id, IpAddress: "10.0.0.1"},
+				{SessionId: "", IpAddress: "10.0.0.3"},
+			}, &model.Response{}, nil).
+			Times(1)
+
+		err := userSessionsListCmdF(s.client, &cobra.Command{}, []string{user.Username})
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 3)
+
+		first := printer.GetLines()[0].(*UserSession)
+		s.Require().Equal(mobile.Id, first.ID)
+		s.Require().Equal(UserSessionTypeMobile, first.Type)
+		s.Require().Equal("android_rn:1234", first.Device)
+		s.Require().Empty(first.IP)
+		s.Require().Empty(first.ExpiresAt)
+
+		s.Require().Equal(UserSessionTypeToken, printer.GetLines()[1].(*UserSession).Type)
+
+		last := printer.GetLines()[2].(*UserSession)
+		s.Require().Equal(UserSessionTypeWeb, last.Type)
+		s.Require().Equal("Linux", last.Platform)
+		s.Require().Equal("10.0.0.2", last.IP)
+		s.Require().Equal(formatActivityTime(5000), last.ExpiresAt)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestUserSessionsRevokeCmd() {
+	user := &model.User{Id: model.NewId(), Username: "john.doe", Email: "john@example.com"}
+	newCmd := func(all bool) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("all", all, "")
+		return cmd
+	}
+
+	s.Run("should require either session ids or --all", func() {
+		err := userSessionsRevokeCmdF(s.client, newCmd(false), []string{user.Email})
+		s.Require().EqualError(err, "expected at least one session id or the --all flag")
+
+		err = userSessionsRevokeCmdF(s.client, newCmd(true), []string{user.Email, "session1"})
+		s.Require().EqualError(err, "the session ids can't be used with the --all flag")
+	})
+
+	s.Run("should revoke the given sessions", func() {
+		printer.Clean()
+
+		s.client.
+			EXPECT().
+			GetUserByEmail(user.Email, "").
+			Return(user, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			RevokeSession(user.Id, "session1").
+			Return(&model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			RevokeSession(user.Id, "session2").
+			Return(&model.Response{StatusCode: http.StatusBadRequest}, errors.New("invalid session")).
+			Times(1)
+
+		err := userSessionsRevokeCmdF(s.client, newCmd(false), []string{user.Email, "session1", "session2"})
+		s.Require().Error(err)
+		s.Require().Equal(ExitCodePartialFailure, ExitCodeForError(err))
+		s.Require().Equal([]interface{}{"Session session1 revoked"}, printer.GetLines())
+		s.Require().Equal([]interface{}{"could not revoke session session2: invalid session"}, printer.GetErrorLines())
+	})
+
+	s.Run("should revoke all the sessions of the user", func() {
+		printer.Clean()
+
+		s.client.
+			EXPECT().
+			GetUserByEmail(user.Email, "").
+			Return(user, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			RevokeAllSessions(user.Id).
+			Return(&model.Response{}, nil).
+			Times(1)
+
+		err := userSessionsRevokeCmdF(s.client, newCmd(true), []string{user.Email})
+		s.Require().NoError(err)
+		s.Require().Equal([]interface{}{"All sessions of user john.doe revoked"}, printer.GetLines())
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestUserSessionsRevokeAllCmd() {
+	s.Run("should revoke the sessions of all users", func() {
+		printer.Clean()
+
+		s.client.
+			EXPECT().
+			RevokeSessionsFromAllUsers().
+			Return(&model.Response{}, nil).
+			Times(1)
+
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("confirm", true, "")
+		err := userSessionsRevokeAllCmdF(s.client, cmd, nil)
+		s.Require().NoError(err)
+		s.Require().Equal([]interface{}{"All sessions revoked"}, printer.GetLines())
+	})
+
+	s.Run("should fail without confirmation in a non interactive terminal", func() {
+		cmd := &cobra.Command{}
+		cmd.Flags().Bool("confirm", false, "")
+		err := userSessionsRevokeAllCmdF(s.client, cmd, nil)
+		s.Require().Error(err)
+		s.Require().Contains(err.Error(), "could not proceed, either enable --confirm flag")
+	})
+}
@@ -0,0 +1,537 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/utils"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/client"
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+const (
+	userSyncFieldID       = "id"
+	userSyncFieldPosition = "position"
+
+	userSyncFormatAuto = "auto"
+	userSyncFormatCSV  = "csv"
+	userSyncFormatLDIF = "ldif"
+
+	UserSyncActionUpdate     = "update"
+	UserSyncActionDeactivate = "deactivate"
+	UserSyncActionNotFound   = "not_found"
+	UserSyncActionInactive   = "inactive"
+	UserSyncActionFailed     = "failed"
+
+	usersSyncPerPage = 200
+)
+
+// userSyncFields are the columns of the CSV files, in the order the
+// changes are described
+var userSyncFields = []string{
+	userSyncFieldID,
+	userCSVColumnUsername,
+	userCSVColumnEmail,
+	userCSVColumnFirstName,
+	userCSVColumnLastName,
+	userCSVColumnNickname,
+	userSyncFieldPosition,
+}
+
+var userSyncKeys = []string{userCSVColumnEmail, userCSVColumnUsername, userSyncFieldID}
+
+// userSyncLDIFAttributes maps the LDIF attributes, in lowercase, to
+// the fields they are synced to
+var userSyncLDIFAttributes = map[string]string{
+	"uid":         userCSVColumnUsername,
+	"mail":        userCSVColumnEmail,
+	"givenname":   userCSVColumnFirstName,
+	"sn":          userCSVColumnLastName,
+	"displayname": userCSVColumnNickname,
+	"title":       userSyncFieldPosition,
+}
+
+var UserSyncCmd = &cobra.Command{
+	Use:   "sync -f [file]",
+	Short: "Sync the attributes of users from a CSV or LDIF file",
+	Long: `Compares the users of the server with the ones of a CSV or LDIF file, which is the source of truth for their attributes, prints the changes and applies them. The users are matched by the field of the --key flag, and the users of the file that don't exist on the server are reported but not created. The users of the file that are deactivated on the server are reported but not updated.
+
+The first row of the CSV files is a header with the names of the columns, which must include the key: id, username, email, first_name, last_name, nickname and position. The attributes of the LDIF entries are mapped as follows:
+
+  uid          username
+  mail         email
+  givenName    first_name
+  sn           last_name
+  displayName  nickname
+  title        position
+
+Only the fields present in the file are synced, and empty values leave the attribute of the user unchanged. With --deactivate-missing the active users that aren't in the file are deactivated, except bots and system admins. The users are only deactivated if all the rows of the file were synced, and after a confirmation unless --confirm is set.`,
+	Example: `  user sync -f hr.csv --key email --dry-run
+  user sync -f hr.csv --key email
+  user sync -f people.ldif --key username --deactivate-missing --confirm`,
+	Args: cobra.NoArgs,
+	RunE: withClient(userSyncCmdF),
+}
+
+func init() {
+	UserSyncCmd.Flags().StringP("file", "f", "", "CSV or LDIF file with the users. Use \"-\" to read it from the standard input")
+	_ = UserSyncCmd.MarkFlagRequired("file")
+	UserSyncCmd.Flags().String("key", userCSVColumnEmail, "field used to match the users of the file with the ones of the server ["+strings.Join(userSyncKeys, ", ")+"]")
+	UserSyncCmd.Flags().String("format", userSyncFormatAuto, "format of the file [auto, csv, ldif]. auto uses the file extension and defaults to csv")
+	UserSyncCmd.Flags().Bool("dry-run", false, "prints the changes without applying them")
+	UserSyncCmd.Flags().Bool("deactivate-missing", false, "deactivates the active users that are not in the file, except bots and system admins")
+	UserSyncCmd.Flags().Bool("confirm", false, "confirm you really want to deactivate the users missing from the file")
+
+	UserCmd.AddCommand(UserSyncCmd)
+}
+
+// UserSyncResult is a change of the sync of a user
+type UserSyncResult struct {
+	Line        int      `json:"line,omitempty"`
+	Key         string   `json:"key"`
+	Username    string   `json:"username,omitempty"`
+	Action      string   `json:"action"`
+	Changes     []string `json:"changes,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	Description string   `json:"description"`
+}
+
+func (r *UserSyncResult) describe(dryRun bool) {
+	verbs := map[string]string{
+		UserSyncActionUpdate:     "updated",
+		UserSyncActionDeactivate: "deactivated, missing from the file",
+		UserSyncActionNotFound:   "not found on the server",
+		UserSyncActionInactive:   "not updated, deactivated on the server",
+		UserSyncActionFailed:     "failed",
+	}
+	if dryRun {
+		verbs[UserSyncActionUpdate] = "would update"
+		verbs[UserSyncActionDeactivate] = "would deactivate, missing from the file"
+	}
+
+	r.Description = fmt.Sprintf("%s %s", r.Key, verbs[r.Action])
+	if r.Line > 0 {
+		r.Description = fmt.Sprintf("line %d: %s", r.Line, r.Description)
+	}
+	if len(r.Changes) > 0 {
+		r.Description += ": " + strings.Join(r.Changes, ", ")
+	}
+	if r.Error != "" {
+		r.Description += ": " + r.Error
+	}
+}
+
+// userSyncRecord contains the non empty fields of a row of a CSV file
+// or an entry of a LDIF file
+type userSyncRecord struct {
+	line   int
+	values map[string]string
+	// err is the validation error of the record
+	err error
+}
+
+func (r *userSyncRecord) validate() error {
+	if username, ok := r.values[userCSVColumnUsername]; ok && !model.IsValidUsername(username) {
+		return fmt.Errorf("invalid username %q", username)
+	}
+	if email, ok := r.values[userCSVColumnEmail]; ok && !model.IsValidEmail(email) {
+		return fmt.Errorf("invalid email %q", email)
+	}
+	if id, ok := r.values[userSyncFieldID]; ok && !model.IsValidId(id) {
+		return fmt.Errorf("invalid id %q", id)
+	}
+	return nil
+}
+
+// normalizeUserSyncValue lowercases the fields that the server stores
+// in lowercase
+func normalizeUserSyncValue(field, value string) string {
+	value = strings.TrimSpace(value)
+	if field == userCSVColumnUsername || field == userCSVColumnEmail {
+		return strings.ToLower(value)
+	}
+	return value
+}
+
+func readUserSyncCSV(r io.Reader, source, key string) ([]*userSyncRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: the file is empty", source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid CSV file: %w", source, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !utils.StringInSlice(name, userSyncFields) {
+			return nil, fmt.Errorf("%s: unknown column %q, must be one of: %s", source, name, strings.Join(userSyncFields, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("%s: duplicated column %q", source, name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns[key]; !ok {
+		return nil, fmt.Errorf("%s: the %q column of the key is required", source, key)
+	}
+
+	var records []*userSyncRecord
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid CSV file: %w", source, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		record := &userSyncRecord{line: line, values: map[string]string{}}
+		for name, i := range columns {
+			if i >= len(row) {
+				continue
+			}
+			if value := normalizeUserSyncValue(name, row[i]); value != "" {
+				record.values[name] = value
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// readUserSyncLDIF reads the entries of a LDIF file, keeping the first
+// value of the attributes that are synced
+func readUserSyncLDIF(r io.Reader, source string) ([]*userSyncRecord, error) {
+	type attribute struct {
+		name   string
+		base64 bool
+		value  string
+	}
+
+	var records []*userSyncRecord
+	var attributes []*attribute
+	start := 0
+	finish := func() error {
+		defer func() { attributes = nil }()
+		if len(attributes) == 0 || len(attributes) == 1 && attributes[0].name == "version" {
+			return nil
+		}
+		record := &userSyncRecord{line: start, values: map[string]string{}}
+		for _, attr := range attributes {
+			field, ok := userSyncLDIFAttributes[attr.name]
+			if !ok {
+				continue
+			}
+			if _, ok := record.values[field]; ok {
+				continue
+			}
+			value := attr.value
+			if attr.base64 {
+				b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+				if err != nil {
+					return fmt.Errorf("%s:%d: invalid base64 value of the %q attribute", source, start, attr.name)
+				}
+				value = string(b)
+			}
+			if value = normalizeUserSyncValue(field, value); value != "" {
+				record.values[field] = value
+			}
+		}
+		records = append(records, record)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case text == "":
+			if err := finish(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(text, "#"):
+		case strings.HasPrefix(text, " "):
+			if len(attributes) == 0 {
+				return nil, fmt.Errorf("%s:%d: continuation line without an attribute", source, line)
+			}
+			attributes[len(attributes)-1].value += text[1:]
+		default:
+			name, value, ok := strings.Cut(text, ":")
+			if !ok {
+				return nil, fmt.Errorf("%s:%d: invalid line, must be in the attribute: value format", source, line)
+			}
+			if len(attributes) == 0 {
+				start = line
+			}
+			attr := &attribute{name: strings.ToLower(name)}
+			switch {
+			case strings.HasPrefix(value, ":"):
+				attr.base64 = true
+				attr.value = strings.TrimSpace(value[1:])
+			case strings.HasPrefix(value, "<"):
+				return nil, fmt.Errorf("%s:%d: the values read from URLs are not supported", source, line)
+			default:
+				attr.value = strings.TrimSpace(value)
+			}
+			attributes = append(attributes, attr)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: could not read the file: %w", source, err)
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// readUserSyncFile reads the records of the file, validating them and
+// checking that their keys are unique
+func readUserSyncFile(r io.Reader, source, format, key string) ([]*userSyncRecord, error) {
+	if format == userSyncFormatAuto {
+		format = userSyncFormatCSV
+		if strings.EqualFold(filepath.Ext(source), ".ldif") {
+			format = userSyncFormatLDIF
+		}
+	}
+
+	var records []*userSyncRecord
+	var err error
+	switch format {
+	case userSyncFormatCSV:
+		records, err = readUserSyncCSV(r, source, key)
+	case userSyncFormatLDIF:
+		if key == userSyncFieldID {
+			return nil, fmt.Errorf("the %q key can't be used with LDIF files", key)
+		}
+		records, err = readUserSyncLDIF(r, source)
+	default:
+		return nil, fmt.Errorf("invalid format %q, must be one of: auto, csv, ldif", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]int{}
+	for _, record := range records {
+		value, ok := record.values[key]
+		if !ok {
+			record.err = fmt.Errorf("the %s of the key is missing", key)
+			continue
+		}
+		record.err = record.validate()
+		if previous, ok := keys[value]; ok && record.err == nil {
+			record.err = fmt.Errorf("the %s is also used in line %d", key, previous)
+		} else if !ok {
+			keys[value] = record.line
+		}
+	}
+	return records, nil
+}
+
+// userSyncKey returns the value of the key field of a user
+func userSyncKey(user *model.User, key string) string {
+	switch key {
+	case userSyncFieldID:
+		return user.Id
+	case userCSVColumnUsername:
+		return user.Username
+	default:
+		return strings.ToLower(user.Email)
+	}
+}
+
+// planUserSync applies the values of the record to a copy of the user,
+// returning the copy and the description of the changes
+func planUserSync(record *userSyncRecord, user *model.User, key string) (*model.User, []string) {
+	updated := user.DeepCopy()
+	fields := map[string]*string{
+		userCSVColumnUsername:  &updated.Username,
+		userCSVColumnEmail:     &updated.Email,
+		userCSVColumnFirstName: &updated.FirstName,
+		userCSVColumnLastName:  &updated.LastName,
+		userCSVColumnNickname:  &updated.Nickname,
+		userSyncFieldPosition:  &updated.Position,
+	}
+
+	var changes []string
+	for _, name := range userSyncFields {
+		current, ok := fields[name]
+		value, present := record.values[name]
+		if !ok || !present || name == key || normalizeUserSyncValue(name, *current) == value {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s %q -> %q", name, *current, value))
+		*current = value
+	}
+	return updated, changes
+}
+
+func userSyncCmdF(c client.Client, cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("file")
+	key, _ := cmd.Flags().GetString("key")
+	format, _ := cmd.Flags().GetString("format")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	deactivateMissing, _ := cmd.Flags().GetBool("deactivate-missing")
+	confirmFlag, _ := cmd.Flags().GetBool("confirm")
+	if format == "" {
+		format = userSyncFormatAuto
+	}
+	if !utils.StringInSlice(key, userSyncKeys) {
+		return fmt.Errorf("invalid key %q, must be one of: %s", key, strings.Join(userSyncKeys, ", "))
+	}
+
+	var r io.Reader = os.Stdin
+	source := "stdin"
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("could not open the users file: %w", err)
+		}
+		defer f.Close()
+		r = f
+		source = path
+	}
+	records, err := readUserSyncFile(r, source, format, key)
+	if err != nil {
+		return err
+	}
+
+	users, err := getPages(c.GetUsers, usersSyncPerPage)
+	if err != nil {
+		return fmt.Errorf("could not get the users: %w", err)
+	}
+	usersByKey := map[string]*model.User{}
+	for _, user := range users {
+		usersByKey[userSyncKey(user, key)] = user
+	}
+
+	var result *multierror.Error
+	succeeded := 0
+	report := func(syncResult *UserSyncResult, err error) {
+		if err != nil {
+			syncResult.Action = UserSyncActionFailed
+			syncResult.Error = err.Error()
+			result = multierror.Append(result, &ArgLineError{Source: source, Line: syncResult.Line, Err: err})
+		} else {
+			succeeded++
+		}
+		syncResult.describe(dryRun)
+		printer.PrintT("{{.Description}}", syncResult)
+	}
+
+	// the users of the rows that failed are marked as synced too, so
+	// they are not deactivated as missing from the file
+	synced := map[string]bool{}
+	failed, valid := 0, 0
+	for _, record := range records {
+		syncResult := &UserSyncResult{Line: record.line, Key: record.values[key]}
+		user, ok := usersByKey[record.values[key]]
+		if ok {
+			synced[user.Id] = true
+		}
+		if record.err != nil {
+			failed++
+			report(syncResult, record.err)
+			continue
+		}
+		valid++
+
+		if !ok {
+			syncResult.Action = UserSyncActionNotFound
+			report(syncResult, nil)
+			continue
+		}
+		syncResult.Username = user.Username
+
+		// deactivated users are reported instead of updated, as
+		// their accounts are not in use
+		if user.DeleteAt != 0 {
+			syncResult.Action = UserSyncActionInactive
+			report(syncResult, nil)
+			continue
+		}
+
+		updated, changes := planUserSync(record, user, key)
+		if len(changes) == 0 {
+			succeeded++
+			continue
+		}
+		syncResult.Action = UserSyncActionUpdate
+		syncResult.Changes = changes
+		if !dryRun {
+			if _, response, err := c.UpdateUser(updated); err != nil {
+				failed++
+				report(syncResult, ExtractErrorFromResponse(response, err))
+				continue
+			}
+		}
+		report(syncResult, nil)
+	}
+
+	if !deactivateMissing {
+		return partialFailure(result, succeeded)
+	}
+
+	// a file that is incomplete or couldn't be read would deactivate
+	// the users that are in it, so the missing users are only
+	// deactivated if all the rows were synced
+	switch {
+	case failed > 0:
+		return partialFailure(multierror.Append(result, fmt.Errorf("the users missing from the file were not deactivated, as %d rows failed", failed)), succeeded)
+	case valid == 0:
+		return partialFailure(multierror.Append(result, errors.New("the users missing from the file were not deactivated, as the file has no valid rows")), succeeded)
+	}
+
+	// the users are deactivated after the updates, and in a stable
+	// order, so the output is the same in every run
+	var missing []*model.User
+	for _, user := range users {
+		if !synced[user.Id] && user.DeleteAt == 0 && !user.IsBot && !user.IsSystemAdmin() {
+			missing = append(missing, user)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Username < missing[j].Username })
+	if len(missing) > 0 && !dryRun && !confirmFlag {
+		if err := getConfirmation(fmt.Sprintf("Are you sure you want to deactivate the %d users missing from the file?", len(missing)), false); err != nil {
+			return partialFailure(multierror.Append(result, err), succeeded)
+		}
+	}
+	for _, user := range missing {
+		syncResult := &UserSyncResult{Key: userSyncKey(user, key), Username: user.Username, Action: UserSyncActionDeactivate}
+		if !dryRun {
+			if err := changeUserActiveStatus(c, user, false); err != nil {
+				syncResult.Action = UserSyncActionFailed
+				syncResult.Error = err.Error()
+				result = multierror.Append(result, err)
+			}
+		}
+		if syncResult.Action != UserSyncActionFailed {
+			succeeded++
+		}
+		syncResult.describe(dryRun)
+		printer.PrintT("{{.Description}}", syncResult)
+	}
+	return partialFailure(result, succeeded)
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/spf13/cobra"
+
+	"github.com/mattermost/mmctl/v6/printer"
+)
+
+func (s *MmctlUnitTestSuite) TestReadUserSyncFile() {
+	s.Run("should read the non empty fields of a CSV file", func() {
+		records, err := readUserSyncFile(strings.NewReader(`Email,first_name,position
+Alice@Example.com,Alice,
+bob@example.com,,Engineer
+alice@example.com,Alicia,
+,Carol,
+`), "hr.csv", userSyncFormatAuto, userCSVColumnEmail)
+		s.Require().NoError(err)
+		s.Require().Len(records, 4)
+		s.Require().Equal(map[string]string{"email": "alice@example.com", "first_name": "Alice"}, records[0].values)
+		s.Require().Equal(2, records[0].line)
+		s.Require().Equal(map[string]string{"email": "bob@example.com", "position": "Engineer"}, records[1].values)
+		s.Require().NoError(records[1].err)
+		s.Require().EqualError(records[2].err, "the email is also used in line 2")
+		s.Require().EqualError(records[3].err, "the email of the key is missing")
+	})
+
+	s.Run("should fail with invalid CSV headers", func() {
+		_, err := readUserSyncFile(strings.NewReader("email,title\n"), "hr.csv", userSyncFormatCSV, userCSVColumnEmail)
+		s.Require().EqualError(err, `hr.csv: unknown column "title", must be one of: id, username, email, first_name, last_name, nickname, position`)
+
+		_, err = readUserSyncFile(strings.NewReader("email,first_name\n"), "hr.csv", userSyncFormatCSV, userCSVColumnUsername)
+		s.Require().EqualError(err, `hr.csv: the "username" column of the key is required`)
+	})
+
+	s.Run("should read the entries of a LDIF file", func() {
+		records, err := readUserSyncFile(strings.NewReader(`version: 1
+
+# engineering
+dn: uid=alice,ou=people,dc=example,dc=com
+objectClass: inetOrgPerson
+uid: alice
+mail: alice@example.com
+givenName:: QWzDrWNl
+sn: Smith
+title: Senior
+  Engineer
+
+dn: uid=bob,ou=people,dc=example,dc=com
+uid: Bob
+mail: bob@example.com
+mail: robert@example.com
+`), "people.ldif", userSyncFormatAuto, userCSVColumnUsername)
+		s.Require().NoError(err)
+		s.Require().Len(records, 2)
+		s.Require().Equal(4, records[0].line)
+		s.Require().Equal(map[string]string{
+			"username":   "alice",
+			"email":      "alice@example.com",
+			"first_name": "Alíce",
+			"last_name":  "Smith",
+			"position":   "Senior Engineer",
+		}, records[0].values)
+		s.Require().Equal(map[string]string{"username": "bob", "email": "bob@example.com"}, records[1].values)
+
+		_, err = readUserSyncFile(strings.NewReader("dn: uid=alice\njpegPhoto:< file:///photo.jpg\n"), "people.ldif", userSyncFormatAuto, userCSVColumnUsername)
+		s.Require().EqualError(err, "people.ldif:2: the values read from URLs are not supported")
+
+		_, err = readUserSyncFile(strings.NewReader(""), "people.ldif", userSyncFormatAuto, userSyncFieldID)
+		s.Require().EqualError(err, `the "id" key can't be used with LDIF files`)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestUserSyncCmd() {
+	dir, err := ioutil.TempDir("", "mmctl-user-sync-")
+	s.Require().NoError(err)
+	s.T().Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "hr.csv")
+	s.Require().NoError(ioutil.WriteFile(path, []byte(`email,first_name,last_name,position
+alice@example.com,Alicia,Smith,Manager
+bob@example.com,Bob,,
+dave@example.com,Dave,,
+`), 0600))
+
+	newUsers := func() []*model.User {
+		return []*model.User{
+			{Id: model.NewId(), Username: "alice", Email: "Alice@example.com", FirstName: "Alice", LastName: "Smith"},
+			{Id: model.NewId(), Username: "bob", Email: "bob@example.com", FirstName: "Bob"},
+			{Id: model.NewId(), Username: "carol", Email: "carol@example.com"},
+			{Id: model.NewId(), Username: "admin", Email: "admin@example.com", Roles: model.SystemAdminRoleId + " " + model.SystemUserRoleId},
+			{Id: model.NewId(), Username: "bot", Email: "bot@example.com", IsBot: true},
+		}
+	}
+	expectUsers := func(users []*model.User) {
+		s.client.
+			EXPECT().
+			GetUsers(0, usersSyncPerPage, "").
+			Return(users, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetUsers(1, usersSyncPerPage, "").
+			Return([]*model.User{}, &model.Response{}, nil).
+			Times(1)
+	}
+	descriptions := func() []string {
+		var lines []string
+		for _, line := range printer.GetLines() {
+			lines = append(lines, line.(*UserSyncResult).Description)
+		}
+		return lines
+	}
+	newCmd := func(dryRun, deactivateMissing bool) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("file", path, "")
+		cmd.Flags().String("key", userCSVColumnEmail, "")
+		cmd.Flags().Bool("dry-run", dryRun, "")
+		cmd.Flags().Bool("deactivate-missing", deactivateMissing, "")
+		cmd.Flags().Bool("confirm", true, "")
+		return cmd
+	}
+
+	s.Run("should print the plan without applying it", func() {
+		printer.Clean()
+		expectUsers(newUsers())
+
+		err := userSyncCmdF(s.client, newCmd(true, true), nil)
+		s.Require().NoError(err)
+		s.Require().Equal([]string{
+			`line 2: alice@example.com would update: first_name "Alice" -> "Alicia", position "" -> "Manager"`,
+			"line 4: dave@example.com not found on the server",
+			"carol@example.com would deactivate, missing from the file",
+		}, descriptions())
+	})
+
+	s.Run("should update and deactivate the users", func() {
+		printer.Clean()
+		users := newUsers()
+		expectUsers(users)
+
+		updated := users[0].DeepCopy()
+		updated.FirstName = "Alicia"
+		updated.Position = "Manager"
+		s.client.
+			EXPECT().
+			UpdateUser(updated).
+			Return(updated, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			UpdateUserActive(users[2].Id, false).
+			Return(&model.Response{}, nil).
+			Times(1)
+
+		err := userSyncCmdF(s.client, newCmd(false, true), nil)
+		s.Require().NoError(err)
+		s.Require().Equal([]string{
+			`line 2: alice@example.com updated: first_name "Alice" -> "Alicia", position "" -> "Manager"`,
+			"line 4: dave@example.com not found on the server",
+			"carol@example.com deactivated, missing from the file",
+		}, descriptions())
+	})
+
+	s.Run("should not update the deactivated users", func() {
+		printer.Clean()
+		users := newUsers()
+		users[0].DeleteAt = model.GetMillis()
+		expectUsers(users)
+
+		err := userSyncCmdF(s.client, newCmd(false, false), nil)
+		s.Require().NoError(err)
+		s.Require().Equal([]string{
+			"line 2: alice@example.com not updated, deactivated on the server",
+			"line 4: dave@example.com not found on the server",
+		}, descriptions())
+		s.Require().Equal(UserSyncActionInactive, printer.GetLines()[0].(*UserSyncResult).Action)
+	})
+
+	s.Run("should not deactivate the missing users when a row fails", func() {
+		printer.Clean()
+		users := newUsers()
+		expectUsers(users)
+
+		updated := users[0].DeepCopy()
+		updated.FirstName = "Alicia"
+		updated.Position = "Manager"
+		s.client.
+			EXPECT().
+			UpdateUser(updated).
+			Return(nil, &model.Response{}, errors.New("email already in use")).
+			Times(1)
+
+		err := userSyncCmdF(s.client, newCmd(false, true), nil)
+		s.Require().Error(err)
+		s.Require().Contains(err.Error(), path+":2: email already in use")
+		s.Require().Contains(err.Error(), "the users missing from the file were not deactivated, as 1 rows failed")
+		s.Require().Equal([]string{
+			`line 2: alice@example.com failed: first_name "Alice" -> "Alicia", position "" -> "Manager": email already in use`,
+			"line 4: dave@example.com not found on the server",
+		}, descriptions())
+	})
+
+	s.Run("should not deactivate the users of invalid rows", func() {
+		printer.Clean()
+		invalidPath := filepath.Join(dir, "invalid.csv")
+		s.Require().NoError(ioutil.WriteFile(invalidPath, []byte("email,username\ncarol@example.com,not a username\n"), 0600))
+		expectUsers(newUsers())
+
+		cmd := newCmd(true, true)
+		s.Require().NoError(cmd.Flags().Set("file", invalidPath))
+		err := userSyncCmdF(s.client, cmd, nil)
+		s.Require().Error(err)
+		s.Require().Contains(err.Error(), "as 1 rows failed")
+		s.Require().Equal([]string{`line 2: carol@example.com failed: invalid username "not a username"`}, descriptions())
+	})
+
+	s.Run("should not deactivate any user with a file without rows", func() {
+		printer.Clean()
+		emptyPath := filepath.Join(dir, "empty.csv")
+		s.Require().NoError(ioutil.WriteFile(emptyPath, []byte("email,first_name\n"), 0600))
+		expectUsers(newUsers())
+
+		cmd := newCmd(false, true)
+		s.Require().NoError(cmd.Flags().Set("file", emptyPath))
+		err := userSyncCmdF(s.client, cmd, nil)
+		s.Require().EqualError(err, "1 error occurred:\n\t* the users missing from the file were not deactivated, as the file has no valid rows\n\n")
+		s.Require().Empty(printer.GetLines())
+	})
+}
@@ -70,6 +70,23 @@ func getUsersFromArgs(c client.Client, userArgs []string) ([]*model.User, error)
 	return users, result.ErrorOrNil()
 }
 
+// getUsersFromFileArgs obtains the users of the arguments returned
+// by fileArgs. The errors of the users read from a file contain the
+// line they were read from
+func getUsersFromFileArgs(c client.Client, args []fileArg) ([]*model.User, error) {
+	users := make([]*model.User, 0, len(args))
+	var result *multierror.Error
+	for _, arg := range args {
+		user, err := getUserFromArg(c, arg.Value)
+		if err != nil {
+			result = multierror.Append(result, arg.wrap(err))
+			continue
+		}
+		users = append(users, user)
+	}
+	return users, result.ErrorOrNil()
+}
+
 func getUserFromArg(c client.Client, userArg string) (*model.User, error) {
 	var user *model.User
 	var response *model.Response
@@ -32,12 +32,13 @@ var ListWebhookCmd = &cobra.Command{
 }
 
 var ShowWebhookCmd = &cobra.Command{
-	Use:     "show [webhookId]",
-	Short:   "Show a webhook",
-	Long:    "Show the webhook specified by [webhookId]",
-	Args:    cobra.ExactArgs(1),
-	Example: "  webhook show w16zb5tu3n1zkqo18goqry1je",
-	RunE:    withClient(showWebhookCmdF),
+	Use:               "show [webhookId]",
+	Short:             "Show a webhook",
+	Long:              "Show the webhook specified by [webhookId]",
+	Args:              cobra.ExactArgs(1),
+	Example:           "  webhook show w16zb5tu3n1zkqo18goqry1je",
+	ValidArgsFunction: validArgs(completeWebhooks, completeNone),
+	RunE:              withClient(showWebhookCmdF),
 }
 
 var CreateIncomingWebhookCmd = &cobra.Command{
@@ -49,12 +50,13 @@ var CreateIncomingWebhookCmd = &cobra.Command{
 }
 
 var ModifyIncomingWebhookCmd = &cobra.Command{
-	Use:     "modify-incoming",
-	Short:   "Modify incoming webhook",
-	Long:    "Modify existing incoming webhook by changing its title, description, channel or icon url",
-	Args:    cobra.ExactArgs(1),
-	Example: "  webhook modify-incoming [webhookID] --channel [channelID] --display-name [displayName] --description [webhookDescription] --lock-to-channel --icon [iconURL]",
-	RunE:    withClient(modifyIncomingWebhookCmdF),
+	Use:               "modify-incoming",
+	Short:             "Modify incoming webhook",
+	Long:              "Modify existing incoming webhook by changing its title, description, channel or icon url",
+	Args:              cobra.ExactArgs(1),
+	Example:           "  webhook modify-incoming [webhookID] --channel [channelID] --display-name [displayName] --description [webhookDescription] --lock-to-channel --icon [iconURL]",
+	ValidArgsFunction: validArgs(completeWebhooks, completeNone),
+	RunE:              withClient(modifyIncomingWebhookCmdF),
 }
 
 var CreateOutgoingWebhookCmd = &cobra.Command{
@@ -67,21 +69,23 @@ var CreateOutgoingWebhookCmd = &cobra.Command{
 }
 
 var ModifyOutgoingWebhookCmd = &cobra.Command{
-	Use:     "modify-outgoing",
-	Short:   "Modify outgoing webhook",
-	Long:    "Modify existing outgoing webhook by changing its title, description, channel, icon, url, content-type, and triggers",
-	Args:    cobra.ExactArgs(1),
-	Example: `  webhook modify-outgoing [webhookId] --channel [channelId] --display-name [displayName] --description "New webhook description" --icon http://localhost:8000/my-slash-handler-bot-icon.png --url http://localhost:8000/my-webhook-handler --content-type "application/json" --trigger-word test --trigger-when start`,
-	RunE:    withClient(modifyOutgoingWebhookCmdF),
+	Use:               "modify-outgoing",
+	Short:             "Modify outgoing webhook",
+	Long:              "Modify existing outgoing webhook by changing its title, description, channel, icon, url, content-type, and triggers",
+	Args:              cobra.ExactArgs(1),
+	Example:           `  webhook modify-outgoing [webhookId] --channel [channelId] --display-name [displayName] --description "New webhook description" --icon http://localhost:8000/my-slash-handler-bot-icon.png --url http://localhost:8000/my-webhook-handler --content-type "application/json" --trigger-word test --trigger-when start`,
+	ValidArgsFunction: validArgs(completeWebhooks, completeNone),
+	RunE:              withClient(modifyOutgoingWebhookCmdF),
 }
 
 var DeleteWebhookCmd = &cobra.Command{
-	Use:     "delete",
-	Short:   "Delete webhooks",
-	Long:    "Delete webhook with given id",
-	Args:    cobra.ExactArgs(1),
-	Example: "  webhook delete [webhookID]",
-	RunE:    withClient(deleteWebhookCmdF),
+	Use:               "delete",
+	Short:             "Delete webhooks",
+	Long:              "Delete webhook with given id",
+	Args:              cobra.ExactArgs(1),
+	Example:           "  webhook delete [webhookID]",
+	ValidArgsFunction: validArgs(completeWebhooks, completeNone),
+	RunE:              withClient(deleteWebhookCmdF),
 }
 
 func listWebhookCmdF(c client.Client, command *cobra.Command, args []string) error {
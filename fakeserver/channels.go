@@ -0,0 +1,386 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+)
+
+// saveChannel validates and stores a new channel
+func (s *Server) saveChannel(channel *model.Channel) *model.AppError {
+	channel.Id = ""
+	channel.PreSave()
+	channel.CreateAt = s.millis()
+	channel.UpdateAt = channel.CreateAt
+
+	if appErr := channel.IsValid(); appErr != nil {
+		return appErr
+	}
+	if _, ok := s.teams[channel.TeamId]; !ok {
+		return notFound("CreateChannel", "team", channel.TeamId)
+	}
+	if s.channelByName(channel.TeamId, channel.Name) != nil {
+		return badRequest("CreateChannel", "store.sql_channel.save_channel.exists.app_error", "name="+channel.Name)
+	}
+
+	s.channels[channel.Id] = channel
+	s.channelMembers[channel.Id] = map[string]*model.ChannelMember{}
+	return nil
+}
+
+func (s *Server) channelByName(teamID, name string) *model.Channel {
+	for _, channel := range s.channels {
+		if channel.TeamId == teamID && channel.Name == name {
+			return channel
+		}
+	}
+	return nil
+}
+
+// requestChannel returns the channel of the channel_id path parameter
+func (s *Server) requestChannel(w http.ResponseWriter, r *http.Request) *model.Channel {
+	id := mux.Vars(r)["channel_id"]
+	channel, ok := s.channels[id]
+	if !ok {
+		writeError(w, notFound("GetChannel", "channel", id))
+		return nil
+	}
+	return channel
+}
+
+func (s *Server) joinChannel(channel *model.Channel, userID, roles string) *model.ChannelMember {
+	if member, ok := s.channelMembers[channel.Id][userID]; ok {
+		return member
+	}
+
+	member := &model.ChannelMember{
+		ChannelId:    channel.Id,
+		UserId:       userID,
+		Roles:        roles,
+		NotifyProps:  model.GetDefaultChannelNotifyProps(),
+		SchemeUser:   true,
+		LastUpdateAt: s.millis(),
+	}
+	s.channelMembers[channel.Id][userID] = member
+	return member
+}
+
+// removeChannel deletes the channel with its members and posts
+func (s *Server) removeChannel(channelID string) {
+	for id, post := range s.posts {
+		if post.ChannelId == channelID {
+			delete(s.posts, id)
+		}
+	}
+	delete(s.channelMembers, channelID)
+	delete(s.channels, channelID)
+}
+
+func sortChannels(channels map[string]*model.Channel, filter func(channel *model.Channel) bool) []*model.Channel {
+	return sortedValues(channels, filter, func(a, b *model.Channel) bool { return a.Name < b.Name })
+}
+
+func (s *Server) createChannel(w http.ResponseWriter, r *http.Request, userID string) {
+	var channel model.Channel
+	if !decodeBody(w, r, &channel) {
+		return
+	}
+
+	channel.CreatorId = userID
+	if appErr := s.saveChannel(&channel); appErr != nil {
+		writeError(w, appErr)
+		return
+	}
+	s.joinChannel(&channel, userID, model.ChannelUserRoleId+" "+model.ChannelAdminRoleId)
+
+	writeJSON(w, http.StatusCreated, &channel)
+}
+
+func (s *Server) getChannel(w http.ResponseWriter, r *http.Request, _ string) {
+	if channel := s.requestChannel(w, r); channel != nil {
+		writeJSON(w, http.StatusOK, channel)
+	}
+}
+
+// storeUpdatedChannel validates the updated copy of a channel and
+// replaces the stored one with it
+func (s *Server) storeUpdatedChannel(w http.ResponseWriter, updated *model.Channel) {
+	updated.PreUpdate()
+	if appErr := updated.IsValid(); appErr != nil {
+		writeError(w, appErr)
+		return
+	}
+	if other := s.channelByName(updated.TeamId, updated.Name); other != nil && other.Id != updated.Id {
+		writeError(w, badRequest("UpdateChannel", "store.sql_channel.update.exists.app_error", "name="+updated.Name))
+		return
+	}
+
+	s.channels[updated.Id] = updated
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) updateChannel(w http.ResponseWriter, r *http.Request, _ string) {
+	channel := s.requestChannel(w, r)
+	if channel == nil {
+		return
+	}
+	var updated model.Channel
+	if !decodeBody(w, r, &updated) {
+		return
+	}
+
+	updated.Id = channel.Id
+	updated.TeamId = channel.TeamId
+	updated.CreateAt = channel.CreateAt
+	updated.DeleteAt = channel.DeleteAt
+	updated.CreatorId = channel.CreatorId
+	s.storeUpdatedChannel(w, &updated)
+}
+
+func (s *Server) patchChannel(w http.ResponseWriter, r *http.Request, _ string) {
+	channel := s.requestChannel(w, r)
+	if channel == nil {
+		return
+	}
+	var patch model.ChannelPatch
+	if !decodeBody(w, r, &patch) {
+		return
+	}
+
+	updated := *channel
+	updated.Patch(&patch)
+	s.storeUpdatedChannel(w, &updated)
+}
+
+func (s *Server) updateChannelPrivacy(w http.ResponseWriter, r *http.Request, _ string) {
+	channel := s.requestChannel(w, r)
+	if channel == nil {
+		return
+	}
+	var props map[string]string
+	if !decodeBody(w, r, &props) {
+		return
+	}
+
+	privacy := model.ChannelType(props["privacy"])
+	if privacy != model.ChannelTypeOpen && privacy != model.ChannelTypePrivate {
+		writeError(w, badRequest("UpdateChannelPrivacy", "api.channel.update_channel_privacy.invalid_type.app_error", "privacy="+string(privacy)))
+		return
+	}
+
+	updated := *channel
+	updated.Type = privacy
+	s.storeUpdatedChannel(w, &updated)
+}
+
+func (s *Server) deleteChannel(w http.ResponseWriter, r *http.Request, _ string) {
+	channel := s.requestChannel(w, r)
+	if channel == nil {
+		return
+	}
+
+	if queryBool(r, "permanent") {
+		s.removeChannel(channel.Id)
+	} else {
+		channel.DeleteAt = s.millis()
+		channel.UpdateAt = channel.DeleteAt
+	}
+	writeStatusOK(w)
+}
+
+func (s *Server) restoreChannel(w http.ResponseWriter, r *http.Request, _ string) {
+	channel := s.requestChannel(w, r)
+	if channel == nil {
+		return
+	}
+
+	channel.DeleteAt = 0
+	channel.UpdateAt = s.millis()
+	writeJSON(w, http.StatusOK, channel)
+}
+
+func (s *Server) moveChannel(w http.ResponseWriter, r *http.Request, _ string) {
+	channel := s.requestChannel(w, r)
+	if channel == nil {
+		return
+	}
+	var props struct {
+		TeamID string `json:"team_id"`
+		Force  bool   `json:"force"`
+	}
+	if !decodeBody(w, r, &props) {
+		return
+	}
+	if _, ok := s.teams[props.TeamID]; !ok {
+		writeError(w, notFound("MoveChannel", "team", props.TeamID))
+		return
+	}
+
+	// the members must belong to the new team unless forced, in which
+	// case the ones that don't are removed
+	for userID := range s.channelMembers[channel.Id] {
+		if s.isTeamMember(props.TeamID, userID) {
+			continue
+		}
+		if !props.Force {
+			writeError(w, badRequest("MoveChannel", "app.channel.move_channel.members_do_not_match.error", "user_id="+userID))
+			return
+		}
+		delete(s.channelMembers[channel.Id], userID)
+	}
+
+	updated := *channel
+	updated.TeamId = props.TeamID
+	s.storeUpdatedChannel(w, &updated)
+}
+
+func (s *Server) getChannelsForTeam(w http.ResponseWriter, r *http.Request, filter func(channel *model.Channel) bool) {
+	team := s.requestTeam(w, r)
+	if team == nil {
+		return
+	}
+
+	channels := sortChannels(s.channels, func(channel *model.Channel) bool {
+		return channel.TeamId == team.Id && filter(channel)
+	})
+	writeJSON(w, http.StatusOK, paginate(r, channels))
+}
+
+func (s *Server) getPublicChannelsForTeam(w http.ResponseWriter, r *http.Request, _ string) {
+	s.getChannelsForTeam(w, r, func(channel *model.Channel) bool {
+		return channel.Type == model.ChannelTypeOpen && channel.DeleteAt == 0
+	})
+}
+
+func (s *Server) getPrivateChannelsForTeam(w http.ResponseWriter, r *http.Request, _ string) {
+	s.getChannelsForTeam(w, r, func(channel *model.Channel) bool {
+		return channel.Type == model.ChannelTypePrivate && channel.DeleteAt == 0
+	})
+}
+
+func (s *Server) getDeletedChannelsForTeam(w http.ResponseWriter, r *http.Request, _ string) {
+	s.getChannelsForTeam(w, r, func(channel *model.Channel) bool {
+		return channel.DeleteAt != 0
+	})
+}
+
+func (s *Server) autocompleteChannelsForTeam(w http.ResponseWriter, r *http.Request, _ string) {
+	team := s.requestTeam(w, r)
+	if team == nil {
+		return
+	}
+
+	name := strings.ToLower(r.URL.Query().Get("name"))
+	channels := sortChannels(s.channels, func(channel *model.Channel) bool {
+		return channel.TeamId == team.Id && channel.DeleteAt == 0 &&
+			(strings.HasPrefix(channel.Name, name) || strings.HasPrefix(strings.ToLower(channel.DisplayName), name))
+	})
+	if len(channels) > model.ChannelSearchDefaultLimit {
+		channels = channels[:model.ChannelSearchDefaultLimit]
+	}
+	writeJSON(w, http.StatusOK, channels)
+}
+
+func (s *Server) writeChannelByName(w http.ResponseWriter, r *http.Request, team *model.Team) {
+	name := mux.Vars(r)["channel_name"]
+	channel := s.channelByName(team.Id, name)
+	if channel == nil || channel.DeleteAt != 0 && !queryBool(r, "include_deleted") {
+		writeError(w, notFound("GetChannelByName", "channel", name))
+		return
+	}
+	writeJSON(w, http.StatusOK, channel)
+}
+
+func (s *Server) getChannelByName(w http.ResponseWriter, r *http.Request, _ string) {
+	if team := s.requestTeam(w, r); team != nil {
+		s.writeChannelByName(w, r, team)
+	}
+}
+
+func (s *Server) getChannelByNameForTeamName(w http.ResponseWriter, r *http.Request, _ string) {
+	name := mux.Vars(r)["team_name"]
+	team := s.teamByName(name)
+	if team == nil {
+		writeError(w, notFound("GetTeamByName", "team", name))
+		return
+	}
+	s.writeChannelByName(w, r, team)
+}
+
+func (s *Server) getChannelMembers(w http.ResponseWriter, r *http.Request, _ string) {
+	channel := s.requestChannel(w, r)
+	if channel == nil {
+		return
+	}
+
+	members := sortedValues(s.channelMembers[channel.Id], nil, func(a, b *model.ChannelMember) bool {
+		return a.UserId < b.UserId
+	})
+	writeJSON(w, http.StatusOK, paginate(r, members))
+}
+
+func (s *Server) addChannelMember(w http.ResponseWriter, r *http.Request, _ string) {
+	channel := s.requestChannel(w, r)
+	if channel == nil {
+		return
+	}
+	var props map[string]string
+	if !decodeBody(w, r, &props) {
+		return
+	}
+
+	userID := props["user_id"]
+	if _, ok := s.users[userID]; !ok {
+		writeError(w, notFound("AddChannelMember", "user", userID))
+		return
+	}
+	if !s.isTeamMember(channel.TeamId, userID) {
+		writeError(w, badRequest("AddChannelMember", "api.channel.add_user.to.channel.failed.deleted.app_error", "the user is not a member of the team"))
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.joinChannel(channel, userID, model.ChannelUserRoleId))
+}
+
+// requestChannelMember returns the member of the channel_id and
+// user_id path parameters
+func (s *Server) requestChannelMember(w http.ResponseWriter, r *http.Request) *model.ChannelMember {
+	channel := s.requestChannel(w, r)
+	if channel == nil {
+		return nil
+	}
+	userID := mux.Vars(r)["user_id"]
+	member, ok := s.channelMembers[channel.Id][userID]
+	if !ok {
+		writeError(w, notFound("GetChannelMember", "channel_member", userID))
+		return nil
+	}
+	return member
+}
+
+func (s *Server) removeChannelMember(w http.ResponseWriter, r *http.Request, _ string) {
+	if member := s.requestChannelMember(w, r); member != nil {
+		delete(s.channelMembers[member.ChannelId], member.UserId)
+		writeStatusOK(w)
+	}
+}
+
+func (s *Server) updateChannelMemberRoles(w http.ResponseWriter, r *http.Request, _ string) {
+	member := s.requestChannelMember(w, r)
+	if member == nil {
+		return
+	}
+	var props map[string]string
+	if !decodeBody(w, r, &props) {
+		return
+	}
+
+	member.Roles = props["roles"]
+	member.LastUpdateAt = s.millis()
+	writeStatusOK(w)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+)
+
+func (s *Server) createPost(w http.ResponseWriter, r *http.Request, userID string) {
+	var post model.Post
+	if !decodeBody(w, r, &post) {
+		return
+	}
+
+	channel, ok := s.channels[post.ChannelId]
+	if !ok || channel.DeleteAt != 0 {
+		writeError(w, notFound("CreatePost", "channel", post.ChannelId))
+		return
+	}
+
+	post.Id = ""
+	post.UserId = userID
+	post.CreateAt = s.millis()
+	post.PreSave()
+	if appErr := post.IsValid(model.PostMessageMaxRunesV2); appErr != nil {
+		writeError(w, appErr)
+		return
+	}
+
+	s.posts[post.Id] = &post
+	channel.LastPostAt = post.CreateAt
+	channel.TotalMsgCount++
+	writeJSON(w, http.StatusCreated, &post)
+}
+
+func (s *Server) getPost(w http.ResponseWriter, r *http.Request, _ string) {
+	id := mux.Vars(r)["post_id"]
+	post, ok := s.posts[id]
+	if !ok {
+		writeError(w, notFound("GetPost", "post", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, post)
+}
+
+// getPostsForChannel returns the posts of the channel from the newest
+// to the oldest, either paginated or the ones created since a time
+func (s *Server) getPostsForChannel(w http.ResponseWriter, r *http.Request, _ string) {
+	channel := s.requestChannel(w, r)
+	if channel == nil {
+		return
+	}
+
+	since, _ := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+	posts := sortedValues(s.posts, func(post *model.Post) bool {
+		return post.ChannelId == channel.Id && post.CreateAt > since
+	}, func(a, b *model.Post) bool {
+		return a.CreateAt > b.CreateAt
+	})
+	if since == 0 {
+		posts = paginate(r, posts)
+	}
+
+	list := model.NewPostList()
+	for _, post := range posts {
+		list.AddPost(post)
+		list.AddOrder(post.Id)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
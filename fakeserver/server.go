@@ -0,0 +1,343 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+// Package fakeserver implements an in-memory subset of the Mattermost
+// API v4 that covers the users, teams, channels, posts, config and
+// jobs endpoints used by mmctl, so scripts can be run against it
+// without a real server.
+//
+// The server doesn't check permissions: every authenticated user can
+// call every endpoint.
+package fakeserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+)
+
+const (
+	DefaultAdminUsername = "admin"
+	DefaultAdminPassword = "Password1!"
+
+	defaultPerPage = 60
+	maxPerPage     = 200
+)
+
+// Options configures the initial state of the server
+type Options struct {
+	// Version is the server version sent to the clients. It defaults
+	// to the version of the model package
+	Version       string
+	AdminUsername string
+	AdminPassword string
+	// AdminToken is an access token of the admin user that is
+	// accepted in addition to the tokens created on login
+	AdminToken string
+}
+
+// Server holds the state of the fake server. Its handlers are safe
+// for concurrent use
+type Server struct {
+	mu         sync.Mutex
+	version    string
+	adminID    string
+	lastMillis int64
+
+	users          map[string]*model.User
+	passwords      map[string]string
+	sessions       map[string]string
+	teams          map[string]*model.Team
+	teamMembers    map[string]map[string]*model.TeamMember
+	channels       map[string]*model.Channel
+	channelMembers map[string]map[string]*model.ChannelMember
+	posts          map[string]*model.Post
+	config         *model.Config
+	jobs           map[string]*model.Job
+}
+
+// handlerFunc handles a request on behalf of the user with the id
+type handlerFunc func(w http.ResponseWriter, r *http.Request, userID string)
+
+// New creates a server with an admin user and the default config
+func New(opts Options) *Server {
+	if opts.Version == "" {
+		opts.Version = model.CurrentVersion
+	}
+	if opts.AdminUsername == "" {
+		opts.AdminUsername = DefaultAdminUsername
+	}
+	if opts.AdminPassword == "" {
+		opts.AdminPassword = DefaultAdminPassword
+	}
+
+	config := &model.Config{}
+	config.SetDefaults()
+
+	s := &Server{
+		version:        opts.Version,
+		users:          map[string]*model.User{},
+		passwords:      map[string]string{},
+		sessions:       map[string]string{},
+		teams:          map[string]*model.Team{},
+		teamMembers:    map[string]map[string]*model.TeamMember{},
+		channels:       map[string]*model.Channel{},
+		channelMembers: map[string]map[string]*model.ChannelMember{},
+		posts:          map[string]*model.Post{},
+		config:         config,
+		jobs:           map[string]*model.Job{},
+	}
+
+	admin := &model.User{
+		Username: opts.AdminUsername,
+		Email:    opts.AdminUsername + "@example.com",
+		Roles:    model.SystemAdminRoleId + " " + model.SystemUserRoleId,
+	}
+	if appErr := s.saveUser(admin, opts.AdminPassword); appErr != nil {
+		panic(appErr)
+	}
+	s.adminID = admin.Id
+	if opts.AdminToken != "" {
+		s.sessions[opts.AdminToken] = admin.Id
+	}
+
+	return s
+}
+
+// Handler returns the handler of the API, which authenticates the
+// requests with the tokens returned on login
+func (s *Server) Handler() http.Handler {
+	return s.router(s.authenticate)
+}
+
+// LocalHandler returns the handler of the API for local mode
+// connections, which run every request as the admin user
+func (s *Server) LocalHandler() http.Handler {
+	return s.router(func(_ *http.Request) (string, *model.AppError) {
+		return s.adminID, nil
+	})
+}
+
+func (s *Server) router(authenticate func(r *http.Request) (string, *model.AppError)) http.Handler {
+	r := mux.NewRouter()
+	api := r.PathPrefix("/api/v4").Subrouter()
+
+	public := func(method, path string, h handlerFunc) {
+		api.Handle(path, s.wrap(h, func(_ *http.Request) (string, *model.AppError) { return "", nil })).Methods(method)
+	}
+	private := func(method, path string, h handlerFunc) {
+		api.Handle(path, s.wrap(h, authenticate)).Methods(method)
+	}
+
+	public(http.MethodGet, "/system/ping", s.ping)
+	public(http.MethodPost, "/users/login", s.login)
+
+	// the routes with fixed segments go first, so they aren't taken
+	// for ids
+	private(http.MethodGet, "/users", s.getUsers)
+	private(http.MethodPost, "/users", s.createUser)
+	private(http.MethodPost, "/users/logout", s.logout)
+	private(http.MethodPost, "/users/ids", s.getUsersByIds)
+	private(http.MethodPost, "/users/usernames", s.getUsersByUsernames)
+	private(http.MethodGet, "/users/autocomplete", s.autocompleteUsers)
+	private(http.MethodGet, "/users/username/{username}", s.getUserByUsername)
+	private(http.MethodGet, "/users/email/{email}", s.getUserByEmail)
+	private(http.MethodGet, "/users/{user_id}", s.getUser)
+	private(http.MethodPut, "/users/{user_id}", s.updateUser)
+	private(http.MethodDelete, "/users/{user_id}", s.deleteUser)
+	private(http.MethodPut, "/users/{user_id}/patch", s.patchUser)
+	private(http.MethodPut, "/users/{user_id}/active", s.updateUserActive)
+	private(http.MethodPut, "/users/{user_id}/roles", s.updateUserRoles)
+	private(http.MethodPut, "/users/{user_id}/password", s.updateUserPassword)
+	private(http.MethodGet, "/users/{user_id}/teams", s.getTeamsForUser)
+	private(http.MethodGet, "/users/{user_id}/teams/{team_id}/channels", s.getChannelsForTeamForUser)
+
+	private(http.MethodGet, "/teams", s.getTeams)
+	private(http.MethodPost, "/teams", s.createTeam)
+	private(http.MethodPost, "/teams/search", s.searchTeams)
+	private(http.MethodGet, "/teams/name/{team_name}", s.getTeamByName)
+	private(http.MethodGet, "/teams/name/{team_name}/channels/name/{channel_name}", s.getChannelByNameForTeamName)
+	private(http.MethodGet, "/teams/{team_id}", s.getTeam)
+	private(http.MethodPut, "/teams/{team_id}", s.updateTeam)
+	private(http.MethodDelete, "/teams/{team_id}", s.deleteTeam)
+	private(http.MethodPut, "/teams/{team_id}/patch", s.patchTeam)
+	private(http.MethodPut, "/teams/{team_id}/privacy", s.updateTeamPrivacy)
+	private(http.MethodPost, "/teams/{team_id}/restore", s.restoreTeam)
+	private(http.MethodGet, "/teams/{team_id}/members", s.getTeamMembers)
+	private(http.MethodPost, "/teams/{team_id}/members", s.addTeamMember)
+	private(http.MethodDelete, "/teams/{team_id}/members/{user_id}", s.removeTeamMember)
+	private(http.MethodPut, "/teams/{team_id}/members/{user_id}/roles", s.updateTeamMemberRoles)
+	private(http.MethodGet, "/teams/{team_id}/channels", s.getPublicChannelsForTeam)
+	private(http.MethodGet, "/teams/{team_id}/channels/private", s.getPrivateChannelsForTeam)
+	private(http.MethodGet, "/teams/{team_id}/channels/deleted", s.getDeletedChannelsForTeam)
+	private(http.MethodGet, "/teams/{team_id}/channels/autocomplete", s.autocompleteChannelsForTeam)
+	private(http.MethodGet, "/teams/{team_id}/channels/name/{channel_name}", s.getChannelByName)
+
+	private(http.MethodPost, "/channels", s.createChannel)
+	private(http.MethodGet, "/channels/{channel_id}", s.getChannel)
+	private(http.MethodPut, "/channels/{channel_id}", s.updateChannel)
+	private(http.MethodDelete, "/channels/{channel_id}", s.deleteChannel)
+	private(http.MethodPut, "/channels/{channel_id}/patch", s.patchChannel)
+	private(http.MethodPut, "/channels/{channel_id}/privacy", s.updateChannelPrivacy)
+	private(http.MethodPost, "/channels/{channel_id}/restore", s.restoreChannel)
+	private(http.MethodPost, "/channels/{channel_id}/move", s.moveChannel)
+	private(http.MethodGet, "/channels/{channel_id}/members", s.getChannelMembers)
+	private(http.MethodPost, "/channels/{channel_id}/members", s.addChannelMember)
+	private(http.MethodDelete, "/channels/{channel_id}/members/{user_id}", s.removeChannelMember)
+	private(http.MethodPut, "/channels/{channel_id}/members/{user_id}/roles", s.updateChannelMemberRoles)
+	private(http.MethodGet, "/channels/{channel_id}/posts", s.getPostsForChannel)
+
+	private(http.MethodPost, "/posts", s.createPost)
+	private(http.MethodGet, "/posts/{post_id}", s.getPost)
+
+	private(http.MethodGet, "/config", s.getConfig)
+	private(http.MethodPut, "/config", s.updateConfig)
+	private(http.MethodPut, "/config/patch", s.patchConfig)
+	private(http.MethodPost, "/config/reload", s.reloadConfig)
+
+	private(http.MethodGet, "/jobs", s.getJobs)
+	private(http.MethodPost, "/jobs", s.createJob)
+	private(http.MethodGet, "/jobs/type/{job_type}", s.getJobsByType)
+	private(http.MethodGet, "/jobs/{job_id}", s.getJob)
+	private(http.MethodPost, "/jobs/{job_id}/cancel", s.cancelJob)
+
+	r.NotFoundHandler = s.wrap(func(w http.ResponseWriter, r *http.Request, _ string) {
+		writeError(w, model.NewAppError("FakeServer", "api.context.404.app_error", nil, "the fake server doesn't implement "+r.Method+" "+r.URL.Path, http.StatusNotFound))
+	}, func(_ *http.Request) (string, *model.AppError) { return "", nil })
+	r.MethodNotAllowedHandler = r.NotFoundHandler
+
+	return r
+}
+
+// wrap authenticates the request and runs the handler holding the
+// lock of the server, so the handlers don't need to synchronize
+func (s *Server) wrap(h handlerFunc, authenticate func(r *http.Request) (string, *model.AppError)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(model.HeaderRequestId, model.NewId())
+		w.Header().Set(model.HeaderVersionId, s.version)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		userID, appErr := authenticate(r)
+		if appErr != nil {
+			writeError(w, appErr)
+			return
+		}
+		h(w, r, userID)
+	})
+}
+
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get(model.HeaderAuth); auth != "" {
+		scheme, value, _ := strings.Cut(auth, " ")
+		if strings.EqualFold(scheme, model.HeaderBearer) || strings.EqualFold(scheme, model.HeaderToken) {
+			return value
+		}
+	}
+	return r.Header.Get(model.HeaderToken)
+}
+
+func (s *Server) authenticate(r *http.Request) (string, *model.AppError) {
+	token := requestToken(r)
+	if userID, ok := s.sessions[token]; ok && token != "" {
+		return userID, nil
+	}
+	return "", model.NewAppError("ServeHTTP", "api.context.session_expired.app_error", nil, "token="+token, http.StatusUnauthorized)
+}
+
+// millis returns the current time in milliseconds, making sure it is
+// different for every call so the objects keep their creation order
+func (s *Server) millis() int64 {
+	now := model.GetMillis()
+	if now <= s.lastMillis {
+		now = s.lastMillis + 1
+	}
+	s.lastMillis = now
+	return now
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeStatusOK(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": model.StatusOk})
+}
+
+func writeError(w http.ResponseWriter, appErr *model.AppError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(appErr.StatusCode)
+	_, _ = w.Write([]byte(appErr.ToJSON()))
+}
+
+// decodeBody reads the JSON body of the request into v, writing an
+// error response if it is invalid
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, model.NewAppError("FakeServer", "api.context.invalid_body_param.app_error", nil, err.Error(), http.StatusBadRequest))
+		return false
+	}
+	return true
+}
+
+func notFound(where, kind, id string) *model.AppError {
+	return model.NewAppError(where, "app."+kind+".get.app_error", nil, kind+" "+id+" not found", http.StatusNotFound)
+}
+
+func badRequest(where, id, details string) *model.AppError {
+	return model.NewAppError(where, id, nil, details, http.StatusBadRequest)
+}
+
+func queryBool(r *http.Request, name string) bool {
+	b, _ := strconv.ParseBool(r.URL.Query().Get(name))
+	return b
+}
+
+// paginate returns the page of the items requested with the page and
+// per_page query parameters
+func paginate[T any](r *http.Request, items []T) []T {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 0 {
+		page = 0
+	}
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+
+	start := page * perPage
+	if start >= len(items) {
+		return []T{}
+	}
+	end := start + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+// sortedValues returns the values of the map that match the filter,
+// sorted with the less function
+func sortedValues[T any](m map[string]T, filter func(v T) bool, less func(a, b T) bool) []T {
+	values := []T{}
+	for _, v := range m {
+		if filter == nil || filter(v) {
+			values = append(values, v)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool { return less(values[i], values[j]) })
+	return values
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, opts Options) *model.Client4 {
+	ts := httptest.NewServer(New(opts).Handler())
+	t.Cleanup(ts.Close)
+
+	c := model.NewAPIv4Client(ts.URL)
+	_, _, err := c.Login(DefaultAdminUsername, DefaultAdminPassword)
+	require.NoError(t, err)
+	return c
+}
+
+func requireStatus(t *testing.T, status int, resp *model.Response, err error) {
+	t.Helper()
+	require.Error(t, err)
+	require.Equal(t, status, resp.StatusCode)
+}
+
+func TestAuthentication(t *testing.T) {
+	ts := httptest.NewServer(New(Options{Version: "7.5.0", AdminToken: "admintoken"}).Handler())
+	defer ts.Close()
+	c := model.NewAPIv4Client(ts.URL)
+
+	status, resp, err := c.GetPing()
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusOk, status)
+	assert.Equal(t, "7.5.0", resp.ServerVersion)
+
+	_, resp, err = c.GetMe("")
+	requireStatus(t, http.StatusUnauthorized, resp, err)
+
+	_, resp, err = c.Login(DefaultAdminUsername, "wrong")
+	requireStatus(t, http.StatusUnauthorized, resp, err)
+
+	me, _, err := c.Login(DefaultAdminUsername, DefaultAdminPassword)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultAdminUsername, me.Username)
+	assert.True(t, me.IsSystemAdmin())
+
+	c.SetToken("admintoken")
+	me2, _, err := c.GetMe("")
+	require.NoError(t, err)
+	assert.Equal(t, me.Id, me2.Id)
+}
+
+func TestUsers(t *testing.T) {
+	c := newTestClient(t, Options{})
+
+	user, resp, err := c.CreateUser(&model.User{Username: "john", Email: "john@example.com", Password: "Password1!"})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Empty(t, user.Password)
+
+	_, resp, err = c.CreateUser(&model.User{Username: "john", Email: "other@example.com", Password: "Password1!"})
+	requireStatus(t, http.StatusBadRequest, resp, err)
+
+	found, _, err := c.GetUserByEmail("john@example.com", "")
+	require.NoError(t, err)
+	assert.Equal(t, user.Id, found.Id)
+
+	nickname := "johnny"
+	patched, _, err := c.PatchUser(user.Id, &model.UserPatch{Nickname: &nickname})
+	require.NoError(t, err)
+	assert.Equal(t, "johnny", patched.Nickname)
+
+	_, err = c.UpdateUserActive(user.Id, false)
+	require.NoError(t, err)
+	found, _, err = c.GetUserByUsername("john", "")
+	require.NoError(t, err)
+	assert.NotZero(t, found.DeleteAt)
+
+	users, _, err := c.GetUsers(0, 1, "")
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, DefaultAdminUsername, users[0].Username)
+	users, _, err = c.GetUsers(1, 1, "")
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "john", users[0].Username)
+
+	_, err = c.PermanentDeleteUser(user.Id)
+	require.NoError(t, err)
+	_, resp, err = c.GetUser(user.Id, "")
+	requireStatus(t, http.StatusNotFound, resp, err)
+}
+
+func TestTeamsAndChannels(t *testing.T) {
+	c := newTestClient(t, Options{})
+
+	team, _, err := c.CreateTeam(&model.Team{Name: "myteam", DisplayName: "My Team", Type: model.TeamOpen})
+	require.NoError(t, err)
+
+	channels, _, err := c.GetPublicChannelsForTeam(team.Id, 0, 100, "")
+	require.NoError(t, err)
+	require.Len(t, channels, 2)
+	assert.Equal(t, "off-topic", channels[0].Name)
+	assert.Equal(t, model.DefaultChannelName, channels[1].Name)
+
+	user, _, err := c.CreateUser(&model.User{Username: "john", Email: "john@example.com", Password: "Password1!"})
+	require.NoError(t, err)
+
+	channel, _, err := c.CreateChannel(&model.Channel{TeamId: team.Id, Name: "secret", DisplayName: "Secret", Type: model.ChannelTypePrivate})
+	require.NoError(t, err)
+	_, resp, err := c.AddChannelMember(channel.Id, user.Id)
+	requireStatus(t, http.StatusBadRequest, resp, err)
+
+	_, _, err = c.AddTeamMember(team.Id, user.Id)
+	require.NoError(t, err)
+	_, _, err = c.AddChannelMember(channel.Id, user.Id)
+	require.NoError(t, err)
+
+	userChannels, _, err := c.GetChannelsForTeamForUser(team.Id, user.Id, false, "")
+	require.NoError(t, err)
+	assert.Len(t, userChannels, 3)
+
+	_, err = c.DeleteChannel(channel.Id)
+	require.NoError(t, err)
+	_, resp, err = c.GetChannelByName("secret", team.Id, "")
+	requireStatus(t, http.StatusNotFound, resp, err)
+	deleted, _, err := c.GetChannelByNameIncludeDeleted("secret", team.Id, "")
+	require.NoError(t, err)
+	assert.Equal(t, channel.Id, deleted.Id)
+
+	found, _, err := c.GetTeamByName("myteam", "")
+	require.NoError(t, err)
+	assert.Equal(t, team.Id, found.Id)
+	teams, _, err := c.SearchTeams(&model.TeamSearch{Term: "my"})
+	require.NoError(t, err)
+	assert.Len(t, teams, 1)
+}
+
+func TestPosts(t *testing.T) {
+	c := newTestClient(t, Options{})
+
+	team, _, err := c.CreateTeam(&model.Team{Name: "myteam", DisplayName: "My Team", Type: model.TeamOpen})
+	require.NoError(t, err)
+	channel, _, err := c.GetChannelByName(model.DefaultChannelName, team.Id, "")
+	require.NoError(t, err)
+
+	first, _, err := c.CreatePost(&model.Post{ChannelId: channel.Id, Message: "first"})
+	require.NoError(t, err)
+	second, _, err := c.CreatePost(&model.Post{ChannelId: channel.Id, Message: "second"})
+	require.NoError(t, err)
+
+	list, _, err := c.GetPostsForChannel(channel.Id, 0, 10, "", false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.Id, first.Id}, list.Order)
+
+	list, _, err = c.GetPostsSince(channel.Id, first.CreateAt, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.Id}, list.Order)
+
+	post, _, err := c.GetPost(first.Id, "")
+	require.NoError(t, err)
+	assert.Equal(t, "first", post.Message)
+}
+
+func TestConfigAndJobs(t *testing.T) {
+	c := newTestClient(t, Options{})
+
+	config, _, err := c.GetConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "", *config.ServiceSettings.SiteURL)
+
+	siteURL := "https://example.com"
+	patched, _, err := c.PatchConfig(&model.Config{ServiceSettings: model.ServiceSettings{SiteURL: &siteURL}})
+	require.NoError(t, err)
+	assert.Equal(t, siteURL, *patched.ServiceSettings.SiteURL)
+	assert.Equal(t, *config.TeamSettings.SiteName, *patched.TeamSettings.SiteName)
+
+	job, _, err := c.CreateJob(&model.Job{Type: model.JobTypeDataRetention})
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusPending, job.Status)
+
+	jobs, _, err := c.GetJobsByType(model.JobTypeDataRetention, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+
+	_, err = c.CancelJob(job.Id)
+	require.NoError(t, err)
+	job, _, err = c.GetJob(job.Id)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobStatusCanceled, job.Status)
+}
+
+func TestLocalHandler(t *testing.T) {
+	ts := httptest.NewServer(New(Options{}).LocalHandler())
+	defer ts.Close()
+
+	me, _, err := model.NewAPIv4Client(ts.URL).GetMe("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultAdminUsername, me.Username)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+)
+
+func (s *Server) ping(w http.ResponseWriter, r *http.Request, _ string) {
+	status := map[string]string{"status": model.StatusOk}
+	if queryBool(r, "get_server_status") {
+		status["database_status"] = model.StatusOk
+		status["filestore_status"] = model.StatusOk
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) getConfig(w http.ResponseWriter, _ *http.Request, _ string) {
+	writeJSON(w, http.StatusOK, s.config)
+}
+
+// storeConfig validates the config and replaces the stored one with it
+func (s *Server) storeConfig(w http.ResponseWriter, config *model.Config) {
+	config.SetDefaults()
+	if appErr := config.IsValid(); appErr != nil {
+		writeError(w, appErr)
+		return
+	}
+
+	s.config = config
+	writeJSON(w, http.StatusOK, config)
+}
+
+func (s *Server) updateConfig(w http.ResponseWriter, r *http.Request, _ string) {
+	var config model.Config
+	if !decodeBody(w, r, &config) {
+		return
+	}
+	s.storeConfig(w, &config)
+}
+
+// patchConfig merges the fields of the patch that are set into the
+// config
+func (s *Server) patchConfig(w http.ResponseWriter, r *http.Request, _ string) {
+	var patch map[string]any
+	if !decodeBody(w, r, &patch) {
+		return
+	}
+
+	b, err := json.Marshal(s.config)
+	if err != nil {
+		writeError(w, model.NewAppError("PatchConfig", "api.config.patch_config.app_error", nil, err.Error(), http.StatusInternalServerError))
+		return
+	}
+	var current map[string]any
+	_ = json.Unmarshal(b, &current)
+	mergeJSON(current, patch)
+
+	var config model.Config
+	b, _ = json.Marshal(current)
+	if err := json.Unmarshal(b, &config); err != nil {
+		writeError(w, badRequest("PatchConfig", "api.config.patch_config.app_error", err.Error()))
+		return
+	}
+	s.storeConfig(w, &config)
+}
+
+// mergeJSON copies the values of src that aren't null into dst,
+// merging the nested objects
+func mergeJSON(dst, src map[string]any) {
+	for key, value := range src {
+		if value == nil {
+			continue
+		}
+		srcObject, srcIsObject := value.(map[string]any)
+		dstObject, dstIsObject := dst[key].(map[string]any)
+		if srcIsObject && dstIsObject {
+			mergeJSON(dstObject, srcObject)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+func (s *Server) reloadConfig(w http.ResponseWriter, _ *http.Request, _ string) {
+	writeStatusOK(w)
+}
+
+func sortJobs(jobs map[string]*model.Job, filter func(job *model.Job) bool) []*model.Job {
+	return sortedValues(jobs, filter, func(a, b *model.Job) bool { return a.CreateAt > b.CreateAt })
+}
+
+func (s *Server) getJobs(w http.ResponseWriter, r *http.Request, _ string) {
+	writeJSON(w, http.StatusOK, paginate(r, sortJobs(s.jobs, nil)))
+}
+
+func (s *Server) getJobsByType(w http.ResponseWriter, r *http.Request, _ string) {
+	jobType := mux.Vars(r)["job_type"]
+	jobs := sortJobs(s.jobs, func(job *model.Job) bool { return job.Type == jobType })
+	writeJSON(w, http.StatusOK, paginate(r, jobs))
+}
+
+// createJob stores the job as pending. Jobs never run, so their status
+// only changes if they are canceled
+func (s *Server) createJob(w http.ResponseWriter, r *http.Request, _ string) {
+	var job model.Job
+	if !decodeBody(w, r, &job) {
+		return
+	}
+	if job.Type == "" {
+		writeError(w, badRequest("CreateJob", "model.job.is_valid.type.app_error", ""))
+		return
+	}
+
+	job.Id = model.NewId()
+	job.CreateAt = s.millis()
+	job.Status = model.JobStatusPending
+	job.StartAt = 0
+	job.LastActivityAt = 0
+	job.Progress = 0
+	if appErr := job.IsValid(); appErr != nil {
+		writeError(w, appErr)
+		return
+	}
+
+	s.jobs[job.Id] = &job
+	writeJSON(w, http.StatusCreated, &job)
+}
+
+func (s *Server) requestJob(w http.ResponseWriter, r *http.Request) *model.Job {
+	id := mux.Vars(r)["job_id"]
+	job, ok := s.jobs[id]
+	if !ok {
+		writeError(w, notFound("GetJob", "job", id))
+		return nil
+	}
+	return job
+}
+
+func (s *Server) getJob(w http.ResponseWriter, r *http.Request, _ string) {
+	if job := s.requestJob(w, r); job != nil {
+		writeJSON(w, http.StatusOK, job)
+	}
+}
+
+func (s *Server) cancelJob(w http.ResponseWriter, r *http.Request, _ string) {
+	job := s.requestJob(w, r)
+	if job == nil {
+		return
+	}
+
+	if job.Status != model.JobStatusPending && job.Status != model.JobStatusInProgress {
+		writeError(w, badRequest("CancelJob", "app.job.update.app_error", "the job is "+job.Status))
+		return
+	}
+	job.Status = model.JobStatusCanceled
+	job.LastActivityAt = s.millis()
+	writeStatusOK(w)
+}
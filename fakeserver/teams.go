@@ -0,0 +1,322 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+)
+
+// defaultChannels are created with every team, and its members join
+// them
+var defaultChannels = []struct{ name, displayName string }{
+	{model.DefaultChannelName, "Town Square"},
+	{"off-topic", "Off-Topic"},
+}
+
+func (s *Server) teamByName(name string) *model.Team {
+	for _, team := range s.teams {
+		if team.Name == name {
+			return team
+		}
+	}
+	return nil
+}
+
+// requestTeam returns the team of the team_id path parameter
+func (s *Server) requestTeam(w http.ResponseWriter, r *http.Request) *model.Team {
+	id := mux.Vars(r)["team_id"]
+	team, ok := s.teams[id]
+	if !ok {
+		writeError(w, notFound("GetTeam", "team", id))
+		return nil
+	}
+	return team
+}
+
+func (s *Server) isTeamMember(teamID, userID string) bool {
+	member, ok := s.teamMembers[teamID][userID]
+	return ok && member.DeleteAt == 0
+}
+
+func sortTeams(teams map[string]*model.Team, filter func(team *model.Team) bool) []*model.Team {
+	return sortedValues(teams, filter, func(a, b *model.Team) bool { return a.Name < b.Name })
+}
+
+// joinTeam adds the user to the team and its default channels
+func (s *Server) joinTeam(team *model.Team, userID, roles string) *model.TeamMember {
+	member := &model.TeamMember{
+		TeamId:     team.Id,
+		UserId:     userID,
+		Roles:      roles,
+		SchemeUser: true,
+		CreateAt:   s.millis(),
+	}
+	s.teamMembers[team.Id][userID] = member
+
+	for _, channel := range s.channels {
+		if channel.TeamId != team.Id || channel.DeleteAt != 0 {
+			continue
+		}
+		for _, dc := range defaultChannels {
+			if channel.Name == dc.name {
+				s.joinChannel(channel, userID, model.ChannelUserRoleId)
+			}
+		}
+	}
+	return member
+}
+
+func (s *Server) getTeams(w http.ResponseWriter, r *http.Request, _ string) {
+	includeDeleted := queryBool(r, "include_deleted")
+	teams := sortTeams(s.teams, func(team *model.Team) bool {
+		return team.DeleteAt == 0 || includeDeleted
+	})
+	writeJSON(w, http.StatusOK, paginate(r, teams))
+}
+
+func (s *Server) createTeam(w http.ResponseWriter, r *http.Request, userID string) {
+	var team model.Team
+	if !decodeBody(w, r, &team) {
+		return
+	}
+
+	team.Id = ""
+	team.PreSave()
+	team.CreateAt = s.millis()
+	team.UpdateAt = team.CreateAt
+	if appErr := team.IsValid(); appErr != nil {
+		writeError(w, appErr)
+		return
+	}
+	if s.teamByName(team.Name) != nil {
+		writeError(w, badRequest("CreateTeam", "store.sql_team.save_team.existing.app_error", "name="+team.Name))
+		return
+	}
+
+	s.teams[team.Id] = &team
+	s.teamMembers[team.Id] = map[string]*model.TeamMember{}
+	for _, dc := range defaultChannels {
+		channel := &model.Channel{TeamId: team.Id, Name: dc.name, DisplayName: dc.displayName, Type: model.ChannelTypeOpen}
+		if appErr := s.saveChannel(channel); appErr != nil {
+			writeError(w, appErr)
+			return
+		}
+	}
+	s.joinTeam(&team, userID, model.TeamUserRoleId+" "+model.TeamAdminRoleId)
+
+	writeJSON(w, http.StatusCreated, &team)
+}
+
+func (s *Server) searchTeams(w http.ResponseWriter, r *http.Request, _ string) {
+	var search model.TeamSearch
+	if !decodeBody(w, r, &search) {
+		return
+	}
+
+	term := strings.ToLower(search.Term)
+	teams := sortTeams(s.teams, func(team *model.Team) bool {
+		return strings.Contains(team.Name, term) || strings.Contains(strings.ToLower(team.DisplayName), term)
+	})
+	writeJSON(w, http.StatusOK, teams)
+}
+
+func (s *Server) getTeamByName(w http.ResponseWriter, r *http.Request, _ string) {
+	name := mux.Vars(r)["team_name"]
+	team := s.teamByName(name)
+	if team == nil {
+		writeError(w, notFound("GetTeamByName", "team", name))
+		return
+	}
+	writeJSON(w, http.StatusOK, team)
+}
+
+func (s *Server) getTeam(w http.ResponseWriter, r *http.Request, _ string) {
+	if team := s.requestTeam(w, r); team != nil {
+		writeJSON(w, http.StatusOK, team)
+	}
+}
+
+// storeUpdatedTeam validates the updated copy of a team and replaces
+// the stored one with it
+func (s *Server) storeUpdatedTeam(w http.ResponseWriter, updated *model.Team) {
+	updated.UpdateAt = s.millis()
+	if appErr := updated.IsValid(); appErr != nil {
+		writeError(w, appErr)
+		return
+	}
+	if other := s.teamByName(updated.Name); other != nil && other.Id != updated.Id {
+		writeError(w, badRequest("UpdateTeam", "store.sql_team.save_team.existing.app_error", "name="+updated.Name))
+		return
+	}
+
+	s.teams[updated.Id] = updated
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) updateTeam(w http.ResponseWriter, r *http.Request, _ string) {
+	team := s.requestTeam(w, r)
+	if team == nil {
+		return
+	}
+	var updated model.Team
+	if !decodeBody(w, r, &updated) {
+		return
+	}
+
+	updated.Id = team.Id
+	updated.Name = team.Name
+	updated.CreateAt = team.CreateAt
+	updated.DeleteAt = team.DeleteAt
+	updated.InviteId = team.InviteId
+	s.storeUpdatedTeam(w, &updated)
+}
+
+func (s *Server) patchTeam(w http.ResponseWriter, r *http.Request, _ string) {
+	team := s.requestTeam(w, r)
+	if team == nil {
+		return
+	}
+	var patch model.TeamPatch
+	if !decodeBody(w, r, &patch) {
+		return
+	}
+
+	updated := *team
+	updated.Patch(&patch)
+	s.storeUpdatedTeam(w, &updated)
+}
+
+func (s *Server) updateTeamPrivacy(w http.ResponseWriter, r *http.Request, _ string) {
+	team := s.requestTeam(w, r)
+	if team == nil {
+		return
+	}
+	var props map[string]string
+	if !decodeBody(w, r, &props) {
+		return
+	}
+
+	updated := *team
+	updated.Type = props["privacy"]
+	updated.AllowOpenInvite = updated.Type == model.TeamOpen
+	s.storeUpdatedTeam(w, &updated)
+}
+
+func (s *Server) deleteTeam(w http.ResponseWriter, r *http.Request, _ string) {
+	team := s.requestTeam(w, r)
+	if team == nil {
+		return
+	}
+
+	if !queryBool(r, "permanent") {
+		team.DeleteAt = s.millis()
+		team.UpdateAt = team.DeleteAt
+		writeStatusOK(w)
+		return
+	}
+
+	for _, channel := range s.channels {
+		if channel.TeamId == team.Id {
+			s.removeChannel(channel.Id)
+		}
+	}
+	delete(s.teamMembers, team.Id)
+	delete(s.teams, team.Id)
+	writeStatusOK(w)
+}
+
+func (s *Server) restoreTeam(w http.ResponseWriter, r *http.Request, _ string) {
+	team := s.requestTeam(w, r)
+	if team == nil {
+		return
+	}
+
+	team.DeleteAt = 0
+	team.UpdateAt = s.millis()
+	writeJSON(w, http.StatusOK, team)
+}
+
+func (s *Server) getTeamMembers(w http.ResponseWriter, r *http.Request, _ string) {
+	team := s.requestTeam(w, r)
+	if team == nil {
+		return
+	}
+
+	members := sortedValues(s.teamMembers[team.Id], func(member *model.TeamMember) bool {
+		return member.DeleteAt == 0
+	}, func(a, b *model.TeamMember) bool {
+		return a.UserId < b.UserId
+	})
+	writeJSON(w, http.StatusOK, paginate(r, members))
+}
+
+func (s *Server) addTeamMember(w http.ResponseWriter, r *http.Request, _ string) {
+	team := s.requestTeam(w, r)
+	if team == nil {
+		return
+	}
+	var member model.TeamMember
+	if !decodeBody(w, r, &member) {
+		return
+	}
+	if _, ok := s.users[member.UserId]; !ok {
+		writeError(w, notFound("AddTeamMember", "user", member.UserId))
+		return
+	}
+
+	if s.isTeamMember(team.Id, member.UserId) {
+		writeJSON(w, http.StatusCreated, s.teamMembers[team.Id][member.UserId])
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.joinTeam(team, member.UserId, model.TeamUserRoleId))
+}
+
+// requestTeamMember returns the member of the team_id and user_id path
+// parameters
+func (s *Server) requestTeamMember(w http.ResponseWriter, r *http.Request) *model.TeamMember {
+	team := s.requestTeam(w, r)
+	if team == nil {
+		return nil
+	}
+	userID := mux.Vars(r)["user_id"]
+	if !s.isTeamMember(team.Id, userID) {
+		writeError(w, notFound("GetTeamMember", "team_member", userID))
+		return nil
+	}
+	return s.teamMembers[team.Id][userID]
+}
+
+func (s *Server) removeTeamMember(w http.ResponseWriter, r *http.Request, _ string) {
+	member := s.requestTeamMember(w, r)
+	if member == nil {
+		return
+	}
+
+	member.DeleteAt = s.millis()
+	for _, channel := range s.channels {
+		if channel.TeamId == member.TeamId {
+			delete(s.channelMembers[channel.Id], member.UserId)
+		}
+	}
+	writeStatusOK(w)
+}
+
+func (s *Server) updateTeamMemberRoles(w http.ResponseWriter, r *http.Request, _ string) {
+	member := s.requestTeamMember(w, r)
+	if member == nil {
+		return
+	}
+	var props map[string]string
+	if !decodeBody(w, r, &props) {
+		return
+	}
+
+	member.Roles = props["roles"]
+	writeStatusOK(w)
+}
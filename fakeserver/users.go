@@ -0,0 +1,410 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package fakeserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+)
+
+// saveUser validates and stores a new user
+func (s *Server) saveUser(user *model.User, password string) *model.AppError {
+	user.Id = ""
+	user.Password = ""
+	user.PreSave()
+	user.CreateAt = s.millis()
+	user.UpdateAt = user.CreateAt
+	user.LastPasswordUpdate = user.CreateAt
+	if user.Roles == "" {
+		user.Roles = model.SystemUserRoleId
+	}
+
+	if appErr := user.IsValid(); appErr != nil {
+		return appErr
+	}
+	if len(password) < *s.config.PasswordSettings.MinimumLength {
+		return badRequest("CreateUser", "model.user.is_valid.pwd.app_error", "the password is too short")
+	}
+	if appErr := s.checkUserUnique(user); appErr != nil {
+		return appErr
+	}
+
+	s.users[user.Id] = user
+	s.passwords[user.Id] = password
+	return nil
+}
+
+// checkUserUnique checks that no other user has the username or the
+// email of the user
+func (s *Server) checkUserUnique(user *model.User) *model.AppError {
+	if other := s.userByUsername(user.Username); other != nil && other.Id != user.Id {
+		return badRequest("SaveUser", "app.user.save.username_exists.app_error", "username="+user.Username)
+	}
+	if other := s.userByEmail(user.Email); other != nil && other.Id != user.Id {
+		return badRequest("SaveUser", "app.user.save.email_exists.app_error", "email="+user.Email)
+	}
+	return nil
+}
+
+func (s *Server) userByUsername(username string) *model.User {
+	username = model.NormalizeUsername(username)
+	for _, user := range s.users {
+		if user.Username == username {
+			return user
+		}
+	}
+	return nil
+}
+
+func (s *Server) userByEmail(email string) *model.User {
+	email = model.NormalizeEmail(email)
+	for _, user := range s.users {
+		if user.Email == email {
+			return user
+		}
+	}
+	return nil
+}
+
+// requestUser returns the user of the user_id path parameter, which
+// can be "me" for the user making the request
+func (s *Server) requestUser(w http.ResponseWriter, r *http.Request, userID string) *model.User {
+	id := mux.Vars(r)["user_id"]
+	if id == model.Me {
+		id = userID
+	}
+	user, ok := s.users[id]
+	if !ok {
+		writeError(w, notFound("GetUser", "user", id))
+		return nil
+	}
+	return user
+}
+
+func sortUsers(users map[string]*model.User, filter func(user *model.User) bool) []*model.User {
+	return sortedValues(users, filter, func(a, b *model.User) bool { return a.Username < b.Username })
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request, _ string) {
+	var props map[string]string
+	if !decodeBody(w, r, &props) {
+		return
+	}
+
+	user := s.userByUsername(props["login_id"])
+	if user == nil {
+		user = s.userByEmail(props["login_id"])
+	}
+	if user == nil || user.DeleteAt != 0 || s.passwords[user.Id] != props["password"] {
+		writeError(w, model.NewAppError("login", "api.user.login.invalid_credentials_email_username", nil, "", http.StatusUnauthorized))
+		return
+	}
+
+	token := model.NewId()
+	s.sessions[token] = user.Id
+	w.Header().Set(model.HeaderToken, token)
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request, _ string) {
+	delete(s.sessions, requestToken(r))
+	writeStatusOK(w)
+}
+
+func (s *Server) getUsers(w http.ResponseWriter, r *http.Request, _ string) {
+	query := r.URL.Query()
+	teamID := query.Get("in_team")
+	inactive := queryBool(r, "inactive")
+	active := queryBool(r, "active")
+
+	users := sortUsers(s.users, func(user *model.User) bool {
+		if teamID != "" && !s.isTeamMember(teamID, user.Id) {
+			return false
+		}
+		if inactive && user.DeleteAt == 0 || active && user.DeleteAt != 0 {
+			return false
+		}
+		return true
+	})
+	writeJSON(w, http.StatusOK, paginate(r, users))
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request, _ string) {
+	var user model.User
+	if !decodeBody(w, r, &user) {
+		return
+	}
+
+	if appErr := s.saveUser(&user, user.Password); appErr != nil {
+		writeError(w, appErr)
+		return
+	}
+	writeJSON(w, http.StatusCreated, &user)
+}
+
+func (s *Server) getUsersByIds(w http.ResponseWriter, r *http.Request, _ string) {
+	var ids []string
+	if !decodeBody(w, r, &ids) {
+		return
+	}
+
+	users := []*model.User{}
+	for _, id := range ids {
+		if user, ok := s.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (s *Server) getUsersByUsernames(w http.ResponseWriter, r *http.Request, _ string) {
+	var usernames []string
+	if !decodeBody(w, r, &usernames) {
+		return
+	}
+
+	users := []*model.User{}
+	for _, username := range usernames {
+		if user := s.userByUsername(username); user != nil {
+			users = append(users, user)
+		}
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (s *Server) autocompleteUsers(w http.ResponseWriter, r *http.Request, _ string) {
+	name := strings.ToLower(r.URL.Query().Get("name"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = model.UserSearchDefaultLimit
+	}
+
+	users := sortUsers(s.users, func(user *model.User) bool {
+		if user.DeleteAt != 0 {
+			return false
+		}
+		for _, field := range []string{user.Username, user.FirstName, user.LastName, user.Nickname} {
+			if strings.HasPrefix(strings.ToLower(field), name) {
+				return true
+			}
+		}
+		return false
+	})
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	writeJSON(w, http.StatusOK, &model.UserAutocomplete{Users: users})
+}
+
+func (s *Server) getUserByUsername(w http.ResponseWriter, r *http.Request, _ string) {
+	username := mux.Vars(r)["username"]
+	user := s.userByUsername(username)
+	if user == nil {
+		writeError(w, notFound("GetUserByUsername", "user", username))
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) getUserByEmail(w http.ResponseWriter, r *http.Request, _ string) {
+	email := mux.Vars(r)["email"]
+	user := s.userByEmail(email)
+	if user == nil {
+		writeError(w, notFound("GetUserByEmail", "user", email))
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request, userID string) {
+	if user := s.requestUser(w, r, userID); user != nil {
+		writeJSON(w, http.StatusOK, user)
+	}
+}
+
+// storeUpdatedUser validates the updated copy of a user and replaces
+// the stored one with it
+func (s *Server) storeUpdatedUser(w http.ResponseWriter, updated *model.User) {
+	updated.Username = model.NormalizeUsername(updated.Username)
+	updated.Email = model.NormalizeEmail(updated.Email)
+	updated.Password = ""
+	updated.UpdateAt = s.millis()
+
+	if appErr := updated.IsValid(); appErr != nil {
+		writeError(w, appErr)
+		return
+	}
+	if appErr := s.checkUserUnique(updated); appErr != nil {
+		writeError(w, appErr)
+		return
+	}
+
+	s.users[updated.Id] = updated
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request, userID string) {
+	user := s.requestUser(w, r, userID)
+	if user == nil {
+		return
+	}
+	var updated model.User
+	if !decodeBody(w, r, &updated) {
+		return
+	}
+
+	// the fields that have their own endpoints are kept
+	updated.Id = user.Id
+	updated.CreateAt = user.CreateAt
+	updated.DeleteAt = user.DeleteAt
+	updated.Roles = user.Roles
+	updated.LastPasswordUpdate = user.LastPasswordUpdate
+	s.storeUpdatedUser(w, &updated)
+}
+
+func (s *Server) patchUser(w http.ResponseWriter, r *http.Request, userID string) {
+	user := s.requestUser(w, r, userID)
+	if user == nil {
+		return
+	}
+	var patch model.UserPatch
+	if !decodeBody(w, r, &patch) {
+		return
+	}
+
+	updated := *user
+	updated.Patch(&patch)
+	s.storeUpdatedUser(w, &updated)
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request, userID string) {
+	user := s.requestUser(w, r, userID)
+	if user == nil {
+		return
+	}
+
+	if queryBool(r, "permanent") {
+		delete(s.users, user.Id)
+		delete(s.passwords, user.Id)
+		for _, members := range s.teamMembers {
+			delete(members, user.Id)
+		}
+		for _, members := range s.channelMembers {
+			delete(members, user.Id)
+		}
+	} else {
+		user.DeleteAt = s.millis()
+		user.UpdateAt = user.DeleteAt
+	}
+	s.revokeSessions(user.Id)
+	writeStatusOK(w)
+}
+
+func (s *Server) revokeSessions(userID string) {
+	for token, id := range s.sessions {
+		if id == userID {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+func (s *Server) updateUserActive(w http.ResponseWriter, r *http.Request, userID string) {
+	user := s.requestUser(w, r, userID)
+	if user == nil {
+		return
+	}
+	var props struct {
+		Active bool `json:"active"`
+	}
+	if !decodeBody(w, r, &props) {
+		return
+	}
+
+	user.UpdateAt = s.millis()
+	if props.Active {
+		user.DeleteAt = 0
+	} else {
+		user.DeleteAt = user.UpdateAt
+		s.revokeSessions(user.Id)
+	}
+	writeStatusOK(w)
+}
+
+func (s *Server) updateUserRoles(w http.ResponseWriter, r *http.Request, userID string) {
+	user := s.requestUser(w, r, userID)
+	if user == nil {
+		return
+	}
+	var props map[string]string
+	if !decodeBody(w, r, &props) {
+		return
+	}
+
+	user.Roles = props["roles"]
+	user.UpdateAt = s.millis()
+	writeStatusOK(w)
+}
+
+func (s *Server) updateUserPassword(w http.ResponseWriter, r *http.Request, userID string) {
+	user := s.requestUser(w, r, userID)
+	if user == nil {
+		return
+	}
+	var props map[string]string
+	if !decodeBody(w, r, &props) {
+		return
+	}
+
+	// users changing their own password must know the current one
+	if user.Id == userID && s.passwords[user.Id] != props["current_password"] {
+		writeError(w, badRequest("UpdatePassword", "api.user.update_password.incorrect.app_error", ""))
+		return
+	}
+	if len(props["new_password"]) < *s.config.PasswordSettings.MinimumLength {
+		writeError(w, badRequest("UpdatePassword", "model.user.is_valid.pwd.app_error", "the password is too short"))
+		return
+	}
+
+	s.passwords[user.Id] = props["new_password"]
+	user.LastPasswordUpdate = s.millis()
+	writeStatusOK(w)
+}
+
+func (s *Server) getTeamsForUser(w http.ResponseWriter, r *http.Request, userID string) {
+	user := s.requestUser(w, r, userID)
+	if user == nil {
+		return
+	}
+
+	teams := sortTeams(s.teams, func(team *model.Team) bool {
+		return s.isTeamMember(team.Id, user.Id)
+	})
+	writeJSON(w, http.StatusOK, teams)
+}
+
+func (s *Server) getChannelsForTeamForUser(w http.ResponseWriter, r *http.Request, userID string) {
+	user := s.requestUser(w, r, userID)
+	if user == nil {
+		return
+	}
+	team := s.requestTeam(w, r)
+	if team == nil {
+		return
+	}
+	includeDeleted := queryBool(r, "include_deleted")
+
+	channels := sortChannels(s.channels, func(channel *model.Channel) bool {
+		if channel.TeamId != team.Id || channel.DeleteAt != 0 && !includeDeleted {
+			return false
+		}
+		_, ok := s.channelMembers[channel.Id][user.Id]
+		return ok
+	})
+	writeJSON(w, http.StatusOK, channels)
+}
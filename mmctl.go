@@ -13,6 +13,6 @@ import (
 
 func main() {
 	if err := commands.Run(os.Args[1:]); err != nil {
-		os.Exit(1)
+		os.Exit(commands.ExitCodeForError(err))
 	}
 }
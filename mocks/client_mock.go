@@ -87,6 +87,38 @@ func (mr *MockClientMockRecorder) AssignBot(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignBot", reflect.TypeOf((*MockClient)(nil).AssignBot), arg0, arg1)
 }
 
+// AutocompleteChannelsForTeam mocks base method.
+func (m *MockClient) AutocompleteChannelsForTeam(arg0, arg1 string) (model.ChannelList, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AutocompleteChannelsForTeam", arg0, arg1)
+	ret0, _ := ret[0].(model.ChannelList)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AutocompleteChannelsForTeam indicates an expected call of AutocompleteChannelsForTeam.
+func (mr *MockClientMockRecorder) AutocompleteChannelsForTeam(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AutocompleteChannelsForTeam", reflect.TypeOf((*MockClient)(nil).AutocompleteChannelsForTeam), arg0, arg1)
+}
+
+// AutocompleteUsers mocks base method.
+func (m *MockClient) AutocompleteUsers(arg0 string, arg1 int, arg2 string) (*model.UserAutocomplete, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AutocompleteUsers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.UserAutocomplete)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AutocompleteUsers indicates an expected call of AutocompleteUsers.
+func (mr *MockClientMockRecorder) AutocompleteUsers(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AutocompleteUsers", reflect.TypeOf((*MockClient)(nil).AutocompleteUsers), arg0, arg1, arg2)
+}
+
 // CancelJob mocks base method.
 func (m *MockClient) CancelJob(arg0 string) (*model.Response, error) {
 	m.ctrl.T.Helper()
@@ -416,6 +448,21 @@ func (mr *MockClientMockRecorder) DeleteOutgoingWebhook(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutgoingWebhook", reflect.TypeOf((*MockClient)(nil).DeleteOutgoingWebhook), arg0)
 }
 
+// DeletePreferences mocks base method.
+func (m *MockClient) DeletePreferences(arg0 string, arg1 model.Preferences) (*model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePreferences", arg0, arg1)
+	ret0, _ := ret[0].(*model.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePreferences indicates an expected call of DeletePreferences.
+func (mr *MockClientMockRecorder) DeletePreferences(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePreferences", reflect.TypeOf((*MockClient)(nil).DeletePreferences), arg0, arg1)
+}
+
 // DemoteUserToGuest mocks base method.
 func (m *MockClient) DemoteUserToGuest(arg0 string) (*model.Response, error) {
 	m.ctrl.T.Helper()
@@ -524,6 +571,22 @@ func (mr *MockClientMockRecorder) EnablePlugin(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnablePlugin", reflect.TypeOf((*MockClient)(nil).EnablePlugin), arg0)
 }
 
+// GetAllRoles mocks base method.
+func (m *MockClient) GetAllRoles() ([]*model.Role, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllRoles")
+	ret0, _ := ret[0].([]*model.Role)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAllRoles indicates an expected call of GetAllRoles.
+func (mr *MockClientMockRecorder) GetAllRoles() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRoles", reflect.TypeOf((*MockClient)(nil).GetAllRoles))
+}
+
 // GetAllTeams mocks base method.
 func (m *MockClient) GetAllTeams(arg0 string, arg1, arg2 int) ([]*model.Team, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -750,6 +813,22 @@ func (mr *MockClientMockRecorder) GetGroupsByTeam(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupsByTeam", reflect.TypeOf((*MockClient)(nil).GetGroupsByTeam), arg0, arg1)
 }
 
+// GetGroupsByUserId mocks base method.
+func (m *MockClient) GetGroupsByUserId(arg0 string) ([]*model.Group, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupsByUserId", arg0)
+	ret0, _ := ret[0].([]*model.Group)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetGroupsByUserId indicates an expected call of GetGroupsByUserId.
+func (mr *MockClientMockRecorder) GetGroupsByUserId(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupsByUserId", reflect.TypeOf((*MockClient)(nil).GetGroupsByUserId), arg0)
+}
+
 // GetIncomingWebhook mocks base method.
 func (m *MockClient) GetIncomingWebhook(arg0, arg1 string) (*model.IncomingWebhook, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1054,6 +1133,54 @@ func (mr *MockClientMockRecorder) GetPostsSince(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostsSince", reflect.TypeOf((*MockClient)(nil).GetPostsSince), arg0, arg1, arg2)
 }
 
+// GetPreferenceByCategoryAndName mocks base method.
+func (m *MockClient) GetPreferenceByCategoryAndName(arg0, arg1, arg2 string) (*model.Preference, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferenceByCategoryAndName", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.Preference)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPreferenceByCategoryAndName indicates an expected call of GetPreferenceByCategoryAndName.
+func (mr *MockClientMockRecorder) GetPreferenceByCategoryAndName(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferenceByCategoryAndName", reflect.TypeOf((*MockClient)(nil).GetPreferenceByCategoryAndName), arg0, arg1, arg2)
+}
+
+// GetPreferences mocks base method.
+func (m *MockClient) GetPreferences(arg0 string) (model.Preferences, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", arg0)
+	ret0, _ := ret[0].(model.Preferences)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPreferences indicates an expected call of GetPreferences.
+func (mr *MockClientMockRecorder) GetPreferences(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockClient)(nil).GetPreferences), arg0)
+}
+
+// GetPreferencesByCategory mocks base method.
+func (m *MockClient) GetPreferencesByCategory(arg0, arg1 string) (model.Preferences, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferencesByCategory", arg0, arg1)
+	ret0, _ := ret[0].(model.Preferences)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPreferencesByCategory indicates an expected call of GetPreferencesByCategory.
+func (mr *MockClientMockRecorder) GetPreferencesByCategory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferencesByCategory", reflect.TypeOf((*MockClient)(nil).GetPreferencesByCategory), arg0, arg1)
+}
+
 // GetPrivateChannelsForTeam mocks base method.
 func (m *MockClient) GetPrivateChannelsForTeam(arg0 string, arg1, arg2 int, arg3 string) ([]*model.Channel, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1070,6 +1197,22 @@ func (mr *MockClientMockRecorder) GetPrivateChannelsForTeam(arg0, arg1, arg2, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrivateChannelsForTeam", reflect.TypeOf((*MockClient)(nil).GetPrivateChannelsForTeam), arg0, arg1, arg2, arg3)
 }
 
+// GetProfileImage mocks base method.
+func (m *MockClient) GetProfileImage(arg0, arg1 string) ([]byte, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfileImage", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetProfileImage indicates an expected call of GetProfileImage.
+func (mr *MockClientMockRecorder) GetProfileImage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfileImage", reflect.TypeOf((*MockClient)(nil).GetProfileImage), arg0, arg1)
+}
+
 // GetPublicChannelsForTeam mocks base method.
 func (m *MockClient) GetPublicChannelsForTeam(arg0 string, arg1, arg2 int, arg3 string) ([]*model.Channel, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1118,6 +1261,22 @@ func (mr *MockClientMockRecorder) GetServerBusy() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServerBusy", reflect.TypeOf((*MockClient)(nil).GetServerBusy))
 }
 
+// GetSessions mocks base method.
+func (m *MockClient) GetSessions(arg0, arg1 string) ([]*model.Session, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", arg0, arg1)
+	ret0, _ := ret[0].([]*model.Session)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockClientMockRecorder) GetSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockClient)(nil).GetSessions), arg0, arg1)
+}
+
 // GetTeam mocks base method.
 func (m *MockClient) GetTeam(arg0, arg1 string) (*model.Team, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1150,6 +1309,54 @@ func (mr *MockClientMockRecorder) GetTeamByName(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamByName", reflect.TypeOf((*MockClient)(nil).GetTeamByName), arg0, arg1)
 }
 
+// GetTeamIcon mocks base method.
+func (m *MockClient) GetTeamIcon(arg0, arg1 string) ([]byte, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTeamIcon", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTeamIcon indicates an expected call of GetTeamIcon.
+func (mr *MockClientMockRecorder) GetTeamIcon(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamIcon", reflect.TypeOf((*MockClient)(nil).GetTeamIcon), arg0, arg1)
+}
+
+// GetTeamMembers mocks base method.
+func (m *MockClient) GetTeamMembers(arg0 string, arg1, arg2 int, arg3 string) ([]*model.TeamMember, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTeamMembers", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*model.TeamMember)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTeamMembers indicates an expected call of GetTeamMembers.
+func (mr *MockClientMockRecorder) GetTeamMembers(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamMembers", reflect.TypeOf((*MockClient)(nil).GetTeamMembers), arg0, arg1, arg2, arg3)
+}
+
+// GetTeamsForUser mocks base method.
+func (m *MockClient) GetTeamsForUser(arg0, arg1 string) ([]*model.Team, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTeamsForUser", arg0, arg1)
+	ret0, _ := ret[0].([]*model.Team)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTeamsForUser indicates an expected call of GetTeamsForUser.
+func (mr *MockClientMockRecorder) GetTeamsForUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamsForUser", reflect.TypeOf((*MockClient)(nil).GetTeamsForUser), arg0, arg1)
+}
+
 // GetUpload mocks base method.
 func (m *MockClient) GetUpload(arg0 string) (*model.UploadSession, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1214,6 +1421,22 @@ func (mr *MockClientMockRecorder) GetUserAccessTokensForUser(arg0, arg1, arg2 in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAccessTokensForUser", reflect.TypeOf((*MockClient)(nil).GetUserAccessTokensForUser), arg0, arg1, arg2)
 }
 
+// GetUserAudits mocks base method.
+func (m *MockClient) GetUserAudits(arg0 string, arg1, arg2 int, arg3 string) (model.Audits, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAudits", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(model.Audits)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUserAudits indicates an expected call of GetUserAudits.
+func (mr *MockClientMockRecorder) GetUserAudits(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAudits", reflect.TypeOf((*MockClient)(nil).GetUserAudits), arg0, arg1, arg2, arg3)
+}
+
 // GetUserByEmail mocks base method.
 func (m *MockClient) GetUserByEmail(arg0, arg1 string) (*model.User, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1560,6 +1783,22 @@ func (mr *MockClientMockRecorder) PatchTeam(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchTeam", reflect.TypeOf((*MockClient)(nil).PatchTeam), arg0, arg1)
 }
 
+// PatchUser mocks base method.
+func (m *MockClient) PatchUser(arg0 string, arg1 *model.UserPatch) (*model.User, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchUser", arg0, arg1)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PatchUser indicates an expected call of PatchUser.
+func (mr *MockClientMockRecorder) PatchUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchUser", reflect.TypeOf((*MockClient)(nil).PatchUser), arg0, arg1)
+}
+
 // PermanentDeleteAllUsers mocks base method.
 func (m *MockClient) PermanentDeleteAllUsers() (*model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1696,6 +1935,21 @@ func (mr *MockClientMockRecorder) RemovePlugin(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePlugin", reflect.TypeOf((*MockClient)(nil).RemovePlugin), arg0)
 }
 
+// RemoveTeamIcon mocks base method.
+func (m *MockClient) RemoveTeamIcon(arg0 string) (*model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTeamIcon", arg0)
+	ret0, _ := ret[0].(*model.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveTeamIcon indicates an expected call of RemoveTeamIcon.
+func (mr *MockClientMockRecorder) RemoveTeamIcon(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTeamIcon", reflect.TypeOf((*MockClient)(nil).RemoveTeamIcon), arg0)
+}
+
 // RemoveTeamMember mocks base method.
 func (m *MockClient) RemoveTeamMember(arg0, arg1 string) (*model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1790,6 +2044,51 @@ func (mr *MockClientMockRecorder) RestoreTeam(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTeam", reflect.TypeOf((*MockClient)(nil).RestoreTeam), arg0)
 }
 
+// RevokeAllSessions mocks base method.
+func (m *MockClient) RevokeAllSessions(arg0 string) (*model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessions", arg0)
+	ret0, _ := ret[0].(*model.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions.
+func (mr *MockClientMockRecorder) RevokeAllSessions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockClient)(nil).RevokeAllSessions), arg0)
+}
+
+// RevokeSession mocks base method.
+func (m *MockClient) RevokeSession(arg0, arg1 string) (*model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", arg0, arg1)
+	ret0, _ := ret[0].(*model.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockClientMockRecorder) RevokeSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockClient)(nil).RevokeSession), arg0, arg1)
+}
+
+// RevokeSessionsFromAllUsers mocks base method.
+func (m *MockClient) RevokeSessionsFromAllUsers() (*model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessionsFromAllUsers")
+	ret0, _ := ret[0].(*model.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSessionsFromAllUsers indicates an expected call of RevokeSessionsFromAllUsers.
+func (mr *MockClientMockRecorder) RevokeSessionsFromAllUsers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessionsFromAllUsers", reflect.TypeOf((*MockClient)(nil).RevokeSessionsFromAllUsers))
+}
+
 // RevokeUserAccessToken mocks base method.
 func (m *MockClient) RevokeUserAccessToken(arg0 string) (*model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1805,6 +2104,22 @@ func (mr *MockClientMockRecorder) RevokeUserAccessToken(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserAccessToken", reflect.TypeOf((*MockClient)(nil).RevokeUserAccessToken), arg0)
 }
 
+// SearchPostsWithParams mocks base method.
+func (m *MockClient) SearchPostsWithParams(arg0 string, arg1 *model.SearchParameter) (*model.PostList, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPostsWithParams", arg0, arg1)
+	ret0, _ := ret[0].(*model.PostList)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchPostsWithParams indicates an expected call of SearchPostsWithParams.
+func (mr *MockClientMockRecorder) SearchPostsWithParams(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPostsWithParams", reflect.TypeOf((*MockClient)(nil).SearchPostsWithParams), arg0, arg1)
+}
+
 // SearchTeams mocks base method.
 func (m *MockClient) SearchTeams(arg0 *model.TeamSearch) ([]*model.Team, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1836,6 +2151,36 @@ func (mr *MockClientMockRecorder) SendPasswordResetEmail(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPasswordResetEmail", reflect.TypeOf((*MockClient)(nil).SendPasswordResetEmail), arg0)
 }
 
+// SetDefaultProfileImage mocks base method.
+func (m *MockClient) SetDefaultProfileImage(arg0 string) (*model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDefaultProfileImage", arg0)
+	ret0, _ := ret[0].(*model.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetDefaultProfileImage indicates an expected call of SetDefaultProfileImage.
+func (mr *MockClientMockRecorder) SetDefaultProfileImage(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDefaultProfileImage", reflect.TypeOf((*MockClient)(nil).SetDefaultProfileImage), arg0)
+}
+
+// SetProfileImage mocks base method.
+func (m *MockClient) SetProfileImage(arg0 string, arg1 []byte) (*model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetProfileImage", arg0, arg1)
+	ret0, _ := ret[0].(*model.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetProfileImage indicates an expected call of SetProfileImage.
+func (mr *MockClientMockRecorder) SetProfileImage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetProfileImage", reflect.TypeOf((*MockClient)(nil).SetProfileImage), arg0, arg1)
+}
+
 // SetServerBusy mocks base method.
 func (m *MockClient) SetServerBusy(arg0 int) (*model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1851,6 +2196,21 @@ func (mr *MockClientMockRecorder) SetServerBusy(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetServerBusy", reflect.TypeOf((*MockClient)(nil).SetServerBusy), arg0)
 }
 
+// SetTeamIcon mocks base method.
+func (m *MockClient) SetTeamIcon(arg0 string, arg1 []byte) (*model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTeamIcon", arg0, arg1)
+	ret0, _ := ret[0].(*model.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetTeamIcon indicates an expected call of SetTeamIcon.
+func (mr *MockClientMockRecorder) SetTeamIcon(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTeamIcon", reflect.TypeOf((*MockClient)(nil).SetTeamIcon), arg0, arg1)
+}
+
 // SoftDeleteTeam mocks base method.
 func (m *MockClient) SoftDeleteTeam(arg0 string) (*model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1897,6 +2257,21 @@ func (mr *MockClientMockRecorder) UpdateChannelPrivacy(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChannelPrivacy", reflect.TypeOf((*MockClient)(nil).UpdateChannelPrivacy), arg0, arg1)
 }
 
+// UpdateChannelRoles mocks base method.
+func (m *MockClient) UpdateChannelRoles(arg0, arg1, arg2 string) (*model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChannelRoles", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateChannelRoles indicates an expected call of UpdateChannelRoles.
+func (mr *MockClientMockRecorder) UpdateChannelRoles(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChannelRoles", reflect.TypeOf((*MockClient)(nil).UpdateChannelRoles), arg0, arg1, arg2)
+}
+
 // UpdateCommand mocks base method.
 func (m *MockClient) UpdateCommand(arg0 *model.Command) (*model.Command, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1961,6 +2336,21 @@ func (mr *MockClientMockRecorder) UpdateOutgoingWebhook(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOutgoingWebhook", reflect.TypeOf((*MockClient)(nil).UpdateOutgoingWebhook), arg0)
 }
 
+// UpdatePreferences mocks base method.
+func (m *MockClient) UpdatePreferences(arg0 string, arg1 model.Preferences) (*model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreferences", arg0, arg1)
+	ret0, _ := ret[0].(*model.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePreferences indicates an expected call of UpdatePreferences.
+func (mr *MockClientMockRecorder) UpdatePreferences(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreferences", reflect.TypeOf((*MockClient)(nil).UpdatePreferences), arg0, arg1)
+}
+
 // UpdateTeam mocks base method.
 func (m *MockClient) UpdateTeam(arg0 *model.Team) (*model.Team, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1977,6 +2367,21 @@ func (mr *MockClientMockRecorder) UpdateTeam(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTeam", reflect.TypeOf((*MockClient)(nil).UpdateTeam), arg0)
 }
 
+// UpdateTeamMemberRoles mocks base method.
+func (m *MockClient) UpdateTeamMemberRoles(arg0, arg1, arg2 string) (*model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTeamMemberRoles", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTeamMemberRoles indicates an expected call of UpdateTeamMemberRoles.
+func (mr *MockClientMockRecorder) UpdateTeamMemberRoles(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTeamMemberRoles", reflect.TypeOf((*MockClient)(nil).UpdateTeamMemberRoles), arg0, arg1, arg2)
+}
+
 // UpdateTeamPrivacy mocks base method.
 func (m *MockClient) UpdateTeamPrivacy(arg0, arg1 string) (*model.Team, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -2147,6 +2552,22 @@ func (mr *MockClientMockRecorder) UploadPluginForced(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadPluginForced", reflect.TypeOf((*MockClient)(nil).UploadPluginForced), arg0)
 }
 
+// UpsertGroupMembers mocks base method.
+func (m *MockClient) UpsertGroupMembers(arg0 string, arg1 *model.GroupModifyMembers) ([]*model.GroupMember, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertGroupMembers", arg0, arg1)
+	ret0, _ := ret[0].([]*model.GroupMember)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpsertGroupMembers indicates an expected call of UpsertGroupMembers.
+func (mr *MockClientMockRecorder) UpsertGroupMembers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertGroupMembers", reflect.TypeOf((*MockClient)(nil).UpsertGroupMembers), arg0, arg1)
+}
+
 // VerifyUserEmailWithoutToken mocks base method.
 func (m *MockClient) VerifyUserEmailWithoutToken(arg0 string) (*model.User, *model.Response, error) {
 	m.ctrl.T.Helper()
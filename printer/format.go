@@ -0,0 +1,251 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package printer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// recordValueColumn is the column used for the lines that are not
+// objects, such as plain messages
+const recordValueColumn = "value"
+
+// Formats returns the list of the supported output formats
+func Formats() []string {
+	return []string{FormatPlain, FormatJSON, FormatYAML, FormatCSV, FormatTSV, FormatTable, FormatTemplate}
+}
+
+// IsValidFormat checks if the format is one of the supported ones
+func IsValidFormat(format string) bool {
+	for _, f := range Formats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// record is a line flattened into a set of columns
+type record map[string]string
+
+// toRecords flattens the lines into records, returning the columns
+// in the order they first appear in the JSON representation of the
+// lines
+func toRecords(lines []interface{}) ([]string, []record, error) {
+	columns := []string{}
+	seen := map[string]bool{}
+	records := make([]record, 0, len(lines))
+
+	for _, line := range lines {
+		b, err := json.Marshal(line)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		keys, values, isObject, err := decodeOrderedObject(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !isObject {
+			keys = []string{recordValueColumn}
+			values = []string{rawToString(b)}
+		}
+
+		r := record{}
+		for i, key := range keys {
+			r[key] = values[i]
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+		records = append(records, r)
+	}
+
+	return columns, records, nil
+}
+
+// decodeOrderedObject decodes the keys and values of a JSON object
+// keeping the order of its fields
+func decodeOrderedObject(b []byte) (keys, values []string, isObject bool, err error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, nil, false, nil
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, false, err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, false, err
+		}
+		keys = append(keys, fmt.Sprint(tok))
+		values = append(values, rawToString(raw))
+	}
+
+	return keys, values, true, nil
+}
+
+// rawToString converts a raw JSON value into the text shown in a
+// cell. Strings are unquoted and nulls become empty cells, while
+// objects and arrays are kept as compact JSON
+func rawToString(raw json.RawMessage) string {
+	trimmed := bytes.TrimSpace(raw)
+	switch {
+	case bytes.Equal(trimmed, []byte("null")):
+		return ""
+	case len(trimmed) > 0 && trimmed[0] == '"':
+		var s string
+		if err := json.Unmarshal(trimmed, &s); err == nil {
+			return s
+		}
+	case len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '['):
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, trimmed); err == nil {
+			return buf.String()
+		}
+	}
+	return string(trimmed)
+}
+
+// selectColumns returns the requested columns, or all of them if
+// none were requested
+func selectColumns(available, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return available, nil
+	}
+
+	exists := map[string]bool{}
+	for _, c := range available {
+		exists[c] = true
+	}
+
+	for _, c := range requested {
+		if !exists[c] && len(available) > 0 {
+			sorted := append([]string{}, available...)
+			sort.Strings(sorted)
+			return nil, fmt.Errorf("column %q not found, available columns are: %s", c, strings.Join(sorted, ", "))
+		}
+	}
+
+	return requested, nil
+}
+
+func recordsToDelimited(lines []interface{}, requested []string, comma rune) ([]byte, error) {
+	available, records, err := toRecords(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := selectColumns(available, requested)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = comma
+	if len(records) > 0 {
+		if err := w.Write(columns); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range records {
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = r[c]
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+
+	return buf.Bytes(), w.Error()
+}
+
+func recordsToTable(lines []interface{}, requested []string) ([]byte, error) {
+	available, records, err := toRecords(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := selectColumns(available, requested)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, r := range records {
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			// tabs and newlines would break the alignment
+			row[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(r[c])
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func linesToYAML(v interface{}) ([]byte, error) {
+	// the lines are converted through JSON so the YAML output uses
+	// the same field names as the JSON one
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(generic)
+}
+
+func linesToTemplate(lines []interface{}, tpl *template.Template, newline string) ([]byte, error) {
+	if tpl == nil {
+		return nil, fmt.Errorf("a template is required for the %q format", FormatTemplate)
+	}
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		if err := tpl.Execute(&buf, line); err != nil {
+			return nil, fmt.Errorf("can't print the output using the provided template: %w", err)
+		}
+		buf.WriteString(newline)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package printer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type formatTestUser struct {
+	ID       string   `json:"id"`
+	Username string   `json:"username"`
+	Roles    []string `json:"roles,omitempty"`
+	Nickname string   `json:"nickname"`
+}
+
+func flushWithFormat(t *testing.T, format string, columns []string, lines ...interface{}) string {
+	t.Helper()
+
+	mw := &mockWriter{}
+	printer.writer = mw
+	printer.Format = format
+	printer.NoNewline = false
+	printer.Single = false
+	SetColumns(columns)
+	defer SetColumns(nil)
+
+	Clean()
+	for _, line := range lines {
+		PrintT("{{.Username}}", line)
+	}
+	require.NoError(t, Flush())
+	return string(*mw)
+}
+
+func TestFlushFormats(t *testing.T) {
+	users := []interface{}{
+		formatTestUser{ID: "1", Username: "alice", Roles: []string{"system_admin"}},
+		formatTestUser{ID: "2", Username: "bob", Nickname: "Bob, the builder"},
+	}
+
+	t.Run("should print csv with the fields in order", func(t *testing.T) {
+		out := flushWithFormat(t, FormatCSV, nil, users...)
+		assert.Equal(t, "id,username,roles,nickname\n1,alice,\"[\"\"system_admin\"\"]\",\n2,bob,,\"Bob, the builder\"\n", out)
+	})
+
+	t.Run("should print tsv with the selected columns", func(t *testing.T) {
+		out := flushWithFormat(t, FormatTSV, []string{"username", "id"}, users...)
+		assert.Equal(t, "username\tid\nalice\t1\nbob\t2\n", out)
+	})
+
+	t.Run("should print an aligned table", func(t *testing.T) {
+		out := flushWithFormat(t, FormatTable, []string{"id", "username"}, users...)
+		assert.Equal(t, "ID  USERNAME\n1   alice\n2   bob\n", out)
+	})
+
+	t.Run("should print yaml using the json field names", func(t *testing.T) {
+		out := flushWithFormat(t, FormatYAML, nil, users[0])
+		assert.Equal(t, "- id: \"1\"\n  nickname: \"\"\n  roles:\n    - system_admin\n  username: alice\n", out)
+	})
+
+	t.Run("should print plain messages in a value column", func(t *testing.T) {
+		out := flushWithFormat(t, FormatCSV, nil, "a message")
+		assert.Equal(t, "value\na message\n", out)
+	})
+
+	t.Run("should fail on an unknown column", func(t *testing.T) {
+		mw := &mockWriter{}
+		printer.writer = mw
+		printer.Format = FormatTable
+		SetColumns([]string{"email"})
+		defer SetColumns(nil)
+
+		Clean()
+		PrintT("", users[0])
+		err := Flush()
+		require.EqualError(t, err, `column "email" not found, available columns are: id, nickname, roles, username`)
+		Clean()
+	})
+}
+
+func TestFlushTemplate(t *testing.T) {
+	t.Run("should execute the user template for each line", func(t *testing.T) {
+		require.NoError(t, SetTemplate("{{.Username}} ({{.ID}})"))
+		defer func() { printer.userTemplate = nil }()
+
+		out := flushWithFormat(t, FormatTemplate, nil, formatTestUser{ID: "1", Username: "alice"}, formatTestUser{ID: "2", Username: "bob"})
+		assert.Equal(t, "alice (1)\nbob (2)\n", out)
+	})
+
+	t.Run("should fail to parse an invalid template", func(t *testing.T) {
+		err := SetTemplate("{{.Username")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid template")
+	})
+}
+
+func TestFlushQuery(t *testing.T) {
+	users := []interface{}{
+		formatTestUser{ID: "1", Username: "alice", Roles: []string{"system_admin"}},
+		formatTestUser{ID: "2", Username: "bob"},
+	}
+
+	t.Run("should filter the lines before printing them", func(t *testing.T) {
+		require.NoError(t, SetQuery("[?roles].username"))
+		defer func() { _ = SetQuery("") }()
+
+		out := flushWithFormat(t, FormatJSON, nil, users...)
+		assert.Equal(t, "[\n  \"alice\"\n]\n", out)
+	})
+
+	t.Run("should combine the query with other formats", func(t *testing.T) {
+		require.NoError(t, SetQuery("[].{name: username, id: id}"))
+		defer func() { _ = SetQuery("") }()
+
+		out := flushWithFormat(t, FormatCSV, []string{"name"}, users...)
+		assert.Equal(t, "name\nalice\nbob\n", out)
+	})
+
+	t.Run("should print scalar results in plain format", func(t *testing.T) {
+		require.NoError(t, SetQuery("length(@)"))
+		defer func() { _ = SetQuery("") }()
+
+		out := flushWithFormat(t, FormatPlain, nil, users...)
+		assert.Equal(t, "2\n", out)
+	})
+
+	t.Run("should fail to compile an invalid query", func(t *testing.T) {
+		err := SetQuery("[?roles")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid query "[?roles"`)
+	})
+
+	t.Run("should fail to evaluate a query with invalid arguments", func(t *testing.T) {
+		require.NoError(t, SetQuery("length(`1`)"))
+		defer func() { _ = SetQuery("") }()
+
+		printer.Format = FormatJSON
+		Clean()
+		Print("line")
+		require.Error(t, Flush())
+		assert.Empty(t, GetLines())
+	})
+}
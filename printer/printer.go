@@ -14,12 +14,18 @@ import (
 	"text/template"
 
 	"github.com/fatih/color"
+	"github.com/jmespath/go-jmespath"
 	"github.com/spf13/cobra"
 )
 
 const (
-	FormatPlain = "plain"
-	FormatJSON  = "json"
+	FormatPlain    = "plain"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatCSV      = "csv"
+	FormatTSV      = "tsv"
+	FormatTable    = "table"
+	FormatTemplate = "template"
 )
 
 type Printer struct { //nolint
@@ -30,6 +36,9 @@ type Printer struct { //nolint
 	Single        bool
 	NoNewline     bool
 	templateFuncs template.FuncMap
+	userTemplate  *template.Template
+	query         *jmespath.JMESPath
+	columns       []string
 	pager         bool
 	Quiet         bool
 	Lines         []interface{}
@@ -46,6 +55,8 @@ type printOpts struct {
 	usePager  bool
 	shortStat bool
 	noNewline bool
+	columns   []string
+	template  *template.Template
 }
 
 var printer Printer
@@ -62,6 +73,23 @@ func SetFormat(t string) {
 	printer.Format = t
 }
 
+// SetColumns sets the columns, and their order, used by the table,
+// csv and tsv formats. If no columns are set, all of them are used
+func SetColumns(columns []string) {
+	printer.columns = columns
+}
+
+// SetTemplate parses the template used to print each element when
+// the template format is used
+func SetTemplate(templateString string) error {
+	tpl, err := template.New("").Funcs(printer.templateFuncs).Parse(templateString)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	printer.userTemplate = tpl
+	return nil
+}
+
 func SetCommand(cmd *cobra.Command) {
 	printer.cmd = cmd
 }
@@ -104,35 +132,36 @@ func PrintT(templateString string, v interface{}) {
 	if printer.Quiet {
 		return
 	}
-	switch printer.Format {
-	case FormatPlain:
-		tpl := template.Must(template.New("").Funcs(printer.templateFuncs).Parse(templateString))
-		sb := &strings.Builder{}
-		if err := tpl.Execute(sb, v); err != nil {
-			PrintError("Can't print the message using the provided template: " + templateString)
-			return
-		}
-		printer.Lines = append(printer.Lines, sb.String())
-	case FormatJSON:
+	// structured formats and queries work with the element itself
+	if printer.Format != FormatPlain || printer.query != nil {
 		printer.Lines = append(printer.Lines, v)
+		return
 	}
+
+	tpl := template.Must(template.New("").Funcs(printer.templateFuncs).Parse(templateString))
+	sb := &strings.Builder{}
+	if err := tpl.Execute(sb, v); err != nil {
+		PrintError("Can't print the message using the provided template: " + templateString)
+		return
+	}
+	printer.Lines = append(printer.Lines, sb.String())
 }
 
 func PrintPreparedT(tpl *template.Template, v interface{}) {
 	if printer.Quiet {
 		return
 	}
-	switch printer.Format {
-	case FormatPlain:
-		sb := &strings.Builder{}
-		if err := tpl.Execute(sb, v); err != nil {
-			PrintError("Can't print the message using the provided template: " + err.Error())
-			return
-		}
-		printer.Lines = append(printer.Lines, sb.String())
-	case FormatJSON:
+	if printer.Format != FormatPlain || printer.query != nil {
 		printer.Lines = append(printer.Lines, v)
+		return
 	}
+
+	sb := &strings.Builder{}
+	if err := tpl.Execute(sb, v); err != nil {
+		PrintError("Can't print the message using the provided template: " + err.Error())
+		return
+	}
+	printer.Lines = append(printer.Lines, sb.String())
 }
 
 // Print an element. If the format requires a template, the element
@@ -152,6 +181,8 @@ func Flush() error {
 		format:    printer.Format,
 		single:    printer.Single,
 		noNewline: printer.NoNewline,
+		columns:   printer.columns,
+		template:  printer.userTemplate,
 	}
 
 	cmd := printer.cmd
@@ -162,7 +193,19 @@ func Flush() error {
 		}
 	}
 
-	b, err := printer.linesToBytes(opts)
+	p := printer
+	if p.query != nil {
+		lines, single, err := applyQuery(p.query, p.Lines, opts.single, opts.format)
+		if err != nil {
+			printer.printErrors()
+			Clean()
+			return err
+		}
+		p.Lines = lines
+		opts.single = single
+	}
+
+	b, err := p.linesToBytes(opts)
 	if err != nil {
 		return err
 	}
@@ -286,6 +329,23 @@ func (p Printer) linesToBytes(opts printOpts) (b []byte, err error) {
 			b, err = json.MarshalIndent(p.Lines, "", "  ")
 		}
 		b = append(b, '\n')
+	case FormatYAML:
+		switch {
+		case opts.single && len(p.Lines) == 0:
+			return
+		case opts.single && len(p.Lines) == 1:
+			b, err = linesToYAML(p.Lines[0])
+		default:
+			b, err = linesToYAML(p.Lines)
+		}
+	case FormatCSV:
+		b, err = recordsToDelimited(p.Lines, opts.columns, ',')
+	case FormatTSV:
+		b, err = recordsToDelimited(p.Lines, opts.columns, '\t')
+	case FormatTable:
+		b, err = recordsToTable(p.Lines, opts.columns)
+	case FormatTemplate:
+		b, err = linesToTemplate(p.Lines, opts.template, newline)
 	}
 	return
 }
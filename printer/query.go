@@ -0,0 +1,86 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// SetQuery compiles the JMESPath expression that will be evaluated
+// over the accumulated lines before printing them. An empty
+// expression disables the query
+func SetQuery(expression string) error {
+	if expression == "" {
+		printer.query = nil
+		return nil
+	}
+
+	query, err := jmespath.Compile(expression)
+	if err != nil {
+		return fmt.Errorf("invalid query %q: %w", expression, err)
+	}
+	printer.query = query
+	return nil
+}
+
+// applyQuery evaluates the query over the lines, returning the
+// resulting lines and whether they should be printed as a single
+// element
+func applyQuery(query *jmespath.JMESPath, lines []interface{}, single bool, format string) ([]interface{}, bool, error) {
+	var data interface{} = lines
+	if single && len(lines) == 1 {
+		data = lines[0]
+	}
+
+	// the query is evaluated over the JSON representation of the
+	// lines, so the field names match the ones of the json format
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, false, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return nil, false, err
+	}
+
+	result, err := query.Search(generic)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not evaluate the query: %w", err)
+	}
+
+	var queried []interface{}
+	if list, ok := result.([]interface{}); ok {
+		queried = list
+		single = false
+	} else {
+		queried = []interface{}{result}
+		single = true
+	}
+
+	if format == FormatPlain {
+		for i := range queried {
+			queried[i] = queryResultToString(queried[i])
+		}
+	}
+
+	return queried, single, nil
+}
+
+func queryResultToString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case map[string]interface{}, []interface{}:
+		b, _ := json.MarshalIndent(t, "", "  ")
+		return string(b)
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}